@@ -2,6 +2,13 @@ package aggregator
 
 import (
 	"context"
+	"iter"
+
+	"github.com/matiniiuu/mongox/callback"
+	mxerrors "github.com/matiniiuu/mongox/errors"
+	"github.com/matiniiuu/mongox/internal/pkg/utils"
+	"github.com/matiniiuu/mongox/operation"
+	"github.com/matiniiuu/mongox/softdelete"
 
 	"go.mongodb.org/mongo-driver/v2/mongo"
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
@@ -16,13 +23,18 @@ type IAggregator[T any] interface {
 var _ IAggregator[any] = (*Aggregator[any])(nil)
 
 type Aggregator[T any] struct {
-	collection *mongo.Collection
-	pipeline   any
+	collection  *mongo.Collection
+	pipeline    any
+	beforeHooks []beforeHookFn
+	afterHooks  []afterHookFn[T]
+	softDelete  bool
+	scope       softdelete.Scope
 }
 
 func NewAggregator[T any](collection *mongo.Collection) *Aggregator[T] {
 	return &Aggregator[T]{
 		collection: collection,
+		softDelete: softdelete.Enabled(),
 	}
 }
 
@@ -31,15 +43,102 @@ func (a *Aggregator[T]) Pipeline(pipeline any) *Aggregator[T] {
 	return a
 }
 
+func (a *Aggregator[T]) RegisterBeforeHooks(hooks ...beforeHookFn) *Aggregator[T] {
+	a.beforeHooks = append(a.beforeHooks, hooks...)
+	return a
+}
+
+// RegisterAfterHooks is used to set the after hooks of the aggregation
+// If you register the hook for Aggregate, only opContext.Docs is set
+// If you register the hook for AggregateWithParse, only opContext.Result is set
+// If you register the hook for Iter, only opContext.Doc is set and the hook runs for every decoded document
+func (a *Aggregator[T]) RegisterAfterHooks(hooks ...afterHookFn[T]) *Aggregator[T] {
+	a.afterHooks = append(a.afterHooks, hooks...)
+	return a
+}
+
+// SoftDelete is used to turn the soft delete mode on or off for this aggregator
+func (a *Aggregator[T]) SoftDelete(enabled bool) *Aggregator[T] {
+	a.softDelete = enabled
+	return a
+}
+
+// WithDeleted makes the pipeline also receive soft deleted documents
+func (a *Aggregator[T]) WithDeleted() *Aggregator[T] {
+	a.scope = softdelete.ScopeWithDeleted
+	return a
+}
+
+// OnlyDeleted makes the pipeline only receive soft deleted documents
+func (a *Aggregator[T]) OnlyDeleted() *Aggregator[T] {
+	a.scope = softdelete.ScopeOnlyDeleted
+	return a
+}
+
+// scopedPipeline returns the pipeline with a leading $match stage for the soft delete scope
+func (a *Aggregator[T]) scopedPipeline() any {
+	if !a.softDelete {
+		return a.pipeline
+	}
+	return softdelete.Pipeline(a.pipeline, a.scope)
+}
+
+// preActionHandler runs the global before callbacks and then the before hooks
+// The pipeline changed by the callbacks is passed on to the hooks through opContext.Pipeline
+func (a *Aggregator[T]) preActionHandler(ctx context.Context, globalOpContext *operation.OpContext, opContext *OpContext) error {
+	err := callback.GetCallback().Execute(ctx, globalOpContext, operation.OpTypeBeforeAggregate)
+	if err != nil {
+		return err
+	}
+	opContext.Pipeline = globalOpContext.Pipeline
+	for i, beforeHook := range a.beforeHooks {
+		err = beforeHook(ctx, opContext)
+		if err != nil {
+			return mxerrors.Hook(mxerrors.HookName(beforeHook, i), operation.OpTypeBeforeAggregate, err)
+		}
+	}
+	return nil
+}
+
+func (a *Aggregator[T]) postActionHandler(ctx context.Context, globalOpContext *operation.OpContext, opContext *AfterOpContext[T], opType operation.OpType) error {
+	err := callback.GetCallback().Execute(ctx, globalOpContext, opType)
+	if err != nil {
+		return err
+	}
+	for i, afterHook := range a.afterHooks {
+		err = afterHook(ctx, opContext)
+		if err != nil {
+			return mxerrors.Hook(mxerrors.HookName(afterHook, i), opType, err)
+		}
+	}
+	return nil
+}
+
+// Aggregate is used to run the aggregation and decode all its documents
+// The global after aggregate callbacks and the after hooks run once with all the documents, the after find callbacks
+// such as the model hooks do not run for them, see Iter for the streaming counterpart
 func (a *Aggregator[T]) Aggregate(ctx context.Context, opts ...options.Lister[options.AggregateOptions]) ([]*T, error) {
-	cursor, err := a.collection.Aggregate(ctx, a.pipeline, opts...)
+	globalOpContext := operation.NewOpContext(a.collection, operation.WithPipeline(a.scopedPipeline()), operation.WithMongoOptions(opts))
+	opContext := NewOpContext(a.collection, nil, WithMongoOptions(opts))
+	err := a.preActionHandler(ctx, globalOpContext, opContext)
 	if err != nil {
 		return nil, err
 	}
+
+	cursor, err := a.collection.Aggregate(ctx, opContext.Pipeline, opts...)
+	if err != nil {
+		return nil, mxerrors.Wrap(err)
+	}
 	defer cursor.Close(ctx)
 
 	result := make([]*T, 0)
 	err = cursor.All(ctx, &result)
+	if err != nil {
+		return nil, mxerrors.Wrap(err)
+	}
+
+	globalOpContext.Doc = result
+	err = a.postActionHandler(ctx, globalOpContext, NewAfterOpContext[T](opContext, WithDocs(result)), operation.OpTypeAfterAggregate)
 	if err != nil {
 		return nil, err
 	}
@@ -48,15 +147,84 @@ func (a *Aggregator[T]) Aggregate(ctx context.Context, opts ...options.Lister[op
 
 // AggregateWithParse is used to parse the result of the aggregation
 // result must be a pointer to a slice
+// The callbacks and hooks run as for Aggregate
 func (a *Aggregator[T]) AggregateWithParse(ctx context.Context, result any, opts ...options.Lister[options.AggregateOptions]) error {
-	cursor, err := a.collection.Aggregate(ctx, a.pipeline, opts...)
+	globalOpContext := operation.NewOpContext(a.collection, operation.WithPipeline(a.scopedPipeline()), operation.WithMongoOptions(opts))
+	opContext := NewOpContext(a.collection, nil, WithMongoOptions(opts))
+	err := a.preActionHandler(ctx, globalOpContext, opContext)
 	if err != nil {
 		return err
 	}
+
+	cursor, err := a.collection.Aggregate(ctx, opContext.Pipeline, opts...)
+	if err != nil {
+		return mxerrors.Wrap(err)
+	}
 	defer cursor.Close(ctx)
 	err = cursor.All(ctx, result)
 	if err != nil {
-		return err
+		return mxerrors.Wrap(err)
 	}
-	return nil
+
+	globalOpContext.Doc = result
+	return a.postActionHandler(ctx, globalOpContext, NewAfterOpContext[T](opContext, WithResult[T](result)), operation.OpTypeAfterAggregate)
+}
+
+// Iter is used to stream the result of the aggregation instead of loading it all into memory
+// The before callbacks and hooks run once before the aggregation, the global after find callbacks and the after hooks
+// run for every decoded document, so unlike Aggregate the model hooks of the documents run, and the global after
+// aggregate callbacks run once the cursor is exhausted, with a nil opContext.Doc since the result is never complete
+// in memory, they do not run if the loop breaks early or the iteration fails
+// The cursor is closed once the iteration finishes or the loop breaks early
+func (a *Aggregator[T]) Iter(ctx context.Context, opts ...options.Lister[options.AggregateOptions]) iter.Seq2[*T, error] {
+	return func(yield func(*T, error) bool) {
+		globalOpContext := operation.NewOpContext(a.collection, operation.WithPipeline(a.scopedPipeline()), operation.WithMongoOptions(opts))
+		opContext := NewOpContext(a.collection, nil, WithMongoOptions(opts))
+		err := a.preActionHandler(ctx, globalOpContext, opContext)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		cursor, err := a.collection.Aggregate(ctx, opContext.Pipeline, opts...)
+		if err != nil {
+			yield(nil, mxerrors.Wrap(err))
+			return
+		}
+		defer cursor.Close(ctx)
+
+		for cursor.Next(ctx) {
+			t := new(T)
+			if err = cursor.Decode(t); err != nil {
+				yield(nil, mxerrors.Wrap(err))
+				return
+			}
+			globalOpContext.Doc = t
+			err = a.postActionHandler(ctx, globalOpContext, NewAfterOpContext[T](opContext, WithDoc(t)), operation.OpTypeAfterFind)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if !yield(t, nil) {
+				return
+			}
+		}
+		if err = cursor.Err(); err != nil {
+			yield(nil, mxerrors.Wrap(err))
+			return
+		}
+
+		globalOpContext.Doc = nil
+		if err = callback.GetCallback().Execute(ctx, globalOpContext, operation.OpTypeAfterAggregate); err != nil {
+			yield(nil, err)
+		}
+	}
+}
+
+// IterBatch is the same as Iter but yields the documents in chunks of the given size, the last chunk may be smaller
+// The size is also used as the batch size of the cursor unless the options set one, a size less than 1 is treated as 1
+func (a *Aggregator[T]) IterBatch(ctx context.Context, size int, opts ...options.Lister[options.AggregateOptions]) iter.Seq2[[]*T, error] {
+	size = max(size, 1)
+	opts = append([]options.Lister[options.AggregateOptions]{options.Aggregate().SetBatchSize(int32(size))}, opts...)
+	return utils.Chunk(a.Iter(ctx, opts...), size)
 }
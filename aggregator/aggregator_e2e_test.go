@@ -1,7 +1,10 @@
+//go:build e2e
+
 package aggregator
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/matiniiuu/mongox/bsonx"
@@ -9,6 +12,10 @@ import (
 
 	"github.com/matiniiuu/mongox/builder/aggregation"
 	"github.com/matiniiuu/mongox/builder/query"
+	"github.com/matiniiuu/mongox/callback"
+	mxerrors "github.com/matiniiuu/mongox/errors"
+	"github.com/matiniiuu/mongox/geojson"
+	"github.com/matiniiuu/mongox/operation"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
@@ -326,3 +333,238 @@ func TestAggregator_e2e_AggregateWithParse(t *testing.T) {
 		})
 	}
 }
+
+func TestAggregator_e2e_Iter(t *testing.T) {
+	collection := getCollection(t)
+	ctx := context.Background()
+
+	insertManyResult, err := collection.InsertMany(ctx, []any{
+		&TestUser{Name: "chenmingyong", Age: 24},
+		&TestUser{Name: "gopher", Age: 20},
+		&TestUser{Name: "burt", Age: 26},
+	})
+	require.NoError(t, err)
+	require.Len(t, insertManyResult.InsertedIDs, 3)
+	defer func() {
+		deleteResult, err := collection.DeleteMany(ctx, query.In("name", "chenmingyong", "gopher", "burt"))
+		require.NoError(t, err)
+		require.Equal(t, int64(3), deleteResult.DeletedCount)
+	}()
+
+	pipeline := aggregation.NewStageBuilder().
+		Match(query.In("name", "chenmingyong", "gopher", "burt")).
+		Sort(bson.D{{Key: "age", Value: 1}}).Build()
+
+	t.Run("iterate with global after find callback", func(t *testing.T) {
+		var afterCount int
+		callback.GetCallback().Register(operation.OpTypeAfterFind, "count", func(_ context.Context, opCtx *operation.OpContext, _ ...any) error {
+			afterCount++
+			require.IsType(t, &TestUser{}, opCtx.Doc)
+			return nil
+		})
+		defer callback.GetCallback().Remove(operation.OpTypeAfterFind, "count")
+		var aggregateCount int
+		callback.GetCallback().Register(operation.OpTypeAfterAggregate, "count", func(_ context.Context, _ *operation.OpContext, _ ...any) error {
+			aggregateCount++
+			require.Equal(t, 3, afterCount)
+			return nil
+		})
+		defer callback.GetCallback().Remove(operation.OpTypeAfterAggregate, "count")
+
+		names := make([]string, 0, 3)
+		for user, err := range NewAggregator[TestUser](collection).Pipeline(pipeline).Iter(ctx) {
+			require.NoError(t, err)
+			names = append(names, user.Name)
+		}
+		require.Equal(t, []string{"gopher", "chenmingyong", "burt"}, names)
+		require.Equal(t, 3, afterCount)
+		require.Equal(t, 1, aggregateCount)
+	})
+
+	t.Run("nil pipeline error", func(t *testing.T) {
+		var gotErr error
+		for _, err := range NewAggregator[TestUser](collection).Iter(ctx) {
+			gotErr = err
+		}
+		require.Error(t, gotErr)
+	})
+
+	t.Run("iterate in batches", func(t *testing.T) {
+		batches := make([][]string, 0, 2)
+		for users, err := range NewAggregator[TestUser](collection).Pipeline(pipeline).IterBatch(ctx, 2) {
+			require.NoError(t, err)
+			names := make([]string, 0, len(users))
+			for _, user := range users {
+				names = append(names, user.Name)
+			}
+			batches = append(batches, names)
+		}
+		require.Equal(t, [][]string{{"gopher", "chenmingyong"}, {"burt"}}, batches)
+	})
+}
+
+func TestAggregator_e2e_Hooks(t *testing.T) {
+	collection := getCollection(t)
+	ctx := context.Background()
+
+	insertManyResult, err := collection.InsertMany(ctx, []any{
+		&TestUser{Name: "chenmingyong", Age: 24},
+		&TestUser{Name: "gopher", Age: 20},
+	})
+	require.NoError(t, err)
+	require.Len(t, insertManyResult.InsertedIDs, 2)
+	defer func() {
+		deleteResult, err := collection.DeleteMany(ctx, query.In("name", "chenmingyong", "gopher"))
+		require.NoError(t, err)
+		require.Equal(t, int64(2), deleteResult.DeletedCount)
+	}()
+
+	// the global callback narrows the pipeline down to a single user
+	callback.GetCallback().Register(operation.OpTypeBeforeAggregate, "narrow", func(_ context.Context, opCtx *operation.OpContext, _ ...any) error {
+		opCtx.Pipeline = append(opCtx.Pipeline.(mongo.Pipeline), bson.D{{Key: "$match", Value: query.Eq("name", "gopher")}})
+		return nil
+	})
+	defer callback.GetCallback().Remove(operation.OpTypeBeforeAggregate, "narrow")
+	var afterAggregate int
+	callback.GetCallback().Register(operation.OpTypeAfterAggregate, "count", func(_ context.Context, _ *operation.OpContext, _ ...any) error {
+		afterAggregate++
+		return nil
+	})
+	defer callback.GetCallback().Remove(operation.OpTypeAfterAggregate, "count")
+
+	pipeline := aggregation.NewStageBuilder().Match(query.In("name", "chenmingyong", "gopher")).Build()
+
+	t.Run("aggregate", func(t *testing.T) {
+		var docs []*TestUser
+		users, err := NewAggregator[TestUser](collection).Pipeline(pipeline).
+			RegisterBeforeHooks(func(_ context.Context, opContext *OpContext, _ ...any) error {
+				require.Len(t, opContext.Pipeline, 2)
+				return nil
+			}).
+			RegisterAfterHooks(func(_ context.Context, opContext *AfterOpContext[TestUser], _ ...any) error {
+				docs = opContext.Docs
+				return nil
+			}).
+			Aggregate(ctx)
+		require.NoError(t, err)
+		require.Len(t, users, 1)
+		require.Equal(t, "gopher", users[0].Name)
+		require.Equal(t, users, docs)
+		require.Equal(t, 1, afterAggregate)
+	})
+
+	t.Run("aggregate with parse", func(t *testing.T) {
+		var result []UserName
+		err := NewAggregator[TestUser](collection).Pipeline(pipeline).
+			RegisterAfterHooks(func(_ context.Context, opContext *AfterOpContext[TestUser], _ ...any) error {
+				require.Equal(t, &result, opContext.Result)
+				return nil
+			}).
+			AggregateWithParse(ctx, &result)
+		require.NoError(t, err)
+		require.Equal(t, []UserName{{Name: "gopher"}}, result)
+		require.Equal(t, 2, afterAggregate)
+	})
+
+	t.Run("before hook error", func(t *testing.T) {
+		_, err := NewAggregator[TestUser](collection).Pipeline(pipeline).
+			RegisterBeforeHooks(func(_ context.Context, _ *OpContext, _ ...any) error {
+				return errors.New("before hook error")
+			}).
+			Aggregate(ctx)
+		require.ErrorIs(t, err, mxerrors.ErrHookFailed)
+		require.ErrorContains(t, err, "before hook error")
+	})
+}
+
+func TestAggregator_e2e_WindowFieldsAndMerge(t *testing.T) {
+	collection := getCollection(t)
+	ctx := context.Background()
+	_, err := collection.InsertMany(ctx, []any{
+		TestTempUser{Id: "1", Name: "cmy", Age: 24},
+		TestTempUser{Id: "2", Name: "gopher", Age: 20},
+		TestTempUser{Id: "3", Name: "burt", Age: 30},
+	})
+	require.NoError(t, err)
+	totals := collection.Database().Collection("test_user_totals")
+	defer func() {
+		_, err := collection.DeleteMany(ctx, query.In("_id", "1", "2", "3"))
+		require.NoError(t, err)
+		require.NoError(t, totals.Drop(ctx))
+	}()
+
+	pipeline := aggregation.NewStageBuilder().
+		SetWindowFields(
+			aggregation.NewBuilder().
+				Sum("total_age", "$age").Window("total_age", &aggregation.WindowOptions{Documents: []any{"unbounded", "current"}}).
+				Rank("rank").Build(),
+			&aggregation.SetWindowFieldsOptions{SortBy: bson.D{{Key: "age", Value: 1}}},
+		).
+		Project(bson.D{{Key: "total_age", Value: 1}, {Key: "rank", Value: 1}}).
+		Merge("test_user_totals", &aggregation.MergeOptions{WhenMatched: "replace", WhenNotMatched: "insert"})
+	stages, err := pipeline.BuildE()
+	require.NoError(t, err)
+	_, err = NewAggregator[TestTempUser](collection).Pipeline(stages).Aggregate(ctx)
+	require.NoError(t, err)
+
+	type total struct {
+		Id       string `bson:"_id"`
+		TotalAge int64  `bson:"total_age"`
+		Rank     int64  `bson:"rank"`
+	}
+	cursor, err := totals.Find(ctx, bson.D{}, options.Find().SetSort(bson.D{{Key: "rank", Value: 1}}))
+	require.NoError(t, err)
+	var got []total
+	require.NoError(t, cursor.All(ctx, &got))
+	require.Equal(t, []total{
+		{Id: "2", TotalAge: 20, Rank: 1},
+		{Id: "1", TotalAge: 44, Rank: 2},
+		{Id: "3", TotalAge: 74, Rank: 3},
+	}, got)
+}
+
+func TestAggregator_e2e_GeoNear(t *testing.T) {
+	type store struct {
+		Id       string        `bson:"_id"`
+		Location geojson.Point `bson:"location"`
+		Distance float64       `bson:"distance,omitempty"`
+	}
+	collection := getCollection(t).Database().Collection("test_store")
+	ctx := context.Background()
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{Key: "location", Value: "2dsphere"}}})
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, collection.Drop(ctx))
+	}()
+	_, err = collection.InsertMany(ctx, []any{
+		store{Id: "central park", Location: geojson.NewPoint(-73.9654, 40.7829)},
+		store{Id: "times square", Location: geojson.NewPoint(-73.9855, 40.7580)},
+		store{Id: "brooklyn", Location: geojson.NewPoint(-73.9442, 40.6782)},
+	})
+	require.NoError(t, err)
+
+	manhattan := geojson.NewPolygon([][]float64{{-74.02, 40.70}, {-73.93, 40.70}, {-73.93, 40.88}, {-74.02, 40.88}, {-74.02, 40.70}})
+	stages, err := aggregation.NewStageBuilder().
+		GeoNear(geojson.NewPoint(-73.9851, 40.7589), "distance", &aggregation.GeoNearOptions{
+			Spherical:   true,
+			MaxDistance: 10000,
+			Query:       query.GeoWithin("location", manhattan),
+		}).
+		BuildE()
+	require.NoError(t, err)
+	got, err := NewAggregator[store](collection).Pipeline(stages).Aggregate(ctx)
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	require.Equal(t, "times square", got[0].Id)
+	require.Equal(t, "central park", got[1].Id)
+	require.Equal(t, geojson.NewPoint(-73.9855, 40.7580), got[0].Location)
+	require.Less(t, got[0].Distance, got[1].Distance)
+
+	cursor, err := collection.Find(ctx, query.NewBuilder().
+		Near("location", geojson.NewPoint(-73.9442, 40.6782), &query.NearOptions{MaxDistance: 1000}).Build())
+	require.NoError(t, err)
+	var near []store
+	require.NoError(t, cursor.All(ctx, &near))
+	require.Len(t, near, 1)
+	require.Equal(t, "brooklyn", near[0].Id)
+}
@@ -6,11 +6,16 @@ import (
 	"testing"
 	"time"
 
+	"github.com/matiniiuu/mongox/callback"
 	mocks "github.com/matiniiuu/mongox/mock"
+	"github.com/matiniiuu/mongox/operation"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"go.mongodb.org/mongo-driver/v2/x/mongo/driver/drivertest"
 	"go.uber.org/mock/gomock"
 )
 
@@ -113,6 +118,69 @@ func TestAggregator_Aggregation(t *testing.T) {
 	}
 }
 
+// newMockCollection returns a collection whose commands are answered with the responses in order
+func newMockCollection(t *testing.T, responses ...bson.D) *mongo.Collection {
+	clientOpts := options.Client()
+	clientOpts.Deployment = drivertest.NewMockDeployment(responses...)
+	client, err := mongo.Connect(clientOpts)
+	require.NoError(t, err)
+	return client.Database("db-test").Collection("test_user")
+}
+
+func TestAggregator_Iter(t *testing.T) {
+	cursor := bson.D{{Key: "ok", Value: 1}, {Key: "cursor", Value: bson.D{
+		{Key: "id", Value: int64(0)},
+		{Key: "ns", Value: "db-test.test_user"},
+		{Key: "firstBatch", Value: bson.A{bson.D{{Key: "name", Value: "chenmingyong"}}, bson.D{{Key: "name", Value: "gopher"}}}},
+	}}}
+	pipeline := mongo.Pipeline{{{Key: "$sort", Value: bson.D{{Key: "age", Value: 1}}}}}
+
+	var calls []operation.OpType
+	for _, opType := range []operation.OpType{operation.OpTypeBeforeAggregate, operation.OpTypeAfterFind, operation.OpTypeAfterAggregate} {
+		callback.GetCallback().Register(opType, "record", func(_ context.Context, _ *operation.OpContext, _ ...any) error {
+			calls = append(calls, opType)
+			return nil
+		})
+		defer callback.GetCallback().Remove(opType, "record")
+	}
+
+	t.Run("exhausted", func(t *testing.T) {
+		calls = nil
+		names := make([]string, 0, 2)
+		for user, err := range NewAggregator[TestUser](newMockCollection(t, cursor)).Pipeline(pipeline).Iter(context.Background()) {
+			require.NoError(t, err)
+			names = append(names, user.Name)
+		}
+		assert.Equal(t, []string{"chenmingyong", "gopher"}, names)
+		assert.Equal(t, []operation.OpType{
+			operation.OpTypeBeforeAggregate, operation.OpTypeAfterFind, operation.OpTypeAfterFind, operation.OpTypeAfterAggregate,
+		}, calls)
+	})
+
+	t.Run("break early", func(t *testing.T) {
+		calls = nil
+		for _, err := range NewAggregator[TestUser](newMockCollection(t, cursor)).Pipeline(pipeline).Iter(context.Background()) {
+			require.NoError(t, err)
+			break
+		}
+		assert.Equal(t, []operation.OpType{operation.OpTypeBeforeAggregate, operation.OpTypeAfterFind}, calls)
+	})
+
+	t.Run("after aggregate error", func(t *testing.T) {
+		callbackErr := errors.New("after aggregate error")
+		callback.GetCallback().Register(operation.OpTypeAfterAggregate, "fail", func(_ context.Context, _ *operation.OpContext, _ ...any) error {
+			return callbackErr
+		})
+		defer callback.GetCallback().Remove(operation.OpTypeAfterAggregate, "fail")
+
+		var gotErr error
+		for _, err := range NewAggregator[TestUser](newMockCollection(t, cursor)).Pipeline(pipeline).Iter(context.Background()) {
+			gotErr = err
+		}
+		assert.ErrorIs(t, gotErr, callbackErr)
+	})
+}
+
 func TestAggregator_AggregateWithParse(t *testing.T) {
 	type User struct {
 		Id           string `bson:"_id"`
@@ -169,3 +237,63 @@ func TestAggregator_AggregateWithParse(t *testing.T) {
 		})
 	}
 }
+
+func TestAggregator_scopedPipeline(t *testing.T) {
+	pipeline := mongo.Pipeline{{{Key: "$limit", Value: 1}}}
+	testCases := []struct {
+		name       string
+		aggregator func() *Aggregator[TestUser]
+		want       any
+	}{
+		{
+			name: "soft delete disabled",
+			aggregator: func() *Aggregator[TestUser] {
+				return NewAggregator[TestUser](&mongo.Collection{}).Pipeline(pipeline)
+			},
+			want: pipeline,
+		},
+		{
+			name: "exclude deleted by default",
+			aggregator: func() *Aggregator[TestUser] {
+				return NewAggregator[TestUser](&mongo.Collection{}).SoftDelete(true).Pipeline(pipeline)
+			},
+			want: bson.A{
+				bson.D{{Key: "$match", Value: bson.D{{Key: "deletedAt", Value: nil}}}},
+				bson.D{{Key: "$limit", Value: 1}},
+			},
+		},
+		{
+			name: "with deleted",
+			aggregator: func() *Aggregator[TestUser] {
+				return NewAggregator[TestUser](&mongo.Collection{}).SoftDelete(true).WithDeleted().Pipeline(pipeline)
+			},
+			want: pipeline,
+		},
+		{
+			name: "only deleted",
+			aggregator: func() *Aggregator[TestUser] {
+				return NewAggregator[TestUser](&mongo.Collection{}).SoftDelete(true).OnlyDeleted().Pipeline(pipeline)
+			},
+			want: bson.A{
+				bson.D{{Key: "$match", Value: bson.D{{Key: "deletedAt", Value: bson.D{{Key: "$ne", Value: nil}}}}}},
+				bson.D{{Key: "$limit", Value: 1}},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.aggregator().scopedPipeline())
+		})
+	}
+}
+
+func TestAggregator_RegisterHooks(t *testing.T) {
+	aggregator := NewAggregator[TestUser](&mongo.Collection{}).
+		RegisterBeforeHooks(func(_ context.Context, _ *OpContext, _ ...any) error { return nil }).
+		RegisterAfterHooks(
+			func(_ context.Context, _ *AfterOpContext[TestUser], _ ...any) error { return nil },
+			func(_ context.Context, _ *AfterOpContext[TestUser], _ ...any) error { return nil },
+		)
+	assert.Len(t, aggregator.beforeHooks, 1)
+	assert.Len(t, aggregator.afterHooks, 2)
+}
@@ -0,0 +1,31 @@
+package aggregator
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+//go:generate optioner -type OpContext
+type OpContext struct {
+	Col *mongo.Collection `opt:"-"`
+	// Pipeline is the pipeline of the aggregation, the before hooks may change it
+	Pipeline     any `opt:"-"`
+	MongoOptions any
+}
+
+//go:generate optioner -type AfterOpContext
+type AfterOpContext[T any] struct {
+	*OpContext `opt:"-"`
+	// Doc is the decoded document, it is only set for Iter
+	Doc *T
+	// Docs is the result of Aggregate
+	Docs []*T
+	// Result is the result parsed by AggregateWithParse
+	Result any
+}
+
+type (
+	beforeHookFn       func(ctx context.Context, opContext *OpContext, opts ...any) error
+	afterHookFn[T any] func(ctx context.Context, opContext *AfterOpContext[T], opts ...any) error
+)
@@ -0,0 +1,110 @@
+//go:build e2e
+
+package mongox
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/matiniiuu/mongox/builder/query"
+	"github.com/matiniiuu/mongox/builder/update"
+	"github.com/matiniiuu/mongox/hook/audit"
+	"github.com/matiniiuu/mongox/operation"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestCollection_e2e_Audit(t *testing.T) {
+	collection := getCollection[baseUser](t)
+	records := collection.Collection().Database().Collection("test_audit")
+	ctx := audit.NewContext(context.Background(), "Mingyong Chen")
+
+	InitPlugin(&PluginConfig{EnableAudit: true, AuditSink: audit.NewCollectionSink(records), AuditSnapshot: true})
+	defer func() {
+		for _, opType := range []operation.OpType{
+			operation.OpTypeAfterInsert, operation.OpTypeAfterUpdate, operation.OpTypeAfterUpsert,
+			operation.OpTypeAfterReplace, operation.OpTypeAfterDelete,
+			operation.OpTypeBeforeUpdate, operation.OpTypeBeforeUpsert, operation.OpTypeBeforeReplace, operation.OpTypeBeforeDelete,
+		} {
+			RemovePlugin("mongox:audit", opType)
+		}
+		audit.SetSink(nil)
+		audit.SetSnapshot(false)
+		require.NoError(t, records.Drop(context.Background()))
+	}()
+
+	id := bson.NewObjectID()
+	_, err := collection.Creator().InsertOne(ctx, &baseUser{Base: Base{ID: id}, Name: "Mingyong Chen", Age: 18})
+	require.NoError(t, err)
+	_, err = collection.Updater().Filter(query.Id(id)).Updates(update.Set("age", 19)).UpdateOne(ctx)
+	require.NoError(t, err)
+	_, err = collection.Deleter().Filter(query.Id(id)).DeleteOne(ctx)
+	require.NoError(t, err)
+
+	var got []audit.Record
+	cursor, err := records.Find(context.Background(), bson.D{})
+	require.NoError(t, err)
+	require.NoError(t, cursor.All(context.Background(), &got))
+	require.Len(t, got, 3)
+
+	require.Equal(t, audit.OperationInsert, got[0].Operation)
+	require.Equal(t, "test_user", got[0].Collection)
+	require.Equal(t, "Mingyong Chen", got[0].Actor)
+	require.Len(t, got[0].After, 1)
+
+	require.Equal(t, audit.OperationUpdate, got[1].Operation)
+	require.Len(t, got[1].Before, 1)
+	require.EqualValues(t, 18, got[1].Before[0]["age"])
+	require.Len(t, got[1].After, 1)
+	require.EqualValues(t, 19, got[1].After[0]["age"])
+
+	require.Equal(t, audit.OperationDelete, got[2].Operation)
+	require.Len(t, got[2].Before, 1)
+	require.Empty(t, got[2].After)
+}
+
+func TestCollection_e2e_AuditTransaction(t *testing.T) {
+	collection := getCollection[baseUser](t)
+	client := collection.Collection().Database().Client()
+	ctx := context.Background()
+
+	var records []*audit.Record
+	InitPlugin(&PluginConfig{EnableAudit: true, AuditSink: audit.SinkFunc(func(_ context.Context, record *audit.Record) error {
+		records = append(records, record)
+		return nil
+	})})
+	defer func() {
+		for _, opType := range []operation.OpType{
+			operation.OpTypeAfterInsert, operation.OpTypeAfterUpdate, operation.OpTypeAfterUpsert,
+			operation.OpTypeAfterReplace, operation.OpTypeAfterDelete,
+			operation.OpTypeBeforeUpdate, operation.OpTypeBeforeUpsert, operation.OpTypeBeforeReplace, operation.OpTypeBeforeDelete,
+		} {
+			RemovePlugin("mongox:audit", opType)
+		}
+		audit.SetSink(nil)
+	}()
+
+	err := WithTransaction(ctx, client, func(ctx context.Context) error {
+		_, err := collection.Creator().InsertOne(ctx, &baseUser{Name: "burt", Age: 19})
+		if err != nil {
+			return err
+		}
+		require.Empty(t, records)
+		return errors.New("abort")
+	})
+	require.Equal(t, errors.New("abort"), err)
+	require.Empty(t, records)
+
+	err = WithTransaction(ctx, client, func(ctx context.Context) error {
+		_, err := collection.Creator().InsertOne(ctx, &baseUser{Name: "Mingyong Chen", Age: 18})
+		return err
+	})
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	require.Equal(t, audit.OperationInsert, records[0].Operation)
+
+	_, err = collection.Collection().DeleteMany(ctx, query.Eq("name", "Mingyong Chen"))
+	require.NoError(t, err)
+}
@@ -3,9 +3,13 @@ package mongox
 import (
 	"time"
 
+	"github.com/matiniiuu/mongox/hook"
+
 	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
+// Base is meant to be embedded inline into the models, it implements hook.DefaultModel,
+// so the default field hook manages the _id, createdAt and updatedAt fields
 type Base struct {
 	ID        bson.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
 	CreatedAt *time.Time    `bson:"createdAt,omitempty" json:"createdAt,omitempty"`
@@ -20,16 +24,18 @@ func (m *Base) DefaultId() bson.ObjectID {
 	return m.ID
 }
 
-func (m *Base) DefaultCreatedAt() *time.Time {
+func (m *Base) DefaultCreatedAt() time.Time {
 	if m.CreatedAt == nil || m.CreatedAt.IsZero() {
 		now := time.Now().Local()
 		m.CreatedAt = &now
 	}
-	return m.CreatedAt
+	return *m.CreatedAt
 }
 
-func (m *Base) DefaultUpdatedAt() *time.Time {
+func (m *Base) DefaultUpdatedAt() time.Time {
 	now := time.Now().Local()
 	m.UpdatedAt = &now
-	return m.UpdatedAt
+	return *m.UpdatedAt
 }
+
+var _ hook.DefaultModel = (*Base)(nil)
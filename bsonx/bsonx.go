@@ -2,8 +2,10 @@ package bsonx
 
 import (
 	"bytes"
+	"reflect"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
 )
 
 func M(key string, value any) bson.M {
@@ -61,6 +63,42 @@ func MapToBsonM(data map[string]any) bson.M {
 	return m
 }
 
+// ToPipeline converts the stages of a pipeline, e.g. a []bson.D, a bson.A of documents or the output of
+// aggregation.StageBuilder, to a mongo.Pipeline. The stages are copied into a new slice so that appending to the
+// result leaves data untouched, ok is false if data is not a slice of documents
+func ToPipeline(data any) (pipeline mongo.Pipeline, ok bool) {
+	if data == nil {
+		return nil, false
+	}
+	value := reflect.ValueOf(data)
+	if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+		return nil, false
+	}
+	pipeline = make(mongo.Pipeline, 0, value.Len())
+	for i := 0; i < value.Len(); i++ {
+		switch stage := value.Index(i).Interface().(type) {
+		case bson.D:
+			pipeline = append(pipeline, stage)
+		case bson.M:
+			pipeline = append(pipeline, mToD(stage))
+		case map[string]any:
+			pipeline = append(pipeline, mToD(stage))
+		default:
+			return nil, false
+		}
+	}
+	return pipeline, true
+}
+
+// mToD converts a stage to a bson.D, a stage has a single key so that the order of the map does not matter
+func mToD(m map[string]any) bson.D {
+	d := make(bson.D, 0, len(m))
+	for k, v := range m {
+		d = append(d, bson.E{Key: k, Value: v})
+	}
+	return d
+}
+
 func dToM(d bson.D) bson.M {
 	marshal, err := bson.Marshal(d)
 	if err != nil {
@@ -5,6 +5,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
 )
 
 func TestD(t *testing.T) {
@@ -48,3 +49,38 @@ func TestA(t *testing.T) {
 	}
 
 }
+
+func TestToPipeline(t *testing.T) {
+	set := bson.D{bson.E{Key: "$set", Value: bson.D{bson.E{Key: "name", Value: "Mingyong Chen"}}}}
+	testCases := []struct {
+		name   string
+		data   any
+		want   mongo.Pipeline
+		wantOk bool
+	}{
+		{name: "nil", data: nil},
+		{name: "document", data: set},
+		{name: "bson.M", data: bson.M{"$set": bson.M{"name": "Mingyong Chen"}}},
+		{name: "not documents", data: bson.A{1, 2}},
+		{name: "pipeline", data: mongo.Pipeline{set}, want: mongo.Pipeline{set}, wantOk: true},
+		{name: "slice of bson.D", data: []bson.D{set}, want: mongo.Pipeline{set}, wantOk: true},
+		{name: "bson.A", data: bson.A{set, bson.M{"$unset": "age"}}, want: mongo.Pipeline{set, {{Key: "$unset", Value: "age"}}}, wantOk: true},
+		{name: "empty", data: bson.A{}, want: mongo.Pipeline{}, wantOk: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := ToPipeline(tc.data)
+			assert.Equal(t, tc.wantOk, ok)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+
+	t.Run("the stages are copied", func(t *testing.T) {
+		data := make(mongo.Pipeline, 1, 2)
+		data[0] = set
+		got, _ := ToPipeline(data)
+		_ = append(got, bson.D{bson.E{Key: "$unset", Value: "age"}})
+		assert.Equal(t, mongo.Pipeline{set}, data)
+		assert.Empty(t, data[:2][1])
+	})
+}
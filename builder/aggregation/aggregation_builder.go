@@ -15,6 +15,11 @@ func NewBuilder() *Builder {
 	b.dateBuilder = dateBuilder{parent: b}
 	b.condBuilder = condBuilder{parent: b}
 	b.accumulatorsBuilder = accumulatorsBuilder{parent: b}
+	b.windowBuilder = windowBuilder{parent: b}
+	b.conversionBuilder = conversionBuilder{parent: b}
+	b.setBuilder = setBuilder{parent: b}
+	b.objectBuilder = objectBuilder{parent: b}
+	b.variableBuilder = variableBuilder{parent: b}
 
 	return b
 }
@@ -28,6 +33,11 @@ type Builder struct {
 	dateBuilder
 	condBuilder
 	accumulatorsBuilder
+	windowBuilder
+	conversionBuilder
+	setBuilder
+	objectBuilder
+	variableBuilder
 
 	d bson.D
 }
@@ -36,6 +46,16 @@ func (b *Builder) Build() bson.D {
 	return b.d
 }
 
+// BuildE returns the expression together with the errors of Err
+func (b *Builder) BuildE() (bson.D, error) {
+	return b.d, b.Err()
+}
+
+// Err returns the errors of the validation of the expression, see Validate
+func (b *Builder) Err() error {
+	return Validate(b.d)
+}
+
 func (b *Builder) KeyValue(key string, value any) *Builder {
 	b.d = append(b.d, bson.E{Key: key, Value: value})
 	return b
@@ -1,12 +1,18 @@
 package aggregation
 
 import (
+	"errors"
+	"slices"
+	"strings"
+
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 )
 
 type StageBuilder struct {
 	pipeline mongo.Pipeline
+
+	err []error
 }
 
 func NewStageBuilder() *StageBuilder {
@@ -24,6 +30,9 @@ func (b *StageBuilder) Set(value any) *StageBuilder {
 }
 
 func (b *StageBuilder) Bucket(groupBy any, boundaries []any, opt *BucketOptions) *StageBuilder {
+	if len(boundaries) < 2 {
+		b.err = append(b.err, invalidStage(StageBucketOp, "must have at least two boundaries"))
+	}
 	d := bson.D{
 		bson.E{Key: StageGroupByOp, Value: groupBy},
 		bson.E{Key: StageBoundariesOp, Value: boundaries},
@@ -41,6 +50,9 @@ func (b *StageBuilder) Bucket(groupBy any, boundaries []any, opt *BucketOptions)
 }
 
 func (b *StageBuilder) BucketAuto(groupBy any, buckets int, opt *BucketAutoOptions) *StageBuilder {
+	if buckets <= 0 {
+		b.err = append(b.err, invalidStage(StageBucketAutoOp, "must have a positive number of buckets"))
+	}
 	d := bson.D{
 		bson.E{Key: StageGroupByOp, Value: groupBy},
 		bson.E{Key: StageBucketsOp, Value: buckets},
@@ -80,16 +92,25 @@ func (b *StageBuilder) Project(value any) *StageBuilder {
 }
 
 func (b *StageBuilder) Limit(limit int64) *StageBuilder {
+	if limit <= 0 {
+		b.err = append(b.err, invalidStage(StageLimitOp, "must be positive"))
+	}
 	b.pipeline = append(b.pipeline, bson.D{bson.E{Key: StageLimitOp, Value: limit}})
 	return b
 }
 
 func (b *StageBuilder) Skip(skip int64) *StageBuilder {
+	if skip < 0 {
+		b.err = append(b.err, invalidStage(StageSkipOp, "must not be negative"))
+	}
 	b.pipeline = append(b.pipeline, bson.D{bson.E{Key: StageSkipOp, Value: skip}})
 	return b
 }
 
 func (b *StageBuilder) Unwind(path string, opt *UnWindOptions) *StageBuilder {
+	if !strings.HasPrefix(path, "$") {
+		b.err = append(b.err, invalidStage(StageUnwindOp, "path must be prefixed with '$'"))
+	}
 	if opt == nil {
 		b.pipeline = append(b.pipeline, bson.D{{Key: StageUnwindOp, Value: path}})
 	} else {
@@ -126,11 +147,18 @@ func (b *StageBuilder) SortByCount(expression any) *StageBuilder {
 }
 
 func (b *StageBuilder) Count(countName string) *StageBuilder {
+	if countName == "" || strings.HasPrefix(countName, "$") || strings.Contains(countName, ".") {
+		b.err = append(b.err, invalidStage(StageCountOp, "name must be non-empty, not start with '$' and not contain '.'"))
+	}
 	b.pipeline = append(b.pipeline, bson.D{bson.E{Key: StageCountOp, Value: countName}})
 	return b
 }
 
 func (b *StageBuilder) Lookup(from, as string, opt *LookUpOptions) *StageBuilder {
+	if opt == nil {
+		b.err = append(b.err, invalidStage(StageLookUpOp, "must have the options of the join"))
+		return b
+	}
 	d := bson.D{bson.E{Key: "from", Value: from}}
 	if opt.LocalField != "" && opt.ForeignField != "" {
 		d = append(d, bson.E{Key: "localField", Value: opt.LocalField})
@@ -164,6 +192,238 @@ func (b *StageBuilder) UnionWith(coll string, pipeline mongo.Pipeline) *StageBui
 	b.pipeline = append(b.pipeline, stage)
 	return b
 }
+
+// Out writes the documents of the pipeline to the collection coll, of the database opt.DB if it is set
+func (b *StageBuilder) Out(coll string, opt *OutOptions) *StageBuilder {
+	if opt == nil || opt.DB == "" && len(opt.TimeSeries) == 0 {
+		b.pipeline = append(b.pipeline, bson.D{{Key: StageOutOp, Value: coll}})
+		return b
+	}
+	d := bson.D{}
+	if opt.DB != "" {
+		d = append(d, bson.E{Key: "db", Value: opt.DB})
+	}
+	d = append(d, bson.E{Key: "coll", Value: coll})
+	if len(opt.TimeSeries) > 0 {
+		d = append(d, bson.E{Key: "timeseries", Value: opt.TimeSeries})
+	}
+	b.pipeline = append(b.pipeline, bson.D{{Key: StageOutOp, Value: d}})
+	return b
+}
+
+// Merge writes the documents of the pipeline into the collection into, which is either the name of the collection
+// or a document of db and coll
+func (b *StageBuilder) Merge(into any, opt *MergeOptions) *StageBuilder {
+	d := bson.D{{Key: "into", Value: into}}
+	if opt != nil {
+		if opt.On != nil {
+			d = append(d, bson.E{Key: "on", Value: opt.On})
+		}
+		if len(opt.Let) > 0 {
+			d = append(d, bson.E{Key: "let", Value: opt.Let})
+		}
+		if opt.WhenMatched != nil {
+			d = append(d, bson.E{Key: "whenMatched", Value: opt.WhenMatched})
+		}
+		if opt.WhenNotMatched != "" {
+			d = append(d, bson.E{Key: "whenNotMatched", Value: opt.WhenNotMatched})
+		}
+	}
+	b.pipeline = append(b.pipeline, bson.D{{Key: StageMergeOp, Value: d}})
+	return b
+}
+
+func (b *StageBuilder) GraphLookup(from string, startWith any, connectFromField, connectToField, as string, opt *GraphLookupOptions) *StageBuilder {
+	d := bson.D{
+		{Key: "from", Value: from},
+		{Key: "startWith", Value: startWith},
+		{Key: "connectFromField", Value: connectFromField},
+		{Key: "connectToField", Value: connectToField},
+		{Key: "as", Value: as},
+	}
+	if opt != nil {
+		if opt.MaxDepth != nil {
+			if *opt.MaxDepth < 0 {
+				b.err = append(b.err, invalidStage(StageGraphLookupOp, "maxDepth must not be negative"))
+			}
+			d = append(d, bson.E{Key: "maxDepth", Value: *opt.MaxDepth})
+		}
+		if opt.DepthField != "" {
+			d = append(d, bson.E{Key: "depthField", Value: opt.DepthField})
+		}
+		if opt.RestrictSearchWithMatch != nil {
+			d = append(d, bson.E{Key: "restrictSearchWithMatch", Value: opt.RestrictSearchWithMatch})
+		}
+	}
+	b.pipeline = append(b.pipeline, bson.D{{Key: StageGraphLookupOp, Value: d}})
+	return b
+}
+
+func (b *StageBuilder) Sample(size int64) *StageBuilder {
+	if size <= 0 {
+		b.err = append(b.err, invalidStage(StageSampleOp, "size must be positive"))
+	}
+	b.pipeline = append(b.pipeline, bson.D{{Key: StageSampleOp, Value: bson.D{{Key: "size", Value: size}}}})
+	return b
+}
+
+func (b *StageBuilder) Unset(fields ...string) *StageBuilder {
+	if len(fields) == 0 {
+		b.err = append(b.err, invalidStage(StageUnsetOp, "must have at least one field"))
+	}
+	b.pipeline = append(b.pipeline, bson.D{{Key: StageUnsetOp, Value: fields}})
+	return b
+}
+
+// Redact restricts the content of the documents by the expression, which resolves to $$DESCEND, $$PRUNE or $$KEEP
+func (b *StageBuilder) Redact(expression any) *StageBuilder {
+	b.pipeline = append(b.pipeline, bson.D{{Key: StageRedactOp, Value: expression}})
+	return b
+}
+
+func (b *StageBuilder) Densify(field string, rng DensifyRange, opt *DensifyOptions) *StageBuilder {
+	d := bson.D{{Key: "field", Value: field}}
+	if opt != nil && len(opt.PartitionByFields) > 0 {
+		d = append(d, bson.E{Key: "partitionByFields", Value: opt.PartitionByFields})
+	}
+	r := bson.D{{Key: "step", Value: rng.Step}}
+	if rng.Unit != "" {
+		r = append(r, bson.E{Key: "unit", Value: rng.Unit})
+	}
+	r = append(r, bson.E{Key: "bounds", Value: rng.Bounds})
+	d = append(d, bson.E{Key: "range", Value: r})
+	b.pipeline = append(b.pipeline, bson.D{{Key: StageDensifyOp, Value: d}})
+	return b
+}
+
+// Fill fills the null and missing fields of output, e.g. bson.D{{Key: "qty", Value: bson.D{{Key: "method", Value: "linear"}}}}
+func (b *StageBuilder) Fill(output any, opt *FillOptions) *StageBuilder {
+	d := bson.D{}
+	if opt != nil {
+		if opt.PartitionBy != nil {
+			d = append(d, bson.E{Key: "partitionBy", Value: opt.PartitionBy})
+		}
+		if len(opt.PartitionByFields) > 0 {
+			d = append(d, bson.E{Key: "partitionByFields", Value: opt.PartitionByFields})
+		}
+		if len(opt.SortBy) > 0 {
+			d = append(d, bson.E{Key: "sortBy", Value: opt.SortBy})
+		}
+	}
+	d = append(d, bson.E{Key: "output", Value: output})
+	b.pipeline = append(b.pipeline, bson.D{{Key: StageFillOp, Value: d}})
+	return b
+}
+
+// SetWindowFields adds the fields of output computed by the window operators, e.g.
+// NewBuilder().Sum("total", "$qty").Window("total", &WindowOptions{Documents: []any{"unbounded", "current"}}).Build()
+func (b *StageBuilder) SetWindowFields(output any, opt *SetWindowFieldsOptions) *StageBuilder {
+	d := bson.D{}
+	if opt != nil {
+		if opt.PartitionBy != nil {
+			d = append(d, bson.E{Key: "partitionBy", Value: opt.PartitionBy})
+		}
+		if len(opt.SortBy) > 0 {
+			d = append(d, bson.E{Key: "sortBy", Value: opt.SortBy})
+		}
+	}
+	d = append(d, bson.E{Key: "output", Value: output})
+	b.pipeline = append(b.pipeline, bson.D{{Key: StageSetWindowFieldsOp, Value: d}})
+	return b
+}
+
+// GeoNear outputs the documents in order of their distance to near, a GeoJSON point such as geojson.Point or legacy
+// coordinates, it must be the first stage of the pipeline
+func (b *StageBuilder) GeoNear(near any, distanceField string, opt *GeoNearOptions) *StageBuilder {
+	if len(b.pipeline) != 0 {
+		b.err = append(b.err, invalidStage(StageGeoNearOp, "must be the first stage"))
+	}
+	d := bson.D{
+		{Key: "near", Value: near},
+		{Key: "distanceField", Value: distanceField},
+	}
+	if opt != nil {
+		if opt.Spherical {
+			d = append(d, bson.E{Key: "spherical", Value: opt.Spherical})
+		}
+		if opt.MaxDistance != 0 {
+			d = append(d, bson.E{Key: "maxDistance", Value: opt.MaxDistance})
+		}
+		if opt.MinDistance != 0 {
+			d = append(d, bson.E{Key: "minDistance", Value: opt.MinDistance})
+		}
+		if opt.Query != nil {
+			d = append(d, bson.E{Key: "query", Value: opt.Query})
+		}
+		if opt.IncludeLocs != "" {
+			d = append(d, bson.E{Key: "includeLocs", Value: opt.IncludeLocs})
+		}
+		if opt.DistanceMultiplier != 0 {
+			d = append(d, bson.E{Key: "distanceMultiplier", Value: opt.DistanceMultiplier})
+		}
+		if opt.Key != "" {
+			d = append(d, bson.E{Key: "key", Value: opt.Key})
+		}
+	}
+	b.pipeline = append(b.pipeline, bson.D{{Key: StageGeoNearOp, Value: d}})
+	return b
+}
+
+// Documents returns the documents, it must be the first stage of a pipeline run by Database.Aggregate
+func (b *StageBuilder) Documents(documents any) *StageBuilder {
+	if len(b.pipeline) != 0 {
+		b.err = append(b.err, invalidStage(StageDocumentsOp, "must be the first stage"))
+	}
+	b.pipeline = append(b.pipeline, bson.D{{Key: StageDocumentsOp, Value: documents}})
+	return b
+}
+
+func (b *StageBuilder) CollStats(opt *CollStatsOptions) *StageBuilder {
+	if len(b.pipeline) != 0 {
+		b.err = append(b.err, invalidStage(StageCollStatsOp, "must be the first stage"))
+	}
+	d := bson.D{}
+	if opt != nil {
+		if opt.LatencyStats {
+			d = append(d, bson.E{Key: "latencyStats", Value: bson.D{{Key: "histograms", Value: opt.LatencyHistograms}}})
+		}
+		if opt.StorageStats {
+			storageStats := bson.D{}
+			if opt.StorageScale > 0 {
+				storageStats = append(storageStats, bson.E{Key: "scale", Value: opt.StorageScale})
+			}
+			d = append(d, bson.E{Key: "storageStats", Value: storageStats})
+		}
+		if opt.Count {
+			d = append(d, bson.E{Key: "count", Value: bson.D{}})
+		}
+		if opt.QueryExecStats {
+			d = append(d, bson.E{Key: "queryExecStats", Value: bson.D{}})
+		}
+	}
+	b.pipeline = append(b.pipeline, bson.D{{Key: StageCollStatsOp, Value: d}})
+	return b
+}
+
+func (b *StageBuilder) IndexStats() *StageBuilder {
+	if len(b.pipeline) != 0 {
+		b.err = append(b.err, invalidStage(StageIndexStatsOp, "must be the first stage"))
+	}
+	b.pipeline = append(b.pipeline, bson.D{{Key: StageIndexStatsOp, Value: bson.D{}}})
+	return b
+}
+
 func (b *StageBuilder) Build() mongo.Pipeline {
 	return b.pipeline
 }
+
+// BuildE returns the pipeline together with the errors of Err
+func (b *StageBuilder) BuildE() (mongo.Pipeline, error) {
+	return b.pipeline, b.Err()
+}
+
+// Err returns the joined errors of the invalid arguments given to the builder and of the validation of the pipeline,
+// see ValidatePipeline
+func (b *StageBuilder) Err() error {
+	return errors.Join(append(slices.Clone(b.err), validatePipeline(b.pipeline)...)...)
+}
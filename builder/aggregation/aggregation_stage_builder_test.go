@@ -772,3 +772,133 @@ func TestStageBuilder_UnionWith(t *testing.T) {
 		})
 	}
 }
+
+func TestStageBuilder_Out(t *testing.T) {
+	assert.Equal(t, mongo.Pipeline{{{Key: "$out", Value: "authors"}}}, NewStageBuilder().Out("authors", nil).Build())
+	assert.Equal(t, mongo.Pipeline{{{Key: "$out", Value: bson.D{
+		{Key: "db", Value: "reporting"},
+		{Key: "coll", Value: "authors"},
+		{Key: "timeseries", Value: bson.D{{Key: "timeField", Value: "ts"}}},
+	}}}}, NewStageBuilder().Out("authors", &OutOptions{DB: "reporting", TimeSeries: bson.D{{Key: "timeField", Value: "ts"}}}).Build())
+}
+
+func TestStageBuilder_Merge(t *testing.T) {
+	assert.Equal(t, mongo.Pipeline{{{Key: "$merge", Value: bson.D{{Key: "into", Value: "monthlytotals"}}}}}, NewStageBuilder().Merge("monthlytotals", nil).Build())
+	assert.Equal(t, mongo.Pipeline{{{Key: "$merge", Value: bson.D{
+		{Key: "into", Value: bson.D{{Key: "db", Value: "reporting"}, {Key: "coll", Value: "budgets"}}},
+		{Key: "on", Value: []string{"dept", "fiscal_year"}},
+		{Key: "let", Value: bson.D{{Key: "year", Value: "2024"}}},
+		{Key: "whenMatched", Value: "replace"},
+		{Key: "whenNotMatched", Value: "insert"},
+	}}}}, NewStageBuilder().Merge(bson.D{{Key: "db", Value: "reporting"}, {Key: "coll", Value: "budgets"}}, &MergeOptions{
+		On:             []string{"dept", "fiscal_year"},
+		Let:            bson.D{{Key: "year", Value: "2024"}},
+		WhenMatched:    "replace",
+		WhenNotMatched: "insert",
+	}).Build())
+}
+
+func TestStageBuilder_GraphLookup(t *testing.T) {
+	maxDepth := int64(2)
+	assert.Equal(t, mongo.Pipeline{{{Key: "$graphLookup", Value: bson.D{
+		{Key: "from", Value: "employees"},
+		{Key: "startWith", Value: "$reportsTo"},
+		{Key: "connectFromField", Value: "reportsTo"},
+		{Key: "connectToField", Value: "name"},
+		{Key: "as", Value: "reportingHierarchy"},
+		{Key: "maxDepth", Value: int64(2)},
+		{Key: "depthField", Value: "depth"},
+		{Key: "restrictSearchWithMatch", Value: bson.D{{Key: "active", Value: true}}},
+	}}}}, NewStageBuilder().GraphLookup("employees", "$reportsTo", "reportsTo", "name", "reportingHierarchy", &GraphLookupOptions{
+		MaxDepth:                &maxDepth,
+		DepthField:              "depth",
+		RestrictSearchWithMatch: bson.D{{Key: "active", Value: true}},
+	}).Build())
+}
+
+func TestStageBuilder_Sample(t *testing.T) {
+	assert.Equal(t, mongo.Pipeline{{{Key: "$sample", Value: bson.D{{Key: "size", Value: int64(3)}}}}}, NewStageBuilder().Sample(3).Build())
+}
+
+func TestStageBuilder_Unset(t *testing.T) {
+	assert.Equal(t, mongo.Pipeline{{{Key: "$unset", Value: []string{"isbn", "copies"}}}}, NewStageBuilder().Unset("isbn", "copies").Build())
+}
+
+func TestStageBuilder_Redact(t *testing.T) {
+	expression := bson.D{{Key: "$cond", Value: bson.D{{Key: "if", Value: "$public"}, {Key: "then", Value: "$$DESCEND"}, {Key: "else", Value: "$$PRUNE"}}}}
+	assert.Equal(t, mongo.Pipeline{{{Key: "$redact", Value: expression}}}, NewStageBuilder().Redact(expression).Build())
+}
+
+func TestStageBuilder_Densify(t *testing.T) {
+	assert.Equal(t, mongo.Pipeline{{{Key: "$densify", Value: bson.D{
+		{Key: "field", Value: "timestamp"},
+		{Key: "partitionByFields", Value: []string{"sensor"}},
+		{Key: "range", Value: bson.D{{Key: "step", Value: 1}, {Key: "unit", Value: "hour"}, {Key: "bounds", Value: "full"}}},
+	}}}}, NewStageBuilder().Densify("timestamp", DensifyRange{Step: 1, Unit: "hour", Bounds: "full"}, &DensifyOptions{PartitionByFields: []string{"sensor"}}).Build())
+	assert.Equal(t, mongo.Pipeline{{{Key: "$densify", Value: bson.D{
+		{Key: "field", Value: "altitude"},
+		{Key: "range", Value: bson.D{{Key: "step", Value: 200}, {Key: "bounds", Value: []any{0, 1000}}}},
+	}}}}, NewStageBuilder().Densify("altitude", DensifyRange{Step: 200, Bounds: []any{0, 1000}}, nil).Build())
+}
+
+func TestStageBuilder_Fill(t *testing.T) {
+	output := bson.D{{Key: "price", Value: bson.D{{Key: "method", Value: "linear"}}}}
+	assert.Equal(t, mongo.Pipeline{{{Key: "$fill", Value: bson.D{{Key: "output", Value: output}}}}}, NewStageBuilder().Fill(output, nil).Build())
+	assert.Equal(t, mongo.Pipeline{{{Key: "$fill", Value: bson.D{
+		{Key: "partitionBy", Value: "$restaurant"},
+		{Key: "sortBy", Value: bson.D{{Key: "date", Value: 1}}},
+		{Key: "output", Value: output},
+	}}}}, NewStageBuilder().Fill(output, &FillOptions{PartitionBy: "$restaurant", SortBy: bson.D{{Key: "date", Value: 1}}}).Build())
+}
+
+func TestStageBuilder_SetWindowFields(t *testing.T) {
+	output := NewBuilder().Sum("cumulativeQuantity", "$quantity").
+		Window("cumulativeQuantity", &WindowOptions{Documents: []any{"unbounded", "current"}}).
+		Rank("rank").Build()
+	assert.Equal(t, mongo.Pipeline{{{Key: "$setWindowFields", Value: bson.D{
+		{Key: "partitionBy", Value: "$state"},
+		{Key: "sortBy", Value: bson.D{{Key: "orderDate", Value: 1}}},
+		{Key: "output", Value: bson.D{
+			{Key: "cumulativeQuantity", Value: bson.D{
+				{Key: "$sum", Value: "$quantity"},
+				{Key: "window", Value: bson.D{{Key: "documents", Value: []any{"unbounded", "current"}}}},
+			}},
+			{Key: "rank", Value: bson.D{{Key: "$rank", Value: bson.D{}}}},
+		}},
+	}}}}, NewStageBuilder().SetWindowFields(output, &SetWindowFieldsOptions{PartitionBy: "$state", SortBy: bson.D{{Key: "orderDate", Value: 1}}}).Build())
+}
+
+func TestStageBuilder_GeoNear(t *testing.T) {
+	near := bson.D{{Key: "type", Value: "Point"}, {Key: "coordinates", Value: []float64{-73.99279, 40.719296}}}
+	assert.Equal(t, mongo.Pipeline{{{Key: "$geoNear", Value: bson.D{
+		{Key: "near", Value: near},
+		{Key: "distanceField", Value: "dist.calculated"},
+		{Key: "spherical", Value: true},
+		{Key: "maxDistance", Value: float64(2)},
+		{Key: "query", Value: bson.D{{Key: "category", Value: "Parks"}}},
+		{Key: "includeLocs", Value: "dist.location"},
+	}}}}, NewStageBuilder().GeoNear(near, "dist.calculated", &GeoNearOptions{
+		Spherical:   true,
+		MaxDistance: 2,
+		Query:       bson.D{{Key: "category", Value: "Parks"}},
+		IncludeLocs: "dist.location",
+	}).Build())
+}
+
+func TestStageBuilder_Documents(t *testing.T) {
+	documents := []bson.D{{{Key: "x", Value: 10}}, {{Key: "x", Value: 2}}}
+	assert.Equal(t, mongo.Pipeline{{{Key: "$documents", Value: documents}}}, NewStageBuilder().Documents(documents).Build())
+}
+
+func TestStageBuilder_CollStats(t *testing.T) {
+	assert.Equal(t, mongo.Pipeline{{{Key: "$collStats", Value: bson.D{}}}}, NewStageBuilder().CollStats(nil).Build())
+	assert.Equal(t, mongo.Pipeline{{{Key: "$collStats", Value: bson.D{
+		{Key: "latencyStats", Value: bson.D{{Key: "histograms", Value: true}}},
+		{Key: "storageStats", Value: bson.D{{Key: "scale", Value: int64(1024)}}},
+		{Key: "count", Value: bson.D{}},
+	}}}}, NewStageBuilder().CollStats(&CollStatsOptions{LatencyStats: true, LatencyHistograms: true, StorageStats: true, StorageScale: 1024, Count: true}).Build())
+}
+
+func TestStageBuilder_IndexStats(t *testing.T) {
+	assert.Equal(t, mongo.Pipeline{{{Key: "$indexStats", Value: bson.D{}}}}, NewStageBuilder().IndexStats().Build())
+}
@@ -137,3 +137,58 @@ func (b *arrayBuilder) FilterWithoutKey(inputArray any, cond any, opt *FilterOpt
 	b.parent.d = append(b.parent.d, bson.E{Key: FilterOp, Value: d})
 	return b.parent
 }
+
+// Reduce applies in to each element of inputArray and $$value, which starts with initialValue
+func (b *arrayBuilder) Reduce(key string, inputArray, initialValue, in any) *Builder {
+	e := bson.E{Key: ReduceOp, Value: bson.D{{Key: InputOp, Value: inputArray}, {Key: "initialValue", Value: initialValue}, {Key: InOp, Value: in}}}
+	if !b.parent.tryMergeValue(key, e) {
+		b.parent.d = append(b.parent.d, bson.E{Key: key, Value: bson.D{e}})
+	}
+	return b.parent
+}
+
+func (b *arrayBuilder) ReduceWithoutKey(inputArray, initialValue, in any) *Builder {
+	b.parent.d = append(b.parent.d, bson.E{Key: ReduceOp, Value: bson.D{{Key: InputOp, Value: inputArray}, {Key: "initialValue", Value: initialValue}, {Key: InOp, Value: in}}})
+	return b.parent
+}
+
+// Zip transposes the arrays of inputs, the result has the length of the shortest array unless opt.UseLongestLength is set
+func (b *arrayBuilder) Zip(key string, inputs []any, opt *ZipOptions) *Builder {
+	e := bson.E{Key: ZipOp, Value: zip(inputs, opt)}
+	if !b.parent.tryMergeValue(key, e) {
+		b.parent.d = append(b.parent.d, bson.E{Key: key, Value: bson.D{e}})
+	}
+	return b.parent
+}
+
+func (b *arrayBuilder) ZipWithoutKey(inputs []any, opt *ZipOptions) *Builder {
+	b.parent.d = append(b.parent.d, bson.E{Key: ZipOp, Value: zip(inputs, opt)})
+	return b.parent
+}
+
+// In returns whether expression is an element of array
+func (b *arrayBuilder) In(key string, expression, array any) *Builder {
+	e := bson.E{Key: InArrayOp, Value: []any{expression, array}}
+	if !b.parent.tryMergeValue(key, e) {
+		b.parent.d = append(b.parent.d, bson.E{Key: key, Value: bson.D{e}})
+	}
+	return b.parent
+}
+
+func (b *arrayBuilder) InWithoutKey(expression, array any) *Builder {
+	b.parent.d = append(b.parent.d, bson.E{Key: InArrayOp, Value: []any{expression, array}})
+	return b.parent
+}
+
+func zip(inputs []any, opt *ZipOptions) bson.D {
+	d := bson.D{{Key: "inputs", Value: inputs}}
+	if opt != nil {
+		if opt.UseLongestLength {
+			d = append(d, bson.E{Key: "useLongestLength", Value: opt.UseLongestLength})
+		}
+		if len(opt.Defaults) > 0 {
+			d = append(d, bson.E{Key: "defaults", Value: opt.Defaults})
+		}
+	}
+	return d
+}
@@ -274,3 +274,41 @@ func Test_arrayBuilder_FilterWithoutKey(t *testing.T) {
 		})
 	}
 }
+
+func Test_arrayBuilder_Reduce(t *testing.T) {
+	in := ConcatWithoutKey("$$value", "$$this")
+	assert.Equal(t,
+		bson.D{{Key: "sentence", Value: bson.D{{Key: "$reduce", Value: bson.D{{Key: "input", Value: "$words"}, {Key: "initialValue", Value: ""}, {Key: "in", Value: in}}}}}},
+		NewBuilder().Reduce("sentence", "$words", "", in).Build(),
+	)
+	assert.Equal(t,
+		bson.D{{Key: "$reduce", Value: bson.D{{Key: "input", Value: "$words"}, {Key: "initialValue", Value: ""}, {Key: "in", Value: in}}}},
+		NewBuilder().ReduceWithoutKey("$words", "", in).Build(),
+	)
+}
+
+func Test_arrayBuilder_Zip(t *testing.T) {
+	assert.Equal(t,
+		bson.D{{Key: "pairs", Value: bson.D{{Key: "$zip", Value: bson.D{{Key: "inputs", Value: []any{"$a", "$b"}}}}}}},
+		NewBuilder().Zip("pairs", []any{"$a", "$b"}, nil).Build(),
+	)
+	assert.Equal(t,
+		bson.D{{Key: "$zip", Value: bson.D{
+			{Key: "inputs", Value: []any{"$a", "$b"}},
+			{Key: "useLongestLength", Value: true},
+			{Key: "defaults", Value: []any{0, 0}},
+		}}},
+		NewBuilder().ZipWithoutKey([]any{"$a", "$b"}, &ZipOptions{UseLongestLength: true, Defaults: []any{0, 0}}).Build(),
+	)
+}
+
+func Test_arrayBuilder_In(t *testing.T) {
+	assert.Equal(t,
+		bson.D{{Key: "hasBananas", Value: bson.D{{Key: "$in", Value: []any{"bananas", "$in_stock"}}}}},
+		NewBuilder().In("hasBananas", "bananas", "$in_stock").Build(),
+	)
+	assert.Equal(t,
+		bson.D{{Key: "$in", Value: []any{"bananas", "$in_stock"}}},
+		NewBuilder().InWithoutKey("bananas", "$in_stock").Build(),
+	)
+}
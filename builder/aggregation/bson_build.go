@@ -50,6 +50,22 @@ func Contact(key string, expressions ...any) bson.D {
 	return bson.D{bson.E{Key: key, Value: bson.D{bson.E{Key: ContactOp, Value: expressions}}}}
 }
 
+func Convert(key string, input, to any, opt *ConvertOptions) bson.D {
+	return bson.D{bson.E{Key: key, Value: bson.D{bson.E{Key: ConvertOp, Value: convert(input, to, opt)}}}}
+}
+
+func DateAdd(key string, startDate any, unit string, amount any, timezone string) bson.D {
+	return bson.D{bson.E{Key: key, Value: bson.D{bson.E{Key: DateAddOp, Value: dateAdd(startDate, unit, amount, timezone)}}}}
+}
+
+func DateDiff(key string, startDate, endDate any, unit string, opt *DateDiffOptions) bson.D {
+	return bson.D{bson.E{Key: key, Value: bson.D{bson.E{Key: DateDiffOp, Value: dateDiff(startDate, endDate, unit, opt)}}}}
+}
+
+func DateFromString(key string, dateString any, opt *DateFromStringOptions) bson.D {
+	return bson.D{bson.E{Key: key, Value: bson.D{bson.E{Key: DateFromStringOp, Value: dateFromString(dateString, opt)}}}}
+}
+
 func DateToString(key string, date any, opt *DateToStringOptions) bson.D {
 	d := bson.D{bson.E{Key: DateOp, Value: date}}
 	if opt != nil {
@@ -66,6 +82,10 @@ func DateToString(key string, date any, opt *DateToStringOptions) bson.D {
 	return bson.D{bson.E{Key: key, Value: bson.D{bson.E{Key: DateToStringOp, Value: d}}}}
 }
 
+func DateTrunc(key string, date any, unit string, opt *DateTruncOptions) bson.D {
+	return bson.D{bson.E{Key: key, Value: bson.D{bson.E{Key: DateTruncOp, Value: dateTrunc(date, unit, opt)}}}}
+}
+
 func DayOfMonth(key string, date time.Time) bson.D {
 	return bson.D{bson.E{Key: key, Value: bson.D{bson.E{Key: DayOfMonthOp, Value: date}}}}
 }
@@ -90,10 +110,22 @@ func DayOfYearWithTimezone(key string, date time.Time, timezone string) bson.D {
 	return bson.D{bson.E{Key: key, Value: bson.D{bson.E{Key: DayOfYearOp, Value: bson.D{bson.E{Key: DateOp, Value: date}, bson.E{Key: TimezoneOp, Value: timezone}}}}}}
 }
 
+func DenseRank(key string) bson.D {
+	return bson.D{bson.E{Key: key, Value: bson.D{bson.E{Key: DenseRankOp, Value: bson.D{}}}}}
+}
+
+func Derivative(key string, input any, unit string) bson.D {
+	return bson.D{bson.E{Key: key, Value: bson.D{bson.E{Key: DerivativeOp, Value: inputUnit(input, unit)}}}}
+}
+
 func Divide(key string, expressions ...any) bson.D {
 	return bson.D{bson.E{Key: key, Value: bson.D{bson.E{Key: DivideOp, Value: expressions}}}}
 }
 
+func DocumentNumber(key string) bson.D {
+	return bson.D{bson.E{Key: key, Value: bson.D{bson.E{Key: DocumentNumberOp, Value: bson.D{}}}}}
+}
+
 func Eq(key string, expressions ...any) bson.D {
 	return bson.D{bson.E{Key: key, Value: bson.D{bson.E{Key: EqOp, Value: expressions}}}}
 }
@@ -102,6 +134,10 @@ func Exp(key string, exponent any) bson.D {
 	return bson.D{bson.E{Key: key, Value: bson.D{bson.E{Key: ExpOp, Value: exponent}}}}
 }
 
+func ExpMovingAvg(key string, input any, n int64) bson.D {
+	return bson.D{bson.E{Key: key, Value: bson.D{bson.E{Key: ExpMovingAvgOp, Value: bson.D{{Key: "input", Value: input}, {Key: "N", Value: n}}}}}}
+}
+
 func Filter(key string, inputArray any, cond any, opt *FilterOptions) bson.D {
 	d := bson.D{bson.E{Key: InputOp, Value: inputArray}, {Key: CondWithoutOperatorOp, Value: cond}}
 	if opt != nil {
@@ -123,6 +159,10 @@ func Floor(key string, numberExpression any) bson.D {
 	return bson.D{bson.E{Key: key, Value: bson.D{bson.E{Key: FloorOp, Value: numberExpression}}}}
 }
 
+func GetField(key string, field, input any) bson.D {
+	return bson.D{bson.E{Key: key, Value: bson.D{bson.E{Key: GetFieldOp, Value: getField(field, input)}}}}
+}
+
 func Gt(key string, expressions ...any) bson.D {
 	return bson.D{bson.E{Key: key, Value: bson.D{bson.E{Key: GtOp, Value: expressions}}}}
 }
@@ -135,10 +175,22 @@ func IfNull(key string, expr, replacement any) bson.D {
 	return bson.D{bson.E{Key: key, Value: bson.D{bson.E{Key: IfNullOp, Value: bson.A{expr, replacement}}}}}
 }
 
+func In(key string, expression, array any) bson.D {
+	return bson.D{bson.E{Key: key, Value: bson.D{bson.E{Key: InArrayOp, Value: []any{expression, array}}}}}
+}
+
+func Integral(key string, input any, unit string) bson.D {
+	return bson.D{bson.E{Key: key, Value: bson.D{bson.E{Key: IntegralOp, Value: inputUnit(input, unit)}}}}
+}
+
 func Last(key string, expression any) bson.D {
 	return bson.D{bson.E{Key: key, Value: bson.D{bson.E{Key: LastOp, Value: expression}}}}
 }
 
+func Let(key string, vars, in any) bson.D {
+	return bson.D{bson.E{Key: key, Value: bson.D{bson.E{Key: LetOp, Value: bson.D{{Key: "vars", Value: vars}, {Key: InOp, Value: in}}}}}}
+}
+
 func Ln(key string, numberExpression any) bson.D {
 	return bson.D{bson.E{Key: key, Value: bson.D{bson.E{Key: LnOp, Value: numberExpression}}}}
 }
@@ -167,6 +219,10 @@ func Max(key string, expression any) bson.D {
 	return bson.D{bson.E{Key: key, Value: bson.D{bson.E{Key: MaxOp, Value: expression}}}}
 }
 
+func MergeObjects(key string, documents ...any) bson.D {
+	return bson.D{bson.E{Key: key, Value: bson.D{bson.E{Key: MergeObjectsOp, Value: documents}}}}
+}
+
 func Min(key string, expression any) bson.D {
 	return bson.D{bson.E{Key: key, Value: bson.D{bson.E{Key: MinOp, Value: expression}}}}
 }
@@ -195,6 +251,10 @@ func Not(key string, expressions ...any) bson.D {
 	return bson.D{bson.E{Key: key, Value: bson.D{bson.E{Key: NotOp, Value: expressions}}}}
 }
 
+func ObjectToArray(key string, expression any) bson.D {
+	return bson.D{bson.E{Key: key, Value: bson.D{bson.E{Key: ObjectToArrayOp, Value: expression}}}}
+}
+
 func Or(key string, expressions ...any) bson.D {
 	return bson.D{bson.E{Key: key, Value: bson.D{bson.E{Key: OrOp, Value: expressions}}}}
 }
@@ -207,10 +267,54 @@ func Push(key string, expression any) bson.D {
 	return bson.D{bson.E{Key: key, Value: bson.D{bson.E{Key: PushOp, Value: expression}}}}
 }
 
+func Rank(key string) bson.D {
+	return bson.D{bson.E{Key: key, Value: bson.D{bson.E{Key: RankOp, Value: bson.D{}}}}}
+}
+
+func Reduce(key string, inputArray, initialValue, in any) bson.D {
+	return bson.D{bson.E{Key: key, Value: bson.D{bson.E{Key: ReduceOp, Value: bson.D{{Key: InputOp, Value: inputArray}, {Key: "initialValue", Value: initialValue}, {Key: InOp, Value: in}}}}}}
+}
+
+func RegexFind(key string, input, regex any, options string) bson.D {
+	return bson.D{bson.E{Key: key, Value: bson.D{bson.E{Key: RegexFindOp, Value: regexInput(input, regex, options)}}}}
+}
+
+func RegexMatch(key string, input, regex any, options string) bson.D {
+	return bson.D{bson.E{Key: key, Value: bson.D{bson.E{Key: RegexMatchOp, Value: regexInput(input, regex, options)}}}}
+}
+
+func ReplaceAll(key string, input, find, replacement any) bson.D {
+	return bson.D{bson.E{Key: key, Value: bson.D{bson.E{Key: ReplaceAllOp, Value: bson.D{{Key: InputOp, Value: input}, {Key: "find", Value: find}, {Key: "replacement", Value: replacement}}}}}}
+}
+
 func Round(key string, numberExpression, placeExpression any) bson.D {
 	return bson.D{bson.E{Key: key, Value: bson.D{bson.E{Key: RoundOp, Value: bson.A{numberExpression, placeExpression}}}}}
 }
 
+func SetDifference(key string, array1, array2 any) bson.D {
+	return bson.D{bson.E{Key: key, Value: bson.D{bson.E{Key: SetDifferenceOp, Value: []any{array1, array2}}}}}
+}
+
+func SetField(key string, field, input, value any) bson.D {
+	return bson.D{bson.E{Key: key, Value: bson.D{bson.E{Key: SetFieldOp, Value: bson.D{{Key: "field", Value: field}, {Key: InputOp, Value: input}, {Key: "value", Value: value}}}}}}
+}
+
+func SetIntersection(key string, arrays ...any) bson.D {
+	return bson.D{bson.E{Key: key, Value: bson.D{bson.E{Key: SetIntersectionOp, Value: arrays}}}}
+}
+
+func SetIsSubset(key string, array1, array2 any) bson.D {
+	return bson.D{bson.E{Key: key, Value: bson.D{bson.E{Key: SetIsSubsetOp, Value: []any{array1, array2}}}}}
+}
+
+func SetUnion(key string, arrays ...any) bson.D {
+	return bson.D{bson.E{Key: key, Value: bson.D{bson.E{Key: SetUnionOp, Value: arrays}}}}
+}
+
+func Shift(key string, output any, by int64, defaultValue any) bson.D {
+	return bson.D{bson.E{Key: key, Value: bson.D{bson.E{Key: ShiftOp, Value: shift(output, by, defaultValue)}}}}
+}
+
 func Size(key string, expression any) bson.D {
 	return bson.D{bson.E{Key: key, Value: bson.D{bson.E{Key: SizeOp, Value: expression}}}}
 }
@@ -223,10 +327,18 @@ func SliceWithPosition(key string, array any, position, nElements int64) bson.D
 	return bson.D{bson.E{Key: key, Value: bson.D{bson.E{Key: SliceOp, Value: []any{array, position, nElements}}}}}
 }
 
+func Split(key string, expression, delimiter any) bson.D {
+	return bson.D{bson.E{Key: key, Value: bson.D{bson.E{Key: SplitOp, Value: []any{expression, delimiter}}}}}
+}
+
 func Sqrt(key string, numberExpression any) bson.D {
 	return bson.D{bson.E{Key: key, Value: bson.D{bson.E{Key: SqrtOp, Value: numberExpression}}}}
 }
 
+func StrLenCP(key string, expression any) bson.D {
+	return bson.D{bson.E{Key: key, Value: bson.D{bson.E{Key: StrLenCPOp, Value: expression}}}}
+}
+
 func SubstrBytes(key string, stringExpression string, byteIndex int64, byteCount int64) bson.D {
 	return bson.D{bson.E{Key: key, Value: bson.D{bson.E{Key: SubstrBytesOp, Value: []any{stringExpression, byteIndex, byteCount}}}}}
 }
@@ -247,14 +359,30 @@ func Switch(key string, cases []CaseThen, defaultCase any) bson.D {
 	return bson.D{bson.E{Key: key, Value: bson.D{bson.E{Key: SwitchOp, Value: bson.D{bson.E{Key: BranchesOp, Value: branches}, bson.E{Key: DefaultCaseOp, Value: defaultCase}}}}}}
 }
 
+func ToDate(key string, expression any) bson.D {
+	return bson.D{bson.E{Key: key, Value: bson.D{bson.E{Key: ToDateOp, Value: expression}}}}
+}
+
 func ToLower(key string, expression any) bson.D {
 	return bson.D{bson.E{Key: key, Value: bson.D{bson.E{Key: ToLowerOp, Value: expression}}}}
 }
 
+func ToObjectId(key string, expression any) bson.D {
+	return bson.D{bson.E{Key: key, Value: bson.D{bson.E{Key: ToObjectIdOp, Value: expression}}}}
+}
+
+func ToString(key string, expression any) bson.D {
+	return bson.D{bson.E{Key: key, Value: bson.D{bson.E{Key: ToStringOp, Value: expression}}}}
+}
+
 func ToUpper(key string, expression any) bson.D {
 	return bson.D{bson.E{Key: key, Value: bson.D{bson.E{Key: ToUpperOp, Value: expression}}}}
 }
 
+func Trim(key string, input, chars any) bson.D {
+	return bson.D{bson.E{Key: key, Value: bson.D{bson.E{Key: TrimOp, Value: trim(input, chars)}}}}
+}
+
 func Trunc(key string, numberExpression, placeExpression any) bson.D {
 	return bson.D{bson.E{Key: key, Value: bson.D{bson.E{Key: TruncOp, Value: bson.A{numberExpression, placeExpression}}}}}
 }
@@ -274,3 +402,7 @@ func Year(key string, date time.Time) bson.D {
 func YearWithTimezone(key string, date time.Time, timezone string) bson.D {
 	return bson.D{bson.E{Key: key, Value: bson.D{bson.E{Key: YearOp, Value: bson.D{bson.E{Key: DateOp, Value: date}, bson.E{Key: TimezoneOp, Value: timezone}}}}}}
 }
+
+func Zip(key string, inputs []any, opt *ZipOptions) bson.D {
+	return bson.D{bson.E{Key: key, Value: bson.D{bson.E{Key: ZipOp, Value: zip(inputs, opt)}}}}
+}
@@ -528,3 +528,43 @@ func TestTrunc(t *testing.T) {
 		)
 	})
 }
+
+func TestExpressionOperators(t *testing.T) {
+	testCases := []struct {
+		name string
+		got  bson.D
+		want bson.D
+	}{
+		{name: "convert", got: Convert("qty", "$qty", "int", nil), want: NewBuilder().Convert("qty", "$qty", "int", nil).Build()},
+		{name: "to string", got: ToString("zip", "$zip"), want: NewBuilder().ToString("zip", "$zip").Build()},
+		{name: "to object id", got: ToObjectId("id", "$id"), want: NewBuilder().ToObjectId("id", "$id").Build()},
+		{name: "to date", got: ToDate("date", "$date"), want: NewBuilder().ToDate("date", "$date").Build()},
+		{name: "set union", got: SetUnion("all", "$a", "$b"), want: NewBuilder().SetUnion("all", "$a", "$b").Build()},
+		{name: "set intersection", got: SetIntersection("common", "$a", "$b"), want: NewBuilder().SetIntersection("common", "$a", "$b").Build()},
+		{name: "set difference", got: SetDifference("onlyA", "$a", "$b"), want: NewBuilder().SetDifference("onlyA", "$a", "$b").Build()},
+		{name: "set is subset", got: SetIsSubset("subset", "$a", "$b"), want: NewBuilder().SetIsSubset("subset", "$a", "$b").Build()},
+		{name: "reduce", got: Reduce("sum", "$a", 0, "$$this"), want: NewBuilder().Reduce("sum", "$a", 0, "$$this").Build()},
+		{name: "zip", got: Zip("pairs", []any{"$a", "$b"}, nil), want: NewBuilder().Zip("pairs", []any{"$a", "$b"}, nil).Build()},
+		{name: "in", got: In("has", "x", "$a"), want: NewBuilder().In("has", "x", "$a").Build()},
+		{name: "regex match", got: RegexMatch("m", "$s", "^a", "i"), want: NewBuilder().RegexMatch("m", "$s", "^a", "i").Build()},
+		{name: "regex find", got: RegexFind("f", "$s", "^a", ""), want: NewBuilder().RegexFind("f", "$s", "^a", "").Build()},
+		{name: "split", got: Split("parts", "$s", ","), want: NewBuilder().Split("parts", "$s", ",").Build()},
+		{name: "trim", got: Trim("s", "$s", nil), want: NewBuilder().Trim("s", "$s", nil).Build()},
+		{name: "replace all", got: ReplaceAll("s", "$s", "a", "b"), want: NewBuilder().ReplaceAll("s", "$s", "a", "b").Build()},
+		{name: "str len cp", got: StrLenCP("n", "$s"), want: NewBuilder().StrLenCP("n", "$s").Build()},
+		{name: "date add", got: DateAdd("d", "$d", "day", 1, ""), want: NewBuilder().DateAdd("d", "$d", "day", 1, "").Build()},
+		{name: "date diff", got: DateDiff("d", "$a", "$b", "day", nil), want: NewBuilder().DateDiff("d", "$a", "$b", "day", nil).Build()},
+		{name: "date trunc", got: DateTrunc("d", "$d", "month", nil), want: NewBuilder().DateTrunc("d", "$d", "month", nil).Build()},
+		{name: "date from string", got: DateFromString("d", "$s", nil), want: NewBuilder().DateFromString("d", "$s", nil).Build()},
+		{name: "merge objects", got: MergeObjects("o", "$a", "$b"), want: NewBuilder().MergeObjects("o", "$a", "$b").Build()},
+		{name: "object to array", got: ObjectToArray("o", "$o"), want: NewBuilder().ObjectToArray("o", "$o").Build()},
+		{name: "get field", got: GetField("f", "a.b", nil), want: NewBuilder().GetField("f", "a.b", nil).Build()},
+		{name: "set field", got: SetField("o", "a.b", "$$ROOT", 1), want: NewBuilder().SetField("o", "a.b", "$$ROOT", 1).Build()},
+		{name: "let", got: Let("v", bson.D{{Key: "x", Value: 1}}, "$$x"), want: NewBuilder().Let("v", bson.D{{Key: "x", Value: 1}}, "$$x").Build()},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.got)
+		})
+	}
+}
@@ -233,3 +233,140 @@ func ToUpperWithoutKey(expression any) bson.D {
 func ContactWithoutKey(expressions ...any) bson.D {
 	return bson.D{{Key: ContactOp, Value: expressions}}
 }
+
+func RankWithoutKey() bson.D {
+	return bson.D{{Key: RankOp, Value: bson.D{}}}
+}
+
+func DenseRankWithoutKey() bson.D {
+	return bson.D{{Key: DenseRankOp, Value: bson.D{}}}
+}
+
+func DocumentNumberWithoutKey() bson.D {
+	return bson.D{{Key: DocumentNumberOp, Value: bson.D{}}}
+}
+
+func ShiftWithoutKey(output any, by int64, defaultValue any) bson.D {
+	return bson.D{{Key: ShiftOp, Value: shift(output, by, defaultValue)}}
+}
+
+func DerivativeWithoutKey(input any, unit string) bson.D {
+	return bson.D{{Key: DerivativeOp, Value: inputUnit(input, unit)}}
+}
+
+func IntegralWithoutKey(input any, unit string) bson.D {
+	return bson.D{{Key: IntegralOp, Value: inputUnit(input, unit)}}
+}
+
+func ExpMovingAvgWithoutKey(input any, n int64) bson.D {
+	return bson.D{{Key: ExpMovingAvgOp, Value: bson.D{{Key: "input", Value: input}, {Key: "N", Value: n}}}}
+}
+
+// Window returns the window of a window operator, e.g. append(SumWithoutKey("$qty"), Window(opt)...)
+func Window(opt *WindowOptions) bson.D {
+	return bson.D{{Key: WindowOp, Value: window(opt)}}
+}
+
+func ConvertWithoutKey(input, to any, opt *ConvertOptions) bson.D {
+	return bson.D{{Key: ConvertOp, Value: convert(input, to, opt)}}
+}
+
+func ToStringWithoutKey(expression any) bson.D {
+	return bson.D{{Key: ToStringOp, Value: expression}}
+}
+
+func ToObjectIdWithoutKey(expression any) bson.D {
+	return bson.D{{Key: ToObjectIdOp, Value: expression}}
+}
+
+func ToDateWithoutKey(expression any) bson.D {
+	return bson.D{{Key: ToDateOp, Value: expression}}
+}
+
+func SetUnionWithoutKey(arrays ...any) bson.D {
+	return bson.D{{Key: SetUnionOp, Value: arrays}}
+}
+
+func SetIntersectionWithoutKey(arrays ...any) bson.D {
+	return bson.D{{Key: SetIntersectionOp, Value: arrays}}
+}
+
+func SetDifferenceWithoutKey(array1, array2 any) bson.D {
+	return bson.D{{Key: SetDifferenceOp, Value: []any{array1, array2}}}
+}
+
+func SetIsSubsetWithoutKey(array1, array2 any) bson.D {
+	return bson.D{{Key: SetIsSubsetOp, Value: []any{array1, array2}}}
+}
+
+func ReduceWithoutKey(inputArray, initialValue, in any) bson.D {
+	return bson.D{{Key: ReduceOp, Value: bson.D{{Key: InputOp, Value: inputArray}, {Key: "initialValue", Value: initialValue}, {Key: InOp, Value: in}}}}
+}
+
+func ZipWithoutKey(inputs []any, opt *ZipOptions) bson.D {
+	return bson.D{{Key: ZipOp, Value: zip(inputs, opt)}}
+}
+
+func InWithoutKey(expression, array any) bson.D {
+	return bson.D{{Key: InArrayOp, Value: []any{expression, array}}}
+}
+
+func RegexMatchWithoutKey(input, regex any, options string) bson.D {
+	return bson.D{{Key: RegexMatchOp, Value: regexInput(input, regex, options)}}
+}
+
+func RegexFindWithoutKey(input, regex any, options string) bson.D {
+	return bson.D{{Key: RegexFindOp, Value: regexInput(input, regex, options)}}
+}
+
+func SplitWithoutKey(expression, delimiter any) bson.D {
+	return bson.D{{Key: SplitOp, Value: []any{expression, delimiter}}}
+}
+
+func TrimWithoutKey(input, chars any) bson.D {
+	return bson.D{{Key: TrimOp, Value: trim(input, chars)}}
+}
+
+func ReplaceAllWithoutKey(input, find, replacement any) bson.D {
+	return bson.D{{Key: ReplaceAllOp, Value: bson.D{{Key: InputOp, Value: input}, {Key: "find", Value: find}, {Key: "replacement", Value: replacement}}}}
+}
+
+func StrLenCPWithoutKey(expression any) bson.D {
+	return bson.D{{Key: StrLenCPOp, Value: expression}}
+}
+
+func DateAddWithoutKey(startDate any, unit string, amount any, timezone string) bson.D {
+	return bson.D{{Key: DateAddOp, Value: dateAdd(startDate, unit, amount, timezone)}}
+}
+
+func DateDiffWithoutKey(startDate, endDate any, unit string, opt *DateDiffOptions) bson.D {
+	return bson.D{{Key: DateDiffOp, Value: dateDiff(startDate, endDate, unit, opt)}}
+}
+
+func DateTruncWithoutKey(date any, unit string, opt *DateTruncOptions) bson.D {
+	return bson.D{{Key: DateTruncOp, Value: dateTrunc(date, unit, opt)}}
+}
+
+func DateFromStringWithoutKey(dateString any, opt *DateFromStringOptions) bson.D {
+	return bson.D{{Key: DateFromStringOp, Value: dateFromString(dateString, opt)}}
+}
+
+func MergeObjectsWithoutKey(documents ...any) bson.D {
+	return bson.D{{Key: MergeObjectsOp, Value: documents}}
+}
+
+func ObjectToArrayWithoutKey(expression any) bson.D {
+	return bson.D{{Key: ObjectToArrayOp, Value: expression}}
+}
+
+func GetFieldWithoutKey(field, input any) bson.D {
+	return bson.D{{Key: GetFieldOp, Value: getField(field, input)}}
+}
+
+func SetFieldWithoutKey(field, input, value any) bson.D {
+	return bson.D{{Key: SetFieldOp, Value: bson.D{{Key: "field", Value: field}, {Key: InputOp, Value: input}, {Key: "value", Value: value}}}}
+}
+
+func LetWithoutKey(vars, in any) bson.D {
+	return bson.D{{Key: LetOp, Value: bson.D{{Key: "vars", Value: vars}, {Key: InOp, Value: in}}}}
+}
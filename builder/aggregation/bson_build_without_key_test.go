@@ -1258,3 +1258,43 @@ func Test_ContactWithoutKey(t *testing.T) {
 		})
 	}
 }
+
+func TestExpressionOperatorsWithoutKey(t *testing.T) {
+	testCases := []struct {
+		name string
+		got  bson.D
+		want bson.D
+	}{
+		{name: "convert", got: ConvertWithoutKey("$qty", "int", nil), want: NewBuilder().ConvertWithoutKey("$qty", "int", nil).Build()},
+		{name: "to string", got: ToStringWithoutKey("$zip"), want: NewBuilder().ToStringWithoutKey("$zip").Build()},
+		{name: "to object id", got: ToObjectIdWithoutKey("$id"), want: NewBuilder().ToObjectIdWithoutKey("$id").Build()},
+		{name: "to date", got: ToDateWithoutKey("$date"), want: NewBuilder().ToDateWithoutKey("$date").Build()},
+		{name: "set union", got: SetUnionWithoutKey("$a", "$b"), want: NewBuilder().SetUnionWithoutKey("$a", "$b").Build()},
+		{name: "set intersection", got: SetIntersectionWithoutKey("$a", "$b"), want: NewBuilder().SetIntersectionWithoutKey("$a", "$b").Build()},
+		{name: "set difference", got: SetDifferenceWithoutKey("$a", "$b"), want: NewBuilder().SetDifferenceWithoutKey("$a", "$b").Build()},
+		{name: "set is subset", got: SetIsSubsetWithoutKey("$a", "$b"), want: NewBuilder().SetIsSubsetWithoutKey("$a", "$b").Build()},
+		{name: "reduce", got: ReduceWithoutKey("$a", 0, "$$this"), want: NewBuilder().ReduceWithoutKey("$a", 0, "$$this").Build()},
+		{name: "zip", got: ZipWithoutKey([]any{"$a"}, nil), want: NewBuilder().ZipWithoutKey([]any{"$a"}, nil).Build()},
+		{name: "in", got: InWithoutKey("x", "$a"), want: NewBuilder().InWithoutKey("x", "$a").Build()},
+		{name: "regex match", got: RegexMatchWithoutKey("$s", "^a", "i"), want: NewBuilder().RegexMatchWithoutKey("$s", "^a", "i").Build()},
+		{name: "regex find", got: RegexFindWithoutKey("$s", "^a", ""), want: NewBuilder().RegexFindWithoutKey("$s", "^a", "").Build()},
+		{name: "split", got: SplitWithoutKey("$s", ","), want: NewBuilder().SplitWithoutKey("$s", ",").Build()},
+		{name: "trim", got: TrimWithoutKey("$s", "-"), want: NewBuilder().TrimWithoutKey("$s", "-").Build()},
+		{name: "replace all", got: ReplaceAllWithoutKey("$s", "a", "b"), want: NewBuilder().ReplaceAllWithoutKey("$s", "a", "b").Build()},
+		{name: "str len cp", got: StrLenCPWithoutKey("$s"), want: NewBuilder().StrLenCPWithoutKey("$s").Build()},
+		{name: "date add", got: DateAddWithoutKey("$d", "day", 1, "UTC"), want: NewBuilder().DateAddWithoutKey("$d", "day", 1, "UTC").Build()},
+		{name: "date diff", got: DateDiffWithoutKey("$a", "$b", "day", nil), want: NewBuilder().DateDiffWithoutKey("$a", "$b", "day", nil).Build()},
+		{name: "date trunc", got: DateTruncWithoutKey("$d", "month", nil), want: NewBuilder().DateTruncWithoutKey("$d", "month", nil).Build()},
+		{name: "date from string", got: DateFromStringWithoutKey("$s", nil), want: NewBuilder().DateFromStringWithoutKey("$s", nil).Build()},
+		{name: "merge objects", got: MergeObjectsWithoutKey("$a", "$b"), want: NewBuilder().MergeObjectsWithoutKey("$a", "$b").Build()},
+		{name: "object to array", got: ObjectToArrayWithoutKey("$o"), want: NewBuilder().ObjectToArrayWithoutKey("$o").Build()},
+		{name: "get field", got: GetFieldWithoutKey("a", "$o"), want: NewBuilder().GetFieldWithoutKey("a", "$o").Build()},
+		{name: "set field", got: SetFieldWithoutKey("a", "$o", 1), want: NewBuilder().SetFieldWithoutKey("a", "$o", 1).Build()},
+		{name: "let", got: LetWithoutKey(bson.D{{Key: "x", Value: 1}}, "$$x"), want: NewBuilder().LetWithoutKey(bson.D{{Key: "x", Value: 1}}, "$$x").Build()},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.got)
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package aggregation
+
+import (
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// conversionBuilder builds the type conversion operators
+type conversionBuilder struct {
+	parent *Builder
+}
+
+// Convert converts input to the type to, e.g. "int", onError and onNull of opt are returned if the conversion fails
+// or input is null
+func (b *conversionBuilder) Convert(key string, input, to any, opt *ConvertOptions) *Builder {
+	e := bson.E{Key: ConvertOp, Value: convert(input, to, opt)}
+	if !b.parent.tryMergeValue(key, e) {
+		b.parent.d = append(b.parent.d, bson.E{Key: key, Value: bson.D{e}})
+	}
+	return b.parent
+}
+
+func (b *conversionBuilder) ConvertWithoutKey(input, to any, opt *ConvertOptions) *Builder {
+	b.parent.d = append(b.parent.d, bson.E{Key: ConvertOp, Value: convert(input, to, opt)})
+	return b.parent
+}
+
+func (b *conversionBuilder) ToString(key string, expression any) *Builder {
+	e := bson.E{Key: ToStringOp, Value: expression}
+	if !b.parent.tryMergeValue(key, e) {
+		b.parent.d = append(b.parent.d, bson.E{Key: key, Value: bson.D{e}})
+	}
+	return b.parent
+}
+
+func (b *conversionBuilder) ToStringWithoutKey(expression any) *Builder {
+	b.parent.d = append(b.parent.d, bson.E{Key: ToStringOp, Value: expression})
+	return b.parent
+}
+
+func (b *conversionBuilder) ToObjectId(key string, expression any) *Builder {
+	e := bson.E{Key: ToObjectIdOp, Value: expression}
+	if !b.parent.tryMergeValue(key, e) {
+		b.parent.d = append(b.parent.d, bson.E{Key: key, Value: bson.D{e}})
+	}
+	return b.parent
+}
+
+func (b *conversionBuilder) ToObjectIdWithoutKey(expression any) *Builder {
+	b.parent.d = append(b.parent.d, bson.E{Key: ToObjectIdOp, Value: expression})
+	return b.parent
+}
+
+func (b *conversionBuilder) ToDate(key string, expression any) *Builder {
+	e := bson.E{Key: ToDateOp, Value: expression}
+	if !b.parent.tryMergeValue(key, e) {
+		b.parent.d = append(b.parent.d, bson.E{Key: key, Value: bson.D{e}})
+	}
+	return b.parent
+}
+
+func (b *conversionBuilder) ToDateWithoutKey(expression any) *Builder {
+	b.parent.d = append(b.parent.d, bson.E{Key: ToDateOp, Value: expression})
+	return b.parent
+}
+
+func convert(input, to any, opt *ConvertOptions) bson.D {
+	d := bson.D{{Key: InputOp, Value: input}, {Key: "to", Value: to}}
+	if opt != nil {
+		if opt.OnError != nil {
+			d = append(d, bson.E{Key: "onError", Value: opt.OnError})
+		}
+		if opt.OnNull != nil {
+			d = append(d, bson.E{Key: OnNullOp, Value: opt.OnNull})
+		}
+	}
+	return d
+}
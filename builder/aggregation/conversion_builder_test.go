@@ -0,0 +1,64 @@
+package aggregation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func Test_conversionBuilder(t *testing.T) {
+	testCases := []struct {
+		name string
+		got  bson.D
+		want bson.D
+	}{
+		{
+			name: "convert",
+			got:  NewBuilder().Convert("price", "$price", "decimal", &ConvertOptions{OnError: "Error", OnNull: 0}).Build(),
+			want: bson.D{{Key: "price", Value: bson.D{{Key: "$convert", Value: bson.D{
+				{Key: "input", Value: "$price"}, {Key: "to", Value: "decimal"}, {Key: "onError", Value: "Error"}, {Key: "onNull", Value: 0},
+			}}}}},
+		},
+		{
+			name: "convert without key",
+			got:  NewBuilder().ConvertWithoutKey("$qty", "int", nil).Build(),
+			want: bson.D{{Key: "$convert", Value: bson.D{{Key: "input", Value: "$qty"}, {Key: "to", Value: "int"}}}},
+		},
+		{
+			name: "to string",
+			got:  NewBuilder().ToString("zip", "$zipcode").Build(),
+			want: bson.D{{Key: "zip", Value: bson.D{{Key: "$toString", Value: "$zipcode"}}}},
+		},
+		{
+			name: "to string without key",
+			got:  NewBuilder().ToStringWithoutKey("$zipcode").Build(),
+			want: bson.D{{Key: "$toString", Value: "$zipcode"}},
+		},
+		{
+			name: "to object id",
+			got:  NewBuilder().ToObjectId("userId", "$user_id").Build(),
+			want: bson.D{{Key: "userId", Value: bson.D{{Key: "$toObjectId", Value: "$user_id"}}}},
+		},
+		{
+			name: "to object id without key",
+			got:  NewBuilder().ToObjectIdWithoutKey("$user_id").Build(),
+			want: bson.D{{Key: "$toObjectId", Value: "$user_id"}},
+		},
+		{
+			name: "to date",
+			got:  NewBuilder().ToDate("date", "$order_date").Build(),
+			want: bson.D{{Key: "date", Value: bson.D{{Key: "$toDate", Value: "$order_date"}}}},
+		},
+		{
+			name: "to date without key",
+			got:  NewBuilder().ToDateWithoutKey("$order_date").Build(),
+			want: bson.D{{Key: "$toDate", Value: "$order_date"}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.got)
+		})
+	}
+}
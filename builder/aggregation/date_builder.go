@@ -202,3 +202,114 @@ func (b *dateBuilder) WeekWithTimezoneWithoutKey(date time.Time, timezone string
 	b.parent.d = append(b.parent.d, bson.E{Key: WeekOp, Value: bson.D{bson.E{Key: DateOp, Value: date}, bson.E{Key: TimezoneOp, Value: timezone}}})
 	return b.parent
 }
+
+// DateAdd adds amount of unit, e.g. "day", to startDate, timezone is left out if empty
+func (b *dateBuilder) DateAdd(key string, startDate any, unit string, amount any, timezone string) *Builder {
+	e := bson.E{Key: DateAddOp, Value: dateAdd(startDate, unit, amount, timezone)}
+	if !b.parent.tryMergeValue(key, e) {
+		b.parent.d = append(b.parent.d, bson.E{Key: key, Value: bson.D{e}})
+	}
+	return b.parent
+}
+
+func (b *dateBuilder) DateAddWithoutKey(startDate any, unit string, amount any, timezone string) *Builder {
+	b.parent.d = append(b.parent.d, bson.E{Key: DateAddOp, Value: dateAdd(startDate, unit, amount, timezone)})
+	return b.parent
+}
+
+// DateDiff returns the number of unit boundaries, e.g. "day", crossed between startDate and endDate
+func (b *dateBuilder) DateDiff(key string, startDate, endDate any, unit string, opt *DateDiffOptions) *Builder {
+	e := bson.E{Key: DateDiffOp, Value: dateDiff(startDate, endDate, unit, opt)}
+	if !b.parent.tryMergeValue(key, e) {
+		b.parent.d = append(b.parent.d, bson.E{Key: key, Value: bson.D{e}})
+	}
+	return b.parent
+}
+
+func (b *dateBuilder) DateDiffWithoutKey(startDate, endDate any, unit string, opt *DateDiffOptions) *Builder {
+	b.parent.d = append(b.parent.d, bson.E{Key: DateDiffOp, Value: dateDiff(startDate, endDate, unit, opt)})
+	return b.parent
+}
+
+// DateTrunc truncates date to unit, e.g. "month"
+func (b *dateBuilder) DateTrunc(key string, date any, unit string, opt *DateTruncOptions) *Builder {
+	e := bson.E{Key: DateTruncOp, Value: dateTrunc(date, unit, opt)}
+	if !b.parent.tryMergeValue(key, e) {
+		b.parent.d = append(b.parent.d, bson.E{Key: key, Value: bson.D{e}})
+	}
+	return b.parent
+}
+
+func (b *dateBuilder) DateTruncWithoutKey(date any, unit string, opt *DateTruncOptions) *Builder {
+	b.parent.d = append(b.parent.d, bson.E{Key: DateTruncOp, Value: dateTrunc(date, unit, opt)})
+	return b.parent
+}
+
+func (b *dateBuilder) DateFromString(key string, dateString any, opt *DateFromStringOptions) *Builder {
+	e := bson.E{Key: DateFromStringOp, Value: dateFromString(dateString, opt)}
+	if !b.parent.tryMergeValue(key, e) {
+		b.parent.d = append(b.parent.d, bson.E{Key: key, Value: bson.D{e}})
+	}
+	return b.parent
+}
+
+func (b *dateBuilder) DateFromStringWithoutKey(dateString any, opt *DateFromStringOptions) *Builder {
+	b.parent.d = append(b.parent.d, bson.E{Key: DateFromStringOp, Value: dateFromString(dateString, opt)})
+	return b.parent
+}
+
+func dateAdd(startDate any, unit string, amount any, timezone string) bson.D {
+	d := bson.D{{Key: "startDate", Value: startDate}, {Key: "unit", Value: unit}, {Key: "amount", Value: amount}}
+	if timezone != "" {
+		d = append(d, bson.E{Key: TimezoneOp, Value: timezone})
+	}
+	return d
+}
+
+func dateDiff(startDate, endDate any, unit string, opt *DateDiffOptions) bson.D {
+	d := bson.D{{Key: "startDate", Value: startDate}, {Key: "endDate", Value: endDate}, {Key: "unit", Value: unit}}
+	if opt != nil {
+		if opt.Timezone != "" {
+			d = append(d, bson.E{Key: TimezoneOp, Value: opt.Timezone})
+		}
+		if opt.StartOfWeek != "" {
+			d = append(d, bson.E{Key: "startOfWeek", Value: opt.StartOfWeek})
+		}
+	}
+	return d
+}
+
+func dateTrunc(date any, unit string, opt *DateTruncOptions) bson.D {
+	d := bson.D{{Key: DateOp, Value: date}, {Key: "unit", Value: unit}}
+	if opt != nil {
+		if opt.BinSize != nil {
+			d = append(d, bson.E{Key: "binSize", Value: opt.BinSize})
+		}
+		if opt.Timezone != "" {
+			d = append(d, bson.E{Key: TimezoneOp, Value: opt.Timezone})
+		}
+		if opt.StartOfWeek != "" {
+			d = append(d, bson.E{Key: "startOfWeek", Value: opt.StartOfWeek})
+		}
+	}
+	return d
+}
+
+func dateFromString(dateString any, opt *DateFromStringOptions) bson.D {
+	d := bson.D{{Key: "dateString", Value: dateString}}
+	if opt != nil {
+		if opt.Format != "" {
+			d = append(d, bson.E{Key: FormatOp, Value: opt.Format})
+		}
+		if opt.Timezone != "" {
+			d = append(d, bson.E{Key: TimezoneOp, Value: opt.Timezone})
+		}
+		if opt.OnError != nil {
+			d = append(d, bson.E{Key: "onError", Value: opt.OnError})
+		}
+		if opt.OnNull != nil {
+			d = append(d, bson.E{Key: OnNullOp, Value: opt.OnNull})
+		}
+	}
+	return d
+}
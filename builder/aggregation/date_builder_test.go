@@ -463,3 +463,56 @@ func Test_dateBuilder_DateToStringWithoutKey(t *testing.T) {
 		})
 	}
 }
+
+func Test_dateBuilder_DateAdd(t *testing.T) {
+	assert.Equal(t,
+		bson.D{{Key: "expectedDelivery", Value: bson.D{{Key: "$dateAdd", Value: bson.D{{Key: "startDate", Value: "$purchaseDate"}, {Key: "unit", Value: "day"}, {Key: "amount", Value: 3}}}}}},
+		NewBuilder().DateAdd("expectedDelivery", "$purchaseDate", "day", 3, "").Build(),
+	)
+	assert.Equal(t,
+		bson.D{{Key: "$dateAdd", Value: bson.D{{Key: "startDate", Value: "$purchaseDate"}, {Key: "unit", Value: "day"}, {Key: "amount", Value: 3}, {Key: "timezone", Value: "Asia/Shanghai"}}}},
+		NewBuilder().DateAddWithoutKey("$purchaseDate", "day", 3, "Asia/Shanghai").Build(),
+	)
+}
+
+func Test_dateBuilder_DateDiff(t *testing.T) {
+	assert.Equal(t,
+		bson.D{{Key: "days", Value: bson.D{{Key: "$dateDiff", Value: bson.D{{Key: "startDate", Value: "$purchased"}, {Key: "endDate", Value: "$delivered"}, {Key: "unit", Value: "day"}}}}}},
+		NewBuilder().DateDiff("days", "$purchased", "$delivered", "day", nil).Build(),
+	)
+	assert.Equal(t,
+		bson.D{{Key: "$dateDiff", Value: bson.D{
+			{Key: "startDate", Value: "$purchased"}, {Key: "endDate", Value: "$delivered"}, {Key: "unit", Value: "week"},
+			{Key: "timezone", Value: "UTC"}, {Key: "startOfWeek", Value: "monday"},
+		}}},
+		NewBuilder().DateDiffWithoutKey("$purchased", "$delivered", "week", &DateDiffOptions{Timezone: "UTC", StartOfWeek: "monday"}).Build(),
+	)
+}
+
+func Test_dateBuilder_DateTrunc(t *testing.T) {
+	assert.Equal(t,
+		bson.D{{Key: "month", Value: bson.D{{Key: "$dateTrunc", Value: bson.D{{Key: "date", Value: "$orderDate"}, {Key: "unit", Value: "month"}}}}}},
+		NewBuilder().DateTrunc("month", "$orderDate", "month", nil).Build(),
+	)
+	assert.Equal(t,
+		bson.D{{Key: "$dateTrunc", Value: bson.D{
+			{Key: "date", Value: "$orderDate"}, {Key: "unit", Value: "week"},
+			{Key: "binSize", Value: 2}, {Key: "timezone", Value: "UTC"}, {Key: "startOfWeek", Value: "monday"},
+		}}},
+		NewBuilder().DateTruncWithoutKey("$orderDate", "week", &DateTruncOptions{BinSize: 2, Timezone: "UTC", StartOfWeek: "monday"}).Build(),
+	)
+}
+
+func Test_dateBuilder_DateFromString(t *testing.T) {
+	assert.Equal(t,
+		bson.D{{Key: "date", Value: bson.D{{Key: "$dateFromString", Value: bson.D{{Key: "dateString", Value: "$date"}}}}}},
+		NewBuilder().DateFromString("date", "$date", nil).Build(),
+	)
+	assert.Equal(t,
+		bson.D{{Key: "$dateFromString", Value: bson.D{
+			{Key: "dateString", Value: "$date"}, {Key: "format", Value: "%Y-%m-%d"}, {Key: "timezone", Value: "UTC"},
+			{Key: "onError", Value: "$date"},
+		}}},
+		NewBuilder().DateFromStringWithoutKey("$date", &DateFromStringOptions{Format: "%Y-%m-%d", Timezone: "UTC", OnError: "$date"}).Build(),
+	)
+}
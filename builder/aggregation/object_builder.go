@@ -0,0 +1,72 @@
+package aggregation
+
+import (
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// objectBuilder builds the operators on the fields of documents
+type objectBuilder struct {
+	parent *Builder
+}
+
+func (b *objectBuilder) MergeObjects(key string, documents ...any) *Builder {
+	e := bson.E{Key: MergeObjectsOp, Value: documents}
+	if !b.parent.tryMergeValue(key, e) {
+		b.parent.d = append(b.parent.d, bson.E{Key: key, Value: bson.D{e}})
+	}
+	return b.parent
+}
+
+func (b *objectBuilder) MergeObjectsWithoutKey(documents ...any) *Builder {
+	b.parent.d = append(b.parent.d, bson.E{Key: MergeObjectsOp, Value: documents})
+	return b.parent
+}
+
+func (b *objectBuilder) ObjectToArray(key string, expression any) *Builder {
+	e := bson.E{Key: ObjectToArrayOp, Value: expression}
+	if !b.parent.tryMergeValue(key, e) {
+		b.parent.d = append(b.parent.d, bson.E{Key: key, Value: bson.D{e}})
+	}
+	return b.parent
+}
+
+func (b *objectBuilder) ObjectToArrayWithoutKey(expression any) *Builder {
+	b.parent.d = append(b.parent.d, bson.E{Key: ObjectToArrayOp, Value: expression})
+	return b.parent
+}
+
+// GetField returns the field of input, or of the current document if input is nil, it also reads the fields whose
+// names contain '.' or start with '$'
+func (b *objectBuilder) GetField(key string, field, input any) *Builder {
+	e := bson.E{Key: GetFieldOp, Value: getField(field, input)}
+	if !b.parent.tryMergeValue(key, e) {
+		b.parent.d = append(b.parent.d, bson.E{Key: key, Value: bson.D{e}})
+	}
+	return b.parent
+}
+
+func (b *objectBuilder) GetFieldWithoutKey(field, input any) *Builder {
+	b.parent.d = append(b.parent.d, bson.E{Key: GetFieldOp, Value: getField(field, input)})
+	return b.parent
+}
+
+// SetField returns input with field set to value, it also sets the fields whose names contain '.' or start with '$'
+func (b *objectBuilder) SetField(key string, field, input, value any) *Builder {
+	e := bson.E{Key: SetFieldOp, Value: bson.D{{Key: "field", Value: field}, {Key: InputOp, Value: input}, {Key: "value", Value: value}}}
+	if !b.parent.tryMergeValue(key, e) {
+		b.parent.d = append(b.parent.d, bson.E{Key: key, Value: bson.D{e}})
+	}
+	return b.parent
+}
+
+func (b *objectBuilder) SetFieldWithoutKey(field, input, value any) *Builder {
+	b.parent.d = append(b.parent.d, bson.E{Key: SetFieldOp, Value: bson.D{{Key: "field", Value: field}, {Key: InputOp, Value: input}, {Key: "value", Value: value}}})
+	return b.parent
+}
+
+func getField(field, input any) any {
+	if input == nil {
+		return field
+	}
+	return bson.D{{Key: "field", Value: field}, {Key: InputOp, Value: input}}
+}
@@ -0,0 +1,66 @@
+package aggregation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func Test_objectBuilder(t *testing.T) {
+	testCases := []struct {
+		name string
+		got  bson.D
+		want bson.D
+	}{
+		{
+			name: "merge objects",
+			got:  NewBuilder().MergeObjects("item", bson.D{{Key: "qty", Value: 0}}, "$item").Build(),
+			want: bson.D{{Key: "item", Value: bson.D{{Key: "$mergeObjects", Value: []any{bson.D{{Key: "qty", Value: 0}}, "$item"}}}}},
+		},
+		{
+			name: "merge objects without key",
+			got:  NewBuilder().MergeObjectsWithoutKey("$a", "$b").Build(),
+			want: bson.D{{Key: "$mergeObjects", Value: []any{"$a", "$b"}}},
+		},
+		{
+			name: "object to array",
+			got:  NewBuilder().ObjectToArray("dimensions", "$dimensions").Build(),
+			want: bson.D{{Key: "dimensions", Value: bson.D{{Key: "$objectToArray", Value: "$dimensions"}}}},
+		},
+		{
+			name: "object to array without key",
+			got:  NewBuilder().ObjectToArrayWithoutKey("$dimensions").Build(),
+			want: bson.D{{Key: "$objectToArray", Value: "$dimensions"}},
+		},
+		{
+			name: "get field of the current document",
+			got:  NewBuilder().GetField("price", "price.usd", nil).Build(),
+			want: bson.D{{Key: "price", Value: bson.D{{Key: "$getField", Value: "price.usd"}}}},
+		},
+		{
+			name: "get field without key",
+			got:  NewBuilder().GetFieldWithoutKey("$price", "$$item").Build(),
+			want: bson.D{{Key: "$getField", Value: bson.D{{Key: "field", Value: "$price"}, {Key: "input", Value: "$$item"}}}},
+		},
+		{
+			name: "set field",
+			got:  NewBuilder().SetField("item", "price.usd", "$$ROOT", 10).Build(),
+			want: bson.D{{Key: "item", Value: bson.D{{Key: "$setField", Value: bson.D{
+				{Key: "field", Value: "price.usd"}, {Key: "input", Value: "$$ROOT"}, {Key: "value", Value: 10},
+			}}}}},
+		},
+		{
+			name: "set field without key",
+			got:  NewBuilder().SetFieldWithoutKey("price.usd", "$$ROOT", 10).Build(),
+			want: bson.D{{Key: "$setField", Value: bson.D{
+				{Key: "field", Value: "price.usd"}, {Key: "input", Value: "$$ROOT"}, {Key: "value", Value: 10},
+			}}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.got)
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package aggregation
+
+import (
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// setBuilder builds the set operators, which treat arrays as sets
+type setBuilder struct {
+	parent *Builder
+}
+
+func (b *setBuilder) SetUnion(key string, arrays ...any) *Builder {
+	e := bson.E{Key: SetUnionOp, Value: arrays}
+	if !b.parent.tryMergeValue(key, e) {
+		b.parent.d = append(b.parent.d, bson.E{Key: key, Value: bson.D{e}})
+	}
+	return b.parent
+}
+
+func (b *setBuilder) SetUnionWithoutKey(arrays ...any) *Builder {
+	b.parent.d = append(b.parent.d, bson.E{Key: SetUnionOp, Value: arrays})
+	return b.parent
+}
+
+func (b *setBuilder) SetIntersection(key string, arrays ...any) *Builder {
+	e := bson.E{Key: SetIntersectionOp, Value: arrays}
+	if !b.parent.tryMergeValue(key, e) {
+		b.parent.d = append(b.parent.d, bson.E{Key: key, Value: bson.D{e}})
+	}
+	return b.parent
+}
+
+func (b *setBuilder) SetIntersectionWithoutKey(arrays ...any) *Builder {
+	b.parent.d = append(b.parent.d, bson.E{Key: SetIntersectionOp, Value: arrays})
+	return b.parent
+}
+
+func (b *setBuilder) SetDifference(key string, array1, array2 any) *Builder {
+	e := bson.E{Key: SetDifferenceOp, Value: []any{array1, array2}}
+	if !b.parent.tryMergeValue(key, e) {
+		b.parent.d = append(b.parent.d, bson.E{Key: key, Value: bson.D{e}})
+	}
+	return b.parent
+}
+
+func (b *setBuilder) SetDifferenceWithoutKey(array1, array2 any) *Builder {
+	b.parent.d = append(b.parent.d, bson.E{Key: SetDifferenceOp, Value: []any{array1, array2}})
+	return b.parent
+}
+
+func (b *setBuilder) SetIsSubset(key string, array1, array2 any) *Builder {
+	e := bson.E{Key: SetIsSubsetOp, Value: []any{array1, array2}}
+	if !b.parent.tryMergeValue(key, e) {
+		b.parent.d = append(b.parent.d, bson.E{Key: key, Value: bson.D{e}})
+	}
+	return b.parent
+}
+
+func (b *setBuilder) SetIsSubsetWithoutKey(array1, array2 any) *Builder {
+	b.parent.d = append(b.parent.d, bson.E{Key: SetIsSubsetOp, Value: []any{array1, array2}})
+	return b.parent
+}
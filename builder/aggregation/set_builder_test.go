@@ -0,0 +1,62 @@
+package aggregation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func Test_setBuilder(t *testing.T) {
+	testCases := []struct {
+		name string
+		got  bson.D
+		want bson.D
+	}{
+		{
+			name: "set union",
+			got:  NewBuilder().SetUnion("all", "$a", "$b").Build(),
+			want: bson.D{{Key: "all", Value: bson.D{{Key: "$setUnion", Value: []any{"$a", "$b"}}}}},
+		},
+		{
+			name: "set union without key",
+			got:  NewBuilder().SetUnionWithoutKey("$a", "$b").Build(),
+			want: bson.D{{Key: "$setUnion", Value: []any{"$a", "$b"}}},
+		},
+		{
+			name: "set intersection",
+			got:  NewBuilder().SetIntersection("common", "$a", "$b").Build(),
+			want: bson.D{{Key: "common", Value: bson.D{{Key: "$setIntersection", Value: []any{"$a", "$b"}}}}},
+		},
+		{
+			name: "set intersection without key",
+			got:  NewBuilder().SetIntersectionWithoutKey("$a", "$b").Build(),
+			want: bson.D{{Key: "$setIntersection", Value: []any{"$a", "$b"}}},
+		},
+		{
+			name: "set difference",
+			got:  NewBuilder().SetDifference("onlyA", "$a", "$b").Build(),
+			want: bson.D{{Key: "onlyA", Value: bson.D{{Key: "$setDifference", Value: []any{"$a", "$b"}}}}},
+		},
+		{
+			name: "set difference without key",
+			got:  NewBuilder().SetDifferenceWithoutKey("$a", "$b").Build(),
+			want: bson.D{{Key: "$setDifference", Value: []any{"$a", "$b"}}},
+		},
+		{
+			name: "set is subset",
+			got:  NewBuilder().SetIsSubset("subset", "$a", []string{"x", "y"}).Build(),
+			want: bson.D{{Key: "subset", Value: bson.D{{Key: "$setIsSubset", Value: []any{"$a", []string{"x", "y"}}}}}},
+		},
+		{
+			name: "set is subset without key",
+			got:  NewBuilder().SetIsSubsetWithoutKey("$a", "$b").Build(),
+			want: bson.D{{Key: "$setIsSubset", Value: []any{"$a", "$b"}}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.got)
+		})
+	}
+}
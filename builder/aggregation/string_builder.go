@@ -72,3 +72,100 @@ func (b *stringBuilder) ContactWithoutKey(expressions ...any) *Builder {
 	b.parent.d = append(b.parent.d, bson.E{Key: ContactOp, Value: expressions})
 	return b.parent
 }
+
+// RegexMatch returns whether input matches regex, options are the regex options such as "i", they are left out if empty
+func (b *stringBuilder) RegexMatch(key string, input, regex any, options string) *Builder {
+	e := bson.E{Key: RegexMatchOp, Value: regexInput(input, regex, options)}
+	if !b.parent.tryMergeValue(key, e) {
+		b.parent.d = append(b.parent.d, bson.E{Key: key, Value: bson.D{e}})
+	}
+	return b.parent
+}
+
+func (b *stringBuilder) RegexMatchWithoutKey(input, regex any, options string) *Builder {
+	b.parent.d = append(b.parent.d, bson.E{Key: RegexMatchOp, Value: regexInput(input, regex, options)})
+	return b.parent
+}
+
+// RegexFind returns the first match of regex in input, options are the regex options such as "i", they are left out if empty
+func (b *stringBuilder) RegexFind(key string, input, regex any, options string) *Builder {
+	e := bson.E{Key: RegexFindOp, Value: regexInput(input, regex, options)}
+	if !b.parent.tryMergeValue(key, e) {
+		b.parent.d = append(b.parent.d, bson.E{Key: key, Value: bson.D{e}})
+	}
+	return b.parent
+}
+
+func (b *stringBuilder) RegexFindWithoutKey(input, regex any, options string) *Builder {
+	b.parent.d = append(b.parent.d, bson.E{Key: RegexFindOp, Value: regexInput(input, regex, options)})
+	return b.parent
+}
+
+func (b *stringBuilder) Split(key string, expression, delimiter any) *Builder {
+	e := bson.E{Key: SplitOp, Value: []any{expression, delimiter}}
+	if !b.parent.tryMergeValue(key, e) {
+		b.parent.d = append(b.parent.d, bson.E{Key: key, Value: bson.D{e}})
+	}
+	return b.parent
+}
+
+func (b *stringBuilder) SplitWithoutKey(expression, delimiter any) *Builder {
+	b.parent.d = append(b.parent.d, bson.E{Key: SplitOp, Value: []any{expression, delimiter}})
+	return b.parent
+}
+
+// Trim removes the whitespace, or the characters of chars if it is not nil, from both ends of input
+func (b *stringBuilder) Trim(key string, input, chars any) *Builder {
+	e := bson.E{Key: TrimOp, Value: trim(input, chars)}
+	if !b.parent.tryMergeValue(key, e) {
+		b.parent.d = append(b.parent.d, bson.E{Key: key, Value: bson.D{e}})
+	}
+	return b.parent
+}
+
+func (b *stringBuilder) TrimWithoutKey(input, chars any) *Builder {
+	b.parent.d = append(b.parent.d, bson.E{Key: TrimOp, Value: trim(input, chars)})
+	return b.parent
+}
+
+func (b *stringBuilder) ReplaceAll(key string, input, find, replacement any) *Builder {
+	e := bson.E{Key: ReplaceAllOp, Value: bson.D{{Key: InputOp, Value: input}, {Key: "find", Value: find}, {Key: "replacement", Value: replacement}}}
+	if !b.parent.tryMergeValue(key, e) {
+		b.parent.d = append(b.parent.d, bson.E{Key: key, Value: bson.D{e}})
+	}
+	return b.parent
+}
+
+func (b *stringBuilder) ReplaceAllWithoutKey(input, find, replacement any) *Builder {
+	b.parent.d = append(b.parent.d, bson.E{Key: ReplaceAllOp, Value: bson.D{{Key: InputOp, Value: input}, {Key: "find", Value: find}, {Key: "replacement", Value: replacement}}})
+	return b.parent
+}
+
+func (b *stringBuilder) StrLenCP(key string, expression any) *Builder {
+	e := bson.E{Key: StrLenCPOp, Value: expression}
+	if !b.parent.tryMergeValue(key, e) {
+		b.parent.d = append(b.parent.d, bson.E{Key: key, Value: bson.D{e}})
+	}
+	return b.parent
+}
+
+func (b *stringBuilder) StrLenCPWithoutKey(expression any) *Builder {
+	b.parent.d = append(b.parent.d, bson.E{Key: StrLenCPOp, Value: expression})
+	return b.parent
+}
+
+func regexInput(input, regex any, options string) bson.D {
+	d := bson.D{{Key: InputOp, Value: input}, {Key: "regex", Value: regex}}
+	if options != "" {
+		d = append(d, bson.E{Key: "options", Value: options})
+	}
+	return d
+}
+
+func trim(input, chars any) bson.D {
+	d := bson.D{{Key: InputOp, Value: input}}
+	if chars != nil {
+		d = append(d, bson.E{Key: "chars", Value: chars})
+	}
+	return d
+}
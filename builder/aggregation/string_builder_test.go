@@ -145,3 +145,43 @@ func Test_stringBuilder_ContactWithoutKey(t *testing.T) {
 		})
 	}
 }
+
+func Test_stringBuilder_Regex(t *testing.T) {
+	assert.Equal(t,
+		bson.D{{Key: "matched", Value: bson.D{{Key: "$regexMatch", Value: bson.D{{Key: "input", Value: "$description"}, {Key: "regex", Value: "line"}, {Key: "options", Value: "i"}}}}}},
+		NewBuilder().RegexMatch("matched", "$description", "line", "i").Build(),
+	)
+	assert.Equal(t,
+		bson.D{{Key: "$regexMatch", Value: bson.D{{Key: "input", Value: "$description"}, {Key: "regex", Value: "line"}}}},
+		NewBuilder().RegexMatchWithoutKey("$description", "line", "").Build(),
+	)
+	assert.Equal(t,
+		bson.D{{Key: "found", Value: bson.D{{Key: "$regexFind", Value: bson.D{{Key: "input", Value: "$description"}, {Key: "regex", Value: "^a"}}}}}},
+		NewBuilder().RegexFind("found", "$description", "^a", "").Build(),
+	)
+	assert.Equal(t,
+		bson.D{{Key: "$regexFind", Value: bson.D{{Key: "input", Value: "$description"}, {Key: "regex", Value: "^a"}, {Key: "options", Value: "m"}}}},
+		NewBuilder().RegexFindWithoutKey("$description", "^a", "m").Build(),
+	)
+}
+
+func Test_stringBuilder_Split(t *testing.T) {
+	assert.Equal(t, bson.D{{Key: "parts", Value: bson.D{{Key: "$split", Value: []any{"$city", ", "}}}}}, NewBuilder().Split("parts", "$city", ", ").Build())
+	assert.Equal(t, bson.D{{Key: "$split", Value: []any{"$city", ", "}}}, NewBuilder().SplitWithoutKey("$city", ", ").Build())
+}
+
+func Test_stringBuilder_Trim(t *testing.T) {
+	assert.Equal(t, bson.D{{Key: "name", Value: bson.D{{Key: "$trim", Value: bson.D{{Key: "input", Value: "$name"}}}}}}, NewBuilder().Trim("name", "$name", nil).Build())
+	assert.Equal(t, bson.D{{Key: "$trim", Value: bson.D{{Key: "input", Value: "$name"}, {Key: "chars", Value: " -"}}}}, NewBuilder().TrimWithoutKey("$name", " -").Build())
+}
+
+func Test_stringBuilder_ReplaceAll(t *testing.T) {
+	want := bson.D{{Key: "input", Value: "$item"}, {Key: "find", Value: "blue paint"}, {Key: "replacement", Value: "red paint"}}
+	assert.Equal(t, bson.D{{Key: "item", Value: bson.D{{Key: "$replaceAll", Value: want}}}}, NewBuilder().ReplaceAll("item", "$item", "blue paint", "red paint").Build())
+	assert.Equal(t, bson.D{{Key: "$replaceAll", Value: want}}, NewBuilder().ReplaceAllWithoutKey("$item", "blue paint", "red paint").Build())
+}
+
+func Test_stringBuilder_StrLenCP(t *testing.T) {
+	assert.Equal(t, bson.D{{Key: "length", Value: bson.D{{Key: "$strLenCP", Value: "$name"}}}}, NewBuilder().StrLenCP("length", "$name").Build())
+	assert.Equal(t, bson.D{{Key: "$strLenCP", Value: "$name"}}, NewBuilder().StrLenCPWithoutKey("$name").Build())
+}
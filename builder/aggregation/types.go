@@ -21,8 +21,13 @@ const (
 	CondOp                = "$cond"
 	CondWithoutOperatorOp = "cond"
 	ContactOp             = "$concat"
+	ConvertOp             = "$convert"
+	DateAddOp             = "$dateAdd"
+	DateDiffOp            = "$dateDiff"
+	DateFromStringOp      = "$dateFromString"
 	DateOp                = "date"
 	DateToStringOp        = "$dateToString"
+	DateTruncOp           = "$dateTrunc"
 	DayOfMonthOp          = "$dayOfMonth"
 	DayOfWeekOp           = "$dayOfWeek"
 	DayOfYearOp           = "$dayOfYear"
@@ -34,14 +39,17 @@ const (
 	FirstOp               = "$first"
 	FloorOp               = "$floor"
 	FormatOp              = "format"
+	GetFieldOp            = "$getField"
 	GtOp                  = "$gt"
 	GteOp                 = "$gte"
 	IfNullOp              = "$ifNull"
+	InArrayOp             = "$in"
 	InOp                  = "in"
 	InputOp               = "input"
 	IndexOfArrayOp        = "$indexOfArray"
 	LastOp                = "$last"
 	LIMIT                 = "limit"
+	LetOp                 = "$let"
 	LnOp                  = "$ln"
 	Log10Op               = "$log10"
 	LogOp                 = "$log"
@@ -49,31 +57,49 @@ const (
 	LteOp                 = "$lte"
 	MapOp                 = "$map"
 	MaxOp                 = "$max"
+	MergeObjectsOp        = "$mergeObjects"
 	MinOp                 = "$min"
 	ModOp                 = "$mod"
 	MonthOp               = "$month"
 	MultiplyOp            = "$multiply"
 	NeOp                  = "$ne"
 	NotOp                 = "$not"
+	ObjectToArrayOp       = "$objectToArray"
 	OnNullOp              = "onNull"
 	OrOp                  = "$or"
 	PowOp                 = "$pow"
 	PushOp                = "$push"
+	ReduceOp              = "$reduce"
+	RegexFindOp           = "$regexFind"
+	RegexMatchOp          = "$regexMatch"
+	ReplaceAllOp          = "$replaceAll"
 	RoundOp               = "$round"
+	SetDifferenceOp       = "$setDifference"
+	SetFieldOp            = "$setField"
+	SetIntersectionOp     = "$setIntersection"
+	SetIsSubsetOp         = "$setIsSubset"
+	SetUnionOp            = "$setUnion"
 	SizeOp                = "$size"
 	SliceOp               = "$slice"
+	SplitOp               = "$split"
 	SqrtOp                = "$sqrt"
+	StrLenCPOp            = "$strLenCP"
 	SubstrBytesOp         = "$substrBytes"
 	SubtractOp            = "$subtract"
 	SumOp                 = "$sum"
 	SwitchOp              = "$switch"
 	ThenOp                = "then"
 	TimezoneOp            = "timezone"
+	ToDateOp              = "$toDate"
 	ToLowerOp             = "$toLower"
+	ToObjectIdOp          = "$toObjectId"
+	ToStringOp            = "$toString"
 	ToUpperOp             = "$toUpper"
+	TrimOp                = "$trim"
 	TruncOp               = "$trunc"
 	WeekOp                = "$week"
 	YearOp                = "$year"
+	ZipOp                 = "$zip"
 )
 
 // Stages
@@ -103,6 +129,32 @@ const (
 	StageSortByCountOp = "$sortByCount"
 	StageSortOp        = "$sort"
 	StageUnwindOp      = "$unwind"
+
+	StageCollStatsOp       = "$collStats"
+	StageDensifyOp         = "$densify"
+	StageDocumentsOp       = "$documents"
+	StageFillOp            = "$fill"
+	StageGeoNearOp         = "$geoNear"
+	StageGraphLookupOp     = "$graphLookup"
+	StageIndexStatsOp      = "$indexStats"
+	StageMergeOp           = "$merge"
+	StageOutOp             = "$out"
+	StageRedactOp          = "$redact"
+	StageSampleOp          = "$sample"
+	StageSetWindowFieldsOp = "$setWindowFields"
+	StageUnsetOp           = "$unset"
+)
+
+// Window operators of $setWindowFields
+const (
+	DenseRankOp      = "$denseRank"
+	DerivativeOp     = "$derivative"
+	DocumentNumberOp = "$documentNumber"
+	ExpMovingAvgOp   = "$expMovingAvg"
+	IntegralOp       = "$integral"
+	RankOp           = "$rank"
+	ShiftOp          = "$shift"
+	WindowOp         = "window"
 )
 
 type BucketAutoOptions struct {
@@ -115,22 +167,91 @@ type BucketOptions struct {
 	Output     any
 }
 
+type CollStatsOptions struct {
+	// LatencyStats adds the latency statistics, with the histograms of the latency if LatencyHistograms is set
+	LatencyStats      bool
+	LatencyHistograms bool
+	// StorageStats adds the storage statistics, scaled by StorageScale if it is set
+	StorageStats   bool
+	StorageScale   int64
+	Count          bool
+	QueryExecStats bool
+}
+
 type CaseThen struct {
 	Case any
 	Then any
 }
 
+type ConvertOptions struct {
+	OnError any
+	OnNull  any
+}
+
+type DateDiffOptions struct {
+	Timezone    string
+	StartOfWeek string
+}
+
+type DateFromStringOptions struct {
+	Format   string
+	Timezone string
+	OnError  any
+	OnNull   any
+}
+
 type DateToStringOptions struct {
 	Format   string
 	Timezone string
 	OnNull   any
 }
 
+type DateTruncOptions struct {
+	BinSize     any
+	Timezone    string
+	StartOfWeek string
+}
+
+type DensifyOptions struct {
+	PartitionByFields []string
+}
+
+// DensifyRange is the range of $densify, Bounds is "full", "partition" or the array of the lower and upper bounds
+// Unit is only set if the field is a date
+type DensifyRange struct {
+	Step   any
+	Unit   string
+	Bounds any
+}
+
+type FillOptions struct {
+	PartitionBy       any
+	PartitionByFields []string
+	SortBy            bson.D
+}
+
 type FilterOptions struct {
 	As    string
 	Limit int64
 }
 
+type GeoNearOptions struct {
+	Spherical          bool
+	MaxDistance        float64
+	MinDistance        float64
+	Query              any
+	IncludeLocs        string
+	DistanceMultiplier float64
+	Key                string
+}
+
+type GraphLookupOptions struct {
+	// MaxDepth is the maximum depth of the recursion, it is unlimited if MaxDepth is nil
+	MaxDepth                *int64
+	DepthField              string
+	RestrictSearchWithMatch any
+}
+
 type LookUpOptions struct {
 	LocalField   string
 	ForeignField string
@@ -138,7 +259,40 @@ type LookUpOptions struct {
 	Pipeline     mongo.Pipeline
 }
 
+// MergeOptions are the options of $merge, WhenMatched is "replace", "keepExisting", "merge", "fail" or a pipeline
+// and WhenNotMatched is "insert", "discard" or "fail"
+type MergeOptions struct {
+	On             any
+	Let            bson.D
+	WhenMatched    any
+	WhenNotMatched string
+}
+
+type OutOptions struct {
+	DB         string
+	TimeSeries bson.D
+}
+
+type SetWindowFieldsOptions struct {
+	PartitionBy any
+	SortBy      bson.D
+}
+
 type UnWindOptions struct {
 	IncludeArrayIndex          string
 	PreserveNullAndEmptyArrays bool
 }
+
+type ZipOptions struct {
+	UseLongestLength bool
+	// Defaults are the values of the missing elements of the shorter arrays if UseLongestLength is set
+	Defaults []any
+}
+
+// WindowOptions are the window of a window operator of $setWindowFields, either Documents or Range is set
+// to the array of the lower and upper bounds, e.g. []any{"unbounded", "current"}, Unit is the unit of a time Range
+type WindowOptions struct {
+	Documents []any
+	Range     []any
+	Unit      string
+}
@@ -0,0 +1,72 @@
+package aggregation
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+var (
+	ErrEmptyKey     = errors.New("mongox: empty key in aggregation")
+	ErrInvalidStage = errors.New("mongox: invalid aggregation stage")
+)
+
+// Validate checks the expression for empty keys and returns the errors joined
+func Validate(expression bson.D) error {
+	return errors.Join(validate(expression)...)
+}
+
+// ValidatePipeline checks that every stage of the pipeline is a document with a single stage operator and that
+// no stage has an empty key, and returns the errors joined
+func ValidatePipeline(pipeline mongo.Pipeline) error {
+	return errors.Join(validatePipeline(pipeline)...)
+}
+
+func validatePipeline(pipeline mongo.Pipeline) []error {
+	var errs []error
+	for i, stage := range pipeline {
+		if len(stage) != 1 || !strings.HasPrefix(stage[0].Key, "$") {
+			errs = append(errs, fmt.Errorf("%w: stage %d must have exactly one stage operator", ErrInvalidStage, i))
+		} else if (stage[0].Key == StageOutOp || stage[0].Key == StageMergeOp) && i != len(pipeline)-1 {
+			errs = append(errs, invalidStage(stage[0].Key, "must be the last stage"))
+		}
+		errs = append(errs, validate(stage)...)
+	}
+	return errs
+}
+
+func validate(d bson.D) []error {
+	var errs []error
+	for _, e := range d {
+		if e.Key == "" {
+			errs = append(errs, ErrEmptyKey)
+		}
+		errs = append(errs, validateValue(e.Value)...)
+	}
+	return errs
+}
+
+func validateValue(value any) []error {
+	switch v := value.(type) {
+	case bson.D:
+		return validate(v)
+	case mongo.Pipeline:
+		return validatePipeline(v)
+	case []any:
+		var errs []error
+		for _, elem := range v {
+			errs = append(errs, validateValue(elem)...)
+		}
+		return errs
+	case bson.A:
+		return validateValue([]any(v))
+	}
+	return nil
+}
+
+func invalidStage(op, reason string) error {
+	return fmt.Errorf("%w: %s %s", ErrInvalidStage, op, reason)
+}
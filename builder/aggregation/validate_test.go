@@ -0,0 +1,150 @@
+package aggregation
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Validate(NewBuilder().Sum("total", "$price").Build()))
+	assert.ErrorIs(t, Validate(bson.D{{Key: "total", Value: bson.D{{Key: "", Value: "$price"}}}}), ErrEmptyKey)
+	assert.ErrorIs(t, Validate(bson.D{{Key: "$and", Value: []any{bson.D{{Key: "", Value: 1}}}}}), ErrEmptyKey)
+}
+
+func TestValidatePipeline(t *testing.T) {
+	testCases := []struct {
+		name     string
+		pipeline mongo.Pipeline
+		wantErr  error
+	}{
+		{
+			name:     "valid",
+			pipeline: NewStageBuilder().Match(bson.D{{Key: "age", Value: 18}}).Limit(10).Build(),
+		},
+		{
+			name:     "no stage operator",
+			pipeline: mongo.Pipeline{{{Key: "age", Value: 18}}},
+			wantErr:  ErrInvalidStage,
+		},
+		{
+			name:     "several stage operators",
+			pipeline: mongo.Pipeline{{{Key: "$match", Value: bson.D{}}, {Key: "$limit", Value: 1}}},
+			wantErr:  ErrInvalidStage,
+		},
+		{
+			name:     "merge not last",
+			pipeline: NewStageBuilder().Merge("totals", nil).Limit(1).Build(),
+			wantErr:  ErrInvalidStage,
+		},
+		{
+			name:     "empty key",
+			pipeline: NewStageBuilder().Project(bson.D{{Key: "", Value: 1}}).Build(),
+			wantErr:  ErrEmptyKey,
+		},
+		{
+			name: "empty key in a sub-pipeline",
+			pipeline: NewStageBuilder().UnionWith("orders", mongo.Pipeline{
+				{{Key: "$project", Value: bson.D{{Key: "", Value: 1}}}},
+			}).Build(),
+			wantErr: ErrEmptyKey,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidatePipeline(tc.pipeline)
+			if tc.wantErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestStageBuilder_BuildE(t *testing.T) {
+	testCases := []struct {
+		name    string
+		sb      *StageBuilder
+		wantErr string
+	}{
+		{
+			name: "valid",
+			sb:   NewStageBuilder().Unwind("$tags", nil).Skip(0).Limit(1).Count("total"),
+		},
+		{
+			name:    "limit",
+			sb:      NewStageBuilder().Limit(0),
+			wantErr: "mongox: invalid aggregation stage: $limit must be positive",
+		},
+		{
+			name:    "skip",
+			sb:      NewStageBuilder().Skip(-1),
+			wantErr: "mongox: invalid aggregation stage: $skip must not be negative",
+		},
+		{
+			name:    "unwind",
+			sb:      NewStageBuilder().Unwind("tags", nil),
+			wantErr: "mongox: invalid aggregation stage: $unwind path must be prefixed with '$'",
+		},
+		{
+			name:    "count",
+			sb:      NewStageBuilder().Count("$total"),
+			wantErr: "mongox: invalid aggregation stage: $count name must be non-empty, not start with '$' and not contain '.'",
+		},
+		{
+			name:    "lookup without options",
+			sb:      NewStageBuilder().Lookup("orders", "orders", nil),
+			wantErr: "mongox: invalid aggregation stage: $lookup must have the options of the join",
+		},
+		{
+			name:    "bucket",
+			sb:      NewStageBuilder().Bucket("$price", []any{0}, nil),
+			wantErr: "mongox: invalid aggregation stage: $bucket must have at least two boundaries",
+		},
+		{
+			name:    "sample",
+			sb:      NewStageBuilder().Sample(0),
+			wantErr: "mongox: invalid aggregation stage: $sample size must be positive",
+		},
+		{
+			name:    "unset",
+			sb:      NewStageBuilder().Unset(),
+			wantErr: "mongox: invalid aggregation stage: $unset must have at least one field",
+		},
+		{
+			name:    "geo near",
+			sb:      NewStageBuilder().Limit(1).GeoNear(bson.D{}, "dist", nil),
+			wantErr: "mongox: invalid aggregation stage: $geoNear must be the first stage",
+		},
+		{
+			name:    "bucket auto",
+			sb:      NewStageBuilder().BucketAuto("$price", 0, nil),
+			wantErr: "mongox: invalid aggregation stage: $bucketAuto must have a positive number of buckets",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pipeline, err := tc.sb.BuildE()
+			assert.Equal(t, tc.sb.Build(), pipeline)
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tc.wantErr)
+			assert.True(t, errors.Is(err, ErrInvalidStage))
+		})
+	}
+}
+
+func TestBuilder_BuildE(t *testing.T) {
+	d, err := NewBuilder().Sum("total", "$price").BuildE()
+	assert.NoError(t, err)
+	assert.Equal(t, NewBuilder().Sum("total", "$price").Build(), d)
+
+	_, err = NewBuilder().KeyValue("", 1).BuildE()
+	assert.ErrorIs(t, err, ErrEmptyKey)
+}
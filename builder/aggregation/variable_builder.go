@@ -0,0 +1,24 @@
+package aggregation
+
+import (
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// variableBuilder builds the operators which define variables
+type variableBuilder struct {
+	parent *Builder
+}
+
+// Let binds vars, e.g. bson.D{{Key: "total", Value: "$price"}}, to the variables used by in as $$total
+func (b *variableBuilder) Let(key string, vars, in any) *Builder {
+	e := bson.E{Key: LetOp, Value: bson.D{{Key: "vars", Value: vars}, {Key: InOp, Value: in}}}
+	if !b.parent.tryMergeValue(key, e) {
+		b.parent.d = append(b.parent.d, bson.E{Key: key, Value: bson.D{e}})
+	}
+	return b.parent
+}
+
+func (b *variableBuilder) LetWithoutKey(vars, in any) *Builder {
+	b.parent.d = append(b.parent.d, bson.E{Key: LetOp, Value: bson.D{{Key: "vars", Value: vars}, {Key: InOp, Value: in}}})
+	return b.parent
+}
@@ -0,0 +1,21 @@
+package aggregation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func Test_variableBuilder_Let(t *testing.T) {
+	vars := bson.D{{Key: "total", Value: AddWithoutKey("$price", "$tax")}}
+	in := MultiplyWithoutKey("$$total", 0.9)
+	assert.Equal(t,
+		bson.D{{Key: "finalTotal", Value: bson.D{{Key: "$let", Value: bson.D{{Key: "vars", Value: vars}, {Key: "in", Value: in}}}}}},
+		NewBuilder().Let("finalTotal", vars, in).Build(),
+	)
+	assert.Equal(t,
+		bson.D{{Key: "$let", Value: bson.D{{Key: "vars", Value: vars}, {Key: "in", Value: in}}}},
+		NewBuilder().LetWithoutKey(vars, in).Build(),
+	)
+}
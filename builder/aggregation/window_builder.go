@@ -0,0 +1,150 @@
+package aggregation
+
+import (
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// windowBuilder builds the window operators of the output of $setWindowFields
+type windowBuilder struct {
+	parent *Builder
+}
+
+func (b *windowBuilder) Rank(key string) *Builder {
+	e := bson.E{Key: RankOp, Value: bson.D{}}
+	if !b.parent.tryMergeValue(key, e) {
+		b.parent.d = append(b.parent.d, bson.E{Key: key, Value: bson.D{e}})
+	}
+	return b.parent
+}
+
+func (b *windowBuilder) RankWithoutKey() *Builder {
+	b.parent.d = append(b.parent.d, bson.E{Key: RankOp, Value: bson.D{}})
+	return b.parent
+}
+
+func (b *windowBuilder) DenseRank(key string) *Builder {
+	e := bson.E{Key: DenseRankOp, Value: bson.D{}}
+	if !b.parent.tryMergeValue(key, e) {
+		b.parent.d = append(b.parent.d, bson.E{Key: key, Value: bson.D{e}})
+	}
+	return b.parent
+}
+
+func (b *windowBuilder) DenseRankWithoutKey() *Builder {
+	b.parent.d = append(b.parent.d, bson.E{Key: DenseRankOp, Value: bson.D{}})
+	return b.parent
+}
+
+func (b *windowBuilder) DocumentNumber(key string) *Builder {
+	e := bson.E{Key: DocumentNumberOp, Value: bson.D{}}
+	if !b.parent.tryMergeValue(key, e) {
+		b.parent.d = append(b.parent.d, bson.E{Key: key, Value: bson.D{e}})
+	}
+	return b.parent
+}
+
+func (b *windowBuilder) DocumentNumberWithoutKey() *Builder {
+	b.parent.d = append(b.parent.d, bson.E{Key: DocumentNumberOp, Value: bson.D{}})
+	return b.parent
+}
+
+// Shift returns the value of output of the document by positions away from the current one, or defaultValue if
+// there is no such document
+func (b *windowBuilder) Shift(key string, output any, by int64, defaultValue any) *Builder {
+	e := bson.E{Key: ShiftOp, Value: shift(output, by, defaultValue)}
+	if !b.parent.tryMergeValue(key, e) {
+		b.parent.d = append(b.parent.d, bson.E{Key: key, Value: bson.D{e}})
+	}
+	return b.parent
+}
+
+func (b *windowBuilder) ShiftWithoutKey(output any, by int64, defaultValue any) *Builder {
+	b.parent.d = append(b.parent.d, bson.E{Key: ShiftOp, Value: shift(output, by, defaultValue)})
+	return b.parent
+}
+
+// Derivative returns the average rate of change of input within the window, unit is only set if the documents
+// are sorted by a date
+func (b *windowBuilder) Derivative(key string, input any, unit string) *Builder {
+	e := bson.E{Key: DerivativeOp, Value: inputUnit(input, unit)}
+	if !b.parent.tryMergeValue(key, e) {
+		b.parent.d = append(b.parent.d, bson.E{Key: key, Value: bson.D{e}})
+	}
+	return b.parent
+}
+
+func (b *windowBuilder) DerivativeWithoutKey(input any, unit string) *Builder {
+	b.parent.d = append(b.parent.d, bson.E{Key: DerivativeOp, Value: inputUnit(input, unit)})
+	return b.parent
+}
+
+// Integral returns the approximation of the area under the curve of input within the window, unit is only set if
+// the documents are sorted by a date
+func (b *windowBuilder) Integral(key string, input any, unit string) *Builder {
+	e := bson.E{Key: IntegralOp, Value: inputUnit(input, unit)}
+	if !b.parent.tryMergeValue(key, e) {
+		b.parent.d = append(b.parent.d, bson.E{Key: key, Value: bson.D{e}})
+	}
+	return b.parent
+}
+
+func (b *windowBuilder) IntegralWithoutKey(input any, unit string) *Builder {
+	b.parent.d = append(b.parent.d, bson.E{Key: IntegralOp, Value: inputUnit(input, unit)})
+	return b.parent
+}
+
+// ExpMovingAvg returns the exponential moving average of input over the n previous documents
+func (b *windowBuilder) ExpMovingAvg(key string, input any, n int64) *Builder {
+	e := bson.E{Key: ExpMovingAvgOp, Value: bson.D{{Key: "input", Value: input}, {Key: "N", Value: n}}}
+	if !b.parent.tryMergeValue(key, e) {
+		b.parent.d = append(b.parent.d, bson.E{Key: key, Value: bson.D{e}})
+	}
+	return b.parent
+}
+
+func (b *windowBuilder) ExpMovingAvgWithoutKey(input any, n int64) *Builder {
+	b.parent.d = append(b.parent.d, bson.E{Key: ExpMovingAvgOp, Value: bson.D{{Key: "input", Value: input}, {Key: "N", Value: n}}})
+	return b.parent
+}
+
+// Window sets the window of the window operator of the key, e.g. NewBuilder().Sum("total", "$qty").Window("total", opt)
+func (b *windowBuilder) Window(key string, opt *WindowOptions) *Builder {
+	e := bson.E{Key: WindowOp, Value: window(opt)}
+	if !b.parent.tryMergeValue(key, e) {
+		b.parent.d = append(b.parent.d, bson.E{Key: key, Value: bson.D{e}})
+	}
+	return b.parent
+}
+
+func shift(output any, by int64, defaultValue any) bson.D {
+	d := bson.D{{Key: "output", Value: output}, {Key: "by", Value: by}}
+	if defaultValue != nil {
+		d = append(d, bson.E{Key: "default", Value: defaultValue})
+	}
+	return d
+}
+
+func inputUnit(input any, unit string) bson.D {
+	d := bson.D{{Key: "input", Value: input}}
+	if unit != "" {
+		d = append(d, bson.E{Key: "unit", Value: unit})
+	}
+	return d
+}
+
+func window(opt *WindowOptions) bson.D {
+	d := bson.D{}
+	if opt == nil {
+		return d
+	}
+	if len(opt.Documents) > 0 {
+		d = append(d, bson.E{Key: "documents", Value: opt.Documents})
+	}
+	if len(opt.Range) > 0 {
+		d = append(d, bson.E{Key: "range", Value: opt.Range})
+	}
+	if opt.Unit != "" {
+		d = append(d, bson.E{Key: "unit", Value: opt.Unit})
+	}
+	return d
+}
@@ -0,0 +1,104 @@
+package aggregation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func Test_windowBuilder(t *testing.T) {
+	testCases := []struct {
+		name string
+		got  bson.D
+		want bson.D
+	}{
+		{
+			name: "rank",
+			got:  NewBuilder().Rank("rank").Build(),
+			want: bson.D{{Key: "rank", Value: bson.D{{Key: "$rank", Value: bson.D{}}}}},
+		},
+		{
+			name: "rank without key",
+			got:  NewBuilder().RankWithoutKey().Build(),
+			want: bson.D{{Key: "$rank", Value: bson.D{}}},
+		},
+		{
+			name: "dense rank",
+			got:  NewBuilder().DenseRank("rank").Build(),
+			want: bson.D{{Key: "rank", Value: bson.D{{Key: "$denseRank", Value: bson.D{}}}}},
+		},
+		{
+			name: "document number",
+			got:  NewBuilder().DocumentNumberWithoutKey().Build(),
+			want: bson.D{{Key: "$documentNumber", Value: bson.D{}}},
+		},
+		{
+			name: "shift",
+			got:  NewBuilder().Shift("next", "$quantity", 1, "Not available").Build(),
+			want: bson.D{{Key: "next", Value: bson.D{{Key: "$shift", Value: bson.D{
+				{Key: "output", Value: "$quantity"}, {Key: "by", Value: int64(1)}, {Key: "default", Value: "Not available"},
+			}}}}},
+		},
+		{
+			name: "shift without default",
+			got:  NewBuilder().ShiftWithoutKey("$quantity", -1, nil).Build(),
+			want: bson.D{{Key: "$shift", Value: bson.D{{Key: "output", Value: "$quantity"}, {Key: "by", Value: int64(-1)}}}},
+		},
+		{
+			name: "derivative",
+			got: NewBuilder().Derivative("speed", "$miles", "hour").
+				Window("speed", &WindowOptions{Range: []any{-30, 0}, Unit: "second"}).Build(),
+			want: bson.D{{Key: "speed", Value: bson.D{
+				{Key: "$derivative", Value: bson.D{{Key: "input", Value: "$miles"}, {Key: "unit", Value: "hour"}}},
+				{Key: "window", Value: bson.D{{Key: "range", Value: []any{-30, 0}}, {Key: "unit", Value: "second"}}},
+			}}},
+		},
+		{
+			name: "integral without unit",
+			got:  NewBuilder().IntegralWithoutKey("$kilowatts", "").Build(),
+			want: bson.D{{Key: "$integral", Value: bson.D{{Key: "input", Value: "$kilowatts"}}}},
+		},
+		{
+			name: "exp moving avg",
+			got:  NewBuilder().ExpMovingAvg("avg", "$price", 2).Build(),
+			want: bson.D{{Key: "avg", Value: bson.D{{Key: "$expMovingAvg", Value: bson.D{{Key: "input", Value: "$price"}, {Key: "N", Value: int64(2)}}}}}},
+		},
+		{
+			name: "running total",
+			got:  NewBuilder().Sum("total", "$quantity").Window("total", &WindowOptions{Documents: []any{"unbounded", "current"}}).Build(),
+			want: bson.D{{Key: "total", Value: bson.D{
+				{Key: "$sum", Value: "$quantity"},
+				{Key: "window", Value: bson.D{{Key: "documents", Value: []any{"unbounded", "current"}}}},
+			}}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.got)
+		})
+	}
+}
+
+func TestWindowOperators(t *testing.T) {
+	assert.Equal(t, NewBuilder().Rank("rank").Build(), Rank("rank"))
+	assert.Equal(t, NewBuilder().DenseRank("rank").Build(), DenseRank("rank"))
+	assert.Equal(t, NewBuilder().DocumentNumber("n").Build(), DocumentNumber("n"))
+	assert.Equal(t, NewBuilder().Shift("next", "$qty", 1, 0).Build(), Shift("next", "$qty", 1, 0))
+	assert.Equal(t, NewBuilder().Derivative("speed", "$miles", "hour").Build(), Derivative("speed", "$miles", "hour"))
+	assert.Equal(t, NewBuilder().Integral("power", "$kw", "hour").Build(), Integral("power", "$kw", "hour"))
+	assert.Equal(t, NewBuilder().ExpMovingAvg("avg", "$price", 2).Build(), ExpMovingAvg("avg", "$price", 2))
+
+	assert.Equal(t, NewBuilder().RankWithoutKey().Build(), RankWithoutKey())
+	assert.Equal(t, NewBuilder().DenseRankWithoutKey().Build(), DenseRankWithoutKey())
+	assert.Equal(t, NewBuilder().DocumentNumberWithoutKey().Build(), DocumentNumberWithoutKey())
+	assert.Equal(t, NewBuilder().ShiftWithoutKey("$qty", 1, 0).Build(), ShiftWithoutKey("$qty", 1, 0))
+	assert.Equal(t, NewBuilder().DerivativeWithoutKey("$miles", "hour").Build(), DerivativeWithoutKey("$miles", "hour"))
+	assert.Equal(t, NewBuilder().IntegralWithoutKey("$kw", "hour").Build(), IntegralWithoutKey("$kw", "hour"))
+	assert.Equal(t, NewBuilder().ExpMovingAvgWithoutKey("$price", 2).Build(), ExpMovingAvgWithoutKey("$price", 2))
+	assert.Equal(t,
+		bson.D{{Key: "$sum", Value: "$qty"}, {Key: "window", Value: bson.D{{Key: "documents", Value: []any{"unbounded", "current"}}}}},
+		append(SumWithoutKey("$qty"), Window(&WindowOptions{Documents: []any{"unbounded", "current"}})...),
+	)
+	assert.Equal(t, bson.D{{Key: "window", Value: bson.D{}}}, Window(nil))
+}
@@ -0,0 +1,39 @@
+package query
+
+import (
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// bitwiseQueryBuilder builds the bitwise operators, whose operand is either a non-negative integer bitmask,
+// an array of the bit positions or a bson.Binary bitmask
+type bitwiseQueryBuilder struct {
+	parent *Builder
+}
+
+// BitsAllClear matches the documents whose field has all the bits of the bitmask clear
+func (b *bitwiseQueryBuilder) BitsAllClear(key string, bitmask any) *Builder {
+	return b.bits(BitsAllClearOp, key, bitmask)
+}
+
+// BitsAllSet matches the documents whose field has all the bits of the bitmask set
+func (b *bitwiseQueryBuilder) BitsAllSet(key string, bitmask any) *Builder {
+	return b.bits(BitsAllSetOp, key, bitmask)
+}
+
+// BitsAnyClear matches the documents whose field has any of the bits of the bitmask clear
+func (b *bitwiseQueryBuilder) BitsAnyClear(key string, bitmask any) *Builder {
+	return b.bits(BitsAnyClearOp, key, bitmask)
+}
+
+// BitsAnySet matches the documents whose field has any of the bits of the bitmask set
+func (b *bitwiseQueryBuilder) BitsAnySet(key string, bitmask any) *Builder {
+	return b.bits(BitsAnySetOp, key, bitmask)
+}
+
+func (b *bitwiseQueryBuilder) bits(op, key string, bitmask any) *Builder {
+	e := bson.E{Key: op, Value: bitmask}
+	if !b.parent.tryMergeValue(key, e) {
+		b.parent.data = append(b.parent.data, bson.E{Key: key, Value: bson.D{e}})
+	}
+	return b.parent
+}
@@ -0,0 +1,70 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func Test_bitwiseQueryBuilder_BitsAllClear(t *testing.T) {
+	assert.Equal(t,
+		bson.D{{Key: "permissions", Value: bson.D{{Key: "$bitsAllClear", Value: 35}}}},
+		NewBuilder().BitsAllClear("permissions", 35).Build(),
+	)
+}
+
+func Test_bitwiseQueryBuilder_BitsAllSet(t *testing.T) {
+	assert.Equal(t,
+		bson.D{{Key: "permissions", Value: bson.D{{Key: "$bitsAllSet", Value: []int{1, 5}}}}},
+		NewBuilder().BitsAllSet("permissions", []int{1, 5}).Build(),
+	)
+}
+
+func Test_bitwiseQueryBuilder_BitsAnyClear(t *testing.T) {
+	mask := bson.Binary{Data: []byte{0x20}}
+	assert.Equal(t,
+		bson.D{{Key: "permissions", Value: bson.D{{Key: "$bitsAnyClear", Value: mask}}}},
+		NewBuilder().BitsAnyClear("permissions", mask).Build(),
+	)
+}
+
+func Test_bitwiseQueryBuilder_BitsAnySet(t *testing.T) {
+	testCases := []struct {
+		name    string
+		b       *Builder
+		bitmask any
+		want    bson.D
+		wantErr error
+	}{
+		{
+			name:    "bitmask",
+			b:       NewBuilder(),
+			bitmask: uint32(0b1010),
+			want:    bson.D{{Key: "permissions", Value: bson.D{{Key: "$bitsAnySet", Value: uint32(0b1010)}}}},
+		},
+		{
+			name:    "merged with another operator",
+			b:       NewBuilder().BitsAllClear("permissions", 1),
+			bitmask: 2,
+			want: bson.D{{Key: "permissions", Value: bson.D{
+				{Key: "$bitsAllClear", Value: 1},
+				{Key: "$bitsAnySet", Value: 2},
+			}}},
+		},
+		{
+			name:    "negative bitmask",
+			b:       NewBuilder(),
+			bitmask: -2,
+			want:    bson.D{{Key: "permissions", Value: bson.D{{Key: "$bitsAnySet", Value: -2}}}},
+			wantErr: ErrInvalidOperand,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.b.BitsAnySet("permissions", tc.bitmask).BuildE()
+			assert.Equal(t, tc.want, got)
+			assert.ErrorIs(t, err, tc.wantErr)
+		})
+	}
+}
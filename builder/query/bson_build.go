@@ -12,6 +12,22 @@ func And(conditions ...any) bson.D {
 	return bson.D{bson.E{Key: AndOp, Value: conditions}}
 }
 
+func BitsAllClear(key string, bitmask any) bson.D {
+	return bson.D{bson.E{Key: key, Value: bson.D{{Key: BitsAllClearOp, Value: bitmask}}}}
+}
+
+func BitsAllSet(key string, bitmask any) bson.D {
+	return bson.D{bson.E{Key: key, Value: bson.D{{Key: BitsAllSetOp, Value: bitmask}}}}
+}
+
+func BitsAnyClear(key string, bitmask any) bson.D {
+	return bson.D{bson.E{Key: key, Value: bson.D{{Key: BitsAnyClearOp, Value: bitmask}}}}
+}
+
+func BitsAnySet(key string, bitmask any) bson.D {
+	return bson.D{bson.E{Key: key, Value: bson.D{{Key: BitsAnySetOp, Value: bitmask}}}}
+}
+
 func ElemMatch(key string, cond any) bson.D {
 	return bson.D{bson.E{Key: key, Value: bson.D{{Key: ElemMatchOp, Value: cond}}}}
 }
@@ -28,6 +44,26 @@ func Expr(value any) bson.D {
 	return bson.D{bson.E{Key: ExprOp, Value: value}}
 }
 
+func GeoIntersects(key string, geometry any) bson.D {
+	return bson.D{bson.E{Key: key, Value: bson.D{{Key: GeoIntersectsOp, Value: bson.D{{Key: GeometryOp, Value: geometry}}}}}}
+}
+
+func GeoWithin(key string, geometry any) bson.D {
+	return bson.D{bson.E{Key: key, Value: bson.D{{Key: GeoWithinOp, Value: bson.D{{Key: GeometryOp, Value: geometry}}}}}}
+}
+
+func GeoWithinBox(key string, bottomLeft, upperRight []float64) bson.D {
+	return bson.D{bson.E{Key: key, Value: bson.D{{Key: GeoWithinOp, Value: bson.D{{Key: BoxOp, Value: bson.A{bottomLeft, upperRight}}}}}}}
+}
+
+func GeoWithinCenterSphere(key string, center []float64, radius float64) bson.D {
+	return bson.D{bson.E{Key: key, Value: bson.D{{Key: GeoWithinOp, Value: bson.D{{Key: CenterSphereOp, Value: bson.A{center, radius}}}}}}}
+}
+
+func GeoWithinPolygon(key string, points ...[]float64) bson.D {
+	return bson.D{bson.E{Key: key, Value: bson.D{{Key: GeoWithinOp, Value: bson.D{{Key: PolygonOp, Value: points}}}}}}
+}
+
 func Gt(key string, value any) bson.D {
 	return bson.D{bson.E{Key: key, Value: bson.D{{Key: GtOp, Value: value}}}}
 }
@@ -68,6 +104,14 @@ func Ne(key string, value any) bson.D {
 	return bson.D{bson.E{Key: key, Value: bson.D{{Key: NeOp, Value: value}}}}
 }
 
+func Near(key string, point any, opt *NearOptions) bson.D {
+	return bson.D{bson.E{Key: key, Value: bson.D{{Key: NearOp, Value: near(point, opt)}}}}
+}
+
+func NearSphere(key string, point any, opt *NearOptions) bson.D {
+	return bson.D{bson.E{Key: key, Value: bson.D{{Key: NearSphereOp, Value: near(point, opt)}}}}
+}
+
 func Nor(conditions ...any) bson.D {
 	return bson.D{bson.E{Key: NorOp, Value: conditions}}
 }
@@ -3,6 +3,7 @@ package query
 import (
 	"testing"
 
+	"github.com/matiniiuu/mongox/geojson"
 	"github.com/stretchr/testify/assert"
 	"go.mongodb.org/mongo-driver/v2/bson"
 )
@@ -654,3 +655,49 @@ func TestSliceRanger(t *testing.T) {
 		})
 	}
 }
+
+func TestGeospatial(t *testing.T) {
+	point := geojson.NewPoint(-73.97, 40.77)
+	area := geojson.NewPolygon([][]float64{{0, 0}, {3, 6}, {6, 1}, {0, 0}})
+	testCases := []struct {
+		name string
+		got  bson.D
+		want bson.D
+	}{
+		{name: "geo within", got: GeoWithin("location", area), want: NewBuilder().GeoWithin("location", area).Build()},
+		{
+			name: "geo within box",
+			got:  GeoWithinBox("location", []float64{0, 0}, []float64{1, 1}),
+			want: NewBuilder().GeoWithinBox("location", []float64{0, 0}, []float64{1, 1}).Build(),
+		},
+		{
+			name: "geo within polygon",
+			got:  GeoWithinPolygon("location", []float64{0, 0}, []float64{3, 6}, []float64{6, 0}),
+			want: NewBuilder().GeoWithinPolygon("location", []float64{0, 0}, []float64{3, 6}, []float64{6, 0}).Build(),
+		},
+		{
+			name: "geo within center sphere",
+			got:  GeoWithinCenterSphere("location", []float64{0, 0}, 0.1),
+			want: NewBuilder().GeoWithinCenterSphere("location", []float64{0, 0}, 0.1).Build(),
+		},
+		{name: "geo intersects", got: GeoIntersects("area", point), want: NewBuilder().GeoIntersects("area", point).Build()},
+		{
+			name: "near",
+			got:  Near("location", point, &NearOptions{MaxDistance: 1000}),
+			want: NewBuilder().Near("location", point, &NearOptions{MaxDistance: 1000}).Build(),
+		},
+		{name: "near sphere", got: NearSphere("location", point, nil), want: NewBuilder().NearSphere("location", point, nil).Build()},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.got)
+		})
+	}
+}
+
+func TestBitwise(t *testing.T) {
+	assert.Equal(t, NewBuilder().BitsAllClear("permissions", 35).Build(), BitsAllClear("permissions", 35))
+	assert.Equal(t, NewBuilder().BitsAllSet("permissions", []int{1, 5}).Build(), BitsAllSet("permissions", []int{1, 5}))
+	assert.Equal(t, NewBuilder().BitsAnyClear("permissions", 8).Build(), BitsAnyClear("permissions", 8))
+	assert.Equal(t, NewBuilder().BitsAnySet("permissions", 8).Build(), BitsAnySet("permissions", 8))
+}
@@ -20,11 +20,14 @@ func (b *evaluationQueryBuilder) JsonSchema(value any) *Builder {
 }
 
 func (b *evaluationQueryBuilder) Mod(key string, divisor any, remainder int) *Builder {
-	if utils.IsNumeric(divisor) {
-		e := bson.E{Key: ModOp, Value: bson.A{divisor, remainder}}
-		if !b.parent.tryMergeValue(key, e) {
-			b.parent.data = append(b.parent.data, bson.E{Key: key, Value: bson.D{e}})
-		}
+	if !utils.IsNumeric(divisor) {
+		// the condition is left out, the error is reported by Err
+		b.parent.err = append(b.parent.err, invalidOperand(ModOp, key, "must have a numeric divisor"))
+		return b.parent
+	}
+	e := bson.E{Key: ModOp, Value: bson.A{divisor, remainder}}
+	if !b.parent.tryMergeValue(key, e) {
+		b.parent.data = append(b.parent.data, bson.E{Key: key, Value: bson.D{e}})
 	}
 	return b.parent
 }
@@ -0,0 +1,91 @@
+package query
+
+import (
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type geospatialQueryBuilder struct {
+	parent *Builder
+}
+
+// GeoWithin matches the documents whose location is within the GeoJSON polygon or multi polygon, e.g. a geojson.Polygon
+func (b *geospatialQueryBuilder) GeoWithin(key string, geometry any) *Builder {
+	return b.geoWithin(key, bson.E{Key: GeometryOp, Value: geometry})
+}
+
+// GeoWithinBox matches the documents whose legacy coordinates are within the box of the bottom left and upper right corners
+func (b *geospatialQueryBuilder) GeoWithinBox(key string, bottomLeft, upperRight []float64) *Builder {
+	return b.geoWithin(key, bson.E{Key: BoxOp, Value: bson.A{bottomLeft, upperRight}})
+}
+
+// GeoWithinPolygon matches the documents whose legacy coordinates are within the polygon of at least three points
+func (b *geospatialQueryBuilder) GeoWithinPolygon(key string, points ...[]float64) *Builder {
+	if len(points) < 3 {
+		b.parent.err = append(b.parent.err, invalidOperand(PolygonOp, key, "must have at least three points"))
+		return b.parent
+	}
+	return b.geoWithin(key, bson.E{Key: PolygonOp, Value: points})
+}
+
+// GeoWithinCenterSphere matches the documents whose location is within the spherical circle, the radius is in radians,
+// e.g. the distance in kilometers divided by 6378.1
+func (b *geospatialQueryBuilder) GeoWithinCenterSphere(key string, center []float64, radius float64) *Builder {
+	if radius < 0 {
+		b.parent.err = append(b.parent.err, invalidOperand(CenterSphereOp, key, "must have a non-negative radius"))
+		return b.parent
+	}
+	return b.geoWithin(key, bson.E{Key: CenterSphereOp, Value: bson.A{center, radius}})
+}
+
+// GeoIntersects matches the documents whose location intersects with the GeoJSON object
+func (b *geospatialQueryBuilder) GeoIntersects(key string, geometry any) *Builder {
+	e := bson.E{Key: GeoIntersectsOp, Value: bson.D{{Key: GeometryOp, Value: geometry}}}
+	if !b.parent.tryMergeValue(key, e) {
+		b.parent.data = append(b.parent.data, bson.E{Key: key, Value: bson.D{e}})
+	}
+	return b.parent
+}
+
+// Near sorts the documents from the nearest to the farthest of the GeoJSON point, the field must have a geospatial index
+func (b *geospatialQueryBuilder) Near(key string, point any, opt *NearOptions) *Builder {
+	return b.near(NearOp, key, point, opt)
+}
+
+// NearSphere is Near with the distances calculated on a sphere
+func (b *geospatialQueryBuilder) NearSphere(key string, point any, opt *NearOptions) *Builder {
+	return b.near(NearSphereOp, key, point, opt)
+}
+
+func (b *geospatialQueryBuilder) geoWithin(key string, shape bson.E) *Builder {
+	e := bson.E{Key: GeoWithinOp, Value: bson.D{shape}}
+	if !b.parent.tryMergeValue(key, e) {
+		b.parent.data = append(b.parent.data, bson.E{Key: key, Value: bson.D{e}})
+	}
+	return b.parent
+}
+
+func (b *geospatialQueryBuilder) near(op, key string, point any, opt *NearOptions) *Builder {
+	if opt != nil && (opt.MinDistance < 0 || opt.MaxDistance < 0) {
+		b.parent.err = append(b.parent.err, invalidOperand(op, key, "must have non-negative distances"))
+		return b.parent
+	}
+	e := bson.E{Key: op, Value: near(point, opt)}
+	if !b.parent.tryMergeValue(key, e) {
+		b.parent.data = append(b.parent.data, bson.E{Key: key, Value: bson.D{e}})
+	}
+	return b.parent
+}
+
+// near returns the operand of $near and $nearSphere
+func near(point any, opt *NearOptions) bson.D {
+	d := bson.D{{Key: GeometryOp, Value: point}}
+	if opt != nil {
+		if opt.MinDistance != 0 {
+			d = append(d, bson.E{Key: MinDistanceOp, Value: opt.MinDistance})
+		}
+		if opt.MaxDistance != 0 {
+			d = append(d, bson.E{Key: MaxDistanceOp, Value: opt.MaxDistance})
+		}
+	}
+	return d
+}
@@ -0,0 +1,142 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/matiniiuu/mongox/geojson"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func Test_geospatialQueryBuilder_GeoWithin(t *testing.T) {
+	area := geojson.NewPolygon([][]float64{{0, 0}, {3, 6}, {6, 1}, {0, 0}})
+	assert.Equal(t,
+		bson.D{{Key: "location", Value: bson.D{{Key: "$geoWithin", Value: bson.D{{Key: "$geometry", Value: area}}}}}},
+		NewBuilder().GeoWithin("location", area).Build(),
+	)
+}
+
+func Test_geospatialQueryBuilder_GeoWithinBox(t *testing.T) {
+	assert.Equal(t,
+		bson.D{{Key: "location", Value: bson.D{{Key: "$geoWithin", Value: bson.D{
+			{Key: "$box", Value: bson.A{[]float64{0, 0}, []float64{100, 100}}},
+		}}}}},
+		NewBuilder().GeoWithinBox("location", []float64{0, 0}, []float64{100, 100}).Build(),
+	)
+}
+
+func Test_geospatialQueryBuilder_GeoWithinPolygon(t *testing.T) {
+	testCases := []struct {
+		name    string
+		points  [][]float64
+		want    bson.D
+		wantErr error
+	}{
+		{
+			name:   "triangle",
+			points: [][]float64{{0, 0}, {3, 6}, {6, 0}},
+			want: bson.D{{Key: "location", Value: bson.D{{Key: "$geoWithin", Value: bson.D{
+				{Key: "$polygon", Value: [][]float64{{0, 0}, {3, 6}, {6, 0}}},
+			}}}}},
+		},
+		{
+			name:    "too few points",
+			points:  [][]float64{{0, 0}, {3, 6}},
+			want:    bson.D{},
+			wantErr: ErrInvalidOperand,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := NewBuilder().GeoWithinPolygon("location", tc.points...).BuildE()
+			assert.Equal(t, tc.want, got)
+			assert.ErrorIs(t, err, tc.wantErr)
+		})
+	}
+}
+
+func Test_geospatialQueryBuilder_GeoWithinCenterSphere(t *testing.T) {
+	testCases := []struct {
+		name    string
+		radius  float64
+		want    bson.D
+		wantErr error
+	}{
+		{
+			name:   "five kilometers",
+			radius: 5 / 6378.1,
+			want: bson.D{{Key: "location", Value: bson.D{{Key: "$geoWithin", Value: bson.D{
+				{Key: "$centerSphere", Value: bson.A{[]float64{-73.97, 40.77}, 5 / 6378.1}},
+			}}}}},
+		},
+		{
+			name:    "negative radius",
+			radius:  -1,
+			want:    bson.D{},
+			wantErr: ErrInvalidOperand,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := NewBuilder().GeoWithinCenterSphere("location", []float64{-73.97, 40.77}, tc.radius).BuildE()
+			assert.Equal(t, tc.want, got)
+			assert.ErrorIs(t, err, tc.wantErr)
+		})
+	}
+}
+
+func Test_geospatialQueryBuilder_GeoIntersects(t *testing.T) {
+	route := geojson.NewLineString([]float64{0, 0}, []float64{1, 1})
+	assert.Equal(t,
+		bson.D{{Key: "area", Value: bson.D{{Key: "$geoIntersects", Value: bson.D{{Key: "$geometry", Value: route}}}}}},
+		NewBuilder().GeoIntersects("area", route).Build(),
+	)
+}
+
+func Test_geospatialQueryBuilder_Near(t *testing.T) {
+	point := geojson.NewPoint(-73.97, 40.77)
+	testCases := []struct {
+		name    string
+		opt     *NearOptions
+		want    bson.D
+		wantErr error
+	}{
+		{
+			name: "nil options",
+			want: bson.D{{Key: "location", Value: bson.D{{Key: "$near", Value: bson.D{{Key: "$geometry", Value: point}}}}}},
+		},
+		{
+			name: "min and max distance",
+			opt:  &NearOptions{MinDistance: 10, MaxDistance: 1000},
+			want: bson.D{{Key: "location", Value: bson.D{{Key: "$near", Value: bson.D{
+				{Key: "$geometry", Value: point},
+				{Key: "$minDistance", Value: 10.0},
+				{Key: "$maxDistance", Value: 1000.0},
+			}}}}},
+		},
+		{
+			name:    "negative distance",
+			opt:     &NearOptions{MaxDistance: -1},
+			want:    bson.D{},
+			wantErr: ErrInvalidOperand,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := NewBuilder().Near("location", point, tc.opt).BuildE()
+			assert.Equal(t, tc.want, got)
+			assert.ErrorIs(t, err, tc.wantErr)
+		})
+	}
+}
+
+func Test_geospatialQueryBuilder_NearSphere(t *testing.T) {
+	point := geojson.NewPoint(-73.97, 40.77)
+	assert.Equal(t,
+		bson.D{{Key: "location", Value: bson.D{{Key: "$nearSphere", Value: bson.D{
+			{Key: "$geometry", Value: point},
+			{Key: "$maxDistance", Value: 500.0},
+		}}}}},
+		NewBuilder().NearSphere("location", point, &NearOptions{MaxDistance: 500}).Build(),
+	)
+}
@@ -0,0 +1,356 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/matiniiuu/mongox/internal/pkg/structs"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+var ErrInvalidSchema = errors.New("mongox: cannot derive $jsonSchema")
+
+// schemaTypes are the bson types of the types which are not encoded according to their kind
+var schemaTypes = map[reflect.Type]string{
+	reflect.TypeFor[time.Time]():       "date",
+	reflect.TypeFor[bson.DateTime]():   "date",
+	reflect.TypeFor[bson.ObjectID]():   "objectId",
+	reflect.TypeFor[bson.Decimal128](): "decimal",
+	reflect.TypeFor[bson.Binary]():     "binData",
+	reflect.TypeFor[bson.Timestamp]():  "timestamp",
+	reflect.TypeFor[bson.Regex]():      "regex",
+	reflect.TypeFor[bson.D]():          "object",
+	reflect.TypeFor[bson.Raw]():        "object",
+}
+
+var (
+	marshalerType      = reflect.TypeFor[bson.Marshaler]()
+	valueMarshalerType = reflect.TypeFor[bson.ValueMarshaler]()
+	oneOfParam         = regexp.MustCompile(`'[^']*'|\S+`)
+)
+
+// schemaKind is what the validate rules of a value are about, the length of a string, the number itself,
+// the items of an array or the properties of an object
+type schemaKind int
+
+const (
+	kindNone schemaKind = iota
+	kindString
+	kindInt
+	kindFloat
+	kindArray
+	kindObject
+)
+
+// JsonSchemaOf derives the operand of $jsonSchema from the struct T, the properties are named after the bson tags
+// and are constrained by the validate tags of go-playground/validator:
+//   - required adds the property to the required ones
+//   - min, max, gte, lte, gt, lt and len bound a number, the length of a string, the items of an array or the properties of a map
+//   - eq and oneof enumerate the allowed values of a number or a string
+//   - unique requires the items of an array to be unique
+//   - dive applies the following rules to the items of an array or the values of a map
+//
+// The other rules have no counterpart and are left out, as are the rules of a field tagged with omitempty,
+// since its zero value is valid whatever they are
+func JsonSchemaOf[T any]() (bson.D, error) {
+	typ := structs.Indirect(reflect.TypeFor[T]())
+	if typ == nil || typ.Kind() != reflect.Struct || schemaTypes[typ] != "" {
+		return nil, fmt.Errorf("%w: %v is not a struct", ErrInvalidSchema, reflect.TypeFor[T]())
+	}
+	properties, required, err := structSchema(typ, map[reflect.Type]bool{typ: true})
+	if err != nil {
+		return nil, err
+	}
+	return objectSchema(properties, required), nil
+}
+
+func objectSchema(properties bson.D, required []string) bson.D {
+	d := bson.D{{Key: "bsonType", Value: "object"}}
+	if len(required) > 0 {
+		d = append(d, bson.E{Key: "required", Value: required})
+	}
+	return append(d, bson.E{Key: "properties", Value: properties})
+}
+
+// structSchema returns the properties of the fields of the struct and the keys of the required ones,
+// the fields of inline structs are flattened
+func structSchema(typ reflect.Type, visiting map[reflect.Type]bool) (bson.D, []string, error) {
+	var (
+		properties = bson.D{}
+		required   []string
+	)
+	for i := 0; i < typ.NumField(); i++ {
+		sf := typ.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		tag := structs.ParseTag(sf)
+		if tag.Skip {
+			continue
+		}
+		ft := structs.Indirect(sf.Type)
+		if tag.Inline && ft.Kind() == reflect.Map {
+			// the entries of an inline map are any other properties
+			continue
+		}
+		if tag.Inline && ft.Kind() == reflect.Struct {
+			if visiting[ft] {
+				continue
+			}
+			visiting[ft] = true
+			inlineProperties, inlineRequired, err := structSchema(ft, visiting)
+			delete(visiting, ft)
+			if err != nil {
+				return nil, nil, err
+			}
+			for _, p := range inlineProperties {
+				if !slices.ContainsFunc(properties, func(e bson.E) bool { return e.Key == p.Key }) {
+					properties = append(properties, p)
+				}
+			}
+			required = append(required, inlineRequired...)
+			continue
+		}
+		rules := parseRules(sf.Tag.Get("validate"))
+		if slices.Contains(rules, "required") {
+			required = append(required, tag.Key)
+		}
+		property, err := schemaOf(sf.Type, rules, visiting)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s.%s: %w", typ.Name(), sf.Name, err)
+		}
+		properties = append(properties, bson.E{Key: tag.Key, Value: property})
+	}
+	return properties, required, nil
+}
+
+// schemaOf returns the schema of a value of the type constrained by the validate rules
+func schemaOf(typ reflect.Type, rules []string, visiting map[reflect.Type]bool) (bson.D, error) {
+	rules, itemRules := splitDive(rules)
+	// a nil pointer, slice or map is encoded as null, which passes the rules unless it is required
+	nullable := !slices.Contains(rules, "required")
+	isNil := false
+	for typ.Kind() == reflect.Pointer {
+		typ, isNil = typ.Elem(), true
+	}
+
+	var (
+		bsonType any
+		kind     schemaKind
+		nested   bson.D
+	)
+	if t, ok := schemaTypes[typ]; ok {
+		bsonType, isNil = t, isNil || typ.Kind() == reflect.Slice
+	} else if typ.Implements(marshalerType) || reflect.PointerTo(typ).Implements(marshalerType) {
+		bsonType = "object"
+	} else if typ.Implements(valueMarshalerType) || reflect.PointerTo(typ).Implements(valueMarshalerType) {
+		// the bson type is only known when the value is marshaled
+		return bson.D{}, nil
+	} else {
+		switch typ.Kind() {
+		case reflect.Bool:
+			bsonType = "bool"
+		case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+			bsonType, kind = "int", kindInt
+		case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+			// the values which fit in 32 bits are encoded as int
+			bsonType, kind = bson.A{"int", "long"}, kindInt
+		case reflect.Float32, reflect.Float64:
+			bsonType, kind = "double", kindFloat
+		case reflect.String:
+			bsonType, kind = "string", kindString
+		case reflect.Slice, reflect.Array:
+			if typ.Elem().Kind() == reflect.Uint8 {
+				bsonType = "binData"
+			} else {
+				bsonType, kind = "array", kindArray
+				items, err := schemaOf(typ.Elem(), itemRules, visiting)
+				if err != nil {
+					return nil, err
+				}
+				if len(items) > 0 {
+					nested = bson.D{{Key: "items", Value: items}}
+				}
+			}
+			isNil = isNil || typ.Kind() == reflect.Slice
+		case reflect.Map:
+			if typ.Key().Kind() != reflect.String {
+				return nil, fmt.Errorf("%w: the key of %v is not a string", ErrInvalidSchema, typ)
+			}
+			bsonType, kind, isNil = "object", kindObject, true
+			values, err := schemaOf(typ.Elem(), itemRules, visiting)
+			if err != nil {
+				return nil, err
+			}
+			if len(values) > 0 {
+				nested = bson.D{{Key: "additionalProperties", Value: values}}
+			}
+		case reflect.Struct:
+			bsonType = "object"
+			// the fields of a struct skipped by the validator are not constrained, the recursive ones neither
+			if !slices.Contains(rules, "-") && !visiting[typ] {
+				visiting[typ] = true
+				properties, required, err := structSchema(typ, visiting)
+				delete(visiting, typ)
+				if err != nil {
+					return nil, err
+				}
+				nested = objectSchema(properties, required)[1:]
+			}
+		case reflect.Interface:
+			return bson.D{}, nil
+		default:
+			return nil, fmt.Errorf("%w: unsupported type %v", ErrInvalidSchema, typ)
+		}
+	}
+
+	if isNil && nullable {
+		if types, ok := bsonType.(bson.A); ok {
+			bsonType = append(types, "null")
+		} else {
+			bsonType = bson.A{bsonType, "null"}
+		}
+	}
+	d := bson.D{{Key: "bsonType", Value: bsonType}}
+	if !slices.Contains(rules, "omitempty") && !slices.Contains(rules, "-") {
+		constraints, err := constraintsOf(kind, rules)
+		if err != nil {
+			return nil, err
+		}
+		d = append(d, constraints...)
+	}
+	return append(d, nested...), nil
+}
+
+// constraintsOf returns the keywords of the validate rules which have a counterpart for the kind of value
+func constraintsOf(kind schemaKind, rules []string) (bson.D, error) {
+	var d bson.D
+	for _, rule := range rules {
+		name, param, _ := strings.Cut(rule, "=")
+		if kind == kindNone || param == "" && name != "unique" {
+			continue
+		}
+		var err error
+		switch kind {
+		case kindInt, kindFloat:
+			d, err = numberConstraint(d, kind, name, param)
+		case kindString:
+			d, err = lengthConstraint(d, "Length", name, param)
+			if name == "eq" || name == "oneof" {
+				d = append(d, bson.E{Key: "enum", Value: enumOf(name, param)})
+			}
+		case kindArray:
+			d, err = lengthConstraint(d, "Items", name, param)
+			if name == "unique" {
+				d = append(d, bson.E{Key: "uniqueItems", Value: true})
+			}
+		case kindObject:
+			d, err = lengthConstraint(d, "Properties", name, param)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid rule %s", ErrInvalidSchema, rule)
+		}
+	}
+	return d, nil
+}
+
+func numberConstraint(d bson.D, kind schemaKind, name, param string) (bson.D, error) {
+	parse := func(s string) (any, error) {
+		if kind == kindInt {
+			return strconv.ParseInt(s, 10, 64)
+		}
+		return strconv.ParseFloat(s, 64)
+	}
+	switch name {
+	case "min", "gte", "gt":
+		v, err := parse(param)
+		if err != nil {
+			return nil, err
+		}
+		d = append(d, bson.E{Key: "minimum", Value: v})
+		if name == "gt" {
+			d = append(d, bson.E{Key: "exclusiveMinimum", Value: true})
+		}
+	case "max", "lte", "lt":
+		v, err := parse(param)
+		if err != nil {
+			return nil, err
+		}
+		d = append(d, bson.E{Key: "maximum", Value: v})
+		if name == "lt" {
+			d = append(d, bson.E{Key: "exclusiveMaximum", Value: true})
+		}
+	case "eq", "len", "oneof":
+		values := bson.A{}
+		for _, s := range enumOf(name, param) {
+			v, err := parse(s)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+		}
+		d = append(d, bson.E{Key: "enum", Value: values})
+	}
+	return d, nil
+}
+
+// lengthConstraint appends the bound of the length of a string, the items of an array or the properties of an object
+func lengthConstraint(d bson.D, suffix, name, param string) (bson.D, error) {
+	switch name {
+	case "min", "gte", "gt", "max", "lte", "lt", "len":
+	default:
+		return d, nil
+	}
+	n, err := strconv.ParseInt(param, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	switch name {
+	case "min", "gte":
+		d = append(d, bson.E{Key: "min" + suffix, Value: n})
+	case "gt":
+		d = append(d, bson.E{Key: "min" + suffix, Value: n + 1})
+	case "max", "lte":
+		d = append(d, bson.E{Key: "max" + suffix, Value: n})
+	case "lt":
+		d = append(d, bson.E{Key: "max" + suffix, Value: max(n-1, 0)})
+	case "len":
+		d = append(d, bson.E{Key: "min" + suffix, Value: n}, bson.E{Key: "max" + suffix, Value: n})
+	}
+	return d, nil
+}
+
+// enumOf returns the values of an eq or a oneof rule, the values of oneof are separated by spaces or single quoted
+func enumOf(name, param string) []string {
+	if name != "oneof" {
+		return []string{param}
+	}
+	values := oneOfParam.FindAllString(param, -1)
+	for i, v := range values {
+		values[i] = strings.Trim(v, "'")
+	}
+	return values
+}
+
+// parseRules splits the validate tag into its rules, the alternatives separated by | have no counterpart and are dropped
+func parseRules(tag string) []string {
+	if tag == "" {
+		return nil
+	}
+	rules := strings.Split(tag, ",")
+	return slices.DeleteFunc(rules, func(rule string) bool { return strings.Contains(rule, "|") })
+}
+
+// splitDive splits the rules of a value from those of its items
+func splitDive(rules []string) ([]string, []string) {
+	if idx := slices.Index(rules, "dive"); idx >= 0 {
+		return rules[:idx], rules[idx+1:]
+	}
+	return rules, nil
+}
@@ -0,0 +1,151 @@
+package query
+
+import (
+	"testing"
+	"time"
+
+	"github.com/matiniiuu/mongox/geojson"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type schemaAddress struct {
+	City string `bson:"city" validate:"required"`
+	Zip  string `bson:"zip" validate:"len=5"`
+}
+
+type schemaModel struct {
+	Base        `bson:",inline"`
+	Name        string            `bson:"name" validate:"required,min=2,max=64"`
+	Age         int               `bson:"age" validate:"gte=0,lt=150"`
+	Score       float64           `bson:"score" validate:"gt=0"`
+	Role        string            `bson:"role" validate:"oneof=admin 'power user' guest"`
+	Level       int8              `bson:"level" validate:"oneof=1 2 3"`
+	Nickname    string            `bson:"nickname,omitempty" validate:"omitempty,min=3"`
+	Email       string            `bson:"email" validate:"required,email"`
+	Tags        []string          `bson:"tags" validate:"max=10,unique,dive,min=1"`
+	Permissions uint32            `bson:"permissions"`
+	Active      bool              `bson:"active"`
+	Avatar      []byte            `bson:"avatar"`
+	Address     *schemaAddress    `bson:"address" validate:"required"`
+	Previous    []schemaAddress   `bson:"previous"`
+	Labels      map[string]string `bson:"labels" validate:"max=5,dive,max=20"`
+	Location    geojson.Point     `bson:"location"`
+	Manager     *schemaModel      `bson:"manager"`
+	Extra       any               `bson:"extra"`
+	Ignored     string            `bson:"-"`
+	internal    string
+}
+
+type Base struct {
+	Id        bson.ObjectID `bson:"_id,omitempty"`
+	CreatedAt time.Time     `bson:"created_at"`
+}
+
+func TestJsonSchemaOf(t *testing.T) {
+	got, err := JsonSchemaOf[schemaModel]()
+	require.NoError(t, err)
+	assert.Equal(t, bson.D{
+		{Key: "bsonType", Value: "object"},
+		{Key: "required", Value: []string{"name", "email", "address"}},
+		{Key: "properties", Value: bson.D{
+			{Key: "_id", Value: bson.D{{Key: "bsonType", Value: "objectId"}}},
+			{Key: "created_at", Value: bson.D{{Key: "bsonType", Value: "date"}}},
+			{Key: "name", Value: bson.D{
+				{Key: "bsonType", Value: "string"},
+				{Key: "minLength", Value: int64(2)},
+				{Key: "maxLength", Value: int64(64)},
+			}},
+			{Key: "age", Value: bson.D{
+				{Key: "bsonType", Value: bson.A{"int", "long"}},
+				{Key: "minimum", Value: int64(0)},
+				{Key: "maximum", Value: int64(150)},
+				{Key: "exclusiveMaximum", Value: true},
+			}},
+			{Key: "score", Value: bson.D{
+				{Key: "bsonType", Value: "double"},
+				{Key: "minimum", Value: 0.0},
+				{Key: "exclusiveMinimum", Value: true},
+			}},
+			{Key: "role", Value: bson.D{
+				{Key: "bsonType", Value: "string"},
+				{Key: "enum", Value: []string{"admin", "power user", "guest"}},
+			}},
+			{Key: "level", Value: bson.D{
+				{Key: "bsonType", Value: "int"},
+				{Key: "enum", Value: bson.A{int64(1), int64(2), int64(3)}},
+			}},
+			{Key: "nickname", Value: bson.D{{Key: "bsonType", Value: "string"}}},
+			{Key: "email", Value: bson.D{{Key: "bsonType", Value: "string"}}},
+			{Key: "tags", Value: bson.D{
+				{Key: "bsonType", Value: bson.A{"array", "null"}},
+				{Key: "maxItems", Value: int64(10)},
+				{Key: "uniqueItems", Value: true},
+				{Key: "items", Value: bson.D{{Key: "bsonType", Value: "string"}, {Key: "minLength", Value: int64(1)}}},
+			}},
+			{Key: "permissions", Value: bson.D{{Key: "bsonType", Value: bson.A{"int", "long"}}}},
+			{Key: "active", Value: bson.D{{Key: "bsonType", Value: "bool"}}},
+			{Key: "avatar", Value: bson.D{{Key: "bsonType", Value: bson.A{"binData", "null"}}}},
+			{Key: "address", Value: bson.D{
+				{Key: "bsonType", Value: "object"},
+				{Key: "required", Value: []string{"city"}},
+				{Key: "properties", Value: bson.D{
+					{Key: "city", Value: bson.D{{Key: "bsonType", Value: "string"}}},
+					{Key: "zip", Value: bson.D{
+						{Key: "bsonType", Value: "string"},
+						{Key: "minLength", Value: int64(5)},
+						{Key: "maxLength", Value: int64(5)},
+					}},
+				}},
+			}},
+			{Key: "previous", Value: bson.D{
+				{Key: "bsonType", Value: bson.A{"array", "null"}},
+				{Key: "items", Value: bson.D{
+					{Key: "bsonType", Value: "object"},
+					{Key: "required", Value: []string{"city"}},
+					{Key: "properties", Value: bson.D{
+						{Key: "city", Value: bson.D{{Key: "bsonType", Value: "string"}}},
+						{Key: "zip", Value: bson.D{
+							{Key: "bsonType", Value: "string"},
+							{Key: "minLength", Value: int64(5)},
+							{Key: "maxLength", Value: int64(5)},
+						}},
+					}},
+				}},
+			}},
+			{Key: "labels", Value: bson.D{
+				{Key: "bsonType", Value: bson.A{"object", "null"}},
+				{Key: "maxProperties", Value: int64(5)},
+				{Key: "additionalProperties", Value: bson.D{{Key: "bsonType", Value: "string"}, {Key: "maxLength", Value: int64(20)}}},
+			}},
+			{Key: "location", Value: bson.D{{Key: "bsonType", Value: "object"}}},
+			{Key: "manager", Value: bson.D{{Key: "bsonType", Value: bson.A{"object", "null"}}}},
+			{Key: "extra", Value: bson.D{}},
+		}},
+	}, got)
+}
+
+func TestJsonSchemaOf_Errors(t *testing.T) {
+	_, err := JsonSchemaOf[time.Time]()
+	assert.ErrorIs(t, err, ErrInvalidSchema)
+
+	_, err = JsonSchemaOf[string]()
+	assert.ErrorIs(t, err, ErrInvalidSchema)
+
+	_, err = JsonSchemaOf[struct {
+		Callback func() `bson:"callback"`
+	}]()
+	assert.ErrorIs(t, err, ErrInvalidSchema)
+
+	_, err = JsonSchemaOf[struct {
+		Age int `bson:"age" validate:"min=ten"`
+	}]()
+	assert.ErrorIs(t, err, ErrInvalidSchema)
+}
+
+func TestJsonSchemaOf_Query(t *testing.T) {
+	schema, err := JsonSchemaOf[schemaAddress]()
+	require.NoError(t, err)
+	assert.Equal(t, bson.D{{Key: "$jsonSchema", Value: schema}}, NewBuilder().JsonSchema(schema).Build())
+}
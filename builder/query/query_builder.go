@@ -1,6 +1,9 @@
 package query
 
 import (
+	"errors"
+	"slices"
+
 	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
@@ -15,6 +18,8 @@ func NewBuilder() *Builder {
 	query.arrayQueryBuilder = arrayQueryBuilder{parent: query}
 	query.evaluationQueryBuilder = evaluationQueryBuilder{parent: query}
 	query.projectionQueryBuilder = projectionQueryBuilder{parent: query}
+	query.geospatialQueryBuilder = geospatialQueryBuilder{parent: query}
+	query.bitwiseQueryBuilder = bitwiseQueryBuilder{parent: query}
 	return query
 }
 
@@ -26,6 +31,8 @@ type Builder struct {
 	arrayQueryBuilder
 	evaluationQueryBuilder
 	projectionQueryBuilder
+	geospatialQueryBuilder
+	bitwiseQueryBuilder
 
 	err []error
 }
@@ -34,6 +41,16 @@ func (b *Builder) Build() bson.D {
 	return b.data
 }
 
+// BuildE returns the query together with the errors of Err
+func (b *Builder) BuildE() (bson.D, error) {
+	return b.data, b.Err()
+}
+
+// Err returns the joined errors of the invalid input given to the builder and of the validation of the query, see Validate
+func (b *Builder) Err() error {
+	return errors.Join(append(slices.Clone(b.err), validate(b.data, "")...)...)
+}
+
 // Id appends an element with '_id' key and given value to the builder's data slice.
 func (b *Builder) Id(v any) *Builder {
 	b.data = append(b.data, bson.E{Key: IdOp, Value: v})
@@ -3,12 +3,21 @@ package query
 const (
 	AllOp                = "$all"
 	AndOp                = "$and"
+	BitsAllClearOp       = "$bitsAllClear"
+	BitsAllSetOp         = "$bitsAllSet"
+	BitsAnyClearOp       = "$bitsAnyClear"
+	BitsAnySetOp         = "$bitsAnySet"
+	BoxOp                = "$box"
 	CaseSensitiveOp      = "$caseSensitive"
+	CenterSphereOp       = "$centerSphere"
 	DiacriticSensitiveOp = "$diacriticSensitive"
 	ElemMatchOp          = "$elemMatch"
 	EqOp                 = "$eq"
 	ExistsOp             = "$exists"
 	ExprOp               = "$expr"
+	GeoIntersectsOp      = "$geoIntersects"
+	GeometryOp           = "$geometry"
+	GeoWithinOp          = "$geoWithin"
 	GtOp                 = "$gt"
 	GteOp                = "$gte"
 	IdOp                 = "_id"
@@ -17,13 +26,18 @@ const (
 	LanguageOp           = "$language"
 	LtOp                 = "$lt"
 	LteOp                = "$lte"
+	MaxDistanceOp        = "$maxDistance"
+	MinDistanceOp        = "$minDistance"
 	ModOp                = "$mod"
+	NearOp               = "$near"
+	NearSphereOp         = "$nearSphere"
 	NeOp                 = "$ne"
 	NinOp                = "$nin"
 	NorOp                = "$nor"
 	NotOp                = "$not"
 	OptionsOp            = "$options"
 	OrOp                 = "$or"
+	PolygonOp            = "$polygon"
 	RegexOp              = "$regex"
 	SearchOp             = "$search"
 	SizeOp               = "$size"
@@ -38,3 +52,9 @@ type TextOptions struct {
 	CaseSensitive      bool
 	DiacriticSensitive bool
 }
+
+// NearOptions are the distances of $near and $nearSphere, in meters for a GeoJSON point, a zero distance is left out
+type NearOptions struct {
+	MinDistance float64
+	MaxDistance float64
+}
@@ -0,0 +1,116 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+var (
+	ErrEmptyKey       = errors.New("mongox: empty key in query")
+	ErrInvalidOperand = errors.New("mongox: invalid operand of query operator")
+)
+
+// Validate checks the query for the mistakes which would only be reported by the server, e.g. an empty key,
+// a $size with a negative value or an $and without conditions, and returns them joined
+func Validate(query bson.D) error {
+	return errors.Join(validate(query, "")...)
+}
+
+func validate(query bson.D, field string) []error {
+	var errs []error
+	for _, e := range query {
+		if e.Key == "" {
+			errs = append(errs, ErrEmptyKey)
+			continue
+		}
+		if !strings.HasPrefix(e.Key, "$") {
+			// the value of a field is either an operator document or the value to match
+			if d, ok := e.Value.(bson.D); ok && isOperators(d) {
+				errs = append(errs, validate(d, e.Key)...)
+			}
+			continue
+		}
+		switch e.Key {
+		case AndOp, OrOp, NorOp:
+			conditions := reflect.ValueOf(e.Value)
+			if !isArray(e.Value) || conditions.Len() == 0 {
+				errs = append(errs, invalidOperand(e.Key, field, "must be a non-empty array"))
+				continue
+			}
+			for i := 0; i < conditions.Len(); i++ {
+				if d, ok := conditions.Index(i).Interface().(bson.D); ok {
+					errs = append(errs, validate(d, field)...)
+				}
+			}
+		case NotOp, ElemMatchOp:
+			if d, ok := e.Value.(bson.D); ok {
+				errs = append(errs, validate(d, field)...)
+			}
+		case InOp, NinOp, AllOp:
+			if !isArray(e.Value) {
+				errs = append(errs, invalidOperand(e.Key, field, "must be an array"))
+			}
+		case SizeOp:
+			if !isNonNegativeInteger(e.Value) {
+				errs = append(errs, invalidOperand(e.Key, field, "must be a non-negative integer"))
+			}
+		case RegexOp, GeoWithinOp, GeoIntersectsOp, NearOp, NearSphereOp:
+			if e.Value == nil {
+				errs = append(errs, invalidOperand(e.Key, field, "must not be nil"))
+			}
+		case BitsAllClearOp, BitsAllSetOp, BitsAnyClearOp, BitsAnySetOp:
+			if !isBitmask(e.Value) {
+				errs = append(errs, invalidOperand(e.Key, field, "must be a non-negative integer, an array of bit positions or a binary"))
+			}
+		case ModOp:
+			if !isArray(e.Value) || reflect.ValueOf(e.Value).Len() != 2 {
+				errs = append(errs, invalidOperand(e.Key, field, "must be an array of a divisor and a remainder"))
+			}
+		}
+	}
+	return errs
+}
+
+func invalidOperand(op, field, reason string) error {
+	if field == "" {
+		return fmt.Errorf("%w: %s %s", ErrInvalidOperand, op, reason)
+	}
+	return fmt.Errorf("%w: %s of %q %s", ErrInvalidOperand, op, field, reason)
+}
+
+// isOperators reports whether the document is made of query operators rather than being an embedded document to match
+func isOperators(d bson.D) bool {
+	return len(d) > 0 && strings.HasPrefix(d[0].Key, "$")
+}
+
+// isArray reports whether the value is a non-nil slice or an array, a nil slice is encoded as null
+func isArray(value any) bool {
+	v := reflect.ValueOf(value)
+	return v.Kind() == reflect.Array || v.Kind() == reflect.Slice && !v.IsNil()
+}
+
+// isBitmask reports whether the value is a valid operand of the bitwise operators
+func isBitmask(value any) bool {
+	if _, ok := value.(bson.Binary); ok {
+		return true
+	}
+	if isArray(value) {
+		positions := reflect.ValueOf(value)
+		for i := 0; i < positions.Len(); i++ {
+			if !isNonNegativeInteger(positions.Index(i).Interface()) {
+				return false
+			}
+		}
+		return true
+	}
+	return isNonNegativeInteger(value)
+}
+
+func isNonNegativeInteger(value any) bool {
+	v := reflect.ValueOf(value)
+	return v.CanUint() || v.CanInt() && v.Int() >= 0
+}
@@ -0,0 +1,112 @@
+package query
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		name    string
+		query   bson.D
+		wantErr []error
+	}{
+		{
+			name:  "valid",
+			query: NewBuilder().Eq("name", "Mingyong Chen").Size("tags", 2).In("age", 18, 19).Or(Eq("age", 18), Regex("name", "^M")).Build(),
+		},
+		{
+			name: "valid bitmasks",
+			query: NewBuilder().BitsAllSet("permissions", 6).BitsAnyClear("permissions", []int{0, 3}).
+				BitsAnySet("flags", bson.Binary{Data: []byte{0x20}}).Build(),
+		},
+		{
+			name:  "embedded document",
+			query: bson.D{{Key: "address", Value: bson.D{{Key: "", Value: "Paris"}}}},
+		},
+		{
+			name:    "empty key",
+			query:   NewBuilder().Eq("", "Mingyong Chen").Build(),
+			wantErr: []error{ErrEmptyKey},
+		},
+		{
+			name:    "negative size",
+			query:   Size("tags", -1),
+			wantErr: []error{ErrInvalidOperand},
+		},
+		{
+			name:    "size of another type",
+			query:   bson.D{{Key: "tags", Value: bson.D{{Key: SizeOp, Value: "1"}}}},
+			wantErr: []error{ErrInvalidOperand},
+		},
+		{
+			name:    "nil regex",
+			query:   bson.D{{Key: "name", Value: bson.D{{Key: RegexOp, Value: nil}}}},
+			wantErr: []error{ErrInvalidOperand},
+		},
+		{
+			name:    "empty or",
+			query:   NewBuilder().Or().Build(),
+			wantErr: []error{ErrInvalidOperand},
+		},
+		{
+			name:    "nested conditions",
+			query:   And(Size("tags", -1), Not(bson.D{{Key: "", Value: 1}})),
+			wantErr: []error{ErrInvalidOperand, ErrEmptyKey},
+		},
+		{
+			name:    "nil in",
+			query:   NewBuilder().In("age").Build(),
+			wantErr: []error{ErrInvalidOperand},
+		},
+		{
+			name:    "invalid mod",
+			query:   bson.D{{Key: "age", Value: bson.D{{Key: ModOp, Value: bson.A{2}}}}},
+			wantErr: []error{ErrInvalidOperand},
+		},
+		{
+			name:    "negative bitmask",
+			query:   bson.D{{Key: "permissions", Value: bson.D{{Key: BitsAllSetOp, Value: -1}}}},
+			wantErr: []error{ErrInvalidOperand},
+		},
+		{
+			name:    "bit positions of strings",
+			query:   bson.D{{Key: "permissions", Value: bson.D{{Key: BitsAnySetOp, Value: []string{"1"}}}}},
+			wantErr: []error{ErrInvalidOperand},
+		},
+		{
+			name:    "nil geometry",
+			query:   bson.D{{Key: "location", Value: bson.D{{Key: NearOp, Value: nil}}}},
+			wantErr: []error{ErrInvalidOperand},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Validate(tc.query)
+			if len(tc.wantErr) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Len(t, err.(interface{ Unwrap() []error }).Unwrap(), len(tc.wantErr))
+			for _, want := range tc.wantErr {
+				assert.True(t, errors.Is(err, want), err)
+			}
+		})
+	}
+}
+
+func TestBuilder_BuildE(t *testing.T) {
+	query, err := NewBuilder().Eq("name", "Mingyong Chen").BuildE()
+	assert.NoError(t, err)
+	assert.Equal(t, bson.D{{Key: "name", Value: bson.D{{Key: EqOp, Value: "Mingyong Chen"}}}}, query)
+
+	b := NewBuilder().Mod("age", "2", 0).Size("tags", -1)
+	query, err = b.BuildE()
+	assert.Equal(t, bson.D{{Key: "tags", Value: bson.D{{Key: SizeOp, Value: -1}}}}, query)
+	assert.ErrorIs(t, err, ErrInvalidOperand)
+	assert.EqualError(t, b.Err(), "mongox: invalid operand of query operator: $mod of \"age\" must have a numeric divisor\n"+
+		"mongox: invalid operand of query operator: $size of \"tags\" must be a non-negative integer")
+}
@@ -79,3 +79,15 @@ func Mul(key string, value any) bson.D {
 func Rename(key string, value any) bson.D {
 	return bson.D{{Key: RenameOp, Value: bson.D{{Key: key, Value: value}}}}
 }
+
+func BitAnd(key string, value any) bson.D {
+	return bson.D{{Key: BitOp, Value: bson.D{{Key: key, Value: bson.D{{Key: BitAndOp, Value: value}}}}}}
+}
+
+func BitOr(key string, value any) bson.D {
+	return bson.D{{Key: BitOp, Value: bson.D{{Key: key, Value: bson.D{{Key: BitOrOp, Value: value}}}}}}
+}
+
+func BitXor(key string, value any) bson.D {
+	return bson.D{{Key: BitOp, Value: bson.D{{Key: key, Value: bson.D{{Key: BitXorOp, Value: value}}}}}}
+}
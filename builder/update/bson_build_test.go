@@ -156,3 +156,15 @@ func TestRename(t *testing.T) {
 		assert.Equal(t, bson.D{bson.E{Key: "$rename", Value: bson.D{bson.E{Key: "nickname", Value: "alias"}}}}, Rename("nickname", "alias"))
 	})
 }
+
+func TestBit(t *testing.T) {
+	t.Run("test BitAnd", func(t *testing.T) {
+		assert.Equal(t, bson.D{bson.E{Key: "$bit", Value: bson.D{bson.E{Key: "flags", Value: bson.D{bson.E{Key: "and", Value: 10}}}}}}, BitAnd("flags", 10))
+	})
+	t.Run("test BitOr", func(t *testing.T) {
+		assert.Equal(t, bson.D{bson.E{Key: "$bit", Value: bson.D{bson.E{Key: "flags", Value: bson.D{bson.E{Key: "or", Value: 5}}}}}}, BitOr("flags", 5))
+	})
+	t.Run("test BitXor", func(t *testing.T) {
+		assert.Equal(t, bson.D{bson.E{Key: "$bit", Value: bson.D{bson.E{Key: "flags", Value: bson.D{bson.E{Key: "xor", Value: 1}}}}}}, BitXor("flags", 1))
+	})
+}
@@ -80,3 +80,40 @@ func (b *fieldUpdateBuilder) Rename(key string, value any) *Builder {
 	}
 	return b.parent
 }
+
+func (b *fieldUpdateBuilder) BitAnd(key string, value any) *Builder {
+	return b.bit(key, BitAndOp, value)
+}
+
+func (b *fieldUpdateBuilder) BitOr(key string, value any) *Builder {
+	return b.bit(key, BitOrOp, value)
+}
+
+func (b *fieldUpdateBuilder) BitXor(key string, value any) *Builder {
+	return b.bit(key, BitXorOp, value)
+}
+
+// bit adds the bitwise operation to the $bit of the key, several operations on the same key are merged
+func (b *fieldUpdateBuilder) bit(key, op string, value any) *Builder {
+	e := bson.E{Key: op, Value: value}
+	for _, datum := range b.parent.data {
+		if datum.Key != BitOp {
+			continue
+		}
+		fields, ok := datum.Value.(bson.D)
+		if !ok {
+			continue
+		}
+		for idx, f := range fields {
+			if ops, ok := f.Value.(bson.D); ok && f.Key == key {
+				fields[idx].Value = append(ops, e)
+				return b.parent
+			}
+		}
+	}
+	field := bson.E{Key: key, Value: bson.D{e}}
+	if !b.parent.tryMergeValue(BitOp, field) {
+		b.parent.data = append(b.parent.data, bson.E{Key: BitOp, Value: bson.D{field}})
+	}
+	return b.parent
+}
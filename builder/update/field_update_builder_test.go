@@ -90,3 +90,17 @@ func Test_fieldUpdateBuilder_CurrentDate(t *testing.T) {
 		assert.Equal(t, bson.D{{Key: "$currentDate", Value: bson.D{bson.E{Key: "lastModified", Value: true}, bson.E{Key: "cancellation.date", Value: bson.D{bson.E{Key: "$type", Value: "timestamp"}}}}}}, NewBuilder().CurrentDate("lastModified", true).CurrentDate("cancellation.date", bsonx.D("$type", "timestamp")).Build())
 	})
 }
+
+func Test_fieldUpdateBuilder_Bit(t *testing.T) {
+	t.Run("single operation", func(t *testing.T) {
+		assert.Equal(t, bson.D{{Key: "$bit", Value: bson.D{bson.E{Key: "flags", Value: bson.D{bson.E{Key: "and", Value: 10}}}}}}, NewBuilder().BitAnd("flags", 10).Build())
+		assert.Equal(t, bson.D{{Key: "$bit", Value: bson.D{bson.E{Key: "flags", Value: bson.D{bson.E{Key: "or", Value: 5}}}}}}, NewBuilder().BitOr("flags", 5).Build())
+		assert.Equal(t, bson.D{{Key: "$bit", Value: bson.D{bson.E{Key: "flags", Value: bson.D{bson.E{Key: "xor", Value: 1}}}}}}, NewBuilder().BitXor("flags", 1).Build())
+	})
+	t.Run("multiple operation", func(t *testing.T) {
+		assert.Equal(t, bson.D{{Key: "$bit", Value: bson.D{
+			bson.E{Key: "flags", Value: bson.D{bson.E{Key: "and", Value: 10}, bson.E{Key: "or", Value: 5}}},
+			bson.E{Key: "mask", Value: bson.D{bson.E{Key: "xor", Value: 1}}},
+		}}}, NewBuilder().BitAnd("flags", 10).BitXor("mask", 1).BitOr("flags", 5).Build())
+	})
+}
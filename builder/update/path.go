@@ -0,0 +1,28 @@
+package update
+
+import (
+	"strings"
+)
+
+// Positional returns the path of the fields of the first array element matched by the query, e.g.
+// Positional("grades", "score") returns "grades.$.score"
+func Positional(array string, fields ...string) string {
+	return join(array, PositionalOp, fields)
+}
+
+// AllPositional returns the path of the fields of all the array elements, e.g.
+// AllPositional("grades", "score") returns "grades.$[].score"
+func AllPositional(array string, fields ...string) string {
+	return join(array, AllPositionalOp, fields)
+}
+
+// FilteredPositional returns the path of the fields of the array elements matched by the array filter of the
+// identifier, e.g. FilteredPositional("grades", "elem", "score") returns "grades.$[elem].score"
+// The array filters are set by updater.Updater.ArrayFilters, e.g. query.Gte("elem.score", 85)
+func FilteredPositional(array, identifier string, fields ...string) string {
+	return join(array, "$["+identifier+"]", fields)
+}
+
+func join(array, positional string, fields []string) string {
+	return strings.Join(append([]string{array, positional}, fields...), ".")
+}
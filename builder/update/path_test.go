@@ -0,0 +1,27 @@
+package update
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPositional(t *testing.T) {
+	testCases := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{name: "positional", got: Positional("grades"), want: "grades.$"},
+		{name: "positional field", got: Positional("grades", "score"), want: "grades.$.score"},
+		{name: "all positional", got: AllPositional("grades"), want: "grades.$[]"},
+		{name: "all positional nested", got: AllPositional("grades", "questions", "$[]"), want: "grades.$[].questions.$[]"},
+		{name: "filtered positional", got: FilteredPositional("grades", "elem"), want: "grades.$[elem]"},
+		{name: "filtered positional field", got: FilteredPositional("grades", "elem", "score"), want: "grades.$[elem].score"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.got)
+		})
+	}
+}
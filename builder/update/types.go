@@ -19,4 +19,11 @@ const (
 	PositionOp       = "$position"
 	SliceForUpdateOp = "$slice"
 	SortOp           = "$sort"
+	BitOp            = "$bit"
+	BitAndOp         = "and"
+	BitOrOp          = "or"
+	BitXorOp         = "xor"
+
+	PositionalOp    = "$"
+	AllPositionalOp = "$[]"
 )
@@ -27,6 +27,16 @@ func (b *Builder) Build() bson.D {
 	return b.data
 }
 
+// BuildE returns the update together with the errors of Err
+func (b *Builder) BuildE() (bson.D, error) {
+	return b.data, b.Err()
+}
+
+// Err returns the errors of the validation of the update, see Validate
+func (b *Builder) Err() error {
+	return Validate(b.data)
+}
+
 // tryMergeValue attempts to merge the provided bson.E elements into an existing bson.D element
 // in the builder's data slice, identified by the specified key.
 func (b *Builder) tryMergeValue(key string, e ...bson.E) bool {
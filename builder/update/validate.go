@@ -0,0 +1,93 @@
+package update
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+var (
+	ErrEmptyUpdate    = errors.New("mongox: update has no operators")
+	ErrNotOperator    = errors.New("mongox: top level key of update is not an operator")
+	ErrEmptyKey       = errors.New("mongox: empty key in update")
+	ErrPathConflict   = errors.New("mongox: update operators conflict on the same path")
+	ErrInvalidOperand = errors.New("mongox: invalid operand of update operator")
+)
+
+// fieldOps are the update operators whose operand is a document of the paths to update
+var fieldOps = map[string]bool{
+	SetOp: true, UnsetOp: true, SetOnInsertOp: true, CurrentDateOp: true, IncOp: true, MinOp: true, MaxOp: true,
+	MulOp: true, RenameOp: true, AddToSetOp: true, PopOp: true, PullOp: true, PushOp: true, PullAllOp: true,
+	BitOp: true,
+}
+
+// Validate checks the update for the mistakes which would only be reported by the server, e.g. an update without
+// operators, an empty key or two operators on the same path such as $set and $unset, and returns them joined
+func Validate(updates bson.D) error {
+	return errors.Join(validate(updates)...)
+}
+
+func validate(updates bson.D) []error {
+	if len(updates) == 0 {
+		return []error{ErrEmptyUpdate}
+	}
+	var (
+		errs  []error
+		paths []string
+		ops   []string
+	)
+	// register reports the conflict of the path with one already updated, a path conflicts with its parents as well
+	register := func(op, path string) {
+		for i, p := range paths {
+			if p == path || strings.HasPrefix(path, p+".") || strings.HasPrefix(p, path+".") {
+				errs = append(errs, fmt.Errorf("%w: %s on %q and %s on %q", ErrPathConflict, ops[i], p, op, path))
+				return
+			}
+		}
+		paths, ops = append(paths, path), append(ops, op)
+	}
+	for _, e := range updates {
+		if !strings.HasPrefix(e.Key, "$") {
+			errs = append(errs, fmt.Errorf("%w: %q", ErrNotOperator, e.Key))
+			continue
+		}
+		if !fieldOps[e.Key] {
+			continue
+		}
+		fields, ok := e.Value.(bson.D)
+		if !ok {
+			continue
+		}
+		for _, f := range fields {
+			if f.Key == "" {
+				errs = append(errs, ErrEmptyKey)
+				continue
+			}
+			register(e.Key, f.Key)
+			switch e.Key {
+			case RenameOp:
+				if name, ok := f.Value.(string); !ok || name == "" {
+					errs = append(errs, fmt.Errorf("%w: %s of %q must be a non-empty field name", ErrInvalidOperand, e.Key, f.Key))
+				} else {
+					register(e.Key, name)
+				}
+			case PopOp:
+				if v := reflect.ValueOf(f.Value); !v.CanInt() || v.Int() != 1 && v.Int() != -1 {
+					errs = append(errs, fmt.Errorf("%w: %s of %q must be 1 or -1", ErrInvalidOperand, e.Key, f.Key))
+				}
+			case BitOp:
+				ops, ok := f.Value.(bson.D)
+				if !ok || len(ops) == 0 || slices.ContainsFunc(ops, func(op bson.E) bool {
+					return op.Key != BitAndOp && op.Key != BitOrOp && op.Key != BitXorOp
+				}) {
+					errs = append(errs, fmt.Errorf("%w: %s of %q must be a document of and, or and xor", ErrInvalidOperand, e.Key, f.Key))
+				}
+			}
+		}
+	}
+	return errs
+}
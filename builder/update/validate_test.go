@@ -0,0 +1,99 @@
+package update
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		name    string
+		updates bson.D
+		wantErr []error
+	}{
+		{
+			name:    "valid",
+			updates: NewBuilder().Set("name", "Mingyong Chen").Inc("age", 1).Push("tags", "go").Rename("nick", "nickname").Pop("scores", -1).Build(),
+		},
+		{
+			name:    "positional paths",
+			updates: NewBuilder().Set(FilteredPositional("grades", "elem", "score"), 90).Inc(AllPositional("grades", "attempts"), 1).BitOr("flags", 4).Build(),
+		},
+		{
+			name:    "sibling paths",
+			updates: NewBuilder().Set("address.city", "Paris").Unset("address.zip").Build(),
+		},
+		{
+			name:    "no operators",
+			updates: NewBuilder().Build(),
+			wantErr: []error{ErrEmptyUpdate},
+		},
+		{
+			name:    "not an operator",
+			updates: bson.D{{Key: "name", Value: "Mingyong Chen"}},
+			wantErr: []error{ErrNotOperator},
+		},
+		{
+			name:    "empty key",
+			updates: Set("", "Mingyong Chen"),
+			wantErr: []error{ErrEmptyKey},
+		},
+		{
+			name:    "set and unset",
+			updates: NewBuilder().Set("name", "Mingyong Chen").Unset("name").Build(),
+			wantErr: []error{ErrPathConflict},
+		},
+		{
+			name:    "parent path",
+			updates: NewBuilder().Set("address", bson.D{}).Inc("address.zip", 1).Build(),
+			wantErr: []error{ErrPathConflict},
+		},
+		{
+			name:    "rename onto an updated path",
+			updates: NewBuilder().Set("nickname", "burt").Rename("nick", "nickname").Build(),
+			wantErr: []error{ErrPathConflict},
+		},
+		{
+			// the server accepts the empty operands since MongoDB 5.0
+			name:    "empty unset",
+			updates: NewBuilder().Unset().Build(),
+		},
+		{
+			name:    "invalid bit",
+			updates: bson.D{{Key: BitOp, Value: bson.D{{Key: "flags", Value: bson.D{{Key: "not", Value: 1}}}}}},
+			wantErr: []error{ErrInvalidOperand},
+		},
+		{
+			name:    "invalid pop",
+			updates: Pop("scores", 2),
+			wantErr: []error{ErrInvalidOperand},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Validate(tc.updates)
+			if len(tc.wantErr) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Len(t, err.(interface{ Unwrap() []error }).Unwrap(), len(tc.wantErr))
+			for _, want := range tc.wantErr {
+				assert.True(t, errors.Is(err, want), err)
+			}
+		})
+	}
+}
+
+func TestBuilder_BuildE(t *testing.T) {
+	updates, err := NewBuilder().Set("name", "Mingyong Chen").BuildE()
+	assert.NoError(t, err)
+	assert.Equal(t, bson.D{{Key: SetOp, Value: bson.D{{Key: "name", Value: "Mingyong Chen"}}}}, updates)
+
+	b := NewBuilder().Set("name", "Mingyong Chen").Unset("name")
+	_, err = b.BuildE()
+	assert.EqualError(t, err, `mongox: update operators conflict on the same path: $set on "name" and $unset on "name"`)
+	assert.Equal(t, err, b.Err())
+}
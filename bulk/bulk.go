@@ -0,0 +1,294 @@
+package bulk
+
+import (
+	"context"
+	"errors"
+	"reflect"
+
+	"github.com/matiniiuu/mongox/bsonx"
+	"github.com/matiniiuu/mongox/callback"
+	mxerrors "github.com/matiniiuu/mongox/errors"
+	"github.com/matiniiuu/mongox/internal/pkg/version"
+	"github.com/matiniiuu/mongox/operation"
+	"github.com/matiniiuu/mongox/softdelete"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// DefaultBatchSize is the number of queued items sent in one bulk write by default
+const DefaultBatchSize = 1000
+
+func NewBulkWriter[T any](collection *mongo.Collection) *BulkWriter[T] {
+	return &BulkWriter[T]{
+		collection: collection,
+		ordered:    true,
+		batchSize:  DefaultBatchSize,
+		softDelete: softdelete.Enabled(),
+	}
+}
+
+// BulkWriter queues write operations on the documents of T and sends them with bulk writes
+// The global callbacks of the matching operation type run for every queued item
+type BulkWriter[T any] struct {
+	collection *mongo.Collection
+	items      []item
+	ordered    bool
+	batchSize  int
+	softDelete bool
+}
+
+// Ordered is used to set whether the items are written in order, it is true by default
+// An ordered bulk writer stops at the first failed item, the items after it are not written
+func (b *BulkWriter[T]) Ordered(ordered bool) *BulkWriter[T] {
+	b.ordered = ordered
+	return b
+}
+
+// BatchSize is used to set the number of items sent in one bulk write, a size less than 1 resets it to DefaultBatchSize
+func (b *BulkWriter[T]) BatchSize(size int) *BulkWriter[T] {
+	if size < 1 {
+		size = DefaultBatchSize
+	}
+	b.batchSize = size
+	return b
+}
+
+// SoftDelete is used to turn the soft delete mode on or off for this bulk writer
+// If it is on, the queued deletes mark the documents as deleted and the other filters skip the deleted documents
+func (b *BulkWriter[T]) SoftDelete(enabled bool) *BulkWriter[T] {
+	b.softDelete = enabled
+	return b
+}
+
+// InsertOne queues the insert of the document, its field tagged with mongox:"version" is set to 1 if it is not set yet
+func (b *BulkWriter[T]) InsertOne(doc *T) *BulkWriter[T] {
+	b.items = append(b.items, item{kind: kindInsertOne, doc: doc})
+	return b
+}
+
+// UpdateOne queues the update of the first document matched by the filter
+// If T has a field tagged with mongox:"version", Execute returns errors.ErrVersionRequired of mongox/errors, use
+// UpdateOneVersion instead
+func (b *BulkWriter[T]) UpdateOne(filter any, updates any) *BulkWriter[T] {
+	b.items = append(b.items, item{kind: kindUpdateOne, filter: filter, updates: updates})
+	return b
+}
+
+// UpdateOneVersion queues the update of the first document matched by the filter which still has the version it was
+// read with, the version is incremented by the update, see updater.Updater.Version
+func (b *BulkWriter[T]) UpdateOneVersion(filter any, updates any, current int64) *BulkWriter[T] {
+	b.items = append(b.items, item{kind: kindUpdateOne, filter: filter, updates: updates, version: &current})
+	return b
+}
+
+// UpdateMany queues the update of the documents matched by the filter, their version field is incremented
+func (b *BulkWriter[T]) UpdateMany(filter any, updates any) *BulkWriter[T] {
+	b.items = append(b.items, item{kind: kindUpdateMany, filter: filter, updates: updates})
+	return b
+}
+
+// ReplaceOne queues the replace of the first document matched by the filter
+// If the replacement has a field tagged with mongox:"version", only the document which still has its version is
+// replaced and the replacement is written with the next version, which is set on it when Execute runs
+func (b *BulkWriter[T]) ReplaceOne(filter any, replacement *T) *BulkWriter[T] {
+	b.items = append(b.items, item{kind: kindReplaceOne, filter: filter, doc: replacement})
+	return b
+}
+
+func (b *BulkWriter[T]) DeleteOne(filter any) *BulkWriter[T] {
+	b.items = append(b.items, item{kind: kindDeleteOne, filter: filter})
+	return b
+}
+
+func (b *BulkWriter[T]) DeleteMany(filter any) *BulkWriter[T] {
+	b.items = append(b.items, item{kind: kindDeleteMany, filter: filter})
+	return b
+}
+
+// Len returns the number of queued items
+func (b *BulkWriter[T]) Len() int {
+	return len(b.items)
+}
+
+// Execute writes the queued items in batches and returns the merged result of the batches
+// The before callbacks of a batch run before it is sent, the after callbacks only run for the items written successfully
+// If some items fail, the error is an *Error which maps the write errors back to the queued items
+// The documents soft deleted are counted in ModifiedCount of the result
+// The versioned items whose document has changed since it was read match no document, they are not reported as
+// errors since the result does not tell which items matched, compare its MatchedCount with the number of such items
+// errors.ErrVersionRequired of mongox/errors is returned before anything is written if T has a version field and an update was queued
+// with UpdateOne rather than UpdateOneVersion
+// The queue is emptied once the items are being written, whether Execute succeeds or not, so that no item is written
+// twice and the bulk writer can queue the next items
+func (b *BulkWriter[T]) Execute(ctx context.Context, opts ...options.Lister[options.BulkWriteOptions]) (*mongo.BulkWriteResult, error) {
+	if len(b.items) == 0 {
+		return nil, mongo.ErrEmptySlice
+	}
+	if _, versioned := version.Lookup(reflect.TypeFor[T]()); versioned {
+		for _, it := range b.items {
+			if it.kind == kindUpdateOne && it.version == nil {
+				return nil, mxerrors.ErrVersionRequired
+			}
+		}
+	}
+	defer func() {
+		b.items = nil
+	}()
+	// the order set by Ordered takes precedence over the options
+	opts = append(opts, options.BulkWrite().SetOrdered(b.ordered))
+
+	result := &mongo.BulkWriteResult{UpsertedIDs: make(map[int64]any), Acknowledged: true}
+	bulkErr := &Error{}
+	for offset := 0; offset < len(b.items); offset += b.batchSize {
+		batch := b.items[offset:min(offset+b.batchSize, len(b.items))]
+
+		opContexts := make([]*operation.OpContext, 0, len(batch))
+		models := make([]mongo.WriteModel, 0, len(batch))
+		for _, it := range batch {
+			opContext := b.opContext(it)
+			before, _ := opTypes(it.kind)
+			if err := callback.GetCallback().Execute(ctx, opContext, before); err != nil {
+				return result, err
+			}
+			opContexts = append(opContexts, opContext)
+			models = append(models, b.writeModel(it.kind, opContext))
+		}
+
+		res, err := b.collection.BulkWrite(ctx, models, opts...)
+		failed := make(map[int]struct{})
+		if err != nil {
+			var bwe mongo.BulkWriteException
+			if !errors.As(err, &bwe) {
+				return result, mxerrors.Wrap(err)
+			}
+			for _, we := range bwe.WriteErrors {
+				failed[we.Index] = struct{}{}
+				bulkErr.Items = append(bulkErr.Items, ItemError{Index: offset + we.Index, WriteError: we.WriteError})
+			}
+			if bwe.WriteConcernError != nil {
+				bulkErr.WriteConcernError = bwe.WriteConcernError
+			}
+		}
+		merge(result, res, offset)
+
+		for i, it := range batch {
+			if _, ok := failed[i]; ok {
+				if b.ordered {
+					break
+				}
+				continue
+			}
+			_, after := opTypes(it.kind)
+			if err = callback.GetCallback().Execute(ctx, opContexts[i], after); err != nil {
+				return result, err
+			}
+		}
+		if b.ordered && len(failed) > 0 {
+			break
+		}
+	}
+	if len(bulkErr.Items) > 0 || bulkErr.WriteConcernError != nil {
+		return result, bulkErr
+	}
+	return result, nil
+}
+
+// opTypes returns the types of the callbacks run before and after the item is written
+func opTypes(k kind) (operation.OpType, operation.OpType) {
+	switch k {
+	case kindInsertOne:
+		return operation.OpTypeBeforeInsert, operation.OpTypeAfterInsert
+	case kindUpdateOne, kindUpdateMany:
+		return operation.OpTypeBeforeUpdate, operation.OpTypeAfterUpdate
+	case kindReplaceOne:
+		return operation.OpTypeBeforeReplace, operation.OpTypeAfterReplace
+	default:
+		return operation.OpTypeBeforeDelete, operation.OpTypeAfterDelete
+	}
+}
+
+// opContext returns the global operation context of the item
+// The version field of T is handled as by creator.Creator and updater.Updater: it is initialized by the inserts,
+// incremented by the updates and replaces, and the filters of UpdateOneVersion and ReplaceOne check it
+func (b *BulkWriter[T]) opContext(it item) *operation.OpContext {
+	versionField, versioned := version.Lookup(reflect.TypeFor[T]())
+	switch it.kind {
+	case kindInsertOne:
+		version.Init(it.doc)
+		return operation.NewOpContext(b.collection, operation.WithDoc(it.doc))
+	case kindUpdateOne, kindUpdateMany:
+		updates, filter := it.updates, b.scopedFilter(it.filter)
+		if m := bsonx.ToBsonM(updates); len(m) != 0 {
+			updates = m
+		}
+		if versioned {
+			updates = version.Inc(updates, versionField.Path)
+			if it.version != nil {
+				filter = version.Filter(filter, versionField.Path, *it.version)
+			}
+		}
+		return operation.NewOpContext(b.collection, operation.WithDoc(new(T)), operation.WithFilter(filter), operation.WithUpdates(updates))
+	case kindReplaceOne:
+		filter := b.scopedFilter(it.filter)
+		if current, ok := version.Get(it.doc); ok {
+			filter = version.Filter(filter, versionField.Path, current)
+			version.Set(it.doc, current+1)
+		}
+		return operation.NewOpContext(b.collection, operation.WithDoc(it.doc), operation.WithFilter(filter), operation.WithReplacement(it.doc))
+	default:
+		opContext := operation.NewOpContext(b.collection, operation.WithFilter(b.scopedFilter(it.filter)))
+		if b.softDelete {
+			opContext.Updates = softdelete.DeleteUpdates()
+		}
+		return opContext
+	}
+}
+
+// writeModel builds the write model from the operation context after the before callbacks have run
+func (b *BulkWriter[T]) writeModel(k kind, opContext *operation.OpContext) mongo.WriteModel {
+	switch k {
+	case kindInsertOne:
+		return mongo.NewInsertOneModel().SetDocument(opContext.Doc)
+	case kindUpdateOne:
+		return mongo.NewUpdateOneModel().SetFilter(opContext.Filter).SetUpdate(opContext.Updates)
+	case kindUpdateMany:
+		return mongo.NewUpdateManyModel().SetFilter(opContext.Filter).SetUpdate(opContext.Updates)
+	case kindReplaceOne:
+		return mongo.NewReplaceOneModel().SetFilter(opContext.Filter).SetReplacement(opContext.Replacement)
+	case kindDeleteOne:
+		if b.softDelete {
+			return mongo.NewUpdateOneModel().SetFilter(opContext.Filter).SetUpdate(opContext.Updates)
+		}
+		return mongo.NewDeleteOneModel().SetFilter(opContext.Filter)
+	default:
+		if b.softDelete {
+			return mongo.NewUpdateManyModel().SetFilter(opContext.Filter).SetUpdate(opContext.Updates)
+		}
+		return mongo.NewDeleteManyModel().SetFilter(opContext.Filter)
+	}
+}
+
+// scopedFilter returns the filter restricted to the documents which have not been soft deleted
+func (b *BulkWriter[T]) scopedFilter(filter any) any {
+	if !b.softDelete {
+		return filter
+	}
+	return softdelete.Filter(filter, softdelete.ScopeDefault)
+}
+
+// merge adds the result of the batch starting at offset to the result
+func merge(result *mongo.BulkWriteResult, res *mongo.BulkWriteResult, offset int) {
+	if res == nil {
+		return
+	}
+	result.InsertedCount += res.InsertedCount
+	result.MatchedCount += res.MatchedCount
+	result.ModifiedCount += res.ModifiedCount
+	result.DeletedCount += res.DeletedCount
+	result.UpsertedCount += res.UpsertedCount
+	for index, id := range res.UpsertedIDs {
+		result.UpsertedIDs[int64(offset)+index] = id
+	}
+	result.Acknowledged = result.Acknowledged && res.Acknowledged
+}
@@ -0,0 +1,145 @@
+//go:build e2e
+
+package bulk
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/matiniiuu/mongox/builder/query"
+	"github.com/matiniiuu/mongox/builder/update"
+	"github.com/matiniiuu/mongox/callback"
+	mxerrors "github.com/matiniiuu/mongox/errors"
+	"github.com/matiniiuu/mongox/operation"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"go.mongodb.org/mongo-driver/v2/mongo/readpref"
+)
+
+func getCollection(t *testing.T) *mongo.Collection {
+	client, err := mongo.Connect(options.Client().ApplyURI("mongodb://localhost:27017").SetAuth(options.Credential{
+		Username:   "test",
+		Password:   "test",
+		AuthSource: "db-test",
+	}))
+	require.NoError(t, err)
+	require.NoError(t, client.Ping(context.Background(), readpref.Primary()))
+	return client.Database("db-test").Collection("test_user")
+}
+
+func TestBulkWriter_e2e_Execute(t *testing.T) {
+	collection := getCollection(t)
+	ctx := context.Background()
+
+	counts := make(map[operation.OpType]int)
+	opTypes := []operation.OpType{
+		operation.OpTypeBeforeInsert, operation.OpTypeAfterInsert,
+		operation.OpTypeBeforeUpdate, operation.OpTypeAfterUpdate,
+		operation.OpTypeBeforeReplace, operation.OpTypeAfterReplace,
+		operation.OpTypeBeforeDelete, operation.OpTypeAfterDelete,
+	}
+	for _, opType := range opTypes {
+		typ := opType
+		callback.GetCallback().Register(typ, "count", func(_ context.Context, _ *operation.OpContext, _ ...any) error {
+			counts[typ]++
+			return nil
+		})
+	}
+	defer func() {
+		for _, opType := range opTypes {
+			callback.GetCallback().Remove(opType, "count")
+		}
+	}()
+
+	users := []*TestUser{
+		{ID: bson.NewObjectID(), Name: "Mingyong Chen", Age: 18},
+		{ID: bson.NewObjectID(), Name: "burt", Age: 19},
+		{ID: bson.NewObjectID(), Name: "gopher", Age: 20},
+	}
+	defer func() {
+		_, err := collection.DeleteMany(ctx, query.In("_id", users[0].ID, users[1].ID, users[2].ID))
+		require.NoError(t, err)
+	}()
+
+	result, err := NewBulkWriter[TestUser](collection).BatchSize(2).
+		InsertOne(users[0]).
+		InsertOne(users[1]).
+		InsertOne(users[2]).
+		UpdateOne(query.Id(users[0].ID), update.Set("age", 28)).
+		UpdateMany(query.In("_id", users[1].ID, users[2].ID), update.Inc("age", 1)).
+		ReplaceOne(query.Id(users[1].ID), &TestUser{Name: "burt", Age: 30}).
+		DeleteOne(query.Id(users[2].ID)).
+		Execute(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), result.InsertedCount)
+	require.Equal(t, int64(4), result.ModifiedCount)
+	require.Equal(t, int64(1), result.DeletedCount)
+
+	require.Equal(t, 3, counts[operation.OpTypeBeforeInsert])
+	require.Equal(t, 3, counts[operation.OpTypeAfterInsert])
+	require.Equal(t, 2, counts[operation.OpTypeAfterUpdate])
+	require.Equal(t, 1, counts[operation.OpTypeAfterReplace])
+	require.Equal(t, 1, counts[operation.OpTypeAfterDelete])
+
+	got := &TestUser{}
+	require.NoError(t, collection.FindOne(ctx, query.Id(users[0].ID)).Decode(got))
+	require.Equal(t, int64(28), got.Age)
+	require.NoError(t, collection.FindOne(ctx, query.Id(users[1].ID)).Decode(got))
+	require.Equal(t, int64(30), got.Age)
+	require.ErrorIs(t, collection.FindOne(ctx, query.Id(users[2].ID)).Err(), mongo.ErrNoDocuments)
+}
+
+func TestBulkWriter_e2e_Errors(t *testing.T) {
+	collection := getCollection(t)
+	ctx := context.Background()
+
+	id := bson.NewObjectID()
+	ids := []bson.ObjectID{id, bson.NewObjectID(), bson.NewObjectID()}
+	defer func() {
+		_, err := collection.DeleteMany(ctx, query.In("_id", ids[0], ids[1], ids[2]))
+		require.NoError(t, err)
+	}()
+
+	t.Run("ordered stops at the first failure", func(t *testing.T) {
+		var afterInsert int
+		callback.GetCallback().Register(operation.OpTypeAfterInsert, "count", func(_ context.Context, _ *operation.OpContext, _ ...any) error {
+			afterInsert++
+			return nil
+		})
+		defer callback.GetCallback().Remove(operation.OpTypeAfterInsert, "count")
+
+		result, err := NewBulkWriter[TestUser](collection).BatchSize(2).
+			InsertOne(&TestUser{ID: ids[0], Name: "Mingyong Chen"}).
+			InsertOne(&TestUser{ID: ids[1], Name: "burt"}).
+			InsertOne(&TestUser{ID: ids[0], Name: "duplicate"}).
+			InsertOne(&TestUser{ID: ids[2], Name: "gopher"}).
+			Execute(ctx)
+		var bulkErr *Error
+		require.True(t, errors.As(err, &bulkErr))
+		require.Len(t, bulkErr.Items, 1)
+		require.Equal(t, 2, bulkErr.Items[0].Index)
+		require.True(t, mongo.IsDuplicateKeyError(bulkErr.Items[0].WriteError))
+		require.ErrorIs(t, err, mxerrors.ErrDuplicateKey)
+		require.Equal(t, int64(2), result.InsertedCount)
+		require.Equal(t, 2, afterInsert)
+		require.ErrorIs(t, collection.FindOne(ctx, query.Id(ids[2])).Err(), mongo.ErrNoDocuments)
+	})
+
+	t.Run("unordered writes the other items", func(t *testing.T) {
+		result, err := NewBulkWriter[TestUser](collection).Ordered(false).BatchSize(2).
+			InsertOne(&TestUser{ID: ids[0], Name: "duplicate"}).
+			InsertOne(&TestUser{ID: ids[2], Name: "gopher"}).
+			InsertOne(&TestUser{ID: ids[1], Name: "duplicate"}).
+			Execute(ctx)
+		var bulkErr *Error
+		require.True(t, errors.As(err, &bulkErr))
+		require.Len(t, bulkErr.Items, 2)
+		require.Equal(t, 0, bulkErr.Items[0].Index)
+		require.Equal(t, 2, bulkErr.Items[1].Index)
+		require.Equal(t, int64(1), result.InsertedCount)
+	})
+}
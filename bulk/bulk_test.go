@@ -0,0 +1,285 @@
+package bulk
+
+import (
+	"context"
+	"testing"
+
+	mxerrors "github.com/matiniiuu/mongox/errors"
+	"github.com/matiniiuu/mongox/internal/pkg/utils"
+	"github.com/matiniiuu/mongox/operation"
+	"github.com/matiniiuu/mongox/softdelete"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"go.mongodb.org/mongo-driver/v2/x/mongo/driver/drivertest"
+)
+
+type TestUser struct {
+	ID   bson.ObjectID `bson:"_id,omitempty"`
+	Name string        `bson:"name"`
+	Age  int64         `bson:"age"`
+}
+
+func TestNewBulkWriter(t *testing.T) {
+	b := NewBulkWriter[TestUser](&mongo.Collection{})
+	assert.True(t, b.ordered)
+	assert.Equal(t, DefaultBatchSize, b.batchSize)
+	assert.False(t, b.softDelete)
+
+	b.Ordered(false).BatchSize(10).SoftDelete(true)
+	assert.False(t, b.ordered)
+	assert.Equal(t, 10, b.batchSize)
+	assert.True(t, b.softDelete)
+
+	b.BatchSize(0)
+	assert.Equal(t, DefaultBatchSize, b.batchSize)
+}
+
+func TestBulkWriter_queue(t *testing.T) {
+	user := &TestUser{Name: "Mingyong Chen"}
+	b := NewBulkWriter[TestUser](&mongo.Collection{}).
+		InsertOne(user).
+		UpdateOne(bson.M{"name": "Mingyong Chen"}, bson.M{"$set": bson.M{"age": 18}}).
+		UpdateMany(bson.M{}, bson.M{"$inc": bson.M{"age": 1}}).
+		ReplaceOne(bson.M{"name": "burt"}, user).
+		DeleteOne(bson.M{"name": "burt"}).
+		DeleteMany(bson.M{})
+	require.Equal(t, 6, b.Len())
+	assert.Equal(t, []item{
+		{kind: kindInsertOne, doc: user},
+		{kind: kindUpdateOne, filter: bson.M{"name": "Mingyong Chen"}, updates: bson.M{"$set": bson.M{"age": 18}}},
+		{kind: kindUpdateMany, filter: bson.M{}, updates: bson.M{"$inc": bson.M{"age": 1}}},
+		{kind: kindReplaceOne, filter: bson.M{"name": "burt"}, doc: user},
+		{kind: kindDeleteOne, filter: bson.M{"name": "burt"}},
+		{kind: kindDeleteMany, filter: bson.M{}},
+	}, b.items)
+}
+
+func TestBulkWriter_Execute_empty(t *testing.T) {
+	_, err := NewBulkWriter[TestUser](&mongo.Collection{}).Execute(context.Background())
+	assert.Equal(t, mongo.ErrEmptySlice, err)
+}
+
+// newMockCollection returns a collection whose commands are answered with the responses in order
+func newMockCollection(t *testing.T, responses ...bson.D) *mongo.Collection {
+	clientOpts := options.Client()
+	clientOpts.Deployment = drivertest.NewMockDeployment(responses...)
+	client, err := mongo.Connect(clientOpts)
+	require.NoError(t, err)
+	return client.Database("db-test").Collection("test_user")
+}
+
+func TestBulkWriter_Execute_reuse(t *testing.T) {
+	collection := newMockCollection(t,
+		bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}},
+		bson.D{{Key: "ok", Value: 0}, {Key: "code", Value: 2}, {Key: "errmsg", Value: "bad value"}},
+		bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}},
+	)
+	b := NewBulkWriter[TestUser](collection)
+
+	result, err := b.InsertOne(&TestUser{Name: "Mingyong Chen"}).Execute(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), result.InsertedCount)
+	assert.Zero(t, b.Len())
+	_, err = b.Execute(context.Background())
+	assert.Equal(t, mongo.ErrEmptySlice, err)
+
+	_, err = b.InsertOne(&TestUser{Name: "burt"}).Execute(context.Background())
+	require.Error(t, err)
+	assert.Zero(t, b.Len())
+
+	result, err = b.InsertOne(&TestUser{Name: "burt"}).Execute(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), result.InsertedCount)
+}
+
+func Test_opTypes(t *testing.T) {
+	testCases := []struct {
+		kind   kind
+		before operation.OpType
+		after  operation.OpType
+	}{
+		{kind: kindInsertOne, before: operation.OpTypeBeforeInsert, after: operation.OpTypeAfterInsert},
+		{kind: kindUpdateOne, before: operation.OpTypeBeforeUpdate, after: operation.OpTypeAfterUpdate},
+		{kind: kindUpdateMany, before: operation.OpTypeBeforeUpdate, after: operation.OpTypeAfterUpdate},
+		{kind: kindReplaceOne, before: operation.OpTypeBeforeReplace, after: operation.OpTypeAfterReplace},
+		{kind: kindDeleteOne, before: operation.OpTypeBeforeDelete, after: operation.OpTypeAfterDelete},
+		{kind: kindDeleteMany, before: operation.OpTypeBeforeDelete, after: operation.OpTypeAfterDelete},
+	}
+	for _, tc := range testCases {
+		before, after := opTypes(tc.kind)
+		assert.Equal(t, tc.before, before)
+		assert.Equal(t, tc.after, after)
+	}
+}
+
+func TestBulkWriter_writeModel(t *testing.T) {
+	user := &TestUser{Name: "Mingyong Chen"}
+	filter := bson.D{{Key: "name", Value: "Mingyong Chen"}}
+	testCases := []struct {
+		name       string
+		softDelete bool
+		item       item
+		want       mongo.WriteModel
+	}{
+		{
+			name: "insert one",
+			item: item{kind: kindInsertOne, doc: user},
+			want: mongo.NewInsertOneModel().SetDocument(user),
+		},
+		{
+			name: "update one",
+			item: item{kind: kindUpdateOne, filter: filter, updates: bson.D{{Key: "$set", Value: bson.D{{Key: "age", Value: 18}}}}},
+			want: mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(bson.M{"$set": bson.M{"age": int32(18)}}),
+		},
+		{
+			name: "update many",
+			item: item{kind: kindUpdateMany, filter: filter, updates: bson.M{"$set": bson.M{"age": 18}}},
+			want: mongo.NewUpdateManyModel().SetFilter(filter).SetUpdate(bson.M{"$set": bson.M{"age": 18}}),
+		},
+		{
+			name: "replace one",
+			item: item{kind: kindReplaceOne, filter: filter, doc: user},
+			want: mongo.NewReplaceOneModel().SetFilter(filter).SetReplacement(user),
+		},
+		{
+			name: "delete one",
+			item: item{kind: kindDeleteOne, filter: filter},
+			want: mongo.NewDeleteOneModel().SetFilter(filter),
+		},
+		{
+			name: "delete many",
+			item: item{kind: kindDeleteMany, filter: filter},
+			want: mongo.NewDeleteManyModel().SetFilter(filter),
+		},
+		{
+			name:       "soft delete one",
+			softDelete: true,
+			item:       item{kind: kindDeleteOne, filter: filter},
+			want:       mongo.NewUpdateOneModel().SetFilter(softdelete.Filter(filter, softdelete.ScopeDefault)),
+		},
+		{
+			name:       "soft delete many",
+			softDelete: true,
+			item:       item{kind: kindDeleteMany, filter: filter},
+			want:       mongo.NewUpdateManyModel().SetFilter(softdelete.Filter(filter, softdelete.ScopeDefault)),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			b := NewBulkWriter[TestUser](&mongo.Collection{}).SoftDelete(tc.softDelete)
+			got := b.writeModel(tc.item.kind, b.opContext(tc.item))
+			switch m := got.(type) {
+			case *mongo.UpdateOneModel:
+				if tc.softDelete {
+					// the deletion time can not be compared
+					assert.NotNil(t, m.Update)
+					m.Update = nil
+				}
+			case *mongo.UpdateManyModel:
+				if tc.softDelete {
+					assert.NotNil(t, m.Update)
+					m.Update = nil
+				}
+			}
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+type versionedUser struct {
+	ID      string `bson:"_id"`
+	Name    string `bson:"name"`
+	Version int64  `bson:"version" mongox:"version"`
+}
+
+func TestBulkWriter_version(t *testing.T) {
+	filter := bson.D{{Key: "_id", Value: "1"}}
+	versioned := func(current int64) any {
+		return bson.D{{Key: "$and", Value: bson.A{filter, bson.D{{Key: "version", Value: current}}}}}
+	}
+
+	t.Run("insert one", func(t *testing.T) {
+		user := &versionedUser{ID: "1"}
+		b := NewBulkWriter[versionedUser](&mongo.Collection{})
+		assert.Equal(t, mongo.NewInsertOneModel().SetDocument(user), b.writeModel(kindInsertOne, b.opContext(item{kind: kindInsertOne, doc: user})))
+		assert.Equal(t, int64(1), user.Version)
+	})
+
+	t.Run("update one", func(t *testing.T) {
+		b := NewBulkWriter[versionedUser](&mongo.Collection{})
+		it := item{kind: kindUpdateOne, filter: filter, updates: bson.M{"$set": bson.M{"name": "burt"}}, version: utils.ToPtr(int64(2))}
+		assert.Equal(t, mongo.NewUpdateOneModel().SetFilter(versioned(2)).SetUpdate(bson.M{
+			"$set": bson.M{"name": "burt"},
+			"$inc": bson.M{"version": 1},
+		}), b.writeModel(kindUpdateOne, b.opContext(it)))
+	})
+
+	t.Run("update many", func(t *testing.T) {
+		b := NewBulkWriter[versionedUser](&mongo.Collection{})
+		it := item{kind: kindUpdateMany, filter: filter, updates: bson.M{"$set": bson.M{"name": "burt"}}}
+		assert.Equal(t, mongo.NewUpdateManyModel().SetFilter(filter).SetUpdate(bson.M{
+			"$set": bson.M{"name": "burt"},
+			"$inc": bson.M{"version": 1},
+		}), b.writeModel(kindUpdateMany, b.opContext(it)))
+	})
+
+	t.Run("replace one", func(t *testing.T) {
+		user := &versionedUser{ID: "1", Name: "burt", Version: 2}
+		b := NewBulkWriter[versionedUser](&mongo.Collection{})
+		got := b.writeModel(kindReplaceOne, b.opContext(item{kind: kindReplaceOne, filter: filter, doc: user}))
+		assert.Equal(t, mongo.NewReplaceOneModel().SetFilter(versioned(2)).SetReplacement(user), got)
+		assert.Equal(t, int64(3), user.Version)
+	})
+
+	t.Run("update one without the version", func(t *testing.T) {
+		b := NewBulkWriter[versionedUser](&mongo.Collection{}).
+			UpdateOneVersion(filter, bson.M{"$set": bson.M{"name": "burt"}}, 2).
+			UpdateOne(filter, bson.M{"$set": bson.M{"name": "burt"}})
+		_, err := b.Execute(context.Background())
+		assert.ErrorIs(t, err, mxerrors.ErrVersionRequired)
+	})
+}
+
+func Test_merge(t *testing.T) {
+	result := &mongo.BulkWriteResult{UpsertedIDs: make(map[int64]any), Acknowledged: true}
+	merge(result, nil, 0)
+	merge(result, &mongo.BulkWriteResult{InsertedCount: 1, MatchedCount: 2, ModifiedCount: 2, UpsertedCount: 1, UpsertedIDs: map[int64]any{1: "a"}, Acknowledged: true}, 0)
+	merge(result, &mongo.BulkWriteResult{DeletedCount: 3, UpsertedCount: 1, UpsertedIDs: map[int64]any{0: "b"}, Acknowledged: true}, 10)
+	assert.Equal(t, &mongo.BulkWriteResult{
+		InsertedCount: 1,
+		MatchedCount:  2,
+		ModifiedCount: 2,
+		DeletedCount:  3,
+		UpsertedCount: 2,
+		UpsertedIDs:   map[int64]any{1: "a", 10: "b"},
+		Acknowledged:  true,
+	}, result)
+}
+
+func TestError_Error(t *testing.T) {
+	err := &Error{
+		Items: []ItemError{
+			{Index: 3, WriteError: mongo.WriteError{Code: 11000, Message: "duplicate key"}},
+		},
+		WriteConcernError: &mongo.WriteConcernError{Code: 64, Message: "waiting for replication timed out"},
+	}
+	assert.Equal(t, "bulk write error: [write concern error: waiting for replication timed out, item 3: duplicate key]", err.Error())
+}
+
+func TestError_Unwrap(t *testing.T) {
+	err := &Error{
+		Items: []ItemError{
+			{Index: 0, WriteError: mongo.WriteError{Code: 121, Message: "document failed validation"}},
+			{Index: 3, WriteError: mongo.WriteError{Code: 11000, Message: "duplicate key"}},
+		},
+	}
+	assert.ErrorIs(t, err, mxerrors.ErrDuplicateKey)
+	var dupErr *mxerrors.DuplicateKeyError
+	require.ErrorAs(t, err, &dupErr)
+	assert.Equal(t, "mongox: duplicate key: duplicate key", dupErr.Error())
+	assert.NotErrorIs(t, &Error{Items: err.Items[:1]}, mxerrors.ErrDuplicateKey)
+}
@@ -0,0 +1,68 @@
+package bulk
+
+import (
+	"fmt"
+	"strings"
+
+	mxerrors "github.com/matiniiuu/mongox/errors"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+type kind int
+
+const (
+	kindInsertOne kind = iota
+	kindUpdateOne
+	kindUpdateMany
+	kindReplaceOne
+	kindDeleteOne
+	kindDeleteMany
+)
+
+// item is a queued write operation
+type item struct {
+	kind    kind
+	doc     any
+	filter  any
+	updates any
+	// version is the version read before the update of UpdateOneVersion
+	version *int64
+}
+
+// ItemError is the write error of a queued item
+// Index is the position of the item in the order it was queued
+type ItemError struct {
+	Index int
+	mongo.WriteError
+}
+
+// Error is returned by Execute when some of the queued items fail to be written
+type Error struct {
+	Items             []ItemError
+	WriteConcernError *mongo.WriteConcernError
+}
+
+func (e *Error) Error() string {
+	causes := make([]string, 0, len(e.Items)+1)
+	if e.WriteConcernError != nil {
+		causes = append(causes, "write concern error: "+e.WriteConcernError.Error())
+	}
+	for _, item := range e.Items {
+		causes = append(causes, fmt.Sprintf("item %d: %s", item.Index, item.WriteError.Error()))
+	}
+	return "bulk write error: [" + strings.Join(causes, ", ") + "]"
+}
+
+// Unwrap returns the classified write errors of the items and the write concern error,
+// e.g. errors.Is(err, errors.ErrDuplicateKey) reports whether an item violates a unique index
+func (e *Error) Unwrap() []error {
+	errs := make([]error, 0, len(e.Items)+1)
+	if e.WriteConcernError != nil {
+		errs = append(errs, e.WriteConcernError)
+	}
+	for _, item := range e.Items {
+		errs = append(errs, mxerrors.Wrap(item.WriteError))
+	}
+	return errs
+}
@@ -10,8 +10,11 @@ import (
 	"github.com/matiniiuu/mongox/hook/model"
 
 	"github.com/matiniiuu/mongox/callback"
+	"github.com/matiniiuu/mongox/hook/audit"
 	"github.com/matiniiuu/mongox/hook/field"
+	"github.com/matiniiuu/mongox/hook/tenant"
 	"github.com/matiniiuu/mongox/operation"
+	"github.com/matiniiuu/mongox/softdelete"
 )
 
 func RegisterPlugin(name string, cb callback.CbFn, opType operation.OpType) {
@@ -28,11 +31,54 @@ type PluginConfig struct {
 	EnableValidationHook   bool
 	// use to replace to the default validate instance
 	Validate *validator2.Validate
+	// UpdateValidation turns on the validation of the update documents of the updates and upserts by the validation hook
+	UpdateValidation *validator.UpdateValidation
+	// EnableSoftDelete makes deletes set the deletedAt field and hides deleted documents from other operations
+	EnableSoftDelete bool
+	// SoftDeleteField replaces the default deletedAt field name of the soft delete mode
+	SoftDeleteField string
+	// EnableTenant scopes every operation to the tenant extracted from the context, see the tenant package
+	EnableTenant bool
+	// TenantField replaces the default tenantId field name of the tenant plugin
+	TenantField string
+	// TenantExtractor replaces the default tenant.FromContext extractor of the tenant plugin
+	TenantExtractor tenant.Extractor
+	// EnableAudit writes an audit record of every insert, update, upsert, replace and delete to AuditSink, see the audit package
+	EnableAudit bool
+	// AuditSink is required by EnableAudit
+	AuditSink audit.Sink
+	// AuditErrorHandler receives the errors of the records the sink writes once their transaction commits
+	AuditErrorHandler audit.ErrorHandler
+	// AuditActorExtractor replaces the default audit.FromContext extractor of the actor
+	AuditActorExtractor audit.Extractor
+	// AuditSnapshot adds the documents before and after the write to the audit records
+	AuditSnapshot bool
 }
 
+// InitPlugin registers the plugins enabled by the config
+// It panics if EnableAudit is set without an AuditSink, since no write would be recorded
 func InitPlugin(config *PluginConfig) {
+	if config.EnableAudit && config.AuditSink == nil {
+		panic("mongox: EnableAudit requires an AuditSink")
+	}
+	// the tenant plugin is registered first so that the other plugins see the filters scoped to the tenant
+	if config.EnableTenant {
+		tenant.SetField(config.TenantField)
+		tenant.SetExtractor(config.TenantExtractor)
+		opTypes := []operation.OpType{
+			operation.OpTypeBeforeInsert, operation.OpTypeBeforeUpdate, operation.OpTypeBeforeUpsert,
+			operation.OpTypeBeforeReplace, operation.OpTypeBeforeDelete, operation.OpTypeBeforeFind,
+			operation.OpTypeBeforeCount, operation.OpTypeBeforeDistinct, operation.OpTypeBeforeAggregate,
+		}
+		for _, opType := range opTypes {
+			typ := opType
+			RegisterPlugin("mongox:tenant", func(ctx context.Context, opCtx *operation.OpContext, opts ...any) error {
+				return tenant.Execute(ctx, opCtx, typ, opts...)
+			}, typ)
+		}
+	}
 	if config.EnableDefaultFieldHook {
-		opTypes := []operation.OpType{operation.OpTypeBeforeInsert, operation.OpTypeBeforeUpdate, operation.OpTypeBeforeUpsert}
+		opTypes := []operation.OpType{operation.OpTypeBeforeInsert, operation.OpTypeBeforeUpdate, operation.OpTypeBeforeUpsert, operation.OpTypeBeforeReplace}
 		for _, opType := range opTypes {
 			typ := opType
 			RegisterPlugin("mongox:default_field", func(ctx context.Context, opCtx *operation.OpContext, opts ...any) error {
@@ -47,6 +93,7 @@ func InitPlugin(config *PluginConfig) {
 			operation.OpTypeBeforeUpdate, operation.OpTypeAfterUpdate,
 			operation.OpTypeBeforeUpsert, operation.OpTypeAfterUpsert,
 			operation.OpTypeBeforeFind, operation.OpTypeAfterFind,
+			operation.OpTypeBeforeReplace, operation.OpTypeAfterReplace,
 		}
 		for _, opType := range opTypes {
 			typ := opType
@@ -57,7 +104,11 @@ func InitPlugin(config *PluginConfig) {
 	}
 	if config.EnableValidationHook {
 		validator.SetValidate(config.Validate)
-		opTypes := []operation.OpType{operation.OpTypeBeforeInsert, operation.OpTypeBeforeUpsert}
+		validator.SetUpdateValidation(config.UpdateValidation)
+		opTypes := []operation.OpType{operation.OpTypeBeforeInsert, operation.OpTypeBeforeUpsert, operation.OpTypeBeforeReplace}
+		if config.UpdateValidation != nil {
+			opTypes = append(opTypes, operation.OpTypeBeforeUpdate)
+		}
 		for _, opType := range opTypes {
 			typ := opType
 			RegisterPlugin("mongox:validation", func(ctx context.Context, opCtx *operation.OpContext, opts ...any) error {
@@ -65,4 +116,28 @@ func InitPlugin(config *PluginConfig) {
 			}, typ)
 		}
 	}
+	if config.EnableSoftDelete {
+		softdelete.SetField(config.SoftDeleteField)
+		softdelete.SetEnabled(true)
+	}
+	// the audit plugin is registered last so that the records and snapshots see the operations as they are sent
+	if config.EnableAudit {
+		audit.SetSink(config.AuditSink)
+		audit.SetExtractor(config.AuditActorExtractor)
+		audit.SetSnapshot(config.AuditSnapshot)
+		audit.SetErrorHandler(config.AuditErrorHandler)
+		opTypes := []operation.OpType{
+			operation.OpTypeAfterInsert, operation.OpTypeAfterUpdate, operation.OpTypeAfterUpsert,
+			operation.OpTypeAfterReplace, operation.OpTypeAfterDelete,
+		}
+		if config.AuditSnapshot {
+			opTypes = append(opTypes, operation.OpTypeBeforeUpdate, operation.OpTypeBeforeUpsert, operation.OpTypeBeforeReplace, operation.OpTypeBeforeDelete)
+		}
+		for _, opType := range opTypes {
+			typ := opType
+			RegisterPlugin("mongox:audit", func(ctx context.Context, opCtx *operation.OpContext, opts ...any) error {
+				return audit.Execute(ctx, opCtx, typ, opts...)
+			}, typ)
+		}
+	}
 }
@@ -3,6 +3,7 @@ package callback
 import (
 	"context"
 
+	mxerrors "github.com/matiniiuu/mongox/errors"
 	"github.com/matiniiuu/mongox/operation"
 )
 
@@ -12,16 +13,24 @@ var Callbacks = initializeCallbacks()
 
 func initializeCallbacks() *Callback {
 	return &Callback{
-		beforeInsert: make([]callbackHandler, 0),
-		afterInsert:  make([]callbackHandler, 0),
-		beforeUpdate: make([]callbackHandler, 0),
-		afterUpdate:  make([]callbackHandler, 0),
-		beforeDelete: make([]callbackHandler, 0),
-		afterDelete:  make([]callbackHandler, 0),
-		beforeUpsert: make([]callbackHandler, 0),
-		afterUpsert:  make([]callbackHandler, 0),
-		beforeFind:   make([]callbackHandler, 0),
-		afterFind:    make([]callbackHandler, 0),
+		beforeInsert:    make([]callbackHandler, 0),
+		afterInsert:     make([]callbackHandler, 0),
+		beforeUpdate:    make([]callbackHandler, 0),
+		afterUpdate:     make([]callbackHandler, 0),
+		beforeDelete:    make([]callbackHandler, 0),
+		afterDelete:     make([]callbackHandler, 0),
+		beforeUpsert:    make([]callbackHandler, 0),
+		afterUpsert:     make([]callbackHandler, 0),
+		beforeFind:      make([]callbackHandler, 0),
+		afterFind:       make([]callbackHandler, 0),
+		beforeReplace:   make([]callbackHandler, 0),
+		afterReplace:    make([]callbackHandler, 0),
+		beforeAggregate: make([]callbackHandler, 0),
+		afterAggregate:  make([]callbackHandler, 0),
+		beforeCount:     make([]callbackHandler, 0),
+		afterCount:      make([]callbackHandler, 0),
+		beforeDistinct:  make([]callbackHandler, 0),
+		afterDistinct:   make([]callbackHandler, 0),
 	}
 }
 
@@ -30,48 +39,75 @@ func GetCallback() *Callback {
 }
 
 type Callback struct {
-	beforeInsert []callbackHandler
-	afterInsert  []callbackHandler
-	beforeUpdate []callbackHandler
-	afterUpdate  []callbackHandler
-	beforeDelete []callbackHandler
-	afterDelete  []callbackHandler
-	beforeUpsert []callbackHandler
-	afterUpsert  []callbackHandler
-	beforeFind   []callbackHandler
-	afterFind    []callbackHandler
+	beforeInsert    []callbackHandler
+	afterInsert     []callbackHandler
+	beforeUpdate    []callbackHandler
+	afterUpdate     []callbackHandler
+	beforeDelete    []callbackHandler
+	afterDelete     []callbackHandler
+	beforeUpsert    []callbackHandler
+	afterUpsert     []callbackHandler
+	beforeFind      []callbackHandler
+	afterFind       []callbackHandler
+	beforeReplace   []callbackHandler
+	afterReplace    []callbackHandler
+	beforeAggregate []callbackHandler
+	afterAggregate  []callbackHandler
+	beforeCount     []callbackHandler
+	afterCount      []callbackHandler
+	beforeDistinct  []callbackHandler
+	afterDistinct   []callbackHandler
 }
 
 func (c *Callback) Execute(ctx context.Context, opCtx *operation.OpContext, opType operation.OpType, opts ...any) error {
+	if opCtx != nil {
+		opCtx.Transactional = operation.InTransaction(ctx)
+	}
 	switch opType {
 	case operation.OpTypeBeforeInsert:
-		return c.execute(ctx, opCtx, c.beforeInsert, opts...)
+		return c.execute(ctx, opCtx, opType, c.beforeInsert, opts...)
 	case operation.OpTypeAfterInsert:
-		return c.execute(ctx, opCtx, c.afterInsert, opts...)
+		return c.execute(ctx, opCtx, opType, c.afterInsert, opts...)
 	case operation.OpTypeBeforeUpdate:
-		return c.execute(ctx, opCtx, c.beforeUpdate, opts...)
+		return c.execute(ctx, opCtx, opType, c.beforeUpdate, opts...)
 	case operation.OpTypeAfterUpdate:
-		return c.execute(ctx, opCtx, c.afterUpdate, opts...)
+		return c.execute(ctx, opCtx, opType, c.afterUpdate, opts...)
 	case operation.OpTypeBeforeDelete:
-		return c.execute(ctx, opCtx, c.beforeDelete, opts...)
+		return c.execute(ctx, opCtx, opType, c.beforeDelete, opts...)
 	case operation.OpTypeAfterDelete:
-		return c.execute(ctx, opCtx, c.afterDelete, opts...)
+		return c.execute(ctx, opCtx, opType, c.afterDelete, opts...)
 	case operation.OpTypeBeforeUpsert:
-		return c.execute(ctx, opCtx, c.beforeUpsert, opts...)
+		return c.execute(ctx, opCtx, opType, c.beforeUpsert, opts...)
 	case operation.OpTypeAfterUpsert:
-		return c.execute(ctx, opCtx, c.afterUpsert, opts...)
+		return c.execute(ctx, opCtx, opType, c.afterUpsert, opts...)
 	case operation.OpTypeBeforeFind:
-		return c.execute(ctx, opCtx, c.beforeFind, opts...)
+		return c.execute(ctx, opCtx, opType, c.beforeFind, opts...)
 	case operation.OpTypeAfterFind:
-		return c.execute(ctx, opCtx, c.afterFind, opts...)
+		return c.execute(ctx, opCtx, opType, c.afterFind, opts...)
+	case operation.OpTypeBeforeReplace:
+		return c.execute(ctx, opCtx, opType, c.beforeReplace, opts...)
+	case operation.OpTypeAfterReplace:
+		return c.execute(ctx, opCtx, opType, c.afterReplace, opts...)
+	case operation.OpTypeBeforeAggregate:
+		return c.execute(ctx, opCtx, opType, c.beforeAggregate, opts...)
+	case operation.OpTypeAfterAggregate:
+		return c.execute(ctx, opCtx, opType, c.afterAggregate, opts...)
+	case operation.OpTypeBeforeCount:
+		return c.execute(ctx, opCtx, opType, c.beforeCount, opts...)
+	case operation.OpTypeAfterCount:
+		return c.execute(ctx, opCtx, opType, c.afterCount, opts...)
+	case operation.OpTypeBeforeDistinct:
+		return c.execute(ctx, opCtx, opType, c.beforeDistinct, opts...)
+	case operation.OpTypeAfterDistinct:
+		return c.execute(ctx, opCtx, opType, c.afterDistinct, opts...)
 	}
 	return nil
 }
 
-func (c *Callback) execute(ctx context.Context, opCtx *operation.OpContext, handlers []callbackHandler, opts ...any) error {
+func (c *Callback) execute(ctx context.Context, opCtx *operation.OpContext, opType operation.OpType, handlers []callbackHandler, opts ...any) error {
 	for _, handler := range handlers {
 		if err := handler.fn(ctx, opCtx, opts...); err != nil {
-			return err
+			return mxerrors.Hook(handler.name, opType, err)
 		}
 	}
 	return nil
@@ -129,6 +165,46 @@ func (c *Callback) Register(opType operation.OpType, name string, fn CbFn) {
 			name: name,
 			fn:   fn,
 		})
+	case operation.OpTypeBeforeReplace:
+		c.beforeReplace = append(c.beforeReplace, callbackHandler{
+			name: name,
+			fn:   fn,
+		})
+	case operation.OpTypeAfterReplace:
+		c.afterReplace = append(c.afterReplace, callbackHandler{
+			name: name,
+			fn:   fn,
+		})
+	case operation.OpTypeBeforeAggregate:
+		c.beforeAggregate = append(c.beforeAggregate, callbackHandler{
+			name: name,
+			fn:   fn,
+		})
+	case operation.OpTypeAfterAggregate:
+		c.afterAggregate = append(c.afterAggregate, callbackHandler{
+			name: name,
+			fn:   fn,
+		})
+	case operation.OpTypeBeforeCount:
+		c.beforeCount = append(c.beforeCount, callbackHandler{
+			name: name,
+			fn:   fn,
+		})
+	case operation.OpTypeAfterCount:
+		c.afterCount = append(c.afterCount, callbackHandler{
+			name: name,
+			fn:   fn,
+		})
+	case operation.OpTypeBeforeDistinct:
+		c.beforeDistinct = append(c.beforeDistinct, callbackHandler{
+			name: name,
+			fn:   fn,
+		})
+	case operation.OpTypeAfterDistinct:
+		c.afterDistinct = append(c.afterDistinct, callbackHandler{
+			name: name,
+			fn:   fn,
+		})
 	}
 }
 
@@ -154,6 +230,22 @@ func (c *Callback) Remove(opType operation.OpType, name string) {
 		c.beforeFind = c.remove(c.beforeFind, name)
 	case operation.OpTypeAfterFind:
 		c.afterFind = c.remove(c.afterFind, name)
+	case operation.OpTypeBeforeReplace:
+		c.beforeReplace = c.remove(c.beforeReplace, name)
+	case operation.OpTypeAfterReplace:
+		c.afterReplace = c.remove(c.afterReplace, name)
+	case operation.OpTypeBeforeAggregate:
+		c.beforeAggregate = c.remove(c.beforeAggregate, name)
+	case operation.OpTypeAfterAggregate:
+		c.afterAggregate = c.remove(c.afterAggregate, name)
+	case operation.OpTypeBeforeCount:
+		c.beforeCount = c.remove(c.beforeCount, name)
+	case operation.OpTypeAfterCount:
+		c.afterCount = c.remove(c.afterCount, name)
+	case operation.OpTypeBeforeDistinct:
+		c.beforeDistinct = c.remove(c.beforeDistinct, name)
+	case operation.OpTypeAfterDistinct:
+		c.afterDistinct = c.remove(c.afterDistinct, name)
 	}
 }
 
@@ -6,6 +6,10 @@ import (
 
 	"github.com/go-playground/validator/v10"
 	"github.com/matiniiuu/mongox/callback"
+	mxerrors "github.com/matiniiuu/mongox/errors"
+	"github.com/matiniiuu/mongox/hook/audit"
+	"github.com/matiniiuu/mongox/hook/tenant"
+	validatorhook "github.com/matiniiuu/mongox/hook/validator"
 	"github.com/matiniiuu/mongox/operation"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -276,7 +280,7 @@ func TestPluginInit_EnableEnableDefaultFieldHook(t *testing.T) {
 		require.NotZero(t, model.UpdatedAt)
 		require.Equal(t, bson.M{
 			"$set": bson.M{
-				"updated_at": model.UpdatedAt,
+				"updatedAt": *model.UpdatedAt,
 			},
 		}, m)
 
@@ -315,11 +319,11 @@ func TestPluginInit_EnableEnableDefaultFieldHook(t *testing.T) {
 		require.NotZero(t, model.UpdatedAt)
 		require.Equal(t, bson.M{
 			"$set": bson.M{
-				"updated_at": model.UpdatedAt,
+				"updatedAt": *model.UpdatedAt,
 			},
 			"$setOnInsert": bson.M{
-				"_id":        model.ID,
-				"created_at": model.CreatedAt,
+				"_id":       model.ID,
+				"createdAt": *model.CreatedAt,
 			},
 		}, m)
 
@@ -671,7 +675,9 @@ func TestPluginInit_EnableValidationHook(t *testing.T) {
 			operation.NewOpContext(nil, operation.WithDoc(&TestModel{})),
 			operation.OpTypeBeforeInsert,
 		)
-		require.NotNil(t, err.(validator.ValidationErrors))
+		var fieldErrs validator.ValidationErrors
+		require.ErrorAs(t, err, &fieldErrs)
+		require.ErrorIs(t, err, mxerrors.ErrValidation)
 		RemovePlugin("mongox:validation", operation.OpTypeBeforeInsert)
 		RemovePlugin("mongox:validation", operation.OpTypeBeforeUpsert)
 	})
@@ -693,8 +699,110 @@ func TestPluginInit_EnableValidationHook(t *testing.T) {
 			operation.NewOpContext(nil, operation.WithReplacement(&TestModel{})),
 			operation.OpTypeBeforeUpsert,
 		)
-		require.NotNil(t, err.(validator.ValidationErrors))
+		var fieldErrs validator.ValidationErrors
+		require.ErrorAs(t, err, &fieldErrs)
+		require.ErrorIs(t, err, mxerrors.ErrValidation)
 		RemovePlugin("mongox:validation", operation.OpTypeBeforeInsert)
 		RemovePlugin("mongox:validation", operation.OpTypeBeforeUpsert)
 	})
+	t.Run("beforeUpdate", func(t *testing.T) {
+		opCtx := operation.NewOpContext(nil, operation.WithDoc(&TestModel{}), operation.WithUpdates(bson.M{"$set": bson.M{"name": ""}}))
+		InitPlugin(&PluginConfig{EnableValidationHook: true})
+		require.NoError(t, callback.GetCallback().Execute(context.Background(), opCtx, operation.OpTypeBeforeUpdate))
+		RemovePlugin("mongox:validation", operation.OpTypeBeforeInsert)
+		RemovePlugin("mongox:validation", operation.OpTypeBeforeUpsert)
+		RemovePlugin("mongox:validation", operation.OpTypeBeforeReplace)
+
+		InitPlugin(&PluginConfig{
+			EnableValidationHook: true,
+			UpdateValidation:     &validatorhook.UpdateValidation{},
+		})
+		err := callback.GetCallback().Execute(context.Background(), opCtx, operation.OpTypeBeforeUpdate)
+		require.ErrorIs(t, err, validatorhook.ErrInvalidUpdate)
+		require.ErrorIs(t, err, mxerrors.ErrValidation)
+		validatorhook.SetUpdateValidation(nil)
+		RemovePlugin("mongox:validation", operation.OpTypeBeforeInsert)
+		RemovePlugin("mongox:validation", operation.OpTypeBeforeUpsert)
+		RemovePlugin("mongox:validation", operation.OpTypeBeforeReplace)
+		RemovePlugin("mongox:validation", operation.OpTypeBeforeUpdate)
+	})
+}
+
+func TestPluginInit_EnableTenant(t *testing.T) {
+	type TestModel struct {
+		Name   string `bson:"name"`
+		Tenant string `bson:"tenant"`
+	}
+	ctx := tenant.NewContext(context.Background(), "acme")
+
+	InitPlugin(&PluginConfig{EnableTenant: true, TenantField: "tenant"})
+	defer func() {
+		for _, opType := range []operation.OpType{
+			operation.OpTypeBeforeInsert, operation.OpTypeBeforeUpdate, operation.OpTypeBeforeUpsert,
+			operation.OpTypeBeforeReplace, operation.OpTypeBeforeDelete, operation.OpTypeBeforeFind,
+			operation.OpTypeBeforeCount, operation.OpTypeBeforeDistinct, operation.OpTypeBeforeAggregate,
+		} {
+			RemovePlugin("mongox:tenant", opType)
+		}
+		tenant.SetField("")
+	}()
+
+	t.Run("beforeInsert", func(t *testing.T) {
+		doc := &TestModel{Name: "Mingyong Chen"}
+		err := callback.GetCallback().Execute(ctx, operation.NewOpContext(nil, operation.WithDoc(doc)), operation.OpTypeBeforeInsert)
+		require.NoError(t, err)
+		assert.Equal(t, "acme", doc.Tenant)
+	})
+	t.Run("beforeFind", func(t *testing.T) {
+		opCtx := operation.NewOpContext(nil, operation.WithFilter(bson.D{}))
+		err := callback.GetCallback().Execute(ctx, opCtx, operation.OpTypeBeforeFind)
+		require.NoError(t, err)
+		assert.Equal(t, bson.D{{Key: "tenant", Value: "acme"}}, opCtx.Filter)
+	})
+	t.Run("missing tenant", func(t *testing.T) {
+		err := callback.GetCallback().Execute(context.Background(), operation.NewOpContext(nil, operation.WithFilter(bson.D{})), operation.OpTypeBeforeDelete)
+		assert.ErrorIs(t, err, tenant.ErrMissingTenant)
+		var hookErr *mxerrors.HookError
+		require.ErrorAs(t, err, &hookErr)
+		assert.Equal(t, "mongox:tenant", hookErr.Hook)
+		assert.Equal(t, operation.OpTypeBeforeDelete, hookErr.OpType)
+	})
+}
+
+func TestPluginInit_EnableAudit(t *testing.T) {
+	var records []*audit.Record
+	InitPlugin(&PluginConfig{EnableAudit: true, AuditSink: audit.SinkFunc(func(_ context.Context, record *audit.Record) error {
+		records = append(records, record)
+		return nil
+	})})
+	defer func() {
+		for _, opType := range []operation.OpType{
+			operation.OpTypeAfterInsert, operation.OpTypeAfterUpdate, operation.OpTypeAfterUpsert,
+			operation.OpTypeAfterReplace, operation.OpTypeAfterDelete,
+		} {
+			RemovePlugin("mongox:audit", opType)
+		}
+		audit.SetSink(nil)
+	}()
+
+	ctx := audit.NewContext(context.Background(), "Mingyong Chen")
+	filter := bson.D{{Key: "name", Value: "burt"}}
+	updates := bson.M{"$set": bson.M{"age": 18}}
+	err := callback.GetCallback().Execute(ctx, operation.NewOpContext(nil, operation.WithFilter(filter), operation.WithUpdates(updates)), operation.OpTypeAfterUpdate)
+	require.NoError(t, err)
+	// the before callbacks are only registered for the snapshots
+	err = callback.GetCallback().Execute(ctx, operation.NewOpContext(nil, operation.WithFilter(filter)), operation.OpTypeBeforeDelete)
+	require.NoError(t, err)
+
+	require.Len(t, records, 1)
+	assert.Equal(t, audit.OperationUpdate, records[0].Operation)
+	assert.Equal(t, filter, records[0].Filter)
+	assert.Equal(t, updates, records[0].Updates)
+	assert.Equal(t, "Mingyong Chen", records[0].Actor)
+}
+
+func TestPluginInit_EnableAuditWithoutSink(t *testing.T) {
+	assert.PanicsWithValue(t, "mongox: EnableAudit requires an AuditSink", func() {
+		InitPlugin(&PluginConfig{EnableAudit: true})
+	})
 }
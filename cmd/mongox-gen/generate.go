@@ -0,0 +1,314 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/types"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/matiniiuu/mongox/internal/pkg/structs"
+)
+
+const fieldsPath = "github.com/matiniiuu/mongox/fields"
+
+// descriptor is a field of the generated descriptors, the nested structs have their own descriptor type
+type descriptor struct {
+	name string
+	path string
+	// kind is the descriptor type of the fields package, e.g. Number, or the embedded one of a nested struct
+	kind     string
+	typeArg  string
+	typeName string
+	children []*descriptor
+}
+
+func (d *descriptor) typ() string {
+	if d.typeName != "" {
+		return d.typeName
+	}
+	return fmt.Sprintf("fields.%s[%s]", d.kind, d.typeArg)
+}
+
+func (d *descriptor) constructor() string {
+	ctor := "New" + d.kind
+	if d.kind == "Field" {
+		ctor = "New"
+	}
+	return fmt.Sprintf("fields.%s[%s](%q)", ctor, d.typeArg, d.path)
+}
+
+type generator struct {
+	pkg *types.Package
+	// imports maps the paths of the imported packages to their names in the generated file
+	imports map[string]string
+	decls   bytes.Buffer
+}
+
+// generate returns the source of the descriptors of the named model structs of the package
+func generate(pkg *types.Package, typeNames []string) ([]byte, error) {
+	g := &generator{pkg: pkg, imports: map[string]string{fieldsPath: "fields"}}
+	var vars bytes.Buffer
+	for _, name := range typeNames {
+		obj, ok := pkg.Scope().Lookup(name).(*types.TypeName)
+		if !ok {
+			return nil, fmt.Errorf("type %s is not found in package %s", name, pkg.Name())
+		}
+		named, ok := obj.Type().(*types.Named)
+		if !ok {
+			return nil, fmt.Errorf("%s is not a named type", name)
+		}
+		st, ok := named.Underlying().(*types.Struct)
+		if !ok {
+			return nil, fmt.Errorf("%s is not a struct", name)
+		}
+		typeName := "fieldsOf" + upperFirst(name)
+		children, err := g.describeStruct(st, "", typeName, map[*types.Named]bool{named: true})
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		root := &descriptor{typeName: typeName, children: children}
+		if err = g.declare(root); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		fmt.Fprintf(&vars, "// %sFields are the typed descriptors of the fields of %s\n", name, name)
+		fmt.Fprintf(&vars, "var %sFields = %s\n\n", name, g.literal(root))
+	}
+
+	var src bytes.Buffer
+	src.WriteString("// Code generated by mongox-gen; DO NOT EDIT.\n\n")
+	fmt.Fprintf(&src, "package %s\n\n", pkg.Name())
+	src.WriteString("import (\n")
+	paths := make([]string, 0, len(g.imports))
+	for path := range g.imports {
+		paths = append(paths, path)
+	}
+	// the packages of the standard library come first, as goimports groups them
+	slices.SortFunc(paths, func(a, b string) int {
+		if std(a) != std(b) {
+			if std(a) {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a, b)
+	})
+	for i, path := range paths {
+		if i > 0 && std(paths[i-1]) != std(path) {
+			src.WriteString("\n")
+		}
+		if name := g.imports[path]; name != defaultName(path) {
+			fmt.Fprintf(&src, "%s %q\n", name, path)
+		} else {
+			fmt.Fprintf(&src, "%q\n", path)
+		}
+	}
+	src.WriteString(")\n\n")
+	src.Write(vars.Bytes())
+	src.Write(g.decls.Bytes())
+	return format.Source(src.Bytes())
+}
+
+// describeStruct returns the descriptors of the fields of the struct, the fields of inline structs are flattened
+func (g *generator) describeStruct(st *types.Struct, prefix, typeName string, visiting map[*types.Named]bool) ([]*descriptor, error) {
+	var result []*descriptor
+	for i := 0; i < st.NumFields(); i++ {
+		f := st.Field(i)
+		if !f.Exported() {
+			continue
+		}
+		tag := structs.ParseTag(reflect.StructField{Name: f.Name(), Tag: reflect.StructTag(st.Tag(i))})
+		if tag.Skip {
+			continue
+		}
+		ft := deref(f.Type())
+		if inner, ok := ft.Underlying().(*types.Struct); ok && tag.Inline {
+			named, _ := ft.(*types.Named)
+			if named != nil {
+				if visiting[named] {
+					continue
+				}
+				visiting[named] = true
+			}
+			children, err := g.describeStruct(inner, prefix, typeName, visiting)
+			delete(visiting, named)
+			if err != nil {
+				return nil, err
+			}
+			result = appendUnique(result, children...)
+			continue
+		}
+		path := tag.Key
+		if prefix != "" {
+			path = prefix + "." + tag.Key
+		}
+		d, err := g.describe(f.Name(), path, ft, typeName+f.Name(), visiting)
+		if err != nil {
+			return nil, err
+		}
+		result = appendUnique(result, d)
+	}
+	return result, nil
+}
+
+// describe returns the descriptor of a field of the type, a struct with fields of its own gets a nested descriptor
+func (g *generator) describe(name, path string, typ types.Type, typeName string, visiting map[*types.Named]bool) (*descriptor, error) {
+	if typ.Underlying() == types.Typ[types.Invalid] {
+		return nil, fmt.Errorf("the type of field %s is invalid", name)
+	}
+	d := &descriptor{name: name, path: path, kind: "Field", typeArg: g.typeString(typ)}
+	nested := typ
+	switch u := typ.Underlying().(type) {
+	case *types.Basic:
+		switch {
+		case u.Info()&types.IsString != 0:
+			d.kind = "String"
+		case u.Info()&(types.IsInteger|types.IsFloat) != 0 && u.Kind() != types.Uintptr:
+			d.kind = "Number"
+		}
+		return d, nil
+	case *types.Slice:
+		if types.Identical(u.Elem(), types.Typ[types.Byte]) {
+			return d, nil
+		}
+		d.kind, d.typeArg = "Array", g.typeString(u.Elem())
+		// the fields of the elements are addressed by the same dotted paths as those of a nested struct
+		nested = deref(u.Elem())
+	}
+
+	inner, ok := nested.Underlying().(*types.Struct)
+	if !ok {
+		return d, nil
+	}
+	named, _ := nested.(*types.Named)
+	if named != nil {
+		if visiting[named] {
+			return d, nil
+		}
+		visiting[named] = true
+		defer delete(visiting, named)
+	}
+	children, err := g.describeStruct(inner, path, typeName, visiting)
+	if err != nil {
+		return nil, err
+	}
+	if len(children) != 0 {
+		d.typeName, d.children = typeName, children
+	}
+	return d, nil
+}
+
+// declare writes the declarations of the descriptor type and of its nested types
+func (g *generator) declare(d *descriptor) error {
+	fmt.Fprintf(&g.decls, "type %s struct {\n", d.typeName)
+	if d.kind != "" {
+		fmt.Fprintf(&g.decls, "fields.%s[%s]\n", d.kind, d.typeArg)
+	}
+	for _, child := range d.children {
+		if child.name == d.kind {
+			return fmt.Errorf("field %s of %s conflicts with the embedded descriptor", child.name, d.path)
+		}
+		fmt.Fprintf(&g.decls, "%s %s\n", child.name, child.typ())
+	}
+	g.decls.WriteString("}\n\n")
+	for _, child := range d.children {
+		if child.typeName == "" {
+			continue
+		}
+		if err := g.declare(child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// literal returns the composite literal which initializes the descriptor
+func (g *generator) literal(d *descriptor) string {
+	if d.typeName == "" {
+		return d.constructor()
+	}
+	var b strings.Builder
+	b.WriteString(d.typeName + "{\n")
+	if d.kind != "" {
+		fmt.Fprintf(&b, "%s: %s,\n", d.kind, d.constructor())
+	}
+	for _, child := range d.children {
+		fmt.Fprintf(&b, "%s: %s,\n", child.name, g.literal(child))
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+// typeString returns the type as written in the generated file and records the packages it refers to
+func (g *generator) typeString(typ types.Type) string {
+	return types.TypeString(typ, func(p *types.Package) string {
+		if p == g.pkg {
+			return ""
+		}
+		if name, ok := g.imports[p.Path()]; ok {
+			return name
+		}
+		name := p.Name()
+		for i := 2; g.nameTaken(name); i++ {
+			name = fmt.Sprintf("%s%d", p.Name(), i)
+		}
+		g.imports[p.Path()] = name
+		return name
+	})
+}
+
+func (g *generator) nameTaken(name string) bool {
+	for _, n := range g.imports {
+		if n == name {
+			return true
+		}
+	}
+	return g.pkg.Scope().Lookup(name) != nil
+}
+
+// appendUnique appends the descriptors whose names are not taken yet, like the shallower fields of a Go struct
+// the fields first declared win over the later ones of the same name
+func appendUnique(result []*descriptor, ds ...*descriptor) []*descriptor {
+	for _, d := range ds {
+		if !slices.ContainsFunc(result, func(r *descriptor) bool { return r.name == d.name }) {
+			result = append(result, d)
+		}
+	}
+	return result
+}
+
+func deref(typ types.Type) types.Type {
+	for {
+		ptr, ok := typ.(*types.Pointer)
+		if !ok {
+			return typ
+		}
+		typ = ptr.Elem()
+	}
+}
+
+// defaultName returns the name a package is imported with if the import has no name, assuming it is the last
+// element of the path which is not a major version
+func defaultName(path string) string {
+	elems := strings.Split(path, "/")
+	name := elems[len(elems)-1]
+	if len(elems) > 1 && len(name) > 1 && name[0] == 'v' && strings.Trim(name[1:], "0123456789") == "" {
+		name = elems[len(elems)-2]
+	}
+	return name
+}
+
+// std reports whether the package is one of the standard library, whose paths have no dot in their first element
+func std(path string) bool {
+	first, _, _ := strings.Cut(path, "/")
+	return !strings.Contains(first, ".")
+}
+
+func upperFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
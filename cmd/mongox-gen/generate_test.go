@@ -0,0 +1,56 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update the generated files of testdata")
+
+func TestGenerate(t *testing.T) {
+	dir := filepath.Join("testdata", "model")
+	pkg, err := load(dir, defaultOutput)
+	require.NoError(t, err)
+
+	got, err := generate(pkg, []string{"User", "Item"})
+	require.NoError(t, err)
+	golden := filepath.Join(dir, defaultOutput)
+	if *update {
+		require.NoError(t, os.WriteFile(golden, got, 0o644))
+	}
+	want, err := os.ReadFile(golden)
+	require.NoError(t, err)
+	assert.Equal(t, string(want), string(got))
+}
+
+func TestGenerate_Errors(t *testing.T) {
+	pkg, err := load(filepath.Join("testdata", "model"), defaultOutput)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name     string
+		typeName string
+		wantErr  string
+	}{
+		{name: "unknown type", typeName: "Order", wantErr: "type Order is not found in package model"},
+		{name: "not a struct", typeName: "Status", wantErr: "Status is not a struct"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := generate(pkg, []string{tc.typeName})
+			assert.EqualError(t, err, tc.wantErr)
+		})
+	}
+}
+
+func Test_defaultName(t *testing.T) {
+	assert.Equal(t, "time", defaultName("time"))
+	assert.Equal(t, "bson", defaultName("go.mongodb.org/mongo-driver/v2/bson"))
+	assert.Equal(t, "mongo-driver", defaultName("go.mongodb.org/mongo-driver/v2"))
+	assert.Equal(t, "yaml.v3", defaultName("gopkg.in/yaml.v3"))
+}
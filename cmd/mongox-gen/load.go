@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+type listedPackage struct {
+	ImportPath string
+	Name       string
+	Dir        string
+	Export     string
+	GoFiles    []string
+}
+
+// load type checks the package in the directory, leaving out the file named exclude
+// The dependencies are imported from the export data built by go list, the type errors of the package itself are
+// ignored so that a stale generated file or code using it does not stop the generation
+func load(dir, exclude string) (*types.Package, error) {
+	cmd := exec.Command("go", "list", "-e", "-export", "-deps", "-json=ImportPath,Name,Dir,Export,GoFiles", ".")
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("go list: %w: %s", err, stderr.String())
+	}
+
+	var (
+		target  listedPackage
+		exports = make(map[string]string)
+	)
+	decoder := json.NewDecoder(bytes.NewReader(out))
+	for {
+		var p listedPackage
+		if err = decoder.Decode(&p); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		exports[p.ImportPath] = p.Export
+		// the package itself is listed after its dependencies
+		target = p
+	}
+	if target.Name == "" {
+		return nil, fmt.Errorf("no Go package in %s", dir)
+	}
+
+	fset := token.NewFileSet()
+	files := make([]*ast.File, 0, len(target.GoFiles))
+	for _, name := range target.GoFiles {
+		if name == exclude {
+			continue
+		}
+		file, err := parser.ParseFile(fset, filepath.Join(target.Dir, name), nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+	conf := types.Config{
+		Importer: importer.ForCompiler(fset, "gc", func(path string) (io.ReadCloser, error) {
+			export := exports[path]
+			if export == "" {
+				return nil, fmt.Errorf("no export data for %s", path)
+			}
+			return os.Open(export)
+		}),
+		Error: func(error) {},
+	}
+	pkg, _ := conf.Check(target.ImportPath, fset, files, nil)
+	return pkg, nil
+}
@@ -0,0 +1,58 @@
+// mongox-gen generates the typed field descriptors of the model structs of a package, see the fields package
+//
+// The descriptors of a model named User are declared as the variable UserFields, whose fields are named after the
+// fields of User and carry their bson paths, e.g. UserFields.Name.Eq("x") or UserFields.Address.City.Set("Paris").
+// The paths follow the bson tags, the fields of inline structs are flattened and nested structs get nested descriptors.
+//
+// Usage:
+//
+//	//go:generate go run github.com/matiniiuu/mongox/cmd/mongox-gen -type User,Order
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const defaultOutput = "mongox_fields_gen.go"
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("mongox-gen: ")
+
+	typeNames := flag.String("type", "", "comma-separated list of the model struct names; must be set")
+	output := flag.String("output", defaultOutput, "output file name, relative to the package directory")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: mongox-gen -type T[,T...] [-output file] [directory]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if *typeNames == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+
+	if err := run(dir, strings.Split(*typeNames, ","), *output); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(dir string, typeNames []string, output string) error {
+	pkg, err := load(dir, filepath.Base(output))
+	if err != nil {
+		return err
+	}
+	src, err := generate(pkg, typeNames)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, output), src, 0o644)
+}
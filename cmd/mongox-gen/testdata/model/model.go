@@ -0,0 +1,51 @@
+package model
+
+import (
+	"time"
+
+	"github.com/matiniiuu/mongox"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type Status string
+
+type Address struct {
+	City    string `bson:"city"`
+	ZipCode int    `bson:"zip_code"`
+}
+
+type Audit struct {
+	UpdatedBy string `bson:"updatedBy"`
+}
+
+type Item struct {
+	SKU      string  `bson:"sku"`
+	Quantity int64   `bson:"qty"`
+	Price    float64 `bson:"price"`
+}
+
+type Node struct {
+	Name     string  `bson:"name"`
+	Children []*Node `bson:"children"`
+}
+
+type User struct {
+	mongox.Base `bson:",inline"`
+	Audit       `bson:",inline"`
+	Name        string            `bson:"name"`
+	Age         int               `bson:"age,omitempty"`
+	Status      Status            `bson:"status"`
+	Active      bool              `bson:"active"`
+	Tags        []string          `bson:"tags"`
+	Avatar      []byte            `bson:"avatar"`
+	Address     *Address          `bson:"address"`
+	Items       []Item            `bson:"items"`
+	Tree        Node              `bson:"tree"`
+	Attrs       map[string]string `bson:"attrs"`
+	Birthday    time.Time         `bson:"birthday"`
+	Owner       bson.ObjectID     `bson:"owner"`
+	Nickname    string
+	Ignored     string `bson:"-"`
+	secret      string
+}
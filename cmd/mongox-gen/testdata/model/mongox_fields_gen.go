@@ -0,0 +1,98 @@
+// Code generated by mongox-gen; DO NOT EDIT.
+
+package model
+
+import (
+	"time"
+
+	"github.com/matiniiuu/mongox/fields"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// UserFields are the typed descriptors of the fields of User
+var UserFields = fieldsOfUser{
+	ID:        fields.New[bson.ObjectID]("_id"),
+	CreatedAt: fields.New[time.Time]("createdAt"),
+	UpdatedAt: fields.New[time.Time]("updatedAt"),
+	DeletedAt: fields.New[time.Time]("deletedAt"),
+	UpdatedBy: fields.NewString[string]("updatedBy"),
+	Name:      fields.NewString[string]("name"),
+	Age:       fields.NewNumber[int]("age"),
+	Status:    fields.NewString[Status]("status"),
+	Active:    fields.New[bool]("active"),
+	Tags:      fields.NewArray[string]("tags"),
+	Avatar:    fields.New[[]byte]("avatar"),
+	Address: fieldsOfUserAddress{
+		Field:   fields.New[Address]("address"),
+		City:    fields.NewString[string]("address.city"),
+		ZipCode: fields.NewNumber[int]("address.zip_code"),
+	},
+	Items: fieldsOfUserItems{
+		Array:    fields.NewArray[Item]("items"),
+		SKU:      fields.NewString[string]("items.sku"),
+		Quantity: fields.NewNumber[int64]("items.qty"),
+		Price:    fields.NewNumber[float64]("items.price"),
+	},
+	Tree: fieldsOfUserTree{
+		Field:    fields.New[Node]("tree"),
+		Name:     fields.NewString[string]("tree.name"),
+		Children: fields.NewArray[*Node]("tree.children"),
+	},
+	Attrs:    fields.New[map[string]string]("attrs"),
+	Birthday: fields.New[time.Time]("birthday"),
+	Owner:    fields.New[bson.ObjectID]("owner"),
+	Nickname: fields.NewString[string]("nickname"),
+}
+
+// ItemFields are the typed descriptors of the fields of Item
+var ItemFields = fieldsOfItem{
+	SKU:      fields.NewString[string]("sku"),
+	Quantity: fields.NewNumber[int64]("qty"),
+	Price:    fields.NewNumber[float64]("price"),
+}
+
+type fieldsOfUser struct {
+	ID        fields.Field[bson.ObjectID]
+	CreatedAt fields.Field[time.Time]
+	UpdatedAt fields.Field[time.Time]
+	DeletedAt fields.Field[time.Time]
+	UpdatedBy fields.String[string]
+	Name      fields.String[string]
+	Age       fields.Number[int]
+	Status    fields.String[Status]
+	Active    fields.Field[bool]
+	Tags      fields.Array[string]
+	Avatar    fields.Field[[]byte]
+	Address   fieldsOfUserAddress
+	Items     fieldsOfUserItems
+	Tree      fieldsOfUserTree
+	Attrs     fields.Field[map[string]string]
+	Birthday  fields.Field[time.Time]
+	Owner     fields.Field[bson.ObjectID]
+	Nickname  fields.String[string]
+}
+
+type fieldsOfUserAddress struct {
+	fields.Field[Address]
+	City    fields.String[string]
+	ZipCode fields.Number[int]
+}
+
+type fieldsOfUserItems struct {
+	fields.Array[Item]
+	SKU      fields.String[string]
+	Quantity fields.Number[int64]
+	Price    fields.Number[float64]
+}
+
+type fieldsOfUserTree struct {
+	fields.Field[Node]
+	Name     fields.String[string]
+	Children fields.Array[*Node]
+}
+
+type fieldsOfItem struct {
+	SKU      fields.String[string]
+	Quantity fields.Number[int64]
+	Price    fields.Number[float64]
+}
@@ -0,0 +1,24 @@
+package model
+
+import (
+	"github.com/matiniiuu/mongox/builder/aggregation"
+	"github.com/matiniiuu/mongox/builder/query"
+	"github.com/matiniiuu/mongox/builder/update"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// the descriptors are used with the builders, the generator still works while this file refers to stale descriptors
+
+func activeAdults() bson.D {
+	return query.And(UserFields.Status.Eq("active"), UserFields.Age.Gte(18), UserFields.Address.City.Eq("Paris"))
+}
+
+func rename(name string) bson.D {
+	return update.NewBuilder().Set(UserFields.Name.String(), name).Inc(UserFields.Items.Quantity.String(), 1).Build()
+}
+
+func byAge() mongo.Pipeline {
+	return aggregation.NewStageBuilder().Match(UserFields.Tags.Contains("go")).Sort(bson.D{UserFields.Age.Desc()}).Build()
+}
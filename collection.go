@@ -1,12 +1,20 @@
 package mongox
 
 import (
+	"context"
+
 	"github.com/matiniiuu/mongox/aggregator"
+	"github.com/matiniiuu/mongox/bulk"
 	"github.com/matiniiuu/mongox/creator"
 	"github.com/matiniiuu/mongox/deleter"
 	"github.com/matiniiuu/mongox/finder"
+	"github.com/matiniiuu/mongox/index"
+	"github.com/matiniiuu/mongox/schema"
 	"github.com/matiniiuu/mongox/updater"
+	"github.com/matiniiuu/mongox/watcher"
+
 	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
 
 func NewCollection[T any](collection *mongo.Collection) *Collection[T] {
@@ -15,10 +23,22 @@ func NewCollection[T any](collection *mongo.Collection) *Collection[T] {
 
 type Collection[T any] struct {
 	collection *mongo.Collection
+	softDelete *bool
+}
+
+// SoftDelete is used to turn the soft delete mode on or off for this collection
+// It overrides the global setting of PluginConfig.EnableSoftDelete
+func (c *Collection[T]) SoftDelete(enabled bool) *Collection[T] {
+	c.softDelete = &enabled
+	return c
 }
 
 func (c *Collection[T]) Finder() *finder.Finder[T] {
-	return finder.NewFinder[T](c.collection)
+	f := finder.NewFinder[T](c.collection)
+	if c.softDelete != nil {
+		f.SoftDelete(*c.softDelete)
+	}
+	return f
 }
 
 func (c *Collection[T]) Creator() *creator.Creator[T] {
@@ -26,14 +46,64 @@ func (c *Collection[T]) Creator() *creator.Creator[T] {
 }
 
 func (c *Collection[T]) Updater() *updater.Updater[T] {
-	return updater.NewUpdater[T](c.collection)
+	u := updater.NewUpdater[T](c.collection)
+	if c.softDelete != nil {
+		u.SoftDelete(*c.softDelete)
+	}
+	return u
 }
 
 func (c *Collection[T]) Deleter() *deleter.Deleter[T] {
-	return deleter.NewDeleter[T](c.collection)
+	d := deleter.NewDeleter[T](c.collection)
+	if c.softDelete != nil {
+		d.SoftDelete(*c.softDelete)
+	}
+	return d
 }
 func (c *Collection[T]) Aggregator() *aggregator.Aggregator[T] {
-	return aggregator.NewAggregator[T](c.collection)
+	a := aggregator.NewAggregator[T](c.collection)
+	if c.softDelete != nil {
+		a.SoftDelete(*c.softDelete)
+	}
+	return a
+}
+
+// Bulk returns a bulk writer which queues write operations and sends them with bulk writes
+func (c *Collection[T]) Bulk() *bulk.BulkWriter[T] {
+	b := bulk.NewBulkWriter[T](c.collection)
+	if c.softDelete != nil {
+		b.SoftDelete(*c.softDelete)
+	}
+	return b
+}
+
+// Watcher returns a watcher which opens typed change streams on the collection
+func (c *Collection[T]) Watcher() *watcher.Watcher[T] {
+	return watcher.NewWatcher[T](c.collection)
+}
+
+// Watch opens a change stream on the collection which decodes the events into watcher.ChangeEvent[T]
+// Use Watcher to set a resume token store
+func (c *Collection[T]) Watch(ctx context.Context, pipeline any, opts ...options.Lister[options.ChangeStreamOptions]) (*watcher.Stream[T], error) {
+	return c.Watcher().Pipeline(pipeline).Watch(ctx, opts...)
+}
+
+// Indexer returns an indexer which keeps the indexes of the collection in sync with the mongox tags of T
+func (c *Collection[T]) Indexer() *index.Indexer[T] {
+	return index.NewIndexer[T](c.collection)
+}
+
+// EnsureIndexes creates the indexes declared by the mongox tags of T which the collection misses
+// and recreates the ones whose options changed, use Indexer to drop stale indexes or to plan a dry run
+func (c *Collection[T]) EnsureIndexes(ctx context.Context) (*index.Plan, error) {
+	return c.Indexer().Ensure(ctx)
+}
+
+// SyncValidator installs the $jsonSchema derived from the struct and validate tags of T as the validator of the
+// collection, creating the collection if it is missing, and returns the difference with the installed validator
+// An empty level or action stands for the default one of the server, use schema.NewSyncer for a dry run
+func (c *Collection[T]) SyncValidator(ctx context.Context, level schema.Level, action schema.Action) (*schema.Diff, error) {
+	return schema.NewSyncer[T](c.collection).Sync(ctx, level, action)
 }
 
 func (c *Collection[T]) Collection() *mongo.Collection {
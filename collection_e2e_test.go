@@ -5,8 +5,15 @@ package mongox
 import (
 	"context"
 	"testing"
+	"time"
 
+	"github.com/matiniiuu/mongox/builder/query"
+	"github.com/matiniiuu/mongox/builder/update"
 	"github.com/matiniiuu/mongox/creator"
+	"github.com/matiniiuu/mongox/operation"
+	"github.com/matiniiuu/mongox/watcher"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
 
 	"github.com/matiniiuu/mongox/finder"
 
@@ -60,3 +67,107 @@ func getCollection[T any](t *testing.T) *Collection[T] {
 	collection := NewCollection[T](client.Database("db-test").Collection("test_user"))
 	return collection
 }
+
+type baseUser struct {
+	Base `bson:",inline"`
+	Name string `bson:"name"`
+	Age  int64  `bson:"age"`
+}
+
+func TestCollection_e2e_DefaultFieldHook(t *testing.T) {
+	collection := getCollection[baseUser](t)
+	InitPlugin(&PluginConfig{EnableDefaultFieldHook: true})
+	defer func() {
+		RemovePlugin("mongox:default_field", operation.OpTypeBeforeInsert)
+		RemovePlugin("mongox:default_field", operation.OpTypeBeforeUpdate)
+		RemovePlugin("mongox:default_field", operation.OpTypeBeforeUpsert)
+	}()
+
+	t.Run("insert one", func(t *testing.T) {
+		ctx := context.Background()
+		user := &baseUser{Name: "Mingyong Chen", Age: 18}
+		_, err := collection.Creator().InsertOne(ctx, user)
+		require.NoError(t, err)
+		defer collection.Collection().DeleteOne(ctx, query.Id(user.ID))
+
+		require.False(t, user.ID.IsZero())
+		require.NotNil(t, user.CreatedAt)
+
+		got, err := collection.Finder().Filter(query.Id(user.ID)).FindOne(ctx)
+		require.NoError(t, err)
+		require.NotNil(t, got.CreatedAt)
+		require.Equal(t, user.CreatedAt.UnixMilli(), got.CreatedAt.UnixMilli())
+	})
+
+	t.Run("insert many", func(t *testing.T) {
+		ctx := context.Background()
+		users := []*baseUser{{Name: "Mingyong Chen", Age: 18}, {Name: "burt", Age: 19}}
+		_, err := collection.Creator().InsertMany(ctx, users)
+		require.NoError(t, err)
+		defer collection.Collection().DeleteMany(ctx, query.In("_id", users[0].ID, users[1].ID))
+
+		for _, user := range users {
+			require.False(t, user.ID.IsZero())
+			require.NotNil(t, user.CreatedAt)
+		}
+		got, err := collection.Finder().Filter(query.In("_id", users[0].ID, users[1].ID)).Find(ctx)
+		require.NoError(t, err)
+		require.Len(t, got, 2)
+		for _, user := range got {
+			require.NotNil(t, user.CreatedAt)
+		}
+	})
+
+	t.Run("update", func(t *testing.T) {
+		ctx := context.Background()
+		id := bson.NewObjectID()
+		_, err := collection.Collection().InsertOne(ctx, bson.M{"_id": id, "name": "Mingyong Chen", "age": 18})
+		require.NoError(t, err)
+		defer collection.Collection().DeleteOne(ctx, query.Id(id))
+
+		result, err := collection.Updater().Filter(query.Id(id)).Updates(update.Set("age", 19)).UpdateOne(ctx)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), result.ModifiedCount)
+
+		got, err := collection.Finder().Filter(query.Id(id)).FindOne(ctx)
+		require.NoError(t, err)
+		require.Equal(t, int64(19), got.Age)
+		require.NotNil(t, got.UpdatedAt)
+		require.Nil(t, got.CreatedAt)
+	})
+
+	t.Run("upsert", func(t *testing.T) {
+		ctx := context.Background()
+		result, err := collection.Updater().Filter(query.Eq("name", "upsert user")).Updates(update.Set("age", 20)).Upsert(ctx)
+		require.NoError(t, err)
+		require.NotNil(t, result.UpsertedID)
+		defer collection.Collection().DeleteOne(ctx, query.Id(result.UpsertedID))
+
+		got, err := collection.Finder().Filter(query.Id(result.UpsertedID)).FindOne(ctx)
+		require.NoError(t, err)
+		require.False(t, got.ID.IsZero())
+		require.NotNil(t, got.CreatedAt)
+		require.NotNil(t, got.UpdatedAt)
+		require.Equal(t, int64(20), got.Age)
+	})
+}
+
+func TestCollection_e2e_Watch(t *testing.T) {
+	collection := getCollection[baseUser](t)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	stream, err := collection.Watch(ctx, nil)
+	require.NoError(t, err)
+	defer stream.Close(ctx)
+
+	user := &baseUser{Name: "Mingyong Chen", Age: 18}
+	user.DefaultId()
+	_, err = collection.Collection().InsertOne(ctx, user)
+	require.NoError(t, err)
+	defer collection.Collection().DeleteOne(context.Background(), query.Id(user.ID))
+
+	require.True(t, stream.Next(ctx))
+	require.Equal(t, watcher.OperationTypeInsert, stream.Event().OperationType)
+	require.Equal(t, user.ID, stream.Event().FullDocument.ID)
+}
@@ -1,8 +1,12 @@
 package mongox
 
 import (
+	"context"
 	"testing"
 
+	"github.com/matiniiuu/mongox/builder/query"
+	"github.com/matiniiuu/mongox/schema"
+
 	"github.com/matiniiuu/mongox/updater"
 
 	"github.com/matiniiuu/mongox/creator"
@@ -46,7 +50,41 @@ func TestCollection_Aggregator(t *testing.T) {
 	assert.NotNil(t, a, "Expected non-nil Aggregator")
 }
 
+func TestCollection_Bulk(t *testing.T) {
+	b := NewCollection[any](&mongo.Collection{}).Bulk()
+	assert.NotNil(t, b, "Expected non-nil BulkWriter")
+}
+
+func TestCollection_Watcher(t *testing.T) {
+	w := NewCollection[any](&mongo.Collection{}).Watcher()
+	assert.NotNil(t, w, "Expected non-nil Watcher")
+}
+
+func TestCollection_Indexer(t *testing.T) {
+	i := NewCollection[any](&mongo.Collection{}).Indexer()
+	assert.NotNil(t, i, "Expected non-nil Indexer")
+}
+
 func TestCollection_Collection(t *testing.T) {
 	a := NewCollection[any](&mongo.Collection{})
 	assert.NotNil(t, a.Collection(), "Expected non-nil *mongo.Collection")
 }
+
+func TestCollection_SoftDelete(t *testing.T) {
+	c := NewCollection[any](&mongo.Collection{})
+	assert.Nil(t, c.softDelete)
+
+	c.SoftDelete(true)
+	assert.NotNil(t, c.softDelete)
+	assert.True(t, *c.softDelete)
+	assert.NotNil(t, c.Finder())
+	assert.NotNil(t, c.Updater())
+	assert.NotNil(t, c.Deleter())
+	assert.NotNil(t, c.Aggregator())
+	assert.NotNil(t, c.Bulk())
+}
+
+func TestCollection_SyncValidator(t *testing.T) {
+	_, err := NewCollection[string](&mongo.Collection{}).SyncValidator(context.Background(), schema.LevelStrict, schema.ActionError)
+	assert.ErrorIs(t, err, query.ErrInvalidSchema)
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 
 	"github.com/matiniiuu/mongox/internal/pkg/utils"
+	"github.com/matiniiuu/mongox/internal/pkg/version"
 
 	"github.com/matiniiuu/mongox/callback"
+	mxerrors "github.com/matiniiuu/mongox/errors"
 	"github.com/matiniiuu/mongox/operation"
 
 	"go.mongodb.org/mongo-driver/v2/mongo"
@@ -56,10 +58,10 @@ func (c *Creator[T]) preActionHandler(ctx context.Context, globalOpContext *oper
 	if err != nil {
 		return err
 	}
-	for _, beforeHook := range c.beforeHooks {
+	for i, beforeHook := range c.beforeHooks {
 		err = beforeHook(ctx, opContext)
 		if err != nil {
-			return err
+			return mxerrors.Hook(mxerrors.HookName(beforeHook, i), opType, err)
 		}
 	}
 	return nil
@@ -70,16 +72,18 @@ func (c *Creator[T]) postActionHandler(ctx context.Context, globalOpContext *ope
 	if err != nil {
 		return err
 	}
-	for _, afterHook := range c.afterHooks {
+	for i, afterHook := range c.afterHooks {
 		err = afterHook(ctx, opContext)
 		if err != nil {
-			return err
+			return mxerrors.Hook(mxerrors.HookName(afterHook, i), opType, err)
 		}
 	}
 	return nil
 }
 
+// InsertOne is used to insert a document, its field tagged with mongox:"version" is set to 1 if it is not set yet
 func (c *Creator[T]) InsertOne(ctx context.Context, doc *T, opts ...options.Lister[options.InsertOneOptions]) (*mongo.InsertOneResult, error) {
+	version.Init(doc)
 	opContext := operation.NewOpContext(c.collection, operation.WithDoc(doc), operation.WithMongoOptions(opts), operation.WithModelHook(c.modelHook))
 	err := c.preActionHandler(ctx, opContext, NewOpContext(c.collection, WithDoc(doc), WithMongoOptions[T](opts), WithModelHook[T](c.modelHook)), operation.OpTypeBeforeInsert)
 	if err != nil {
@@ -88,7 +92,7 @@ func (c *Creator[T]) InsertOne(ctx context.Context, doc *T, opts ...options.List
 
 	result, err := c.collection.InsertOne(ctx, doc, opts...)
 	if err != nil {
-		return nil, err
+		return nil, mxerrors.Wrap(err)
 	}
 
 	err = c.postActionHandler(ctx, opContext, NewOpContext(c.collection, WithDoc(doc), WithMongoOptions[T](opts), WithModelHook[T](c.modelHook)), operation.OpTypeAfterInsert)
@@ -99,7 +103,11 @@ func (c *Creator[T]) InsertOne(ctx context.Context, doc *T, opts ...options.List
 	return result, nil
 }
 
+// InsertMany is used to insert the documents, their field tagged with mongox:"version" is set to 1 if it is not set yet
 func (c *Creator[T]) InsertMany(ctx context.Context, docs []*T, opts ...options.Lister[options.InsertManyOptions]) (*mongo.InsertManyResult, error) {
+	for _, doc := range docs {
+		version.Init(doc)
+	}
 	opContext := operation.NewOpContext(c.collection, operation.WithDoc(docs), operation.WithMongoOptions(opts), operation.WithModelHook(c.modelHook))
 	err := c.preActionHandler(ctx, opContext, NewOpContext(c.collection, WithDocs(docs), WithMongoOptions[T](opts), WithModelHook[T](c.modelHook)), operation.OpTypeBeforeInsert)
 	if err != nil {
@@ -108,7 +116,7 @@ func (c *Creator[T]) InsertMany(ctx context.Context, docs []*T, opts ...options.
 
 	result, err := c.collection.InsertMany(ctx, utils.ToAnySlice(docs...), opts...)
 	if err != nil {
-		return nil, err
+		return nil, mxerrors.Wrap(err)
 	}
 
 	err = c.postActionHandler(ctx, opContext, NewOpContext(c.collection, WithDocs(docs), WithMongoOptions[T](opts), WithModelHook[T](c.modelHook)), operation.OpTypeAfterInsert)
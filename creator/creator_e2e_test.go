@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/matiniiuu/mongox/callback"
+	mxerrors "github.com/matiniiuu/mongox/errors"
 	"github.com/matiniiuu/mongox/operation"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
@@ -138,7 +139,7 @@ func TestCreator_e2e_One(t *testing.T) {
 				},
 			},
 			wantError: func(t assert.TestingT, err error, i ...interface{}) bool {
-				return assert.Equal(t, errors.New("before hook error"), err)
+				return assert.ErrorIs(t, err, mxerrors.ErrHookFailed) && assert.ErrorContains(t, err, "before hook error")
 			},
 		},
 		{
@@ -167,7 +168,7 @@ func TestCreator_e2e_One(t *testing.T) {
 				},
 			},
 			wantError: func(t assert.TestingT, err error, i ...interface{}) bool {
-				return assert.Equal(t, errors.New("after hook error"), err)
+				return assert.ErrorIs(t, err, mxerrors.ErrHookFailed) && assert.ErrorContains(t, err, "after hook error")
 			},
 		},
 		{
@@ -225,7 +226,7 @@ func TestCreator_e2e_One(t *testing.T) {
 				},
 			},
 			wantError: func(t assert.TestingT, err error, i ...interface{}) bool {
-				return assert.Equal(t, errors.New("before hook error"), err)
+				return assert.ErrorIs(t, err, mxerrors.ErrHookFailed) && assert.ErrorContains(t, err, "before hook error")
 			},
 		},
 		{
@@ -250,7 +251,7 @@ func TestCreator_e2e_One(t *testing.T) {
 				},
 			},
 			wantError: func(t assert.TestingT, err error, i ...interface{}) bool {
-				return assert.Equal(t, errors.New("after hook error"), err)
+				return assert.ErrorIs(t, err, mxerrors.ErrHookFailed) && assert.ErrorContains(t, err, "after hook error")
 			},
 		},
 		{
@@ -392,7 +393,7 @@ func TestCreator_e2e_Many(t *testing.T) {
 				},
 			},
 			wantError: func(t assert.TestingT, err error, i ...interface{}) bool {
-				return assert.Equal(t, errors.New("before hook error"), err)
+				return assert.ErrorIs(t, err, mxerrors.ErrHookFailed) && assert.ErrorContains(t, err, "before hook error")
 			},
 		},
 		{
@@ -427,7 +428,7 @@ func TestCreator_e2e_Many(t *testing.T) {
 				},
 			},
 			wantError: func(t assert.TestingT, err error, i ...interface{}) bool {
-				return assert.Equal(t, errors.New("after hook error"), err)
+				return assert.ErrorIs(t, err, mxerrors.ErrHookFailed) && assert.ErrorContains(t, err, "after hook error")
 			},
 		},
 		{
@@ -492,7 +493,7 @@ func TestCreator_e2e_Many(t *testing.T) {
 				},
 			},
 			wantError: func(t assert.TestingT, err error, i ...interface{}) bool {
-				return assert.Equal(t, errors.New("before hook error"), err)
+				return assert.ErrorIs(t, err, mxerrors.ErrHookFailed) && assert.ErrorContains(t, err, "before hook error")
 			},
 		},
 		{
@@ -523,7 +524,7 @@ func TestCreator_e2e_Many(t *testing.T) {
 				},
 			},
 			wantError: func(t assert.TestingT, err error, i ...interface{}) bool {
-				return assert.Equal(t, errors.New("after hook error"), err)
+				return assert.ErrorIs(t, err, mxerrors.ErrHookFailed) && assert.ErrorContains(t, err, "after hook error")
 			},
 		},
 		{
@@ -592,3 +593,24 @@ func TestCreator_e2e_Many(t *testing.T) {
 		})
 	}
 }
+
+func TestCreator_e2e_DuplicateKey(t *testing.T) {
+	collection := newCollection(t)
+	ctx := context.Background()
+
+	user := &User{ID: bson.NewObjectID(), Name: "Mingyong Chen"}
+	_, err := NewCreator[User](collection).InsertOne(ctx, user)
+	require.NoError(t, err)
+	defer func() {
+		_, err := collection.DeleteOne(ctx, query.Id(user.ID))
+		require.NoError(t, err)
+	}()
+
+	_, err = NewCreator[User](collection).InsertOne(ctx, &User{ID: user.ID, Name: "burt"})
+	require.ErrorIs(t, err, mxerrors.ErrDuplicateKey)
+	require.True(t, mongo.IsDuplicateKeyError(err))
+	var dupErr *mxerrors.DuplicateKeyError
+	require.ErrorAs(t, err, &dupErr)
+	require.Equal(t, bson.D{{Key: "_id", Value: int32(1)}}, dupErr.KeyPattern)
+	require.Equal(t, bson.D{{Key: "_id", Value: user.ID}}, dupErr.KeyValue)
+}
@@ -3,8 +3,11 @@ package deleter
 import (
 	"context"
 
+	"github.com/matiniiuu/mongox/bsonx"
 	"github.com/matiniiuu/mongox/callback"
+	mxerrors "github.com/matiniiuu/mongox/errors"
 	"github.com/matiniiuu/mongox/operation"
+	"github.com/matiniiuu/mongox/softdelete"
 
 	"go.mongodb.org/mongo-driver/v2/mongo"
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
@@ -19,7 +22,7 @@ type IDeleter[T any] interface {
 var _ IDeleter[any] = (*Deleter[any])(nil)
 
 func NewDeleter[T any](collection *mongo.Collection) *Deleter[T] {
-	return &Deleter[T]{collection: collection, filter: nil}
+	return &Deleter[T]{collection: collection, filter: nil, softDelete: softdelete.Enabled()}
 }
 
 type Deleter[T any] struct {
@@ -28,6 +31,8 @@ type Deleter[T any] struct {
 	modelHook   any
 	beforeHooks []beforeHookFn
 	afterHooks  []afterHookFn
+	softDelete  bool
+	force       bool
 }
 
 func (d *Deleter[T]) RegisterBeforeHooks(hooks ...beforeHookFn) *Deleter[T] {
@@ -45,10 +50,12 @@ func (d *Deleter[T]) preActionHandler(ctx context.Context, globalOpContext *oper
 	if err != nil {
 		return err
 	}
-	for _, beforeHook := range d.beforeHooks {
+	// the global callbacks may have changed the filter, e.g. to scope it to a tenant
+	opContext.Filter = globalOpContext.Filter
+	for i, beforeHook := range d.beforeHooks {
 		err = beforeHook(ctx, opContext)
 		if err != nil {
-			return err
+			return mxerrors.Hook(mxerrors.HookName(beforeHook, i), opType, err)
 		}
 	}
 	return nil
@@ -59,10 +66,10 @@ func (d *Deleter[T]) postActionHandler(ctx context.Context, globalOpContext *ope
 	if err != nil {
 		return err
 	}
-	for _, afterHook := range d.afterHooks {
+	for i, afterHook := range d.afterHooks {
 		err = afterHook(ctx, opContext)
 		if err != nil {
-			return err
+			return mxerrors.Hook(mxerrors.HookName(afterHook, i), opType, err)
 		}
 	}
 	return nil
@@ -79,19 +86,40 @@ func (d *Deleter[T]) ModelHook(modelHook any) *Deleter[T] {
 	return d
 }
 
+// SoftDelete is used to turn the soft delete mode on or off for this deleter
+func (d *Deleter[T]) SoftDelete(enabled bool) *Deleter[T] {
+	d.softDelete = enabled
+	return d
+}
+
+// ForceDelete makes the deleter remove the documents physically even if the soft delete mode is on
+func (d *Deleter[T]) ForceDelete() *Deleter[T] {
+	d.force = true
+	return d
+}
+
+func (d *Deleter[T]) isSoftDelete() bool {
+	return d.softDelete && !d.force
+}
+
 func (d *Deleter[T]) DeleteOne(ctx context.Context, opts ...options.Lister[options.DeleteOneOptions]) (*mongo.DeleteResult, error) {
+	if d.isSoftDelete() {
+		return d.softDeleteOne(ctx, opts...)
+	}
+
 	globalPoContext := operation.NewOpContext(d.collection, operation.WithFilter(d.filter), operation.WithMongoOptions(opts), operation.WithModelHook(d.modelHook))
 	err := d.preActionHandler(ctx, globalPoContext, NewOpContext(d.collection, d.filter, WithMongoOptions(opts), WithModelHook(d.modelHook)), operation.OpTypeBeforeDelete)
 	if err != nil {
 		return nil, err
 	}
+	filter := globalPoContext.Filter
 
-	result, err := d.collection.DeleteOne(ctx, d.filter, opts...)
+	result, err := d.collection.DeleteOne(ctx, filter, opts...)
 	if err != nil {
-		return nil, err
+		return nil, mxerrors.Wrap(err)
 	}
 
-	err = d.postActionHandler(ctx, globalPoContext, NewOpContext(d.collection, d.filter, WithMongoOptions(opts), WithModelHook(d.modelHook)), operation.OpTypeAfterDelete)
+	err = d.postActionHandler(ctx, globalPoContext, NewOpContext(d.collection, filter, WithMongoOptions(opts), WithModelHook(d.modelHook)), operation.OpTypeAfterDelete)
 	if err != nil {
 		return nil, err
 	}
@@ -100,21 +128,245 @@ func (d *Deleter[T]) DeleteOne(ctx context.Context, opts ...options.Lister[optio
 }
 
 func (d *Deleter[T]) DeleteMany(ctx context.Context, opts ...options.Lister[options.DeleteManyOptions]) (*mongo.DeleteResult, error) {
+	if d.isSoftDelete() {
+		return d.softDeleteMany(ctx, opts...)
+	}
+
 	globalPoContext := operation.NewOpContext(d.collection, operation.WithFilter(d.filter), operation.WithMongoOptions(opts), operation.WithModelHook(d.modelHook))
 	err := d.preActionHandler(ctx, globalPoContext, NewOpContext(d.collection, d.filter, WithMongoOptions(opts), WithModelHook(d.modelHook)), operation.OpTypeBeforeDelete)
 	if err != nil {
 		return nil, err
 	}
+	filter := globalPoContext.Filter
+
+	result, err := d.collection.DeleteMany(ctx, filter, opts...)
+	if err != nil {
+		return nil, mxerrors.Wrap(err)
+	}
+
+	err = d.postActionHandler(ctx, globalPoContext, NewOpContext(d.collection, filter, WithMongoOptions(opts), WithModelHook(d.modelHook)), operation.OpTypeAfterDelete)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
 
-	result, err := d.collection.DeleteMany(ctx, d.filter, opts...)
+// softDeleteOne marks the first matched document as deleted instead of removing it
+func (d *Deleter[T]) softDeleteOne(ctx context.Context, opts ...options.Lister[options.DeleteOneOptions]) (*mongo.DeleteResult, error) {
+	filter := softdelete.Filter(d.filter, softdelete.ScopeDefault)
+	var updates any = softdelete.DeleteUpdates()
+
+	globalPoContext := operation.NewOpContext(d.collection, operation.WithFilter(filter), operation.WithUpdates(updates), operation.WithMongoOptions(opts), operation.WithModelHook(d.modelHook))
+	err := d.preActionHandler(ctx, globalPoContext, NewOpContext(d.collection, filter, WithMongoOptions(opts), WithModelHook(d.modelHook)), operation.OpTypeBeforeDelete)
 	if err != nil {
 		return nil, err
 	}
+	// the global callbacks may have changed the updates, e.g. to stamp who deleted the document
+	filter, updates = globalPoContext.Filter, globalPoContext.Updates
 
-	err = d.postActionHandler(ctx, globalPoContext, NewOpContext(d.collection, d.filter, WithMongoOptions(opts), WithModelHook(d.modelHook)), operation.OpTypeAfterDelete)
+	updateOpts, err := toUpdateOneOptions(opts...)
 	if err != nil {
 		return nil, err
 	}
+	result, err := d.collection.UpdateOne(ctx, filter, updates, updateOpts)
+	if err != nil {
+		return nil, mxerrors.Wrap(err)
+	}
 
+	err = d.postActionHandler(ctx, globalPoContext, NewOpContext(d.collection, filter, WithMongoOptions(opts), WithModelHook(d.modelHook)), operation.OpTypeAfterDelete)
+	if err != nil {
+		return nil, err
+	}
+
+	return &mongo.DeleteResult{DeletedCount: result.ModifiedCount, Acknowledged: result.Acknowledged}, nil
+}
+
+// softDeleteMany marks all matched documents as deleted instead of removing them
+func (d *Deleter[T]) softDeleteMany(ctx context.Context, opts ...options.Lister[options.DeleteManyOptions]) (*mongo.DeleteResult, error) {
+	filter := softdelete.Filter(d.filter, softdelete.ScopeDefault)
+	var updates any = softdelete.DeleteUpdates()
+
+	globalPoContext := operation.NewOpContext(d.collection, operation.WithFilter(filter), operation.WithUpdates(updates), operation.WithMongoOptions(opts), operation.WithModelHook(d.modelHook))
+	err := d.preActionHandler(ctx, globalPoContext, NewOpContext(d.collection, filter, WithMongoOptions(opts), WithModelHook(d.modelHook)), operation.OpTypeBeforeDelete)
+	if err != nil {
+		return nil, err
+	}
+	// the global callbacks may have changed the updates, e.g. to stamp who deleted the document
+	filter, updates = globalPoContext.Filter, globalPoContext.Updates
+
+	updateOpts, err := toUpdateManyOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+	result, err := d.collection.UpdateMany(ctx, filter, updates, updateOpts)
+	if err != nil {
+		return nil, mxerrors.Wrap(err)
+	}
+
+	err = d.postActionHandler(ctx, globalPoContext, NewOpContext(d.collection, filter, WithMongoOptions(opts), WithModelHook(d.modelHook)), operation.OpTypeAfterDelete)
+	if err != nil {
+		return nil, err
+	}
+
+	return &mongo.DeleteResult{DeletedCount: result.ModifiedCount, Acknowledged: result.Acknowledged}, nil
+}
+
+// FindOneAndDelete is used to delete the first document matched by the filter and return it
+// The global after find and after delete callbacks receive the removed document through opContext.Doc, and so do the after hooks
+// If the soft delete mode is on, the document is marked as deleted and returned with the deletion time
+func (d *Deleter[T]) FindOneAndDelete(ctx context.Context, opts ...options.Lister[options.FindOneAndDeleteOptions]) (*T, error) {
+	t := new(T)
+	globalOpContext := operation.NewOpContext(d.collection, operation.WithFilter(d.filter), operation.WithMongoOptions(opts), operation.WithModelHook(d.modelHook))
+	if d.isSoftDelete() {
+		globalOpContext.Filter = softdelete.Filter(d.filter, softdelete.ScopeDefault)
+		globalOpContext.Updates = softdelete.DeleteUpdates()
+	}
+	err := d.preActionHandler(ctx, globalOpContext, NewOpContext(d.collection, globalOpContext.Filter, WithMongoOptions(opts), WithModelHook(d.modelHook)), operation.OpTypeBeforeDelete)
+	if err != nil {
+		return nil, err
+	}
+	filter := globalOpContext.Filter
+
+	if d.isSoftDelete() {
+		var updateOpts *options.FindOneAndUpdateOptionsBuilder
+		updateOpts, err = toFindOneAndUpdateOptions(opts...)
+		if err != nil {
+			return nil, err
+		}
+		err = d.collection.FindOneAndUpdate(ctx, filter, globalOpContext.Updates, updateOpts).Decode(t)
+	} else {
+		err = d.collection.FindOneAndDelete(ctx, filter, opts...).Decode(t)
+	}
+	if err != nil {
+		return nil, mxerrors.Wrap(err)
+	}
+
+	globalOpContext.Doc = t
+	err = callback.GetCallback().Execute(ctx, globalOpContext, operation.OpTypeAfterFind)
+	if err != nil {
+		return nil, err
+	}
+	err = d.postActionHandler(ctx, globalOpContext, NewOpContext(d.collection, filter, WithDoc(t), WithMongoOptions(opts), WithModelHook(d.modelHook)), operation.OpTypeAfterDelete)
+	if err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// Restore brings back all the soft deleted documents matched by the filter
+// It runs the global update callbacks, so the updated time is refreshed by the default field hook, and the before and
+// after hooks of the deleter
+func (d *Deleter[T]) Restore(ctx context.Context, opts ...options.Lister[options.UpdateManyOptions]) (*mongo.UpdateResult, error) {
+	filter := softdelete.Filter(d.filter, softdelete.ScopeOnlyDeleted)
+	var updates any = bsonx.ToBsonM(softdelete.RestoreUpdates())
+
+	globalOpContext := operation.NewOpContext(d.collection, operation.WithDoc(new(T)), operation.WithFilter(filter), operation.WithUpdates(updates), operation.WithMongoOptions(opts), operation.WithModelHook(d.modelHook))
+	err := d.preActionHandler(ctx, globalOpContext, NewOpContext(d.collection, filter, WithMongoOptions(opts), WithModelHook(d.modelHook)), operation.OpTypeBeforeUpdate)
+	if err != nil {
+		return nil, err
+	}
+	filter, updates = globalOpContext.Filter, globalOpContext.Updates
+
+	result, err := d.collection.UpdateMany(ctx, filter, updates, opts...)
+	if err != nil {
+		return nil, mxerrors.Wrap(err)
+	}
+
+	err = d.postActionHandler(ctx, globalOpContext, NewOpContext(d.collection, filter, WithMongoOptions(opts), WithModelHook(d.modelHook)), operation.OpTypeAfterUpdate)
+	if err != nil {
+		return nil, err
+	}
 	return result, nil
 }
+
+func toUpdateOneOptions(opts ...options.Lister[options.DeleteOneOptions]) (*options.UpdateOneOptionsBuilder, error) {
+	deleteOpts := &options.DeleteOneOptions{}
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		for _, fn := range opt.List() {
+			if err := fn(deleteOpts); err != nil {
+				return nil, err
+			}
+		}
+	}
+	updateOpts := options.UpdateOne()
+	if deleteOpts.Collation != nil {
+		updateOpts.SetCollation(deleteOpts.Collation)
+	}
+	if deleteOpts.Comment != nil {
+		updateOpts.SetComment(deleteOpts.Comment)
+	}
+	if deleteOpts.Hint != nil {
+		updateOpts.SetHint(deleteOpts.Hint)
+	}
+	if deleteOpts.Let != nil {
+		updateOpts.SetLet(deleteOpts.Let)
+	}
+	return updateOpts, nil
+}
+
+func toUpdateManyOptions(opts ...options.Lister[options.DeleteManyOptions]) (*options.UpdateManyOptionsBuilder, error) {
+	deleteOpts := &options.DeleteManyOptions{}
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		for _, fn := range opt.List() {
+			if err := fn(deleteOpts); err != nil {
+				return nil, err
+			}
+		}
+	}
+	updateOpts := options.UpdateMany()
+	if deleteOpts.Collation != nil {
+		updateOpts.SetCollation(deleteOpts.Collation)
+	}
+	if deleteOpts.Comment != nil {
+		updateOpts.SetComment(deleteOpts.Comment)
+	}
+	if deleteOpts.Hint != nil {
+		updateOpts.SetHint(deleteOpts.Hint)
+	}
+	if deleteOpts.Let != nil {
+		updateOpts.SetLet(deleteOpts.Let)
+	}
+	return updateOpts, nil
+}
+
+func toFindOneAndUpdateOptions(opts ...options.Lister[options.FindOneAndDeleteOptions]) (*options.FindOneAndUpdateOptionsBuilder, error) {
+	deleteOpts := &options.FindOneAndDeleteOptions{}
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		for _, fn := range opt.List() {
+			if err := fn(deleteOpts); err != nil {
+				return nil, err
+			}
+		}
+	}
+	updateOpts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	if deleteOpts.Collation != nil {
+		updateOpts.SetCollation(deleteOpts.Collation)
+	}
+	if deleteOpts.Comment != nil {
+		updateOpts.SetComment(deleteOpts.Comment)
+	}
+	if deleteOpts.Projection != nil {
+		updateOpts.SetProjection(deleteOpts.Projection)
+	}
+	if deleteOpts.Sort != nil {
+		updateOpts.SetSort(deleteOpts.Sort)
+	}
+	if deleteOpts.Hint != nil {
+		updateOpts.SetHint(deleteOpts.Hint)
+	}
+	if deleteOpts.Let != nil {
+		updateOpts.SetLet(deleteOpts.Let)
+	}
+	return updateOpts, nil
+}
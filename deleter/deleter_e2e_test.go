@@ -6,10 +6,12 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/matiniiuu/mongox/internal/pkg/utils"
 
 	"github.com/matiniiuu/mongox/callback"
+	mxerrors "github.com/matiniiuu/mongox/errors"
 	"github.com/matiniiuu/mongox/operation"
 
 	"github.com/stretchr/testify/require"
@@ -588,3 +590,122 @@ func TestDeleter_e2e_DeleteMany(t *testing.T) {
 		})
 	}
 }
+
+func TestDeleter_e2e_SoftDelete(t *testing.T) {
+	collection := newCollection(t)
+	ctx := context.Background()
+
+	type softDeleteUser struct {
+		Id        string     `bson:"_id"`
+		Name      string     `bson:"name"`
+		DeletedAt *time.Time `bson:"deletedAt,omitempty"`
+	}
+
+	_, err := collection.InsertMany(ctx, []any{
+		softDeleteUser{Id: "1", Name: "Mingyong Chen"},
+		softDeleteUser{Id: "2", Name: "Mingyong Chen"},
+	})
+	require.NoError(t, err)
+	defer func() {
+		_, err := collection.DeleteMany(ctx, query.NewBuilder().InString("_id", "1", "2").Build())
+		require.NoError(t, err)
+	}()
+
+	// soft delete one document
+	result, err := NewDeleter[softDeleteUser](collection).SoftDelete(true).Filter(query.NewBuilder().Id("1").Build()).DeleteOne(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), result.DeletedCount)
+
+	user := &softDeleteUser{}
+	require.NoError(t, collection.FindOne(ctx, query.NewBuilder().Id("1").Build()).Decode(user))
+	require.NotNil(t, user.DeletedAt)
+
+	// deleting a soft deleted document again does nothing
+	result, err = NewDeleter[softDeleteUser](collection).SoftDelete(true).Filter(query.NewBuilder().Id("1").Build()).DeleteOne(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(0), result.DeletedCount)
+
+	// soft delete many documents
+	result, err = NewDeleter[softDeleteUser](collection).SoftDelete(true).Filter(query.Eq("name", "Mingyong Chen")).DeleteMany(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), result.DeletedCount)
+
+	// restore
+	updateResult, err := NewDeleter[softDeleteUser](collection).SoftDelete(true).Filter(query.NewBuilder().Id("1").Build()).Restore(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), updateResult.ModifiedCount)
+
+	user = &softDeleteUser{}
+	require.NoError(t, collection.FindOne(ctx, query.NewBuilder().Id("1").Build()).Decode(user))
+	require.Nil(t, user.DeletedAt)
+
+	// force delete
+	result, err = NewDeleter[softDeleteUser](collection).SoftDelete(true).ForceDelete().Filter(query.NewBuilder().Id("2").Build()).DeleteOne(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), result.DeletedCount)
+	require.ErrorIs(t, collection.FindOne(ctx, query.NewBuilder().Id("2").Build()).Err(), mongo.ErrNoDocuments)
+}
+
+func TestDeleter_e2e_FindOneAndDelete(t *testing.T) {
+	collection := newCollection(t)
+	ctx := context.Background()
+
+	type job struct {
+		Id        string     `bson:"_id"`
+		Name      string     `bson:"name"`
+		Age       int64      `bson:"age"`
+		DeletedAt *time.Time `bson:"deletedAt,omitempty"`
+	}
+
+	_, err := collection.InsertMany(ctx, []any{
+		job{Id: "1", Name: "Mingyong Chen", Age: 18},
+		job{Id: "2", Name: "Mingyong Chen", Age: 19},
+		job{Id: "3", Name: "burt", Age: 20},
+	})
+	require.NoError(t, err)
+	defer func() {
+		_, err := collection.DeleteMany(ctx, query.NewBuilder().InString("_id", "1", "2", "3").Build())
+		require.NoError(t, err)
+	}()
+
+	t.Run("delete and return the document", func(t *testing.T) {
+		var (
+			afterFindDoc   any
+			afterDeleteDoc any
+		)
+		callback.GetCallback().Register(operation.OpTypeAfterFind, "after find", func(_ context.Context, opCtx *operation.OpContext, _ ...any) error {
+			afterFindDoc = opCtx.Doc
+			return nil
+		})
+		defer callback.GetCallback().Remove(operation.OpTypeAfterFind, "after find")
+
+		got, err := NewDeleter[job](collection).Filter(query.Eq("name", "Mingyong Chen")).
+			RegisterAfterHooks(func(_ context.Context, opContext *OpContext, _ ...any) error {
+				afterDeleteDoc = opContext.Doc
+				return nil
+			}).
+			FindOneAndDelete(ctx, options.FindOneAndDelete().SetSort(bson.D{{Key: "age", Value: -1}}))
+		require.NoError(t, err)
+		require.Equal(t, "2", got.Id)
+		require.Equal(t, got, afterFindDoc)
+		require.Equal(t, got, afterDeleteDoc)
+		require.ErrorIs(t, collection.FindOne(ctx, query.NewBuilder().Id("2").Build()).Err(), mongo.ErrNoDocuments)
+	})
+
+	t.Run("no document", func(t *testing.T) {
+		_, err := NewDeleter[job](collection).Filter(query.Eq("name", "nobody")).FindOneAndDelete(ctx)
+		require.ErrorIs(t, err, mxerrors.ErrNotFound)
+		require.ErrorIs(t, err, mongo.ErrNoDocuments)
+	})
+
+	t.Run("soft delete", func(t *testing.T) {
+		got, err := NewDeleter[job](collection).SoftDelete(true).Filter(query.Eq("name", "burt")).FindOneAndDelete(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "3", got.Id)
+		require.NotNil(t, got.DeletedAt)
+
+		_, err = NewDeleter[job](collection).SoftDelete(true).Filter(query.Eq("name", "burt")).FindOneAndDelete(ctx)
+		require.ErrorIs(t, err, mxerrors.ErrNotFound)
+		require.ErrorIs(t, err, mongo.ErrNoDocuments)
+	})
+}
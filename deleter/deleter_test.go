@@ -3,14 +3,22 @@ package deleter
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
+	"github.com/matiniiuu/mongox/callback"
+	mxerrors "github.com/matiniiuu/mongox/errors"
 	mocks "github.com/matiniiuu/mongox/mock"
+	"github.com/matiniiuu/mongox/operation"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/event"
 	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"go.mongodb.org/mongo-driver/v2/x/mongo/driver/drivertest"
 	"go.uber.org/mock/gomock"
 )
 
@@ -169,3 +177,183 @@ func TestDeleter_DeleteMany(t *testing.T) {
 		})
 	}
 }
+
+func TestDeleter_isSoftDelete(t *testing.T) {
+	assert.False(t, NewDeleter[TestUser](&mongo.Collection{}).isSoftDelete())
+	assert.True(t, NewDeleter[TestUser](&mongo.Collection{}).SoftDelete(true).isSoftDelete())
+	assert.False(t, NewDeleter[TestUser](&mongo.Collection{}).SoftDelete(true).ForceDelete().isSoftDelete())
+}
+
+func Test_toUpdateOneOptions(t *testing.T) {
+	updateOpts, err := toUpdateOneOptions(options.DeleteOne().SetComment("test").SetHint("name_1"), nil)
+	assert.NoError(t, err)
+
+	got := &options.UpdateOneOptions{}
+	for _, fn := range updateOpts.List() {
+		assert.NoError(t, fn(got))
+	}
+	assert.Equal(t, "test", got.Comment)
+	assert.Equal(t, "name_1", got.Hint)
+	assert.Nil(t, got.Collation)
+	assert.Nil(t, got.Let)
+}
+
+func Test_toUpdateManyOptions(t *testing.T) {
+	updateOpts, err := toUpdateManyOptions(options.DeleteMany().SetComment("test").SetLet(bson.M{"name": "Mingyong Chen"}))
+	assert.NoError(t, err)
+
+	got := &options.UpdateManyOptions{}
+	for _, fn := range updateOpts.List() {
+		assert.NoError(t, fn(got))
+	}
+	assert.Equal(t, "test", got.Comment)
+	assert.Equal(t, bson.M{"name": "Mingyong Chen"}, got.Let)
+	assert.Nil(t, got.Hint)
+}
+
+func Test_toFindOneAndUpdateOptions(t *testing.T) {
+	updateOpts, err := toFindOneAndUpdateOptions(options.FindOneAndDelete().SetSort(bson.D{{Key: "age", Value: 1}}).SetProjection(bson.M{"name": 1}))
+	assert.NoError(t, err)
+
+	got := &options.FindOneAndUpdateOptions{}
+	for _, fn := range updateOpts.List() {
+		assert.NoError(t, fn(got))
+	}
+	assert.Equal(t, bson.D{{Key: "age", Value: 1}}, got.Sort)
+	assert.Equal(t, bson.M{"name": 1}, got.Projection)
+	assert.Equal(t, options.After, *got.ReturnDocument)
+	assert.Nil(t, got.Collation)
+}
+
+// newMockCollection returns a collection whose commands are answered with the responses in order
+func newMockCollection(t *testing.T, responses ...bson.D) *mongo.Collection {
+	return newRecordedCollection(t, nil, responses...)
+}
+
+// newRecordedCollection is the same as newMockCollection but also appends the sent commands to commands if it is not nil
+func newRecordedCollection(t *testing.T, commands *[]bson.Raw, responses ...bson.D) *mongo.Collection {
+	clientOpts := options.Client().SetMonitor(&event.CommandMonitor{
+		Started: func(_ context.Context, e *event.CommandStartedEvent) {
+			if commands != nil {
+				*commands = append(*commands, e.Command)
+			}
+		},
+	})
+	clientOpts.Deployment = drivertest.NewMockDeployment(responses...)
+	client, err := mongo.Connect(clientOpts)
+	require.NoError(t, err)
+	return client.Database("db-test").Collection("test_user")
+}
+
+func TestDeleter_softDeleteUpdates(t *testing.T) {
+	callback.GetCallback().Register(operation.OpTypeBeforeDelete, "deletedBy", func(_ context.Context, opCtx *operation.OpContext, _ ...any) error {
+		if opCtx.Updates != nil {
+			opCtx.Updates = bson.D{{Key: "$set", Value: bson.D{{Key: "deletedBy", Value: "admin"}}}}
+		}
+		return nil
+	})
+	defer callback.GetCallback().Remove(operation.OpTypeBeforeDelete, "deletedBy")
+
+	updated := bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}, {Key: "nModified", Value: 1}}
+	testCases := []struct {
+		name     string
+		response bson.D
+		delete   func(d *Deleter[TestUser]) error
+		// path is the path of the deletedBy field in the sent command
+		path []string
+	}{
+		{
+			name:     "delete one",
+			response: updated,
+			delete: func(d *Deleter[TestUser]) error {
+				_, err := d.DeleteOne(context.Background())
+				return err
+			},
+			path: []string{"updates", "0", "u", "$set", "deletedBy"},
+		},
+		{
+			name:     "delete many",
+			response: updated,
+			delete: func(d *Deleter[TestUser]) error {
+				_, err := d.DeleteMany(context.Background())
+				return err
+			},
+			path: []string{"updates", "0", "u", "$set", "deletedBy"},
+		},
+		{
+			name:     "find one and delete",
+			response: bson.D{{Key: "ok", Value: 1}, {Key: "value", Value: bson.D{{Key: "name", Value: "burt"}}}},
+			delete: func(d *Deleter[TestUser]) error {
+				_, err := d.FindOneAndDelete(context.Background())
+				return err
+			},
+			path: []string{"update", "$set", "deletedBy"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var commands []bson.Raw
+			collection := newRecordedCollection(t, &commands, tc.response)
+			require.NoError(t, tc.delete(NewDeleter[TestUser](collection).SoftDelete(true).Filter(bson.D{{Key: "name", Value: "burt"}})))
+			require.Len(t, commands, 1)
+			deletedBy, err := commands[0].LookupErr(tc.path...)
+			require.NoError(t, err)
+			assert.Equal(t, "admin", deletedBy.StringValue())
+		})
+	}
+}
+
+func TestDeleter_Restore(t *testing.T) {
+	callback.GetCallback().Register(operation.OpTypeBeforeUpdate, "restoredBy", func(_ context.Context, opCtx *operation.OpContext, _ ...any) error {
+		opCtx.Updates = bson.D{{Key: "$unset", Value: bson.D{{Key: "deletedAt", Value: ""}}}, {Key: "$set", Value: bson.D{{Key: "restoredBy", Value: "admin"}}}}
+		return nil
+	})
+	defer callback.GetCallback().Remove(operation.OpTypeBeforeUpdate, "restoredBy")
+
+	var commands []bson.Raw
+	var hooks []string
+	collection := newRecordedCollection(t, &commands, bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}, {Key: "nModified", Value: 1}})
+	_, err := NewDeleter[TestUser](collection).Filter(bson.D{{Key: "name", Value: "burt"}}).
+		RegisterBeforeHooks(func(_ context.Context, _ *OpContext, _ ...any) error {
+			hooks = append(hooks, "before")
+			return nil
+		}).
+		RegisterAfterHooks(func(_ context.Context, _ *OpContext, _ ...any) error {
+			hooks = append(hooks, "after")
+			return nil
+		}).
+		Restore(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"before", "after"}, hooks)
+	require.Len(t, commands, 1)
+	restoredBy, err := commands[0].LookupErr("updates", "0", "u", "$set", "restoredBy")
+	require.NoError(t, err)
+	assert.Equal(t, "admin", restoredBy.StringValue())
+
+	_, err = NewDeleter[TestUser](newMockCollection(t)).
+		RegisterBeforeHooks(func(_ context.Context, _ *OpContext, _ ...any) error {
+			return assert.AnError
+		}).
+		Restore(context.Background())
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.ErrorIs(t, err, mxerrors.ErrHookFailed)
+}
+
+func TestDeleter_FindOneAndDelete_noDocument(t *testing.T) {
+	for _, softDelete := range []bool{false, true} {
+		t.Run(fmt.Sprintf("soft delete %t", softDelete), func(t *testing.T) {
+			collection := newMockCollection(t, bson.D{{Key: "ok", Value: 1}, {Key: "value", Value: nil}})
+			var afterDelete bool
+			user, err := NewDeleter[TestUser](collection).SoftDelete(softDelete).Filter(bson.D{{Key: "name", Value: "nobody"}}).
+				RegisterAfterHooks(func(_ context.Context, _ *OpContext, _ ...any) error {
+					afterDelete = true
+					return nil
+				}).
+				FindOneAndDelete(context.Background())
+			require.ErrorIs(t, err, mxerrors.ErrNotFound)
+			require.ErrorIs(t, err, mongo.ErrNoDocuments)
+			assert.Nil(t, user)
+			assert.False(t, afterDelete)
+		})
+	}
+}
@@ -21,6 +21,12 @@ func NewOpContext(col *mongo.Collection, filter any, opts ...OpContextOption) *O
 	return opContext
 }
 
+func WithDoc(doc any) OpContextOption {
+	return func(opContext *OpContext) {
+		opContext.Doc = doc
+	}
+}
+
 func WithMongoOptions(mongoOptions any) OpContextOption {
 	return func(opContext *OpContext) {
 		opContext.MongoOptions = mongoOptions
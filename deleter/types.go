@@ -8,8 +8,10 @@ import (
 
 //go:generate optioner -type OpContext
 type OpContext struct {
-	Col          *mongo.Collection `opt:"-"`
-	Filter       any               `opt:"-"`
+	Col    *mongo.Collection `opt:"-"`
+	Filter any               `opt:"-"`
+	// Doc is the removed document, it is only set for the after hooks of FindOneAndDelete
+	Doc          any
 	MongoOptions any
 	ModelHook    any
 }
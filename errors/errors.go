@@ -0,0 +1,222 @@
+// Package errors classifies the errors returned by the operators, so that the callers can check them with errors.Is
+// and errors.As without depending on the driver. The classified errors still wrap the driver errors they come from
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/matiniiuu/mongox/operation"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+var (
+	// ErrNotFound is returned when no document matches the filter of an operation which needs one, e.g. Finder.FindOne
+	ErrNotFound = errors.New("mongox: document not found")
+	// ErrDuplicateKey is returned when a write violates a unique index, see DuplicateKeyError for the key
+	ErrDuplicateKey = errors.New("mongox: duplicate key")
+	// ErrValidation is returned when a document or an update is rejected by the validation hook, see ValidationError
+	ErrValidation = errors.New("mongox: validation failed")
+	// ErrHookFailed is returned when a callback or a hook of an operator fails, see HookError
+	ErrHookFailed = errors.New("mongox: hook failed")
+	// ErrVersionConflict is returned when the document to update has been changed since its version was read
+	ErrVersionConflict = errors.New("mongox: version conflict, the document has been changed")
+	// ErrVersionRequired is returned when a single document of a model with a version field is updated without the
+	// version it was read with, which would update it without the optimistic locking
+	ErrVersionRequired = errors.New("mongox: the version read before the update is required for the optimistic locking")
+)
+
+// DuplicateKeyError is the error of a write which violates a unique index
+type DuplicateKeyError struct {
+	// KeyPattern is the keys of the violated index, e.g. {email: 1}, it is nil if the server does not report it
+	KeyPattern bson.D
+	// KeyValue is the duplicated values of the keys, e.g. {email: "cmy@example.com"}
+	KeyValue bson.D
+	Err      error
+}
+
+func (e *DuplicateKeyError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrDuplicateKey, e.Err)
+}
+
+func (e *DuplicateKeyError) Unwrap() []error {
+	return []error{ErrDuplicateKey, e.Err}
+}
+
+// ValidationError is the error of the validation hook
+type ValidationError struct {
+	// Fields is the errors of the fields which failed their validate tags
+	Fields validator.ValidationErrors
+	Err    error
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrValidation, e.Err)
+}
+
+func (e *ValidationError) Unwrap() []error {
+	return []error{ErrValidation, e.Err}
+}
+
+// HookError is the error of a global callback or of a hook registered on an operator
+type HookError struct {
+	// Hook is the name the global callback is registered with, or the name of the hook registered on an operator,
+	// see HookName
+	Hook   string
+	OpType operation.OpType
+	Err    error
+}
+
+func (e *HookError) Error() string {
+	if e.Hook == "" {
+		return fmt.Sprintf("%s on %s: %s", ErrHookFailed, e.OpType, e.Err)
+	}
+	return fmt.Sprintf("%s: %s on %s: %s", ErrHookFailed, e.Hook, e.OpType, e.Err)
+}
+
+func (e *HookError) Unwrap() []error {
+	return []error{ErrHookFailed, e.Err}
+}
+
+// Hook wraps the error of the hook into a HookError, an error which already is one is returned as is
+func Hook(name string, opType operation.OpType, err error) error {
+	if err == nil {
+		return nil
+	}
+	var hookErr *HookError
+	if errors.As(err, &hookErr) {
+		return err
+	}
+	return &HookError{Hook: name, OpType: opType, Err: err}
+}
+
+// HookName returns the name of a hook registered on an operator for HookError, i.e. the name of its function such as
+// "main.checkOwner" or "main.main.func1" for a closure, or its position among the hooks of the operator if the
+// function is unknown
+func HookName(hook any, index int) string {
+	value := reflect.ValueOf(hook)
+	if value.Kind() == reflect.Func && !value.IsNil() {
+		if fn := runtime.FuncForPC(value.Pointer()); fn != nil {
+			return fn.Name()
+		}
+	}
+	return fmt.Sprintf("hook %d", index)
+}
+
+// Validation wraps the error of a validation into a ValidationError which collects its field errors
+func Validation(err error) error {
+	if err == nil {
+		return nil
+	}
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		return err
+	}
+	return &ValidationError{Fields: fieldErrors(err), Err: err}
+}
+
+// Wrap classifies the errors of the driver, mongo.ErrNoDocuments becomes ErrNotFound and the duplicate key errors
+// become DuplicateKeyError, the other errors and the ones already classified are returned as is
+func Wrap(err error) error {
+	if err == nil || classified(err) {
+		return err
+	}
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return fmt.Errorf("%w: %w", ErrNotFound, err)
+	}
+	if mongo.IsDuplicateKeyError(err) {
+		dupErr := &DuplicateKeyError{Err: err}
+		if raw := duplicateKeyRaw(err); raw != nil {
+			dupErr.KeyPattern, dupErr.KeyValue = lookupD(raw, "keyPattern"), lookupD(raw, "keyValue")
+		}
+		return dupErr
+	}
+	return err
+}
+
+// IsRetryable reports whether the operation failed for a transient reason and may succeed if it is run again,
+// i.e. a network error or a server error labelled as a retryable write or a transient transaction error
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if mongo.IsNetworkError(err) {
+		return true
+	}
+	var labeled mongo.LabeledError
+	return errors.As(err, &labeled) &&
+		(labeled.HasErrorLabel("RetryableWriteError") || labeled.HasErrorLabel("TransientTransactionError"))
+}
+
+func classified(err error) bool {
+	for _, target := range []error{ErrNotFound, ErrDuplicateKey, ErrValidation, ErrHookFailed, ErrVersionConflict} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// duplicateKeyRaw returns the server document of the duplicate key error, which reports the key pattern and value
+func duplicateKeyRaw(err error) bson.Raw {
+	var we mongo.WriteError
+	if errors.As(err, &we) {
+		return we.Raw
+	}
+	var writeErr mongo.WriteException
+	if errors.As(err, &writeErr) {
+		for _, we := range writeErr.WriteErrors {
+			if mongo.IsDuplicateKeyError(we) {
+				return we.Raw
+			}
+		}
+	}
+	var bulkErr mongo.BulkWriteException
+	if errors.As(err, &bulkErr) {
+		for _, we := range bulkErr.WriteErrors {
+			if mongo.IsDuplicateKeyError(we.WriteError) {
+				return we.Raw
+			}
+		}
+	}
+	var cmdErr mongo.CommandError
+	if errors.As(err, &cmdErr) {
+		return cmdErr.Raw
+	}
+	return nil
+}
+
+func lookupD(raw bson.Raw, key string) bson.D {
+	doc, ok := raw.Lookup(key).DocumentOK()
+	if !ok {
+		return nil
+	}
+	var d bson.D
+	if bson.Unmarshal(doc, &d) != nil {
+		return nil
+	}
+	return d
+}
+
+// fieldErrors collects the field errors of the validator wrapped in the error, also the joined ones
+func fieldErrors(err error) validator.ValidationErrors {
+	var result validator.ValidationErrors
+	switch e := err.(type) {
+	case validator.ValidationErrors:
+		return e
+	case interface{ Unwrap() []error }:
+		for _, inner := range e.Unwrap() {
+			result = append(result, fieldErrors(inner)...)
+		}
+	case interface{ Unwrap() error }:
+		if inner := e.Unwrap(); inner != nil {
+			return fieldErrors(inner)
+		}
+	}
+	return result
+}
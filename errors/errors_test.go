@@ -0,0 +1,149 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/matiniiuu/mongox/operation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+func duplicateKeyRawOf(t *testing.T) bson.Raw {
+	raw, err := bson.Marshal(bson.D{
+		{Key: "keyPattern", Value: bson.D{{Key: "email", Value: int32(1)}}},
+		{Key: "keyValue", Value: bson.D{{Key: "email", Value: "cmy@example.com"}}},
+	})
+	require.NoError(t, err)
+	return raw
+}
+
+func TestWrap(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		assert.NoError(t, Wrap(nil))
+	})
+
+	t.Run("no documents", func(t *testing.T) {
+		err := Wrap(mongo.ErrNoDocuments)
+		assert.ErrorIs(t, err, ErrNotFound)
+		assert.ErrorIs(t, err, mongo.ErrNoDocuments)
+		assert.Equal(t, "mongox: document not found: mongo: no documents in result", err.Error())
+	})
+
+	t.Run("duplicate key of a write", func(t *testing.T) {
+		writeErr := mongo.WriteException{WriteErrors: mongo.WriteErrors{
+			{Index: 0, Code: 11000, Message: "E11000 duplicate key error", Raw: duplicateKeyRawOf(t)},
+		}}
+		err := Wrap(writeErr)
+		assert.ErrorIs(t, err, ErrDuplicateKey)
+		assert.True(t, mongo.IsDuplicateKeyError(err))
+		var dupErr *DuplicateKeyError
+		require.ErrorAs(t, err, &dupErr)
+		assert.Equal(t, bson.D{{Key: "email", Value: int32(1)}}, dupErr.KeyPattern)
+		assert.Equal(t, bson.D{{Key: "email", Value: "cmy@example.com"}}, dupErr.KeyValue)
+	})
+
+	t.Run("duplicate key of a bulk write", func(t *testing.T) {
+		bulkErr := mongo.BulkWriteException{WriteErrors: []mongo.BulkWriteError{
+			{WriteError: mongo.WriteError{Index: 1, Code: 11000, Message: "E11000 duplicate key error", Raw: duplicateKeyRawOf(t)}},
+		}}
+		var dupErr *DuplicateKeyError
+		require.ErrorAs(t, Wrap(bulkErr), &dupErr)
+		assert.Equal(t, bson.D{{Key: "email", Value: "cmy@example.com"}}, dupErr.KeyValue)
+	})
+
+	t.Run("duplicate key without key", func(t *testing.T) {
+		var dupErr *DuplicateKeyError
+		require.ErrorAs(t, Wrap(mongo.CommandError{Code: 11000, Message: "E11000 duplicate key error"}), &dupErr)
+		assert.Nil(t, dupErr.KeyPattern)
+		assert.Nil(t, dupErr.KeyValue)
+	})
+
+	t.Run("other error", func(t *testing.T) {
+		assert.Equal(t, mongo.ErrNilDocument, Wrap(mongo.ErrNilDocument))
+	})
+
+	t.Run("already classified", func(t *testing.T) {
+		err := Wrap(mongo.ErrNoDocuments)
+		assert.Equal(t, err, Wrap(err))
+		hookErr := Hook("tenant", operation.OpTypeBeforeFind, mongo.ErrNoDocuments)
+		assert.Equal(t, hookErr, Wrap(hookErr))
+	})
+}
+
+func TestHook(t *testing.T) {
+	assert.NoError(t, Hook("tenant", operation.OpTypeBeforeFind, nil))
+
+	err := Hook("tenant", operation.OpTypeBeforeFind, assert.AnError)
+	assert.ErrorIs(t, err, ErrHookFailed)
+	assert.ErrorIs(t, err, assert.AnError)
+	var hookErr *HookError
+	require.ErrorAs(t, err, &hookErr)
+	assert.Equal(t, "tenant", hookErr.Hook)
+	assert.Equal(t, operation.OpTypeBeforeFind, hookErr.OpType)
+	assert.Equal(t, "mongox: hook failed: tenant on beforeFind: "+assert.AnError.Error(), err.Error())
+
+	// an error which already carries the failed callback is not wrapped again
+	wrapped := fmt.Errorf("hook: %w", err)
+	assert.Equal(t, wrapped, Hook("", operation.OpTypeBeforeUpdate, wrapped))
+	assert.Equal(t, "mongox: hook failed on beforeInsert: "+assert.AnError.Error(), Hook("", operation.OpTypeBeforeInsert, assert.AnError).Error())
+}
+
+func checkOwner() error {
+	return nil
+}
+
+func TestHookName(t *testing.T) {
+	assert.Equal(t, "github.com/matiniiuu/mongox/errors.checkOwner", HookName(checkOwner, 0))
+	assert.Equal(t, "github.com/matiniiuu/mongox/errors.TestHookName.func1", HookName(func() {}, 1))
+	var hook func()
+	assert.Equal(t, "hook 2", HookName(hook, 2))
+	assert.Equal(t, "hook 3", HookName(nil, 3))
+}
+
+type user struct {
+	Name string `validate:"required"`
+	Age  int    `validate:"gte=0"`
+}
+
+func TestValidation(t *testing.T) {
+	assert.NoError(t, Validation(nil))
+
+	validateErr := validator.New().Struct(user{Age: -1})
+	err := Validation(errors.Join(validateErr, errors.New("mongox: invalid update")))
+	assert.ErrorIs(t, err, ErrValidation)
+	var fieldErrs validator.ValidationErrors
+	assert.ErrorAs(t, err, &fieldErrs)
+	var validationErr *ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	require.Len(t, validationErr.Fields, 2)
+	assert.Equal(t, "required", validationErr.Fields[0].Tag())
+	assert.Equal(t, "gte", validationErr.Fields[1].Tag())
+	assert.Equal(t, err, Validation(err))
+}
+
+func TestIsRetryable(t *testing.T) {
+	testCases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil, want: false},
+		{name: "not found", err: Wrap(mongo.ErrNoDocuments), want: false},
+		{name: "network error", err: mongo.CommandError{Labels: []string{"NetworkError"}}, want: true},
+		{name: "retryable write error", err: mongo.CommandError{Code: 91, Labels: []string{"RetryableWriteError"}}, want: true},
+		{name: "transient transaction error", err: fmt.Errorf("commit: %w", mongo.CommandError{Labels: []string{"TransientTransactionError"}}), want: true},
+		{name: "duplicate key", err: Wrap(mongo.CommandError{Code: 11000}), want: false},
+		{name: "hook error", err: Hook("", operation.OpTypeBeforeInsert, mongo.CommandError{Labels: []string{"RetryableWriteError"}}), want: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, IsRetryable(tc.err))
+		})
+	}
+}
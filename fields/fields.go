@@ -0,0 +1,190 @@
+package fields
+
+import (
+	"github.com/matiniiuu/mongox/builder/query"
+	"github.com/matiniiuu/mongox/builder/update"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Numeric is the constraint of the values of a Number field
+type Numeric interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 | ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~float32 | ~float64
+}
+
+// Field is the typed descriptor of a model field whose values are of type V, usually generated by mongox-gen
+// The conditions and updates it builds are the same documents as those of the query and update packages,
+// so they can be passed to query.And, update.NewBuilder, aggregation.StageBuilder.Match and so on.
+// The methods of the builders which take a key are given the path of the field by String
+type Field[V any] struct {
+	path string
+}
+
+func New[V any](path string) Field[V] {
+	return Field[V]{path: path}
+}
+
+// Path returns the dotted bson path of the field
+func (f Field[V]) Path() string {
+	return f.path
+}
+
+// String returns the dotted bson path of the field
+func (f Field[V]) String() string {
+	return f.path
+}
+
+// Ref returns the field path expression of the field, e.g. "$age", for the aggregation expressions
+func (f Field[V]) Ref() string {
+	return "$" + f.path
+}
+
+// Asc returns the ascending sort element of the field
+func (f Field[V]) Asc() bson.E {
+	return bson.E{Key: f.path, Value: 1}
+}
+
+// Desc returns the descending sort element of the field
+func (f Field[V]) Desc() bson.E {
+	return bson.E{Key: f.path, Value: -1}
+}
+
+func (f Field[V]) Eq(value V) bson.D {
+	return query.Eq(f.path, value)
+}
+
+func (f Field[V]) Ne(value V) bson.D {
+	return query.Ne(f.path, value)
+}
+
+func (f Field[V]) Gt(value V) bson.D {
+	return query.Gt(f.path, value)
+}
+
+func (f Field[V]) Gte(value V) bson.D {
+	return query.Gte(f.path, value)
+}
+
+func (f Field[V]) Lt(value V) bson.D {
+	return query.Lt(f.path, value)
+}
+
+func (f Field[V]) Lte(value V) bson.D {
+	return query.Lte(f.path, value)
+}
+
+func (f Field[V]) In(values ...V) bson.D {
+	return query.In(f.path, values...)
+}
+
+func (f Field[V]) NIn(values ...V) bson.D {
+	return query.NIn(f.path, values...)
+}
+
+func (f Field[V]) Exists(exists bool) bson.D {
+	return query.Exists(f.path, exists)
+}
+
+func (f Field[V]) Type(typ bson.Type) bson.D {
+	return query.Type(f.path, typ)
+}
+
+func (f Field[V]) Set(value V) bson.D {
+	return update.Set(f.path, value)
+}
+
+func (f Field[V]) SetOnInsert(value V) bson.D {
+	return update.SetOnInsert(f.path, value)
+}
+
+func (f Field[V]) Unset() bson.D {
+	return update.Unset(f.path)
+}
+
+func (f Field[V]) Min(value V) bson.D {
+	return update.Min(f.path, value)
+}
+
+func (f Field[V]) Max(value V) bson.D {
+	return update.Max(f.path, value)
+}
+
+// Number is the descriptor of a numeric field
+type Number[V Numeric] struct {
+	Field[V]
+}
+
+func NewNumber[V Numeric](path string) Number[V] {
+	return Number[V]{Field: New[V](path)}
+}
+
+func (f Number[V]) Inc(value V) bson.D {
+	return update.Inc(f.path, value)
+}
+
+func (f Number[V]) Mul(value V) bson.D {
+	return update.Mul(f.path, value)
+}
+
+func (f Number[V]) Mod(divisor V, remainder int) bson.D {
+	return query.Mod(f.path, divisor, remainder)
+}
+
+// String is the descriptor of a string field
+type String[V ~string] struct {
+	Field[V]
+}
+
+func NewString[V ~string](path string) String[V] {
+	return String[V]{Field: New[V](path)}
+}
+
+func (f String[V]) Regex(pattern string) bson.D {
+	return query.Regex(f.path, pattern)
+}
+
+func (f String[V]) RegexOptions(pattern, options string) bson.D {
+	return query.RegexOptions(f.path, pattern, options)
+}
+
+// Array is the descriptor of a slice field whose elements are of type E
+type Array[E any] struct {
+	Field[[]E]
+}
+
+func NewArray[E any](path string) Array[E] {
+	return Array[E]{Field: New[[]E](path)}
+}
+
+// Contains matches the documents whose array contains the element
+func (f Array[E]) Contains(element E) bson.D {
+	return bson.D{bson.E{Key: f.path, Value: element}}
+}
+
+func (f Array[E]) All(elements ...E) bson.D {
+	return bson.D{bson.E{Key: f.path, Value: query.All(elements...)}}
+}
+
+func (f Array[E]) Size(size int) bson.D {
+	return query.Size(f.path, size)
+}
+
+func (f Array[E]) ElemMatch(cond any) bson.D {
+	return query.ElemMatch(f.path, cond)
+}
+
+func (f Array[E]) Push(element E) bson.D {
+	return update.Push(f.path, element)
+}
+
+func (f Array[E]) AddToSet(element E) bson.D {
+	return update.AddToSet(f.path, element)
+}
+
+func (f Array[E]) Pull(element E) bson.D {
+	return update.Pull(f.path, element)
+}
+
+func (f Array[E]) PullAll(elements ...E) bson.D {
+	return update.PullAll(f.path, elements...)
+}
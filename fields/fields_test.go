@@ -0,0 +1,71 @@
+package fields
+
+import (
+	"testing"
+
+	"github.com/matiniiuu/mongox/builder/aggregation"
+	"github.com/matiniiuu/mongox/builder/query"
+	"github.com/matiniiuu/mongox/builder/update"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+type status string
+
+func TestField(t *testing.T) {
+	name := NewString[string]("profile.name")
+	age := NewNumber[int]("age")
+	tags := NewArray[string]("tags")
+	state := NewString[status]("status")
+
+	testCases := []struct {
+		name string
+		got  any
+		want any
+	}{
+		{name: "path", got: name.Path(), want: "profile.name"},
+		{name: "string", got: age.String(), want: "age"},
+		{name: "ref", got: age.Ref(), want: "$age"},
+		{name: "asc", got: age.Asc(), want: bson.E{Key: "age", Value: 1}},
+		{name: "desc", got: age.Desc(), want: bson.E{Key: "age", Value: -1}},
+		{name: "eq", got: name.Eq("Mingyong Chen"), want: query.Eq("profile.name", "Mingyong Chen")},
+		{name: "named type", got: state.Ne("active"), want: query.Ne("status", status("active"))},
+		{name: "gt", got: age.Gt(3), want: query.Gt("age", 3)},
+		{name: "in", got: age.In(1, 2), want: query.In("age", 1, 2)},
+		{name: "exists", got: age.Exists(true), want: query.Exists("age", true)},
+		{name: "regex", got: name.Regex("^M"), want: query.Regex("profile.name", "^M")},
+		{name: "mod", got: age.Mod(2, 0), want: query.Mod("age", 2, 0)},
+		{name: "set", got: name.Set("burt"), want: update.Set("profile.name", "burt")},
+		{name: "unset", got: age.Unset(), want: update.Unset("age")},
+		{name: "inc", got: age.Inc(1), want: update.Inc("age", 1)},
+		{name: "contains", got: tags.Contains("go"), want: bson.D{{Key: "tags", Value: "go"}}},
+		{name: "all", got: tags.All("go", "mongo"), want: bson.D{{Key: "tags", Value: bson.D{{Key: "$all", Value: []string{"go", "mongo"}}}}}},
+		{name: "array eq", got: tags.Eq([]string{"go"}), want: query.Eq("tags", []string{"go"})},
+		{name: "push", got: tags.Push("go"), want: update.Push("tags", "go")},
+		{name: "pull all", got: tags.PullAll("go"), want: update.PullAll("tags", "go")},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.got)
+		})
+	}
+}
+
+func TestField_Builders(t *testing.T) {
+	name := NewString[string]("name")
+	age := NewNumber[int]("age")
+
+	assert.Equal(t,
+		query.NewBuilder().Eq("name", "Mingyong Chen").Gt("age", 18).Build(),
+		query.NewBuilder().Eq(name.String(), "Mingyong Chen").Gt(age.String(), 18).Build(),
+	)
+	assert.Equal(t,
+		mongo.Pipeline{
+			{{Key: "$match", Value: bson.D{{Key: "$and", Value: []any{query.Eq("name", "Mingyong Chen"), query.Gt("age", 18)}}}}},
+			{{Key: "$sort", Value: bson.D{{Key: "age", Value: -1}}}},
+		},
+		aggregation.NewStageBuilder().Match(query.And(name.Eq("Mingyong Chen"), age.Gt(18))).Sort(bson.D{age.Desc()}).Build(),
+	)
+}
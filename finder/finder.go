@@ -2,9 +2,13 @@ package finder
 
 import (
 	"context"
+	"iter"
 
 	"github.com/matiniiuu/mongox/callback"
+	mxerrors "github.com/matiniiuu/mongox/errors"
+	"github.com/matiniiuu/mongox/internal/pkg/utils"
 	"github.com/matiniiuu/mongox/operation"
+	"github.com/matiniiuu/mongox/softdelete"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
@@ -19,7 +23,7 @@ type IFinder[T any] interface {
 }
 
 func NewFinder[T any](collection *mongo.Collection) *Finder[T] {
-	return &Finder[T]{collection: collection, filter: bson.D{}}
+	return &Finder[T]{collection: collection, filter: bson.D{}, softDelete: softdelete.Enabled()}
 }
 
 var _ IFinder[any] = (*Finder[any])(nil)
@@ -28,9 +32,12 @@ type Finder[T any] struct {
 	collection  *mongo.Collection
 	filter      any
 	updates     any
+	replacement any
 	modelHook   any
 	beforeHooks []beforeHookFn
 	afterHooks  []afterHookFn[T]
+	softDelete  bool
+	scope       softdelete.Scope
 }
 
 func (f *Finder[T]) RegisterBeforeHooks(hooks ...beforeHookFn) *Finder[T] {
@@ -57,11 +64,42 @@ func (f *Finder[T]) Updates(update any) *Finder[T] {
 	return f
 }
 
+func (f *Finder[T]) Replacement(replacement any) *Finder[T] {
+	f.replacement = replacement
+	return f
+}
+
 func (f *Finder[T]) ModelHook(modelHook any) *Finder[T] {
 	f.modelHook = modelHook
 	return f
 }
 
+// SoftDelete is used to turn the soft delete mode on or off for this finder
+func (f *Finder[T]) SoftDelete(enabled bool) *Finder[T] {
+	f.softDelete = enabled
+	return f
+}
+
+// WithDeleted makes the query also match soft deleted documents
+func (f *Finder[T]) WithDeleted() *Finder[T] {
+	f.scope = softdelete.ScopeWithDeleted
+	return f
+}
+
+// OnlyDeleted makes the query only match soft deleted documents
+func (f *Finder[T]) OnlyDeleted() *Finder[T] {
+	f.scope = softdelete.ScopeOnlyDeleted
+	return f
+}
+
+// scopedFilter returns the filter restricted to the soft delete scope
+func (f *Finder[T]) scopedFilter() any {
+	if !f.softDelete {
+		return f.filter
+	}
+	return softdelete.Filter(f.filter, f.scope)
+}
+
 func (f *Finder[T]) preActionHandler(ctx context.Context, globalOpContext *operation.OpContext, opContext *OpContext, opTypes ...operation.OpType) (err error) {
 	for _, opType := range opTypes {
 		err = callback.GetCallback().Execute(ctx, globalOpContext, opType)
@@ -69,10 +107,12 @@ func (f *Finder[T]) preActionHandler(ctx context.Context, globalOpContext *opera
 			return
 		}
 	}
-	for _, beforeHook := range f.beforeHooks {
+	// the global callbacks may have changed the filter, e.g. to scope it to a tenant
+	opContext.Filter = globalOpContext.Filter
+	for i, beforeHook := range f.beforeHooks {
 		err = beforeHook(ctx, opContext)
 		if err != nil {
-			return err
+			return mxerrors.Hook(mxerrors.HookName(beforeHook, i), opTypes[0], err)
 		}
 	}
 	return
@@ -85,10 +125,10 @@ func (f *Finder[T]) postActionHandler(ctx context.Context, globalOpContext *oper
 			return
 		}
 	}
-	for _, afterHook := range f.afterHooks {
+	for i, afterHook := range f.afterHooks {
 		err = afterHook(ctx, opContext)
 		if err != nil {
-			return
+			return mxerrors.Hook(mxerrors.HookName(afterHook, i), opTypes[0], err)
 		}
 	}
 	return
@@ -96,19 +136,21 @@ func (f *Finder[T]) postActionHandler(ctx context.Context, globalOpContext *oper
 
 func (f *Finder[T]) FindOne(ctx context.Context, opts ...options.Lister[options.FindOneOptions]) (*T, error) {
 	t := new(T)
+	filter := f.scopedFilter()
 
-	globalOpContext := operation.NewOpContext(f.collection, operation.WithDoc(t), operation.WithFilter(f.filter), operation.WithMongoOptions(opts), operation.WithModelHook(f.modelHook))
-	err := f.preActionHandler(ctx, globalOpContext, NewOpContext(f.collection, f.filter, WithMongoOptions(opts), WithModelHook(f.modelHook)), operation.OpTypeBeforeFind)
+	globalOpContext := operation.NewOpContext(f.collection, operation.WithDoc(t), operation.WithFilter(filter), operation.WithMongoOptions(opts), operation.WithModelHook(f.modelHook))
+	err := f.preActionHandler(ctx, globalOpContext, NewOpContext(f.collection, filter, WithMongoOptions(opts), WithModelHook(f.modelHook)), operation.OpTypeBeforeFind)
 	if err != nil {
 		return nil, err
 	}
+	filter = globalOpContext.Filter
 
-	err = f.collection.FindOne(ctx, f.filter, opts...).Decode(t)
+	err = f.collection.FindOne(ctx, filter, opts...).Decode(t)
 	if err != nil {
-		return nil, err
+		return nil, mxerrors.Wrap(err)
 	}
 
-	err = f.postActionHandler(ctx, globalOpContext, NewAfterOpContext[T](NewOpContext(f.collection, f.filter, WithMongoOptions(opts), WithModelHook(f.modelHook)), WithDoc(t)), operation.OpTypeAfterFind)
+	err = f.postActionHandler(ctx, globalOpContext, NewAfterOpContext[T](NewOpContext(f.collection, filter, WithMongoOptions(opts), WithModelHook(f.modelHook)), WithDoc(t)), operation.OpTypeAfterFind)
 	if err != nil {
 		return nil, err
 	}
@@ -117,26 +159,32 @@ func (f *Finder[T]) FindOne(ctx context.Context, opts ...options.Lister[options.
 }
 
 func (f *Finder[T]) Find(ctx context.Context, opts ...options.Lister[options.FindOptions]) ([]*T, error) {
+	return f.find(ctx, f.scopedFilter(), opts...)
+}
+
+// find runs the query with the given filter and the hooks of Find
+func (f *Finder[T]) find(ctx context.Context, filter any, opts ...options.Lister[options.FindOptions]) ([]*T, error) {
 	t := make([]*T, 0)
 
-	opContext := operation.NewOpContext(f.collection, operation.WithFilter(f.filter), operation.WithMongoOptions(opts), operation.WithModelHook(f.modelHook))
-	err := f.preActionHandler(ctx, opContext, NewOpContext(f.collection, f.filter, WithMongoOptions(opts), WithModelHook(f.modelHook)), operation.OpTypeBeforeFind)
+	opContext := operation.NewOpContext(f.collection, operation.WithFilter(filter), operation.WithMongoOptions(opts), operation.WithModelHook(f.modelHook))
+	err := f.preActionHandler(ctx, opContext, NewOpContext(f.collection, filter, WithMongoOptions(opts), WithModelHook(f.modelHook)), operation.OpTypeBeforeFind)
 	if err != nil {
 		return nil, err
 	}
+	filter = opContext.Filter
 
-	cursor, err := f.collection.Find(ctx, f.filter, opts...)
+	cursor, err := f.collection.Find(ctx, filter, opts...)
 	if err != nil {
-		return nil, err
+		return nil, mxerrors.Wrap(err)
 	}
 	defer cursor.Close(ctx)
 	err = cursor.All(ctx, &t)
 	if err != nil {
-		return nil, err
+		return nil, mxerrors.Wrap(err)
 	}
 
 	opContext.Doc = t
-	err = f.postActionHandler(ctx, opContext, NewAfterOpContext[T](NewOpContext(f.collection, f.filter, WithMongoOptions(opts), WithModelHook(f.modelHook)), WithDocs(t)), operation.OpTypeAfterFind)
+	err = f.postActionHandler(ctx, opContext, NewAfterOpContext[T](NewOpContext(f.collection, filter, WithMongoOptions(opts), WithModelHook(f.modelHook)), WithDocs(t)), operation.OpTypeAfterFind)
 	if err != nil {
 		return nil, err
 	}
@@ -144,43 +192,169 @@ func (f *Finder[T]) Find(ctx context.Context, opts ...options.Lister[options.Fin
 	return t, nil
 }
 
+// Iter is used to stream the documents matched by the filter instead of loading them all into memory
+// The before hooks run once before the query, the after hooks run for every decoded document with opContext.Doc set
+// The cursor is closed once the iteration finishes or the loop breaks early
+func (f *Finder[T]) Iter(ctx context.Context, opts ...options.Lister[options.FindOptions]) iter.Seq2[*T, error] {
+	return func(yield func(*T, error) bool) {
+		filter := f.scopedFilter()
+
+		globalOpContext := operation.NewOpContext(f.collection, operation.WithFilter(filter), operation.WithMongoOptions(opts), operation.WithModelHook(f.modelHook))
+		err := f.preActionHandler(ctx, globalOpContext, NewOpContext(f.collection, filter, WithMongoOptions(opts), WithModelHook(f.modelHook)), operation.OpTypeBeforeFind)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		filter = globalOpContext.Filter
+
+		cursor, err := f.collection.Find(ctx, filter, opts...)
+		if err != nil {
+			yield(nil, mxerrors.Wrap(err))
+			return
+		}
+		defer cursor.Close(ctx)
+
+		for cursor.Next(ctx) {
+			t := new(T)
+			if err = cursor.Decode(t); err != nil {
+				yield(nil, mxerrors.Wrap(err))
+				return
+			}
+			globalOpContext.Doc = t
+			err = f.postActionHandler(ctx, globalOpContext, NewAfterOpContext[T](NewOpContext(f.collection, filter, WithMongoOptions(opts), WithModelHook(f.modelHook)), WithDoc(t)), operation.OpTypeAfterFind)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if !yield(t, nil) {
+				return
+			}
+		}
+		if err = cursor.Err(); err != nil {
+			yield(nil, mxerrors.Wrap(err))
+		}
+	}
+}
+
+// IterBatch is the same as Iter but yields the documents in chunks of the given size, the last chunk may be smaller
+// The size is also used as the batch size of the cursor unless the options set one, a size less than 1 is treated as 1
+func (f *Finder[T]) IterBatch(ctx context.Context, size int, opts ...options.Lister[options.FindOptions]) iter.Seq2[[]*T, error] {
+	size = max(size, 1)
+	opts = append([]options.Lister[options.FindOptions]{options.Find().SetBatchSize(int32(size))}, opts...)
+	return utils.Chunk(f.Iter(ctx, opts...), size)
+}
+
+// Count is used to count the documents matched by the filter
+// Only the global callbacks run for Count, the before callbacks may change the filter through opContext.Filter
 func (f *Finder[T]) Count(ctx context.Context, opts ...options.Lister[options.CountOptions]) (int64, error) {
-	return f.collection.CountDocuments(ctx, f.filter, opts...)
+	globalOpContext := operation.NewOpContext(f.collection, operation.WithFilter(f.scopedFilter()), operation.WithMongoOptions(opts), operation.WithModelHook(f.modelHook))
+	err := callback.GetCallback().Execute(ctx, globalOpContext, operation.OpTypeBeforeCount)
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := f.collection.CountDocuments(ctx, globalOpContext.Filter, opts...)
+	if err != nil {
+		return 0, mxerrors.Wrap(err)
+	}
+
+	err = callback.GetCallback().Execute(ctx, globalOpContext, operation.OpTypeAfterCount)
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
 }
 
+// Distinct is used to query the distinct values of the field among the documents matched by the filter
+// The global callbacks run as for DistinctE, but the result of the driver cannot carry their errors, so Distinct ignores
+// them: when a callback fails, e.g. the tenant hook rejects the operation, no value is read and the result is empty
+// while its Err is nil
+//
+// Deprecated: Distinct ignores the errors of the callbacks, use DistinctE or DistinctWithParse instead.
 func (f *Finder[T]) Distinct(ctx context.Context, fieldName string, opts ...options.Lister[options.DistinctOptions]) *mongo.DistinctResult {
-	return f.collection.Distinct(ctx, fieldName, f.filter, opts...)
+	distinctResult, _ := f.DistinctE(ctx, fieldName, opts...)
+	if distinctResult == nil {
+		return &mongo.DistinctResult{}
+	}
+	return distinctResult
+}
+
+// DistinctE is the same as Distinct but also returns the error of the callbacks or of the operation
+// Only the global callbacks run for Distinct, the before callbacks may change the filter through opContext.Filter
+func (f *Finder[T]) DistinctE(ctx context.Context, fieldName string, opts ...options.Lister[options.DistinctOptions]) (*mongo.DistinctResult, error) {
+	globalOpContext := operation.NewOpContext(f.collection, operation.WithFilter(f.scopedFilter()), operation.WithMongoOptions(opts), operation.WithModelHook(f.modelHook))
+	err := callback.GetCallback().Execute(ctx, globalOpContext, operation.OpTypeBeforeDistinct)
+	if err != nil {
+		return nil, err
+	}
+
+	distinctResult := f.collection.Distinct(ctx, fieldName, globalOpContext.Filter, opts...)
+	if err = distinctResult.Err(); err != nil {
+		return distinctResult, mxerrors.Wrap(err)
+	}
+
+	globalOpContext.Doc = distinctResult
+	err = callback.GetCallback().Execute(ctx, globalOpContext, operation.OpTypeAfterDistinct)
+	if err != nil {
+		return nil, err
+	}
+	return distinctResult, nil
 }
 
 // DistinctWithParse is used to parse the result of Distinct
 // result must be a pointer
 func (f *Finder[T]) DistinctWithParse(ctx context.Context, fieldName string, result any, opts ...options.Lister[options.DistinctOptions]) error {
-	distinctResult := f.collection.Distinct(ctx, fieldName, f.filter, opts...)
-	if distinctResult.Err() != nil {
-		return distinctResult.Err()
-	}
-	err := distinctResult.Decode(result)
+	distinctResult, err := f.DistinctE(ctx, fieldName, opts...)
 	if err != nil {
 		return err
 	}
-	return nil
+	return mxerrors.Wrap(distinctResult.Decode(result))
 }
 
 func (f *Finder[T]) FindOneAndUpdate(ctx context.Context, opts ...options.Lister[options.FindOneAndUpdateOptions]) (*T, error) {
 	t := new(T)
+	filter := f.scopedFilter()
+
+	globalOpContext := operation.NewOpContext(f.collection, operation.WithDoc(t), operation.WithFilter(filter), operation.WithUpdates(f.updates), operation.WithMongoOptions(opts), operation.WithModelHook(f.modelHook))
+	err := f.preActionHandler(ctx, globalOpContext, NewOpContext(f.collection, filter, WithUpdates(f.updates), WithMongoOptions(opts), WithModelHook(f.modelHook)), operation.OpTypeBeforeFind, operation.OpTypeBeforeUpdate)
+	if err != nil {
+		return nil, err
+	}
+	filter = globalOpContext.Filter
+
+	err = f.collection.FindOneAndUpdate(ctx, filter, f.updates, opts...).Decode(t)
+	if err != nil {
+		return nil, mxerrors.Wrap(err)
+	}
 
-	globalOpContext := operation.NewOpContext(f.collection, operation.WithDoc(t), operation.WithFilter(f.filter), operation.WithUpdates(f.updates), operation.WithMongoOptions(opts), operation.WithModelHook(f.modelHook))
-	err := f.preActionHandler(ctx, globalOpContext, NewOpContext(f.collection, f.filter, WithUpdates(f.updates), WithMongoOptions(opts), WithModelHook(f.modelHook)), operation.OpTypeBeforeFind, operation.OpTypeBeforeUpdate)
+	err = f.postActionHandler(ctx, globalOpContext, NewAfterOpContext[T](NewOpContext(f.collection, filter, WithUpdates(f.updates), WithMongoOptions(opts), WithModelHook(f.modelHook)), WithDoc(t)), operation.OpTypeAfterFind, operation.OpTypeAfterUpdate)
 	if err != nil {
 		return nil, err
 	}
 
-	err = f.collection.FindOneAndUpdate(ctx, f.filter, f.updates, opts...).Decode(t)
+	return t, nil
+}
+
+// FindOneAndReplace is used to replace the first document matched by the filter with the replacement and return the document
+// The document before the replacement is returned unless options.After is set as the return document option
+func (f *Finder[T]) FindOneAndReplace(ctx context.Context, opts ...options.Lister[options.FindOneAndReplaceOptions]) (*T, error) {
+	t := new(T)
+	filter := f.scopedFilter()
+
+	globalOpContext := operation.NewOpContext(f.collection, operation.WithDoc(f.replacement), operation.WithFilter(filter), operation.WithReplacement(f.replacement), operation.WithMongoOptions(opts), operation.WithModelHook(f.modelHook))
+	err := f.preActionHandler(ctx, globalOpContext, NewOpContext(f.collection, filter, WithReplacement(f.replacement), WithMongoOptions(opts), WithModelHook(f.modelHook)), operation.OpTypeBeforeFind, operation.OpTypeBeforeReplace)
 	if err != nil {
 		return nil, err
 	}
+	filter = globalOpContext.Filter
+
+	err = f.collection.FindOneAndReplace(ctx, filter, f.replacement, opts...).Decode(t)
+	if err != nil {
+		return nil, mxerrors.Wrap(err)
+	}
 
-	err = f.postActionHandler(ctx, globalOpContext, NewAfterOpContext[T](NewOpContext(f.collection, f.filter, WithUpdates(f.updates), WithMongoOptions(opts), WithModelHook(f.modelHook)), WithDoc(t)), operation.OpTypeAfterFind, operation.OpTypeAfterUpdate)
+	globalOpContext.Doc = t
+	err = f.postActionHandler(ctx, globalOpContext, NewAfterOpContext[T](NewOpContext(f.collection, filter, WithReplacement(f.replacement), WithMongoOptions(opts), WithModelHook(f.modelHook)), WithDoc(t)), operation.OpTypeAfterFind, operation.OpTypeAfterReplace)
 	if err != nil {
 		return nil, err
 	}
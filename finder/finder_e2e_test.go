@@ -13,6 +13,7 @@ import (
 	"github.com/matiniiuu/mongox/internal/pkg/utils"
 
 	"github.com/matiniiuu/mongox/callback"
+	mxerrors "github.com/matiniiuu/mongox/errors"
 	"github.com/matiniiuu/mongox/operation"
 
 	"github.com/stretchr/testify/require"
@@ -88,7 +89,7 @@ func TestFinder_e2e_FindOne(t *testing.T) {
 				finder.filter = bson.D{}
 			},
 			filter:  query.Eq("name", "burt"),
-			wantErr: mongo.ErrNoDocuments,
+			wantErr: mxerrors.ErrNotFound,
 		},
 		{
 			name: "find by name",
@@ -152,7 +153,7 @@ func TestFinder_e2e_FindOne(t *testing.T) {
 					},
 				},
 			},
-			wantErr: errors.New("global before hook error"),
+			wantErr: mxerrors.ErrHookFailed,
 		},
 		{
 			name: "global after hook error",
@@ -181,7 +182,7 @@ func TestFinder_e2e_FindOne(t *testing.T) {
 					},
 				},
 			},
-			wantErr: errors.New("global after hook error"),
+			wantErr: mxerrors.ErrHookFailed,
 		},
 		{
 			name: "global before and after hook",
@@ -239,7 +240,7 @@ func TestFinder_e2e_FindOne(t *testing.T) {
 					return errors.New("before hook error")
 				},
 			},
-			wantErr: errors.New("before hook error"),
+			wantErr: mxerrors.ErrHookFailed,
 		},
 		{
 			name: "after hook error",
@@ -264,7 +265,7 @@ func TestFinder_e2e_FindOne(t *testing.T) {
 					return errors.New("after hook error")
 				},
 			},
-			wantErr: errors.New("after hook error"),
+			wantErr: mxerrors.ErrHookFailed,
 		},
 		{
 			name: "before and after hook",
@@ -317,7 +318,7 @@ func TestFinder_e2e_FindOne(t *testing.T) {
 				RegisterAfterHooks(tc.afterHook...).Filter(tc.filter).
 				FindOne(tc.ctx, tc.opts...)
 			tc.after(tc.ctx, t)
-			require.Equal(t, tc.wantErr, err)
+			require.ErrorIs(t, err, tc.wantErr)
 			if err == nil {
 				tc.want.ID = user.ID
 				require.Equal(t, tc.want, user)
@@ -541,7 +542,8 @@ func TestFinder_e2e_Find(t *testing.T) {
 			},
 			ctx: context.Background(),
 			wantErr: func(t require.TestingT, err error, i ...interface{}) {
-				require.Equal(t, errors.New("before hook error"), err)
+				require.ErrorIs(t, err, mxerrors.ErrHookFailed)
+				require.ErrorContains(t, err, "before hook error")
 			},
 		},
 		{
@@ -578,7 +580,8 @@ func TestFinder_e2e_Find(t *testing.T) {
 				},
 			},
 			wantErr: func(t require.TestingT, err error, i ...interface{}) {
-				require.Equal(t, errors.New("after hook error"), err)
+				require.ErrorIs(t, err, mxerrors.ErrHookFailed)
+				require.ErrorContains(t, err, "after hook error")
 			},
 		},
 		{
@@ -653,7 +656,8 @@ func TestFinder_e2e_Find(t *testing.T) {
 			},
 			ctx: context.Background(),
 			wantErr: func(t require.TestingT, err error, i ...interface{}) {
-				require.Equal(t, errors.New("before hook error"), err)
+				require.ErrorIs(t, err, mxerrors.ErrHookFailed)
+				require.ErrorContains(t, err, "before hook error")
 			},
 		},
 		{
@@ -686,7 +690,8 @@ func TestFinder_e2e_Find(t *testing.T) {
 				},
 			},
 			wantErr: func(t require.TestingT, err error, i ...interface{}) {
-				require.Equal(t, errors.New("after hook error"), err)
+				require.ErrorIs(t, err, mxerrors.ErrHookFailed)
+				require.ErrorContains(t, err, "after hook error")
 			},
 		},
 		{
@@ -770,6 +775,179 @@ func TestFinder_e2e_Find(t *testing.T) {
 	}
 }
 
+func TestFinder_e2e_Iter(t *testing.T) {
+	collection := getCollection(t)
+	ctx := context.Background()
+
+	insertManyResult, err := collection.InsertMany(ctx, []any{
+		&TestUser{Name: "Mingyong Chen", Age: 24},
+		&TestUser{Name: "burt", Age: 25},
+		&TestUser{Name: "gopher", Age: 26},
+	})
+	require.NoError(t, err)
+	require.Len(t, insertManyResult.InsertedIDs, 3)
+	defer func() {
+		deleteResult, err := collection.DeleteMany(ctx, query.In("name", "Mingyong Chen", "burt", "gopher"))
+		require.NoError(t, err)
+		require.Equal(t, int64(3), deleteResult.DeletedCount)
+	}()
+
+	t.Run("iterate all documents with hooks", func(t *testing.T) {
+		var beforeCount, afterCount int
+		finder := NewFinder[TestUser](collection).Filter(query.In("name", "Mingyong Chen", "burt", "gopher")).
+			RegisterBeforeHooks(func(_ context.Context, _ *OpContext, _ ...any) error {
+				beforeCount++
+				return nil
+			}).
+			RegisterAfterHooks(func(_ context.Context, opContext *AfterOpContext[TestUser], _ ...any) error {
+				afterCount++
+				require.NotNil(t, opContext.Doc)
+				return nil
+			})
+
+		names := make([]string, 0, 3)
+		for user, err := range finder.Iter(ctx, options.Find().SetSort(bson.D{{Key: "age", Value: 1}})) {
+			require.NoError(t, err)
+			names = append(names, user.Name)
+		}
+		require.Equal(t, []string{"Mingyong Chen", "burt", "gopher"}, names)
+		require.Equal(t, 1, beforeCount)
+		require.Equal(t, 3, afterCount)
+	})
+
+	t.Run("break early", func(t *testing.T) {
+		var afterCount int
+		finder := NewFinder[TestUser](collection).Filter(query.In("name", "Mingyong Chen", "burt", "gopher")).
+			RegisterAfterHooks(func(_ context.Context, _ *AfterOpContext[TestUser], _ ...any) error {
+				afterCount++
+				return nil
+			})
+		for _, err := range finder.Iter(ctx) {
+			require.NoError(t, err)
+			break
+		}
+		require.Equal(t, 1, afterCount)
+	})
+
+	t.Run("after hook error", func(t *testing.T) {
+		finder := NewFinder[TestUser](collection).Filter(query.In("name", "Mingyong Chen", "burt", "gopher")).
+			RegisterAfterHooks(func(_ context.Context, _ *AfterOpContext[TestUser], _ ...any) error {
+				return errors.New("after hook error")
+			})
+		var gotErr error
+		for user, err := range finder.Iter(ctx) {
+			require.Nil(t, user)
+			gotErr = err
+		}
+		require.ErrorIs(t, gotErr, mxerrors.ErrHookFailed)
+		require.ErrorContains(t, gotErr, "after hook error")
+	})
+
+	t.Run("nil filter error", func(t *testing.T) {
+		var gotErr error
+		for _, err := range NewFinder[TestUser](collection).Filter(nil).Iter(ctx) {
+			gotErr = err
+		}
+		require.Error(t, gotErr)
+	})
+
+	t.Run("iterate in batches", func(t *testing.T) {
+		finder := NewFinder[TestUser](collection).Filter(query.In("name", "Mingyong Chen", "burt", "gopher"))
+		sizes := make([]int, 0, 2)
+		for users, err := range finder.IterBatch(ctx, 2, options.Find().SetSort(bson.D{{Key: "age", Value: 1}})) {
+			require.NoError(t, err)
+			sizes = append(sizes, len(users))
+		}
+		require.Equal(t, []int{2, 1}, sizes)
+	})
+}
+
+func TestFinder_e2e_Paginate(t *testing.T) {
+	collection := getCollection(t)
+	ctx := context.Background()
+
+	docs := make([]any, 0, 5)
+	for i := 0; i < 5; i++ {
+		docs = append(docs, &TestUser{Name: fmt.Sprintf("page-user-%d", i), Age: int64(20 + i)})
+	}
+	insertManyResult, err := collection.InsertMany(ctx, docs)
+	require.NoError(t, err)
+	require.Len(t, insertManyResult.InsertedIDs, 5)
+	defer func() {
+		deleteResult, err := collection.DeleteMany(ctx, query.Gte("age", 20))
+		require.NoError(t, err)
+		require.Equal(t, int64(5), deleteResult.DeletedCount)
+	}()
+
+	finder := NewFinder[TestUser](collection).Filter(query.Gte("age", 20))
+
+	page, err := finder.Paginate(ctx, 2, 2, options.Find().SetSort(bson.D{{Key: "age", Value: 1}}))
+	require.NoError(t, err)
+	require.Equal(t, int64(5), page.Total)
+	require.Equal(t, int64(3), page.TotalPages)
+	require.True(t, page.HasNext)
+	require.Len(t, page.Items, 2)
+	require.Equal(t, int64(22), page.Items[0].Age)
+	require.Equal(t, int64(23), page.Items[1].Age)
+
+	page, err = finder.Paginate(ctx, 3, 2, options.Find().SetSort(bson.D{{Key: "age", Value: 1}}))
+	require.NoError(t, err)
+	require.False(t, page.HasNext)
+	require.Len(t, page.Items, 1)
+
+	_, err = finder.Paginate(ctx, 0, 2)
+	require.Equal(t, ErrInvalidPageSize, err)
+}
+
+func TestFinder_e2e_PaginateAfter(t *testing.T) {
+	collection := getCollection(t)
+	ctx := context.Background()
+
+	// two documents share each age so that the pages have to break ties on _id
+	docs := make([]any, 0, 6)
+	for i := 0; i < 6; i++ {
+		docs = append(docs, &TestUser{Name: fmt.Sprintf("cursor-user-%d", i), Age: int64(30 + i/2)})
+	}
+	insertManyResult, err := collection.InsertMany(ctx, docs)
+	require.NoError(t, err)
+	require.Len(t, insertManyResult.InsertedIDs, 6)
+	defer func() {
+		deleteResult, err := collection.DeleteMany(ctx, query.Gte("age", 30))
+		require.NoError(t, err)
+		require.Equal(t, int64(6), deleteResult.DeletedCount)
+	}()
+
+	finder := NewFinder[TestUser](collection).Filter(query.Gte("age", 30))
+
+	for _, sortField := range []string{"age", "-age"} {
+		t.Run(sortField, func(t *testing.T) {
+			var (
+				token string
+				names = make(map[string]struct{})
+				pages int
+			)
+			for {
+				page, err := finder.PaginateAfter(ctx, token, 4, sortField)
+				require.NoError(t, err)
+				pages++
+				for _, user := range page.Items {
+					names[user.Name] = struct{}{}
+				}
+				if !page.HasNext {
+					require.Empty(t, page.NextToken)
+					break
+				}
+				token = page.NextToken
+			}
+			require.Equal(t, 2, pages)
+			require.Len(t, names, 6)
+		})
+	}
+
+	_, err = finder.PaginateAfter(ctx, "invalid token", 4, "age")
+	require.Equal(t, ErrInvalidCursorToken, err)
+}
+
 func TestFinder_e2e_Count(t *testing.T) {
 	collection := getCollection(t)
 	finder := NewFinder[TestUser](collection)
@@ -836,6 +1014,63 @@ func TestFinder_e2e_Count(t *testing.T) {
 	}
 }
 
+func TestFinder_e2e_CountAndDistinctCallbacks(t *testing.T) {
+	collection := getCollection(t)
+	ctx := context.Background()
+
+	insertManyResult, err := collection.InsertMany(ctx, []any{
+		&TestUser{Name: "Mingyong Chen", Age: 24},
+		&TestUser{Name: "burt", Age: 25},
+	})
+	require.NoError(t, err)
+	require.Len(t, insertManyResult.InsertedIDs, 2)
+	defer func() {
+		deleteResult, err := collection.DeleteMany(ctx, query.In("name", "Mingyong Chen", "burt"))
+		require.NoError(t, err)
+		require.Equal(t, int64(2), deleteResult.DeletedCount)
+	}()
+
+	// the before callbacks restrict the filter to a single user
+	narrow := func(_ context.Context, opCtx *operation.OpContext, _ ...any) error {
+		opCtx.Filter = query.Eq("name", "burt")
+		return nil
+	}
+	var after []operation.OpType
+	for _, opType := range []operation.OpType{operation.OpTypeBeforeCount, operation.OpTypeBeforeDistinct} {
+		callback.GetCallback().Register(opType, "narrow", narrow)
+		defer callback.GetCallback().Remove(opType, "narrow")
+	}
+	for _, opType := range []operation.OpType{operation.OpTypeAfterCount, operation.OpTypeAfterDistinct} {
+		callback.GetCallback().Register(opType, "record", func(_ context.Context, _ *operation.OpContext, _ ...any) error {
+			after = append(after, opType)
+			return nil
+		})
+		defer callback.GetCallback().Remove(opType, "record")
+	}
+
+	count, err := NewFinder[TestUser](collection).Filter(query.In("name", "Mingyong Chen", "burt")).Count(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), count)
+
+	names := make([]string, 0)
+	err = NewFinder[TestUser](collection).Filter(query.In("name", "Mingyong Chen", "burt")).DistinctWithParse(ctx, "name", &names)
+	require.NoError(t, err)
+	require.Equal(t, []string{"burt"}, names)
+	require.Equal(t, []operation.OpType{operation.OpTypeAfterCount, operation.OpTypeAfterDistinct}, after)
+
+	callback.GetCallback().Register(operation.OpTypeBeforeDistinct, "fail", func(_ context.Context, _ *operation.OpContext, _ ...any) error {
+		return errors.New("before distinct error")
+	})
+	defer callback.GetCallback().Remove(operation.OpTypeBeforeDistinct, "fail")
+	distinctResult, err := NewFinder[TestUser](collection).DistinctE(ctx, "name")
+	require.Nil(t, distinctResult)
+	require.ErrorIs(t, err, mxerrors.ErrHookFailed)
+	require.ErrorContains(t, err, "before distinct error")
+	err = NewFinder[TestUser](collection).DistinctWithParse(ctx, "name", &names)
+	require.ErrorIs(t, err, mxerrors.ErrHookFailed)
+	require.ErrorContains(t, err, "before distinct error")
+}
+
 func TestFinder_e2e_Distinct(t *testing.T) {
 	collection := getCollection(t)
 	finder := NewFinder[TestUser](collection)
@@ -941,12 +1176,12 @@ func TestFinder_e2e_Distinct(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			tc.before(tc.ctx, t)
-			distinctResult := finder.Filter(tc.filter).Distinct(tc.ctx, tc.fieldName, tc.opts...)
+			distinctResult, err := finder.Filter(tc.filter).DistinctE(tc.ctx, tc.fieldName, tc.opts...)
 			tc.after(tc.ctx, t)
-			tc.wantErr(t, distinctResult.Err())
-			if distinctResult.Err() == nil {
+			tc.wantErr(t, err)
+			if err == nil {
 				result := make([]string, 0)
-				err := distinctResult.Decode(&result)
+				err = distinctResult.Decode(&result)
 				require.NoError(t, err)
 				require.ElementsMatch(t, tc.want, result)
 			}
@@ -1349,3 +1584,37 @@ func TestFinder_e2e_FindOneAndUpdate(t *testing.T) {
 		})
 	}
 }
+
+func TestFinder_e2e_FindOneAndReplace(t *testing.T) {
+	collection := getCollection(t)
+	ctx := context.Background()
+
+	insertOneResult, err := collection.InsertOne(ctx, &TestUser{Name: "Mingyong Chen", Age: 18})
+	require.NoError(t, err)
+	defer func() {
+		_, err := collection.DeleteOne(ctx, query.Id(insertOneResult.InsertedID))
+		require.NoError(t, err)
+	}()
+
+	var globalHookReplacement any
+	callback.GetCallback().Register(operation.OpTypeBeforeReplace, "replacement", func(_ context.Context, opCtx *operation.OpContext, _ ...any) error {
+		globalHookReplacement = opCtx.Replacement
+		return nil
+	})
+	defer callback.GetCallback().Remove(operation.OpTypeBeforeReplace, "replacement")
+
+	replacement := &TestUser{Name: "burt", Age: 19}
+	got, err := NewFinder[TestUser](collection).Filter(query.Id(insertOneResult.InsertedID)).
+		Replacement(replacement).
+		FindOneAndReplace(ctx, options.FindOneAndReplace().SetReturnDocument(options.After))
+	require.NoError(t, err)
+	require.Equal(t, "burt", got.Name)
+	require.Equal(t, int64(19), got.Age)
+	require.Equal(t, replacement, globalHookReplacement)
+
+	_, err = NewFinder[TestUser](collection).Filter(query.Eq("name", "nobody")).
+		Replacement(replacement).
+		FindOneAndReplace(ctx)
+	require.ErrorIs(t, err, mxerrors.ErrNotFound)
+	require.ErrorIs(t, err, mongo.ErrNoDocuments)
+}
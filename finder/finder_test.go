@@ -5,10 +5,17 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/matiniiuu/mongox/callback"
+	mxerrors "github.com/matiniiuu/mongox/errors"
 	mocks "github.com/matiniiuu/mongox/mock"
+	"github.com/matiniiuu/mongox/operation"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"go.mongodb.org/mongo-driver/v2/x/mongo/driver/drivertest"
 	"go.uber.org/mock/gomock"
 )
 
@@ -176,3 +183,109 @@ func TestFinder_Count(t *testing.T) {
 		})
 	}
 }
+
+func TestFinder_scopedFilter(t *testing.T) {
+	testCases := []struct {
+		name   string
+		finder func() *Finder[TestUser]
+		want   any
+	}{
+		{
+			name: "soft delete disabled",
+			finder: func() *Finder[TestUser] {
+				return NewFinder[TestUser](&mongo.Collection{}).Filter(bson.M{"name": "Mingyong Chen"})
+			},
+			want: bson.M{"name": "Mingyong Chen"},
+		},
+		{
+			name: "exclude deleted by default",
+			finder: func() *Finder[TestUser] {
+				return NewFinder[TestUser](&mongo.Collection{}).SoftDelete(true)
+			},
+			want: bson.D{{Key: "deletedAt", Value: nil}},
+		},
+		{
+			name: "with deleted",
+			finder: func() *Finder[TestUser] {
+				return NewFinder[TestUser](&mongo.Collection{}).SoftDelete(true).WithDeleted().Filter(bson.M{"name": "Mingyong Chen"})
+			},
+			want: bson.M{"name": "Mingyong Chen"},
+		},
+		{
+			name: "only deleted",
+			finder: func() *Finder[TestUser] {
+				return NewFinder[TestUser](&mongo.Collection{}).SoftDelete(true).OnlyDeleted().Filter(bson.M{"name": "Mingyong Chen"})
+			},
+			want: bson.D{{Key: "$and", Value: bson.A{
+				bson.M{"name": "Mingyong Chen"},
+				bson.D{{Key: "deletedAt", Value: bson.D{{Key: "$ne", Value: nil}}}},
+			}}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.finder().scopedFilter())
+		})
+	}
+}
+
+// newMockCollection returns a collection whose commands are answered with the responses in order
+func newMockCollection(t *testing.T, responses ...bson.D) *mongo.Collection {
+	clientOpts := options.Client()
+	clientOpts.Deployment = drivertest.NewMockDeployment(responses...)
+	client, err := mongo.Connect(clientOpts)
+	require.NoError(t, err)
+	return client.Database("db-test").Collection("test_user")
+}
+
+func TestFinder_Distinct(t *testing.T) {
+	values := bson.D{{Key: "ok", Value: 1}, {Key: "values", Value: bson.A{"Mingyong Chen", "burt"}}}
+
+	t.Run("values", func(t *testing.T) {
+		result, err := NewFinder[TestUser](newMockCollection(t, values)).DistinctE(context.Background(), "name")
+		require.NoError(t, err)
+		var names []string
+		require.NoError(t, result.Decode(&names))
+		assert.Equal(t, []string{"Mingyong Chen", "burt"}, names)
+
+		names = nil
+		require.NoError(t, NewFinder[TestUser](newMockCollection(t, values)).Distinct(context.Background(), "name").Decode(&names))
+		assert.Equal(t, []string{"Mingyong Chen", "burt"}, names)
+	})
+
+	t.Run("callback error", func(t *testing.T) {
+		callbackErr := errors.New("callback error")
+		for _, opType := range []operation.OpType{operation.OpTypeBeforeDistinct, operation.OpTypeAfterDistinct} {
+			t.Run(string(opType), func(t *testing.T) {
+				callback.GetCallback().Register(opType, "fail", func(_ context.Context, _ *operation.OpContext, _ ...any) error {
+					return callbackErr
+				})
+				defer callback.GetCallback().Remove(opType, "fail")
+
+				result, err := NewFinder[TestUser](newMockCollection(t, values)).DistinctE(context.Background(), "name")
+				assert.ErrorIs(t, err, callbackErr)
+				assert.ErrorIs(t, err, mxerrors.ErrHookFailed)
+				assert.Nil(t, result)
+
+				// Distinct cannot report the error, the values are not returned
+				result = NewFinder[TestUser](newMockCollection(t, values)).Distinct(context.Background(), "name")
+				raw, err := result.Raw()
+				assert.NoError(t, err)
+				assert.Empty(t, raw)
+
+				var names []string
+				assert.ErrorIs(t, NewFinder[TestUser](newMockCollection(t, values)).DistinctWithParse(context.Background(), "name", &names), callbackErr)
+				assert.Empty(t, names)
+			})
+		}
+	})
+
+	t.Run("operation error", func(t *testing.T) {
+		failed := bson.D{{Key: "ok", Value: 0}, {Key: "code", Value: 2}, {Key: "errmsg", Value: "bad value"}}
+		_, err := NewFinder[TestUser](newMockCollection(t, failed)).DistinctE(context.Background(), "name")
+		var cmdErr mongo.CommandError
+		require.ErrorAs(t, err, &cmdErr)
+		assert.Equal(t, int32(2), cmdErr.Code)
+		assert.Error(t, NewFinder[TestUser](newMockCollection(t, failed)).Distinct(context.Background(), "name").Err())
+	})
+}
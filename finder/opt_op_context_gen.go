@@ -27,6 +27,12 @@ func WithUpdates(updates any) OpContextOption {
 	}
 }
 
+func WithReplacement(replacement any) OpContextOption {
+	return func(opContext *OpContext) {
+		opContext.Replacement = replacement
+	}
+}
+
 func WithMongoOptions(mongoOptions any) OpContextOption {
 	return func(opContext *OpContext) {
 		opContext.MongoOptions = mongoOptions
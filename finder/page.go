@@ -0,0 +1,193 @@
+package finder
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+var (
+	ErrInvalidPageSize    = errors.New("mongox: page and size must be positive")
+	ErrInvalidCursorToken = errors.New("mongox: invalid cursor token")
+	// ErrMissingSortField is returned by PaginateAfter when the last document of a page has no value for a sort field,
+	// since the documents after it cannot be matched by comparing with a missing or null value
+	ErrMissingSortField = errors.New("mongox: missing sort field")
+)
+
+// Page is a page of documents returned by Paginate
+type Page[T any] struct {
+	Items      []*T
+	Total      int64
+	Page       int64
+	Size       int64
+	TotalPages int64
+	HasNext    bool
+}
+
+// CursorPage is a page of documents returned by PaginateAfter
+// NextToken is empty when there is no next page
+type CursorPage[T any] struct {
+	Items     []*T
+	NextToken string
+	HasNext   bool
+}
+
+// sortField is a field of the keyset, order is 1 for ascending and -1 for descending
+type sortField struct {
+	key   string
+	order int
+}
+
+// cursorToken is the decoded form of the token returned by PaginateAfter
+type cursorToken struct {
+	Keys   []string `bson:"k"`
+	Values bson.A   `bson:"v"`
+}
+
+// Paginate is used to query the given page of the documents matched by the filter, the page starts from 1
+// The documents are queried through Find so the hooks of Find also apply
+func (f *Finder[T]) Paginate(ctx context.Context, page, size int64, opts ...options.Lister[options.FindOptions]) (*Page[T], error) {
+	if page < 1 || size < 1 {
+		return nil, ErrInvalidPageSize
+	}
+	total, err := f.Count(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items, err := f.Find(ctx, append(opts, options.Find().SetSkip((page-1)*size).SetLimit(size))...)
+	if err != nil {
+		return nil, err
+	}
+	totalPages := (total + size - 1) / size
+	return &Page[T]{
+		Items:      items,
+		Total:      total,
+		Page:       page,
+		Size:       size,
+		TotalPages: totalPages,
+		HasNext:    page < totalPages,
+	}, nil
+}
+
+// PaginateAfter is used to query the page of documents after the cursor token with keyset pagination
+// An empty token queries the first page, NextToken of the result is the token of the next page
+// The sort fields are the bson paths of the keyset, a "-" prefix sorts the field in descending order
+// _id is appended to the keyset with the order of the last field to break ties unless it is already present
+// The sort fields must be set to a value other than null in every document, otherwise ErrMissingSortField is returned
+// for the page whose last document misses one
+// The documents are queried through Find so the hooks of Find also apply
+func (f *Finder[T]) PaginateAfter(ctx context.Context, token string, size int64, sortFields ...string) (*CursorPage[T], error) {
+	if size < 1 {
+		return nil, ErrInvalidPageSize
+	}
+	fields := parseSortFields(sortFields)
+	sort := make(bson.D, 0, len(fields))
+	for _, field := range fields {
+		sort = append(sort, bson.E{Key: field.key, Value: field.order})
+	}
+
+	filter := f.scopedFilter()
+	if token != "" {
+		values, err := decodeCursorToken(token, fields)
+		if err != nil {
+			return nil, err
+		}
+		filter = bson.D{bson.E{Key: "$and", Value: bson.A{filter, keysetFilter(fields, values)}}}
+	}
+
+	items, err := f.find(ctx, filter, options.Find().SetSort(sort).SetLimit(size+1))
+	if err != nil {
+		return nil, err
+	}
+	page := &CursorPage[T]{Items: items}
+	if int64(len(items)) > size {
+		page.Items = items[:size]
+		page.HasNext = true
+		page.NextToken, err = encodeCursorToken(page.Items[size-1], fields)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return page, nil
+}
+
+func parseSortFields(sortFields []string) []sortField {
+	fields := make([]sortField, 0, len(sortFields)+1)
+	order := 1
+	for _, field := range sortFields {
+		order = 1
+		if strings.HasPrefix(field, "-") {
+			field, order = field[1:], -1
+		}
+		fields = append(fields, sortField{key: field, order: order})
+	}
+	if !slices.ContainsFunc(fields, func(field sortField) bool { return field.key == "_id" }) {
+		fields = append(fields, sortField{key: "_id", order: order})
+	}
+	return fields
+}
+
+// keysetFilter matches the documents after the given values of the sort fields
+// e.g. {$or: [{a: {$gt: va}}, {a: va, _id: {$gt: vid}}]}
+func keysetFilter(fields []sortField, values bson.A) bson.D {
+	or := make(bson.A, 0, len(fields))
+	for i, field := range fields {
+		cond := make(bson.D, 0, i+1)
+		for j := 0; j < i; j++ {
+			cond = append(cond, bson.E{Key: fields[j].key, Value: values[j]})
+		}
+		op := "$gt"
+		if field.order < 0 {
+			op = "$lt"
+		}
+		cond = append(cond, bson.E{Key: field.key, Value: bson.D{bson.E{Key: op, Value: values[i]}}})
+		or = append(or, cond)
+	}
+	return bson.D{bson.E{Key: "$or", Value: or}}
+}
+
+// encodeCursorToken returns the token of the values of the sort fields in the document
+// A sort field which is missing or null in the document is reported with ErrMissingSortField
+func encodeCursorToken(doc any, fields []sortField) (string, error) {
+	raw, err := bson.Marshal(doc)
+	if err != nil {
+		return "", err
+	}
+	token := cursorToken{Keys: make([]string, 0, len(fields)), Values: make(bson.A, 0, len(fields))}
+	for _, field := range fields {
+		value, err := bson.Raw(raw).LookupErr(strings.Split(field.key, ".")...)
+		if err != nil || value.Type == bson.TypeNull || value.Type == bson.TypeUndefined {
+			return "", fmt.Errorf("%w: %q", ErrMissingSortField, field.key)
+		}
+		token.Keys = append(token.Keys, field.key)
+		token.Values = append(token.Values, value)
+	}
+	b, err := bson.Marshal(token)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func decodeCursorToken(token string, fields []sortField) (bson.A, error) {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursorToken
+	}
+	var ct cursorToken
+	if err = bson.Unmarshal(b, &ct); err != nil || len(ct.Keys) != len(fields) || len(ct.Values) != len(fields) {
+		return nil, ErrInvalidCursorToken
+	}
+	for i, field := range fields {
+		if ct.Keys[i] != field.key {
+			return nil, ErrInvalidCursorToken
+		}
+	}
+	return ct.Values, nil
+}
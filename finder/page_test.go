@@ -0,0 +1,100 @@
+package finder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func Test_parseSortFields(t *testing.T) {
+	testCases := []struct {
+		name       string
+		sortFields []string
+		want       []sortField
+	}{
+		{
+			name: "no sort fields",
+			want: []sortField{{key: "_id", order: 1}},
+		},
+		{
+			name:       "ascending",
+			sortFields: []string{"age"},
+			want:       []sortField{{key: "age", order: 1}, {key: "_id", order: 1}},
+		},
+		{
+			name:       "descending",
+			sortFields: []string{"age", "-created_at"},
+			want:       []sortField{{key: "age", order: 1}, {key: "created_at", order: -1}, {key: "_id", order: -1}},
+		},
+		{
+			name:       "_id present",
+			sortFields: []string{"-_id", "age"},
+			want:       []sortField{{key: "_id", order: -1}, {key: "age", order: 1}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, parseSortFields(tc.sortFields))
+		})
+	}
+}
+
+func Test_keysetFilter(t *testing.T) {
+	fields := []sortField{{key: "age", order: 1}, {key: "_id", order: -1}}
+	id := bson.NewObjectID()
+	want := bson.D{{Key: "$or", Value: bson.A{
+		bson.D{{Key: "age", Value: bson.D{{Key: "$gt", Value: 24}}}},
+		bson.D{{Key: "age", Value: 24}, {Key: "_id", Value: bson.D{{Key: "$lt", Value: id}}}},
+	}}}
+	assert.Equal(t, want, keysetFilter(fields, bson.A{24, id}))
+}
+
+func Test_cursorToken(t *testing.T) {
+	fields := parseSortFields([]string{"-age"})
+	user := &TestUser{ID: bson.NewObjectID(), Name: "Mingyong Chen", Age: 24}
+
+	token, err := encodeCursorToken(user, fields)
+	require.NoError(t, err)
+	require.NotEmpty(t, token)
+
+	values, err := decodeCursorToken(token, fields)
+	require.NoError(t, err)
+	assert.Equal(t, bson.A{int64(24), user.ID}, values)
+
+	t.Run("missing field", func(t *testing.T) {
+		_, err := encodeCursorToken(user, parseSortFields([]string{"email"}))
+		assert.ErrorIs(t, err, ErrMissingSortField)
+		assert.EqualError(t, err, `mongox: missing sort field: "email"`)
+	})
+	t.Run("null field", func(t *testing.T) {
+		doc := bson.D{{Key: "_id", Value: user.ID}, {Key: "age", Value: nil}}
+		_, err := encodeCursorToken(doc, fields)
+		assert.ErrorIs(t, err, ErrMissingSortField)
+	})
+	t.Run("nested field", func(t *testing.T) {
+		doc := bson.D{{Key: "_id", Value: user.ID}, {Key: "profile", Value: bson.D{{Key: "age", Value: 24}}}}
+		nested := parseSortFields([]string{"profile.age"})
+		token, err := encodeCursorToken(doc, nested)
+		require.NoError(t, err)
+		values, err := decodeCursorToken(token, nested)
+		require.NoError(t, err)
+		assert.Equal(t, bson.A{int32(24), user.ID}, values)
+
+		_, err = encodeCursorToken(bson.D{{Key: "_id", Value: user.ID}, {Key: "profile", Value: bson.D{}}}, nested)
+		assert.ErrorIs(t, err, ErrMissingSortField)
+	})
+	t.Run("not base64", func(t *testing.T) {
+		_, err := decodeCursorToken("!!!", fields)
+		assert.Equal(t, ErrInvalidCursorToken, err)
+	})
+	t.Run("not bson", func(t *testing.T) {
+		_, err := decodeCursorToken("YWJj", fields)
+		assert.Equal(t, ErrInvalidCursorToken, err)
+	})
+	t.Run("different sort fields", func(t *testing.T) {
+		_, err := decodeCursorToken(token, parseSortFields([]string{"name"}))
+		assert.Equal(t, ErrInvalidCursorToken, err)
+	})
+}
@@ -14,6 +14,7 @@ type OpContext struct {
 	Col          *mongo.Collection `opt:"-"`
 	Filter       any               `opt:"-"`
 	Updates      any
+	Replacement  any
 	MongoOptions any
 	ModelHook    any
 }
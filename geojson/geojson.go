@@ -0,0 +1,128 @@
+// Package geojson provides the GeoJSON objects stored in and queried against the 2dsphere indexed fields,
+// the coordinates are given in the longitude, latitude order
+package geojson
+
+import (
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const (
+	TypePoint        = "Point"
+	TypeLineString   = "LineString"
+	TypePolygon      = "Polygon"
+	TypeMultiPolygon = "MultiPolygon"
+)
+
+var ErrInvalidType = errors.New("mongox: invalid GeoJSON type")
+
+// geometry is the document a GeoJSON object is encoded as
+type geometry[T any] struct {
+	Type        string `bson:"type"`
+	Coordinates T      `bson:"coordinates"`
+}
+
+// Point is a single position, e.g. the location of a store
+type Point struct {
+	Coordinates []float64
+}
+
+func NewPoint(longitude, latitude float64) Point {
+	return Point{Coordinates: []float64{longitude, latitude}}
+}
+
+func (p Point) MarshalBSON() ([]byte, error) {
+	return bson.Marshal(geometry[[]float64]{Type: TypePoint, Coordinates: p.Coordinates})
+}
+
+// IsZero reports whether the point has no coordinates, so that a field tagged omitempty skips it
+func (p Point) IsZero() bool {
+	return len(p.Coordinates) == 0
+}
+
+func (p *Point) UnmarshalBSON(data []byte) error {
+	return unmarshal(data, TypePoint, &p.Coordinates)
+}
+
+// LineString is a line of two or more positions
+type LineString struct {
+	Coordinates [][]float64
+}
+
+func NewLineString(positions ...[]float64) LineString {
+	return LineString{Coordinates: positions}
+}
+
+func (l LineString) MarshalBSON() ([]byte, error) {
+	return bson.Marshal(geometry[[][]float64]{Type: TypeLineString, Coordinates: l.Coordinates})
+}
+
+func (l LineString) IsZero() bool {
+	return len(l.Coordinates) == 0
+}
+
+func (l *LineString) UnmarshalBSON(data []byte) error {
+	return unmarshal(data, TypeLineString, &l.Coordinates)
+}
+
+// Polygon is made of linear rings, the first is the exterior one and the others are the holes within it
+// A ring must be closed, its first and last positions are the same
+type Polygon struct {
+	Coordinates [][][]float64
+}
+
+func NewPolygon(rings ...[][]float64) Polygon {
+	return Polygon{Coordinates: rings}
+}
+
+func (p Polygon) MarshalBSON() ([]byte, error) {
+	return bson.Marshal(geometry[[][][]float64]{Type: TypePolygon, Coordinates: p.Coordinates})
+}
+
+func (p Polygon) IsZero() bool {
+	return len(p.Coordinates) == 0
+}
+
+func (p *Polygon) UnmarshalBSON(data []byte) error {
+	return unmarshal(data, TypePolygon, &p.Coordinates)
+}
+
+type MultiPolygon struct {
+	Coordinates [][][][]float64
+}
+
+func NewMultiPolygon(polygons ...Polygon) MultiPolygon {
+	coordinates := make([][][][]float64, 0, len(polygons))
+	for _, p := range polygons {
+		coordinates = append(coordinates, p.Coordinates)
+	}
+	return MultiPolygon{Coordinates: coordinates}
+}
+
+func (m MultiPolygon) MarshalBSON() ([]byte, error) {
+	return bson.Marshal(geometry[[][][][]float64]{Type: TypeMultiPolygon, Coordinates: m.Coordinates})
+}
+
+func (m MultiPolygon) IsZero() bool {
+	return len(m.Coordinates) == 0
+}
+
+func (m *MultiPolygon) UnmarshalBSON(data []byte) error {
+	return unmarshal(data, TypeMultiPolygon, &m.Coordinates)
+}
+
+// unmarshal decodes the coordinates of the document, which must be a GeoJSON object of the given type
+func unmarshal[T any](data []byte, typ string, coordinates *T) error {
+	// the type is checked first as the coordinates of another type do not decode into those of this one
+	if got, _ := bson.Raw(data).Lookup("type").StringValueOK(); got != typ {
+		return fmt.Errorf("%w: %q is not %s", ErrInvalidType, got, typ)
+	}
+	var g geometry[T]
+	if err := bson.Unmarshal(data, &g); err != nil {
+		return err
+	}
+	*coordinates = g.Coordinates
+	return nil
+}
@@ -0,0 +1,102 @@
+package geojson
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestMarshal(t *testing.T) {
+	square := [][]float64{{0, 0}, {0, 1}, {1, 1}, {1, 0}, {0, 0}}
+	testCases := []struct {
+		name  string
+		value any
+		want  bson.D
+	}{
+		{
+			name:  "point",
+			value: NewPoint(-73.97, 40.77),
+			want:  bson.D{{Key: "type", Value: "Point"}, {Key: "coordinates", Value: bson.A{-73.97, 40.77}}},
+		},
+		{
+			name:  "pointer to point",
+			value: &Point{Coordinates: []float64{1, 2}},
+			want:  bson.D{{Key: "type", Value: "Point"}, {Key: "coordinates", Value: bson.A{1.0, 2.0}}},
+		},
+		{
+			name:  "line string",
+			value: NewLineString([]float64{0, 0}, []float64{1, 1}),
+			want: bson.D{{Key: "type", Value: "LineString"}, {Key: "coordinates", Value: bson.A{
+				bson.A{0.0, 0.0}, bson.A{1.0, 1.0},
+			}}},
+		},
+		{
+			name:  "polygon",
+			value: NewPolygon(square),
+			want: bson.D{{Key: "type", Value: "Polygon"}, {Key: "coordinates", Value: bson.A{
+				bson.A{bson.A{0.0, 0.0}, bson.A{0.0, 1.0}, bson.A{1.0, 1.0}, bson.A{1.0, 0.0}, bson.A{0.0, 0.0}},
+			}}},
+		},
+		{
+			name:  "multi polygon",
+			value: NewMultiPolygon(NewPolygon(square)),
+			want: bson.D{{Key: "type", Value: "MultiPolygon"}, {Key: "coordinates", Value: bson.A{
+				bson.A{bson.A{bson.A{0.0, 0.0}, bson.A{0.0, 1.0}, bson.A{1.0, 1.0}, bson.A{1.0, 0.0}, bson.A{0.0, 0.0}}},
+			}}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := bson.Marshal(bson.D{{Key: "location", Value: tc.value}})
+			require.NoError(t, err)
+			var got bson.D
+			require.NoError(t, bson.Unmarshal(data, &got))
+			assert.Equal(t, bson.D{{Key: "location", Value: tc.want}}, got)
+		})
+	}
+}
+
+func TestMarshal_omitempty(t *testing.T) {
+	type place struct {
+		Point        Point        `bson:"point,omitempty"`
+		LineString   LineString   `bson:"line_string,omitempty"`
+		Polygon      Polygon      `bson:"polygon,omitempty"`
+		MultiPolygon MultiPolygon `bson:"multi_polygon,omitempty"`
+		Location     Point        `bson:"location"`
+	}
+
+	data, err := bson.Marshal(place{})
+	require.NoError(t, err)
+	var got bson.D
+	require.NoError(t, bson.Unmarshal(data, &got))
+	assert.Equal(t, bson.D{{Key: "location", Value: bson.D{{Key: "type", Value: "Point"}, {Key: "coordinates", Value: nil}}}}, got)
+
+	data, err = bson.Marshal(place{Point: NewPoint(1, 2)})
+	require.NoError(t, err)
+	got = nil
+	require.NoError(t, bson.Unmarshal(data, &got))
+	assert.Equal(t, "point", got[0].Key)
+}
+
+func TestUnmarshal(t *testing.T) {
+	type store struct {
+		Location Point `bson:"location"`
+		Area     *Polygon
+	}
+	want := store{
+		Location: NewPoint(-73.97, 40.77),
+		Area:     &Polygon{Coordinates: [][][]float64{{{0, 0}, {0, 1}, {1, 1}, {0, 0}}}},
+	}
+	data, err := bson.Marshal(want)
+	require.NoError(t, err)
+	var got store
+	require.NoError(t, bson.Unmarshal(data, &got))
+	assert.Equal(t, want, got)
+
+	data, err = bson.Marshal(bson.D{{Key: "location", Value: NewLineString([]float64{0, 0}, []float64{1, 1})}})
+	require.NoError(t, err)
+	err = bson.Unmarshal(data, &got)
+	assert.ErrorIs(t, err, ErrInvalidType)
+}
@@ -0,0 +1,256 @@
+package audit
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/matiniiuu/mongox/operation"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// SnapshotLimit is the maximum number of documents kept in a snapshot of an operation which writes many documents
+const SnapshotLimit = 100
+
+// Operation is the kind of write recorded in the audit trail
+type Operation string
+
+const (
+	OperationInsert  Operation = "insert"
+	OperationUpdate  Operation = "update"
+	OperationUpsert  Operation = "upsert"
+	OperationReplace Operation = "replace"
+	OperationDelete  Operation = "delete"
+)
+
+// Record is the audit record written to the sink after every write
+type Record struct {
+	Collection string    `bson:"collection"`
+	Operation  Operation `bson:"operation"`
+	Filter     any       `bson:"filter,omitempty"`
+	Updates    any       `bson:"updates,omitempty"`
+	// Doc is the inserted document, or documents, or the replacement
+	Doc       any       `bson:"doc,omitempty"`
+	Actor     any       `bson:"actor,omitempty"`
+	Timestamp time.Time `bson:"timestamp"`
+	// Before and After are the documents before and after the write, they are only taken if the snapshots are enabled
+	Before []bson.M `bson:"before,omitempty"`
+	After  []bson.M `bson:"after,omitempty"`
+}
+
+// Extractor returns the actor of the operation from the context, ok is false if there is none
+type Extractor func(ctx context.Context) (actor any, ok bool)
+
+// ErrorHandler receives the error of a record written once its transaction commits, when the operation has already returned
+type ErrorHandler func(ctx context.Context, record *Record, err error)
+
+type (
+	actorKey  struct{}
+	beforeKey struct{}
+)
+
+var (
+	sink         Sink
+	extractor    Extractor = FromContext
+	snapshot     bool
+	errorHandler ErrorHandler
+)
+
+// SetSink replaces the sink of the audit records, nothing is recorded while the sink is nil
+func SetSink(s Sink) {
+	sink = s
+}
+
+// SetExtractor replaces the extractor of the actor, a nil extractor resets it to FromContext
+func SetExtractor(e Extractor) {
+	if e == nil {
+		e = FromContext
+	}
+	extractor = e
+}
+
+// SetErrorHandler replaces the handler of the errors of the records written at commit time, they are dropped while it is nil
+func SetErrorHandler(h ErrorHandler) {
+	errorHandler = h
+}
+
+// SetSnapshot turns the before and after snapshots of the written documents on or off
+// Taking the snapshots costs a read before and after every write
+func SetSnapshot(enabled bool) {
+	snapshot = enabled
+}
+
+// NewContext returns a copy of the context which carries the actor for FromContext
+func NewContext(ctx context.Context, actor any) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// FromContext is the default extractor, it returns the actor set by NewContext
+func FromContext(ctx context.Context) (any, bool) {
+	actor := ctx.Value(actorKey{})
+	return actor, actor != nil
+}
+
+// Execute writes the audit record of the operation to the sink in the after callbacks
+// If the snapshots are enabled, the documents matched by the filter are read in the before callbacks
+// An error of the sink is returned to the caller of the operation although the write itself has been done
+// In a transaction of mongox.WithTransaction, the collection sink writes the record in the transaction, the other
+// sinks receive it once the transaction commits, so that they miss the aborted and retried attempts, and their errors
+// go to the handler of SetErrorHandler as the operation has already returned
+func Execute(ctx context.Context, opCtx *operation.OpContext, opType operation.OpType, _ ...any) error {
+	if opCtx == nil || sink == nil {
+		return nil
+	}
+	switch opType {
+	case operation.OpTypeBeforeUpdate, operation.OpTypeBeforeUpsert, operation.OpTypeBeforeReplace, operation.OpTypeBeforeDelete:
+		if !snapshot {
+			return nil
+		}
+		docs, err := find(ctx, opCtx.Col, opCtx.Filter, limit(opCtx))
+		if err != nil {
+			return err
+		}
+		opCtx.SetValue(beforeKey{}, docs)
+		return nil
+	case operation.OpTypeAfterInsert, operation.OpTypeAfterUpdate, operation.OpTypeAfterUpsert,
+		operation.OpTypeAfterReplace, operation.OpTypeAfterDelete:
+	default:
+		return nil
+	}
+
+	record := &Record{
+		Operation: operations[opType],
+		Filter:    opCtx.Filter,
+		Updates:   opCtx.Updates,
+		Timestamp: time.Now().Local(),
+	}
+	if opCtx.Col != nil {
+		record.Collection = opCtx.Col.Name()
+	}
+	switch opType {
+	case operation.OpTypeAfterInsert:
+		record.Doc = opCtx.Doc
+	case operation.OpTypeAfterReplace:
+		record.Doc = opCtx.Replacement
+	}
+	if actor, ok := extractor(ctx); ok {
+		record.Actor = actor
+	}
+	if snapshot {
+		if err := takeSnapshots(ctx, opCtx, opType, record); err != nil {
+			return err
+		}
+	}
+	if _, ok := sink.(collectionSink); !ok && opCtx.Transactional {
+		s, handle := sink, errorHandler
+		if operation.OnCommit(ctx, func(ctx context.Context) {
+			if err := s.Write(ctx, record); err != nil && handle != nil {
+				handle(ctx, record, err)
+			}
+		}) {
+			return nil
+		}
+	}
+	return sink.Write(ctx, record)
+}
+
+var operations = map[operation.OpType]Operation{
+	operation.OpTypeAfterInsert:  OperationInsert,
+	operation.OpTypeAfterUpdate:  OperationUpdate,
+	operation.OpTypeAfterUpsert:  OperationUpsert,
+	operation.OpTypeAfterReplace: OperationReplace,
+	operation.OpTypeAfterDelete:  OperationDelete,
+}
+
+// takeSnapshots sets the snapshots of the record, the documents after the write are read again by their _id
+func takeSnapshots(ctx context.Context, opCtx *operation.OpContext, opType operation.OpType, record *Record) error {
+	record.Before, _ = opCtx.Value(beforeKey{}).([]bson.M)
+	switch opType {
+	case operation.OpTypeAfterInsert:
+		docs, err := toDocs(opCtx.Doc)
+		if err != nil {
+			return err
+		}
+		record.After = docs
+		return nil
+	case operation.OpTypeAfterDelete:
+		// the soft deleted documents are still there, but they are deleted as far as the callers can tell
+		return nil
+	}
+	filter, n := opCtx.Filter, limit(opCtx)
+	if len(record.Before) != 0 {
+		ids := make(bson.A, 0, len(record.Before))
+		for _, doc := range record.Before {
+			ids = append(ids, doc["_id"])
+		}
+		filter, n = bson.D{bson.E{Key: "_id", Value: bson.D{bson.E{Key: "$in", Value: ids}}}}, int64(len(ids))
+	}
+	docs, err := find(ctx, opCtx.Col, filter, n)
+	if err != nil {
+		return err
+	}
+	record.After = docs
+	return nil
+}
+
+// find reads the documents with the driver so that the reads run none of the callbacks
+func find(ctx context.Context, col *mongo.Collection, filter any, n int64) ([]bson.M, error) {
+	if col == nil || filter == nil {
+		return nil, nil
+	}
+	cursor, err := col.Find(ctx, filter, options.Find().SetLimit(n))
+	if err != nil {
+		return nil, err
+	}
+	var docs []bson.M
+	if err = cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	return docs, nil
+}
+
+// limit returns the number of documents the operation may write, as far as its options tell
+func limit(opCtx *operation.OpContext) int64 {
+	switch opCtx.MongoOptions.(type) {
+	case []options.Lister[options.UpdateOneOptions], []options.Lister[options.ReplaceOptions],
+		[]options.Lister[options.DeleteOneOptions], []options.Lister[options.FindOneAndDeleteOptions],
+		[]options.Lister[options.FindOneAndUpdateOptions], []options.Lister[options.FindOneAndReplaceOptions]:
+		return 1
+	}
+	return SnapshotLimit
+}
+
+// toDocs converts the inserted document or documents to their bson form
+func toDocs(doc any) ([]bson.M, error) {
+	if doc == nil {
+		return nil, nil
+	}
+	values := []any{doc}
+	value := reflect.ValueOf(doc)
+	switch doc.(type) {
+	case bson.D, bson.Raw:
+	default:
+		if value.Kind() == reflect.Slice {
+			values = make([]any, 0, value.Len())
+			for i := 0; i < value.Len(); i++ {
+				values = append(values, value.Index(i).Interface())
+			}
+		}
+	}
+	result := make([]bson.M, 0, len(values))
+	for _, v := range values {
+		data, err := bson.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		var m bson.M
+		if err = bson.Unmarshal(data, &m); err != nil {
+			return nil, err
+		}
+		result = append(result, m)
+	}
+	return result, nil
+}
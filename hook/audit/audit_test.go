@@ -0,0 +1,225 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/matiniiuu/mongox/operation"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type testUser struct {
+	Name string `bson:"name"`
+	Age  int    `bson:"age"`
+}
+
+func TestExecute(t *testing.T) {
+	var records []*Record
+	SetSink(SinkFunc(func(_ context.Context, record *Record) error {
+		records = append(records, record)
+		return nil
+	}))
+	defer SetSink(nil)
+
+	ctx := NewContext(context.Background(), "Mingyong Chen")
+	user := &testUser{Name: "burt", Age: 18}
+	filter := bson.D{{Key: "name", Value: "burt"}}
+
+	testCases := []struct {
+		name   string
+		ctx    context.Context
+		opCtx  *operation.OpContext
+		opType operation.OpType
+		want   *Record
+	}{
+		{
+			name:   "insert",
+			ctx:    ctx,
+			opCtx:  operation.NewOpContext(nil, operation.WithDoc(user)),
+			opType: operation.OpTypeAfterInsert,
+			want:   &Record{Operation: OperationInsert, Doc: user, Actor: "Mingyong Chen"},
+		},
+		{
+			name:   "update",
+			ctx:    ctx,
+			opCtx:  operation.NewOpContext(nil, operation.WithFilter(filter), operation.WithUpdates(bson.M{"$set": bson.M{"age": 19}})),
+			opType: operation.OpTypeAfterUpdate,
+			want:   &Record{Operation: OperationUpdate, Filter: filter, Updates: bson.M{"$set": bson.M{"age": 19}}, Actor: "Mingyong Chen"},
+		},
+		{
+			name:   "replace",
+			ctx:    ctx,
+			opCtx:  operation.NewOpContext(nil, operation.WithFilter(filter), operation.WithDoc(user), operation.WithReplacement(user)),
+			opType: operation.OpTypeAfterReplace,
+			want:   &Record{Operation: OperationReplace, Filter: filter, Doc: user, Actor: "Mingyong Chen"},
+		},
+		{
+			name:   "delete without actor",
+			ctx:    context.Background(),
+			opCtx:  operation.NewOpContext(nil, operation.WithFilter(filter)),
+			opType: operation.OpTypeAfterDelete,
+			want:   &Record{Operation: OperationDelete, Filter: filter},
+		},
+		{
+			name:   "other operation",
+			ctx:    ctx,
+			opCtx:  operation.NewOpContext(nil, operation.WithFilter(filter)),
+			opType: operation.OpTypeAfterFind,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			records = nil
+			require.NoError(t, Execute(tc.ctx, tc.opCtx, tc.opType))
+			if tc.want == nil {
+				assert.Empty(t, records)
+				return
+			}
+			require.Len(t, records, 1)
+			assert.False(t, records[0].Timestamp.IsZero())
+			// the records are timed in local time, as the soft deletes and the default fields are
+			assert.Equal(t, time.Local, records[0].Timestamp.Location())
+			records[0].Timestamp = tc.want.Timestamp
+			assert.Equal(t, tc.want, records[0])
+		})
+	}
+}
+
+func TestExecute_Sink(t *testing.T) {
+	opCtx := operation.NewOpContext(nil, operation.WithDoc(&testUser{Name: "burt"}))
+
+	t.Run("no sink", func(t *testing.T) {
+		assert.NoError(t, Execute(context.Background(), opCtx, operation.OpTypeAfterInsert))
+	})
+	t.Run("sink error", func(t *testing.T) {
+		sinkErr := errors.New("sink error")
+		SetSink(SinkFunc(func(_ context.Context, _ *Record) error {
+			return sinkErr
+		}))
+		defer SetSink(nil)
+		assert.Equal(t, sinkErr, Execute(context.Background(), opCtx, operation.OpTypeAfterInsert))
+	})
+	t.Run("extractor", func(t *testing.T) {
+		var record *Record
+		SetSink(SinkFunc(func(_ context.Context, r *Record) error {
+			record = r
+			return nil
+		}))
+		SetExtractor(func(_ context.Context) (any, bool) {
+			return "system", true
+		})
+		defer func() {
+			SetSink(nil)
+			SetExtractor(nil)
+		}()
+		require.NoError(t, Execute(context.Background(), opCtx, operation.OpTypeAfterInsert))
+		assert.Equal(t, "system", record.Actor)
+	})
+}
+
+func TestExecute_Transaction(t *testing.T) {
+	var records []*Record
+	SetSink(SinkFunc(func(_ context.Context, r *Record) error {
+		records = append(records, r)
+		return nil
+	}))
+	defer SetSink(nil)
+
+	newOpCtx := func() *operation.OpContext {
+		opCtx := operation.NewOpContext(nil, operation.WithDoc(&testUser{Name: "burt"}))
+		opCtx.Transactional = true
+		return opCtx
+	}
+
+	t.Run("aborted", func(t *testing.T) {
+		records = nil
+		ctx, _ := operation.WithCommitHooks(context.Background())
+		require.NoError(t, Execute(ctx, newOpCtx(), operation.OpTypeAfterInsert))
+		assert.Empty(t, records)
+	})
+	t.Run("retried and committed", func(t *testing.T) {
+		records = nil
+		// the first attempt is aborted and its hooks are dropped, as mongox.WithTransaction does
+		ctx, _ := operation.WithCommitHooks(context.Background())
+		require.NoError(t, Execute(ctx, newOpCtx(), operation.OpTypeAfterInsert))
+		ctx, runCommitHooks := operation.WithCommitHooks(context.Background())
+		require.NoError(t, Execute(ctx, newOpCtx(), operation.OpTypeAfterInsert))
+		assert.Empty(t, records)
+
+		runCommitHooks(ctx)
+		require.Len(t, records, 1)
+		assert.Equal(t, OperationInsert, records[0].Operation)
+	})
+	t.Run("committed write error", func(t *testing.T) {
+		writeErr := errors.New("sink unavailable")
+		SetSink(SinkFunc(func(_ context.Context, _ *Record) error {
+			return writeErr
+		}))
+		var handled []error
+		SetErrorHandler(func(_ context.Context, record *Record, err error) {
+			assert.Equal(t, OperationInsert, record.Operation)
+			handled = append(handled, err)
+		})
+		defer SetErrorHandler(nil)
+
+		ctx, runCommitHooks := operation.WithCommitHooks(context.Background())
+		require.NoError(t, Execute(ctx, newOpCtx(), operation.OpTypeAfterInsert))
+		assert.Empty(t, handled)
+		runCommitHooks(ctx)
+		assert.Equal(t, []error{writeErr}, handled)
+		SetSink(SinkFunc(func(_ context.Context, r *Record) error {
+			records = append(records, r)
+			return nil
+		}))
+	})
+	t.Run("transaction not started by mongox", func(t *testing.T) {
+		records = nil
+		require.NoError(t, Execute(context.Background(), newOpCtx(), operation.OpTypeAfterInsert))
+		assert.Len(t, records, 1)
+	})
+}
+
+func TestNewChanSink(t *testing.T) {
+	ch := make(chan *Record, 1)
+	sink := NewChanSink(ch)
+	record := &Record{Operation: OperationInsert}
+	require.NoError(t, sink.Write(context.Background(), record))
+	assert.Equal(t, record, <-ch)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Equal(t, context.Canceled, NewChanSink(make(chan *Record)).Write(ctx, record))
+}
+
+func Test_limit(t *testing.T) {
+	assert.Equal(t, int64(1), limit(operation.NewOpContext(nil, operation.WithMongoOptions([]options.Lister[options.UpdateOneOptions]{}))))
+	assert.Equal(t, int64(1), limit(operation.NewOpContext(nil, operation.WithMongoOptions([]options.Lister[options.DeleteOneOptions](nil)))))
+	assert.Equal(t, int64(SnapshotLimit), limit(operation.NewOpContext(nil, operation.WithMongoOptions([]options.Lister[options.UpdateManyOptions]{}))))
+	assert.Equal(t, int64(SnapshotLimit), limit(operation.NewOpContext(nil)))
+}
+
+func Test_toDocs(t *testing.T) {
+	testCases := []struct {
+		name string
+		doc  any
+		want []bson.M
+	}{
+		{name: "nil", doc: nil, want: nil},
+		{name: "struct", doc: &testUser{Name: "burt", Age: 18}, want: []bson.M{{"name": "burt", "age": int32(18)}}},
+		{name: "structs", doc: []*testUser{{Name: "burt"}, {Name: "Mingyong Chen"}}, want: []bson.M{{"name": "burt", "age": int32(0)}, {"name": "Mingyong Chen", "age": int32(0)}}},
+		{name: "document", doc: bson.D{{Key: "name", Value: "burt"}}, want: []bson.M{{"name": "burt"}}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := toDocs(tc.doc)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package audit
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// Sink receives the audit records
+type Sink interface {
+	Write(ctx context.Context, record *Record) error
+}
+
+// SinkFunc adapts a function to a Sink
+type SinkFunc func(ctx context.Context, record *Record) error
+
+func (f SinkFunc) Write(ctx context.Context, record *Record) error {
+	return f(ctx, record)
+}
+
+// NewCollectionSink returns a sink which inserts the records into the collection, e.g. the Collection() of a mongox collection
+// The records are inserted with the driver, so that they are neither recorded again nor changed by the other callbacks
+func NewCollectionSink(collection *mongo.Collection) Sink {
+	return collectionSink{collection: collection}
+}
+
+// collectionSink inserts the records in the transaction of the operation if there is one,
+// so that the records of an aborted transaction are rolled back together with its writes
+type collectionSink struct {
+	collection *mongo.Collection
+}
+
+func (s collectionSink) Write(ctx context.Context, record *Record) error {
+	_, err := s.collection.InsertOne(ctx, record)
+	return err
+}
+
+// NewChanSink returns a sink which sends the records to the channel
+// Sending blocks until the channel receives the record or the context of the operation is done
+func NewChanSink(ch chan<- *Record) Sink {
+	return SinkFunc(func(ctx context.Context, record *Record) error {
+		select {
+		case ch <- record:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+}
@@ -2,9 +2,17 @@ package field
 
 import (
 	"context"
+	"errors"
 	"reflect"
+	"strings"
 
+	"github.com/matiniiuu/mongox/bsonx"
+	"github.com/matiniiuu/mongox/hook"
 	"github.com/matiniiuu/mongox/operation"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
 
 func Execute(ctx context.Context, opCtx *operation.OpContext, opType operation.OpType, opts ...any) error {
@@ -21,6 +29,18 @@ func Execute(ctx context.Context, opCtx *operation.OpContext, opType operation.O
 		if valueOf.IsZero() {
 			return nil
 		}
+		// a stage is appended to the update pipelines since their fields can not be added in place
+		if strategy, ok := pipelineStrategies[opType]; ok {
+			if pipeline, ok := bsonx.ToPipeline(opCtx.Updates); ok {
+				opCtx.Updates = strategy(doc, pipeline)
+				return nil
+			}
+		}
+		if opType == operation.OpTypeBeforeReplace {
+			if err := keepCreatedAt(ctx, opCtx.Col, opCtx.Filter, doc); err != nil {
+				return err
+			}
+		}
 		return execute(ctx, doc, opType, opts...)
 	default:
 		return nil
@@ -43,3 +63,63 @@ func execute(_ context.Context, doc any, opType operation.OpType, opts ...any) e
 	}
 	return nil
 }
+
+// keepCreatedAt copies the created time of the document to be replaced into the replacement if the replacement has none,
+// so that replacing a document does not reset its created time
+func keepCreatedAt(ctx context.Context, col *mongo.Collection, filter any, doc any) error {
+	if col == nil || filter == nil {
+		return nil
+	}
+	name := createdAtName(doc)
+	if name == "" {
+		return nil
+	}
+	raw, err := bson.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	if value, err := bson.Raw(raw).LookupErr(strings.Split(name, ".")...); err == nil && !isZeroValue(value) {
+		return nil
+	}
+	existing, err := col.FindOne(ctx, filter, options.FindOne().SetProjection(bson.D{{Key: name, Value: 1}, {Key: "_id", Value: 0}})).Raw()
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return bson.Unmarshal(existing, doc)
+}
+
+func createdAtName(doc any) string {
+	if defaultModel, ok := doc.(hook.DefaultModel); ok {
+		return defaultFieldName(defaultModel, "created_at")
+	}
+	if _, ok := doc.(hook.CustomModel); ok {
+		// the name is taken from a zero value so that the created time of doc is left untouched
+		if zero, ok := reflect.New(reflect.TypeOf(doc).Elem()).Interface().(hook.CustomModel); ok {
+			name, _ := zero.CustomCreatedAt()
+			return name
+		}
+	}
+	return ""
+}
+
+// isZeroValue reports whether the created time is unset, custom models may store it as a number or a string
+func isZeroValue(value bson.RawValue) bool {
+	switch value.Type {
+	case bson.TypeNull, bson.TypeUndefined:
+		return true
+	case bson.TypeDateTime:
+		return value.Time().IsZero()
+	case bson.TypeInt32:
+		return value.Int32() == 0
+	case bson.TypeInt64:
+		return value.Int64() == 0
+	case bson.TypeDouble:
+		return value.Double() == 0
+	case bson.TypeString:
+		return value.StringValue() == ""
+	}
+	return false
+}
@@ -6,8 +6,10 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
 
 	"github.com/matiniiuu/mongox/operation"
 )
@@ -136,6 +138,14 @@ func TestExecute(t *testing.T) {
 			opts:    nil,
 			wantErr: nil,
 		},
+		{
+			name:    "replace without collection",
+			ctx:     context.Background(),
+			opCtx:   operation.NewOpContext(nil, operation.WithDoc(&model{}), operation.WithFilter(bson.M{})),
+			opType:  operation.OpTypeBeforeReplace,
+			opts:    nil,
+			wantErr: nil,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -146,6 +156,21 @@ func TestExecute(t *testing.T) {
 	}
 }
 
+func TestExecute_pipeline(t *testing.T) {
+	stage := bson.D{{Key: "$set", Value: bson.D{{Key: "name", Value: "Mingyong Chen"}}}}
+	updates := []bson.D{stage}
+	doc := &model{}
+	opCtx := operation.NewOpContext(nil, operation.WithDoc(doc), operation.WithUpdates(updates))
+
+	require.NoError(t, Execute(context.Background(), opCtx, operation.OpTypeBeforeUpdate))
+	assert.Equal(t, mongo.Pipeline{
+		stage,
+		{{Key: "$set", Value: bson.D{{Key: "updated_at", Value: bson.D{{Key: "$literal", Value: doc.UpdatedAt}}}}}},
+	}, opCtx.Updates)
+	// the pipeline of the caller is left as it is
+	assert.Equal(t, []bson.D{stage}, updates)
+}
+
 func Test_execute(t *testing.T) {
 	testCases := []struct {
 		name    string
@@ -173,3 +198,39 @@ func Test_execute(t *testing.T) {
 		})
 	}
 }
+
+func Test_createdAtName(t *testing.T) {
+	assert.Equal(t, "created_at", createdAtName(&model{}))
+	assert.Equal(t, "createdAt", createdAtName(&customModel{}))
+	assert.Equal(t, "", createdAtName(&struct{}{}))
+
+	// the created time of a custom model is not touched while resolving the name
+	cm := &customModel{}
+	createdAtName(cm)
+	assert.Zero(t, cm.CreatedAt)
+}
+
+func Test_isZeroValue(t *testing.T) {
+	testCases := []struct {
+		name  string
+		value any
+		want  bool
+	}{
+		{name: "nil", value: nil, want: true},
+		{name: "zero time", value: time.Time{}, want: true},
+		{name: "time", value: time.Now(), want: false},
+		{name: "zero int32", value: int32(0), want: true},
+		{name: "int64", value: int64(1), want: false},
+		{name: "zero int64", value: int64(0), want: true},
+		{name: "zero double", value: float64(0), want: true},
+		{name: "empty string", value: "", want: true},
+		{name: "bool", value: false, want: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			raw, err := bson.Marshal(bson.M{"v": tc.value})
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, isZeroValue(bson.Raw(raw).Lookup("v")))
+		})
+	}
+}
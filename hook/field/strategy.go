@@ -1,9 +1,13 @@
 package field
 
 import (
+	"reflect"
+
 	"github.com/matiniiuu/mongox/hook"
+	"github.com/matiniiuu/mongox/internal/pkg/structs"
 	"github.com/matiniiuu/mongox/operation"
 	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
 )
 
 type (
@@ -14,9 +18,15 @@ type (
 )
 
 var strategies = map[operation.OpType]func(doc any, opts ...any) error{
-	operation.OpTypeBeforeInsert: BeforeInsert,
-	operation.OpTypeBeforeUpdate: BeforeUpdate,
-	operation.OpTypeBeforeUpsert: BeforeUpsert,
+	operation.OpTypeBeforeInsert:  BeforeInsert,
+	operation.OpTypeBeforeUpdate:  BeforeUpdate,
+	operation.OpTypeBeforeUpsert:  BeforeUpsert,
+	operation.OpTypeBeforeReplace: BeforeReplace,
+}
+
+var pipelineStrategies = map[operation.OpType]func(doc any, pipeline mongo.Pipeline) mongo.Pipeline{
+	operation.OpTypeBeforeUpdate: BeforeUpdatePipeline,
+	operation.OpTypeBeforeUpsert: BeforeUpsertPipeline,
 }
 
 func BeforeInsert(doc any, _ ...any) error {
@@ -125,12 +135,100 @@ func BeforeUpsert(doc any, opts ...any) error {
 	idField, createdAtField, updatedAtField := getField("_id", defaultModel, customModel), getField("created_at", defaultModel, customModel), getField("updated_at", defaultModel, customModel)
 	setFields[updatedAtField.name] = updatedAtField.value
 
-	setOnInsertFields[idField.name] = idField.value
-	setOnInsertFields[createdAtField.name] = createdAtField.value
+	// a field can not be updated by $set and $setOnInsert at the same time
+	for _, f := range []field{idField, createdAtField} {
+		if _, ok := setFields[f.name]; !ok {
+			setOnInsertFields[f.name] = f.value
+		}
+	}
 
 	return nil
 }
 
+// BeforeReplace refreshes the updated time of the replacement and sets the created time if it has none
+// The _id is left alone because the _id of the replaced document can not be changed
+func BeforeReplace(doc any, _ ...any) error {
+	if doc == nil {
+		return nil
+	}
+	if defaultModel, ok := doc.(hook.DefaultModel); ok {
+		defaultModel.DefaultCreatedAt()
+		defaultModel.DefaultUpdatedAt()
+	}
+
+	if customModel, ok := doc.(hook.CustomModel); ok {
+		customModel.CustomCreatedAt()
+		customModel.CustomUpdatedAt()
+	}
+
+	return nil
+}
+
+// BeforeUpdatePipeline returns the update pipeline with a $set stage of the updated time appended
+func BeforeUpdatePipeline(doc any, pipeline mongo.Pipeline) mongo.Pipeline {
+	defaultModel, customModel, ok := models(doc)
+	if !ok {
+		return pipeline
+	}
+	updatedAtField := getField("updated_at", defaultModel, customModel)
+	return append(pipeline, bson.D{{Key: "$set", Value: bson.D{{Key: updatedAtField.name, Value: literal(updatedAtField.value)}}}})
+}
+
+// BeforeUpsertPipeline returns the update pipeline with a $set stage of the updated time appended, the _id and the
+// created time are only set if the document has none, which emulates $setOnInsert with $ifNull
+func BeforeUpsertPipeline(doc any, pipeline mongo.Pipeline) mongo.Pipeline {
+	defaultModel, customModel, ok := models(doc)
+	if !ok {
+		return pipeline
+	}
+	idField, createdAtField, updatedAtField := getField("_id", defaultModel, customModel), getField("created_at", defaultModel, customModel), getField("updated_at", defaultModel, customModel)
+	set := bson.D{{Key: updatedAtField.name, Value: literal(updatedAtField.value)}}
+	for _, f := range []field{idField, createdAtField} {
+		set = append(set, bson.E{Key: f.name, Value: bson.D{{Key: "$ifNull", Value: bson.A{"$" + f.name, literal(f.value)}}}})
+	}
+	return append(pipeline, bson.D{{Key: "$set", Value: set}})
+}
+
+func models(doc any) (hook.DefaultModel, hook.CustomModel, bool) {
+	if doc == nil {
+		return nil, nil, false
+	}
+	if defaultModel, ok := doc.(hook.DefaultModel); ok {
+		return defaultModel, nil, true
+	}
+	if customModel, ok := doc.(hook.CustomModel); ok {
+		return nil, customModel, true
+	}
+	return nil, nil, false
+}
+
+// literal keeps a value of a pipeline stage from being taken as an expression, e.g. a string starting with $
+func literal(value any) bson.D {
+	return bson.D{{Key: "$literal", Value: value}}
+}
+
+// defaultFieldNames maps the fields managed by hook.DefaultModel to the Go field names looked up in the model
+// and the names used when the model has no such field
+var defaultFieldNames = map[string]struct {
+	goNames  []string
+	fallback string
+}{
+	"_id":        {goNames: []string{"ID", "Id"}, fallback: "_id"},
+	"created_at": {goNames: []string{"CreatedAt"}, fallback: "created_at"},
+	"updated_at": {goNames: []string{"UpdatedAt"}, fallback: "updated_at"},
+}
+
+// defaultFieldName resolves the bson path of a field managed by hook.DefaultModel from the struct tags of the model
+func defaultFieldName(defaultModel hook.DefaultModel, filed string) string {
+	names := defaultFieldNames[filed]
+	for _, goName := range names.goNames {
+		if f, ok := structs.Lookup(reflect.TypeOf(defaultModel), goName); ok {
+			return f.Path
+		}
+	}
+	return names.fallback
+}
+
 func getField(filed string, defaultModel hook.DefaultModel, customModel hook.CustomModel) field {
 	var (
 		name  string
@@ -139,17 +237,17 @@ func getField(filed string, defaultModel hook.DefaultModel, customModel hook.Cus
 	switch filed {
 	case "_id":
 		if defaultModel != nil {
-			return field{name: "_id", value: defaultModel.DefaultId()}
+			return field{name: defaultFieldName(defaultModel, filed), value: defaultModel.DefaultId()}
 		}
 		name, value = customModel.CustomID()
 	case "created_at":
 		if defaultModel != nil {
-			return field{name: "created_at", value: defaultModel.DefaultCreatedAt()}
+			return field{name: defaultFieldName(defaultModel, filed), value: defaultModel.DefaultCreatedAt()}
 		}
 		name, value = customModel.CustomCreatedAt()
 	case "updated_at":
 		if defaultModel != nil {
-			return field{name: "updated_at", value: defaultModel.DefaultUpdatedAt()}
+			return field{name: defaultFieldName(defaultModel, filed), value: defaultModel.DefaultUpdatedAt()}
 		}
 		name, value = customModel.CustomUpdatedAt()
 	default:
@@ -2,10 +2,12 @@ package field
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -489,8 +491,209 @@ func TestBeforeUpsert(t *testing.T) {
 	}
 }
 
+func TestBeforeReplace(t *testing.T) {
+	createdAt := time.Now().Add(-time.Hour).Local()
+	testCases := []struct {
+		name string
+		doc  any
+
+		wantErr      error
+		validateFunc func(*testing.T, *model, *customModel)
+	}{
+		{
+			name:    "nil document",
+			doc:     nil,
+			wantErr: nil,
+		},
+		{
+			name:    "the type not implement DefaultModel and CustomModel",
+			doc:     struct{}{},
+			wantErr: nil,
+		},
+		{
+			name:    "default model",
+			doc:     &model{},
+			wantErr: nil,
+			validateFunc: func(t *testing.T, defaultModel *model, _ *customModel) {
+				assert.Zero(t, defaultModel.ID)
+				assert.NotZero(t, defaultModel.CreatedAt)
+				assert.NotZero(t, defaultModel.UpdatedAt)
+			},
+		},
+		{
+			name:    "default model keeps the created time",
+			doc:     &model{CreatedAt: createdAt},
+			wantErr: nil,
+			validateFunc: func(t *testing.T, defaultModel *model, _ *customModel) {
+				assert.Equal(t, createdAt, defaultModel.CreatedAt)
+				assert.NotZero(t, defaultModel.UpdatedAt)
+			},
+		},
+		{
+			name:    "custom model",
+			doc:     &customModel{},
+			wantErr: nil,
+			validateFunc: func(t *testing.T, _ *model, customModel *customModel) {
+				assert.Zero(t, customModel.ID)
+				assert.NotZero(t, customModel.CreatedAt)
+				assert.NotZero(t, customModel.UpdatedAt)
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := BeforeReplace(tc.doc)
+			assert.Equal(t, tc.wantErr, err)
+			if tc.validateFunc != nil {
+				m, _ := tc.doc.(*model)
+				cm, _ := tc.doc.(*customModel)
+				tc.validateFunc(t, m, cm)
+			}
+		})
+	}
+}
+
 func Test_getField(t *testing.T) {
 	t.Run("supplement test", func(t *testing.T) {
 		require.Equal(t, field{}, getField("", nil, nil))
 	})
 }
+
+type Base struct {
+	ID        bson.ObjectID `bson:"_id,omitempty"`
+	CreatedAt *time.Time    `bson:"createdAt,omitempty"`
+	UpdatedAt *time.Time    `bson:"updatedAt,omitempty"`
+}
+
+func (m *Base) DefaultId() bson.ObjectID {
+	if m.ID.IsZero() {
+		m.ID = bson.NewObjectID()
+	}
+	return m.ID
+}
+
+func (m *Base) DefaultCreatedAt() time.Time {
+	if m.CreatedAt == nil {
+		now := time.Now().Local()
+		m.CreatedAt = &now
+	}
+	return *m.CreatedAt
+}
+
+func (m *Base) DefaultUpdatedAt() time.Time {
+	now := time.Now().Local()
+	m.UpdatedAt = &now
+	return *m.UpdatedAt
+}
+
+type embeddedModel struct {
+	Base `bson:",inline"`
+	Name string `bson:"name"`
+}
+
+type nestedModel struct {
+	Base
+	Name string `bson:"name"`
+}
+
+func TestBeforeUpsert_fieldNamesFromTags(t *testing.T) {
+	t.Run("inline embedded model", func(t *testing.T) {
+		doc := &embeddedModel{}
+		updates := bson.M{"$set": bson.M{"name": "Mingyong Chen"}}
+		require.NoError(t, BeforeUpsert(doc, updates))
+		assert.Equal(t, bson.M{
+			"$set": bson.M{
+				"name":      "Mingyong Chen",
+				"updatedAt": *doc.UpdatedAt,
+			},
+			"$setOnInsert": bson.M{
+				"_id":       doc.ID,
+				"createdAt": *doc.CreatedAt,
+			},
+		}, updates)
+	})
+	t.Run("embedded model without inline", func(t *testing.T) {
+		doc := &nestedModel{}
+		updates := bson.M{}
+		require.NoError(t, BeforeUpdate(doc, updates))
+		assert.Equal(t, bson.M{
+			"$set": bson.M{
+				"base.updatedAt": *doc.UpdatedAt,
+			},
+		}, updates)
+	})
+	t.Run("skip $setOnInsert for the fields in $set", func(t *testing.T) {
+		doc := &embeddedModel{}
+		createdAt := time.Now().Add(-time.Hour)
+		updates := bson.M{"$set": bson.M{"createdAt": createdAt}}
+		require.NoError(t, BeforeUpsert(doc, updates))
+		assert.Equal(t, bson.M{
+			"$set": bson.M{
+				"createdAt": createdAt,
+				"updatedAt": *doc.UpdatedAt,
+			},
+			"$setOnInsert": bson.M{
+				"_id": doc.ID,
+			},
+		}, updates)
+	})
+}
+
+func TestBeforeUpdatePipeline(t *testing.T) {
+	stage := bson.D{{Key: "$set", Value: bson.D{{Key: "name", Value: "Mingyong Chen"}}}}
+
+	t.Run("not a model", func(t *testing.T) {
+		assert.Equal(t, mongo.Pipeline{stage}, BeforeUpdatePipeline(struct{}{}, mongo.Pipeline{stage}))
+	})
+	t.Run("default model", func(t *testing.T) {
+		doc := &model{}
+		got := BeforeUpdatePipeline(doc, mongo.Pipeline{stage})
+		assert.Equal(t, mongo.Pipeline{
+			stage,
+			{{Key: "$set", Value: bson.D{{Key: "updated_at", Value: bson.D{{Key: "$literal", Value: doc.UpdatedAt}}}}}},
+		}, got)
+	})
+	t.Run("custom model", func(t *testing.T) {
+		doc := &customModel{}
+		got := BeforeUpdatePipeline(doc, mongo.Pipeline{stage})
+		assert.Equal(t, mongo.Pipeline{
+			stage,
+			{{Key: "$set", Value: bson.D{{Key: "updatedAt", Value: bson.D{{Key: "$literal", Value: doc.UpdatedAt}}}}}},
+		}, got)
+	})
+}
+
+func TestBeforeUpsertPipeline(t *testing.T) {
+	stage := bson.D{{Key: "$set", Value: bson.D{{Key: "name", Value: "Mingyong Chen"}}}}
+	ifNull := func(name string, value any) bson.D {
+		return bson.D{{Key: "$ifNull", Value: bson.A{"$" + name, bson.D{{Key: "$literal", Value: value}}}}}
+	}
+
+	t.Run("not a model", func(t *testing.T) {
+		assert.Equal(t, mongo.Pipeline{stage}, BeforeUpsertPipeline(nil, mongo.Pipeline{stage}))
+	})
+	t.Run("default model", func(t *testing.T) {
+		doc := &model{}
+		got := BeforeUpsertPipeline(doc, mongo.Pipeline{stage})
+		assert.Equal(t, mongo.Pipeline{
+			stage,
+			{{Key: "$set", Value: bson.D{
+				{Key: "updated_at", Value: bson.D{{Key: "$literal", Value: doc.UpdatedAt}}},
+				{Key: "_id", Value: ifNull("_id", doc.ID)},
+				{Key: "created_at", Value: ifNull("created_at", doc.CreatedAt)},
+			}}},
+		}, got)
+	})
+	t.Run("custom model", func(t *testing.T) {
+		doc := &customModel{}
+		got := BeforeUpsertPipeline(doc, mongo.Pipeline{stage})
+		assert.Equal(t, mongo.Pipeline{
+			stage,
+			{{Key: "$set", Value: bson.D{
+				{Key: "updatedAt", Value: bson.D{{Key: "$literal", Value: doc.UpdatedAt}}},
+				{Key: "_id", Value: ifNull("_id", doc.ID)},
+				{Key: "createdAt", Value: ifNull("createdAt", doc.CreatedAt)},
+			}}},
+		}, got)
+	})
+}
@@ -41,3 +41,11 @@ type BeforeFind interface {
 type AfterFind interface {
 	AfterFind(ctx context.Context) error
 }
+
+type BeforeReplace interface {
+	BeforeReplace(ctx context.Context) error
+}
+
+type AfterReplace interface {
+	AfterReplace(ctx context.Context) error
+}
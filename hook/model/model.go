@@ -16,10 +16,12 @@ func getPayload(opCtx *operation.OpContext, opType operation.OpType) any {
 	}
 
 	switch opType {
-	case operation.OpTypeBeforeInsert, operation.OpTypeAfterInsert, operation.OpTypeAfterFind:
+	case operation.OpTypeBeforeInsert, operation.OpTypeAfterInsert, operation.OpTypeAfterFind, operation.OpTypeAfterDelete:
 		return opCtx.Doc
 	case operation.OpTypeBeforeUpdate, operation.OpTypeAfterUpdate, operation.OpTypeBeforeUpsert, operation.OpTypeAfterUpsert:
 		return opCtx.Updates
+	case operation.OpTypeBeforeReplace, operation.OpTypeAfterReplace:
+		return opCtx.Replacement
 	default:
 		return opCtx.ModelHook
 	}
@@ -100,6 +102,14 @@ func execute(ctx context.Context, doc any, opType operation.OpType, _ ...any) er
 		if m, ok := doc.(AfterFind); ok {
 			return m.AfterFind(ctx)
 		}
+	case operation.OpTypeBeforeReplace:
+		if m, ok := doc.(BeforeReplace); ok {
+			return m.BeforeReplace(ctx)
+		}
+	case operation.OpTypeAfterReplace:
+		if m, ok := doc.(AfterReplace); ok {
+			return m.AfterReplace(ctx)
+		}
 	}
 	return nil
 }
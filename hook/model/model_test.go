@@ -11,16 +11,18 @@ import (
 )
 
 type entity struct {
-	beforeInsert int
-	afterInsert  int
-	beforeDelete int
-	afterDelete  int
-	beforeUpdate int
-	afterUpdate  int
-	beforeUpsert int
-	afterUpsert  int
-	beforeFind   int
-	afterFind    int
+	beforeInsert  int
+	afterInsert   int
+	beforeDelete  int
+	afterDelete   int
+	beforeUpdate  int
+	afterUpdate   int
+	beforeUpsert  int
+	afterUpsert   int
+	beforeFind    int
+	afterFind     int
+	beforeReplace int
+	afterReplace  int
 }
 
 func (m *entity) BeforeInsert(_ context.Context) error {
@@ -76,6 +78,16 @@ func (m *entity) AfterFind(_ context.Context) error {
 	return nil
 }
 
+func (m *entity) BeforeReplace(_ context.Context) error {
+	m.beforeReplace++
+	return nil
+}
+
+func (m *entity) AfterReplace(_ context.Context) error {
+	m.afterReplace++
+	return nil
+}
+
 func Test_getPayload(t *testing.T) {
 	testCases := []struct {
 		name   string
@@ -143,6 +155,12 @@ func Test_getPayload(t *testing.T) {
 			opType: operation.OpTypeAfterDelete,
 			want:   &entity{},
 		},
+		{
+			name:   "after delete with doc",
+			opCtx:  operation.NewOpContext(nil, operation.WithDoc(&entity{afterDelete: 1})),
+			opType: operation.OpTypeAfterDelete,
+			want:   &entity{afterDelete: 1},
+		},
 		{
 			name:   "before update",
 			opCtx:  operation.NewOpContext(nil, operation.WithUpdates(&entity{})),
@@ -209,6 +227,18 @@ func Test_getPayload(t *testing.T) {
 			opType: operation.OpTypeAfterFind,
 			want:   &entity{afterFind: 1},
 		},
+		{
+			name:   "before replace",
+			opCtx:  operation.NewOpContext(nil, operation.WithReplacement(&entity{beforeReplace: 1})),
+			opType: operation.OpTypeBeforeReplace,
+			want:   &entity{beforeReplace: 1},
+		},
+		{
+			name:   "after replace with model hook",
+			opCtx:  operation.NewOpContext(nil, operation.WithReplacement(&entity{}), operation.WithModelHook(&entity{afterReplace: 1})),
+			opType: operation.OpTypeAfterReplace,
+			want:   &entity{afterReplace: 1},
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -403,6 +433,24 @@ func Test_execute(t *testing.T) {
 			want:    &entity{afterUpsert: 1},
 			wantErr: nil,
 		},
+		{
+			name:   "before replace",
+			ctx:    context.Background(),
+			doc:    &entity{},
+			opType: operation.OpTypeBeforeReplace,
+
+			want:    &entity{beforeReplace: 1},
+			wantErr: nil,
+		},
+		{
+			name:   "after replace",
+			ctx:    context.Background(),
+			doc:    &entity{},
+			opType: operation.OpTypeAfterReplace,
+
+			want:    &entity{afterReplace: 1},
+			wantErr: nil,
+		},
 		{
 			name:   "before find",
 			ctx:    context.Background(),
@@ -0,0 +1,521 @@
+package tenant
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/matiniiuu/mongox/internal/pkg/structs"
+	"github.com/matiniiuu/mongox/internal/pkg/utils"
+	"github.com/matiniiuu/mongox/operation"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// DefaultField is the field which stores the tenant of a document
+const DefaultField = "tenantId"
+
+var (
+	ErrMissingTenant  = errors.New("mongox: no tenant in the context")
+	ErrTenantChange   = errors.New("mongox: the tenant of a document can not be changed")
+	ErrTenantMismatch = errors.New("mongox: the document belongs to another tenant")
+	ErrNoTenantField  = errors.New("mongox: the document has no tenant field which can be set")
+	// ErrUnscopablePipeline is returned for the pipelines which cannot be read as a list of stages, so that an
+	// aggregation is never run without the condition on the tenant
+	ErrUnscopablePipeline = errors.New("mongox: the pipeline can not be scoped to the tenant")
+)
+
+// Extractor returns the tenant of the operation from the context, ok is false if there is none
+type Extractor func(ctx context.Context) (tenant any, ok bool)
+
+type (
+	tenantKey struct{}
+	bypassKey struct{}
+)
+
+var (
+	field               = DefaultField
+	extractor Extractor = FromContext
+)
+
+// SetField replaces the field which stores the tenant, an empty name resets it to DefaultField
+func SetField(name string) {
+	if name == "" {
+		name = DefaultField
+	}
+	field = name
+}
+
+func Field() string {
+	return field
+}
+
+// SetExtractor replaces the extractor of the tenant, a nil extractor resets it to FromContext
+func SetExtractor(e Extractor) {
+	if e == nil {
+		e = FromContext
+	}
+	extractor = e
+}
+
+// NewContext returns a copy of the context which carries the tenant for FromContext
+func NewContext(ctx context.Context, tenant any) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// FromContext is the default extractor, it returns the tenant set by NewContext
+func FromContext(ctx context.Context) (any, bool) {
+	tenant := ctx.Value(tenantKey{})
+	return tenant, tenant != nil
+}
+
+// Bypass returns a copy of the context whose operations are neither checked nor scoped to a tenant,
+// e.g. for the maintenance jobs which work across the tenants
+func Bypass(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bypassKey{}, true)
+}
+
+func bypassed(ctx context.Context) bool {
+	b, _ := ctx.Value(bypassKey{}).(bool)
+	return b
+}
+
+// Execute scopes the operation to the tenant of the context
+// The filters and pipelines only match the documents of the tenant, the inserted and replacing documents are stamped
+// with the tenant and the updates must not change it
+func Execute(ctx context.Context, opCtx *operation.OpContext, opType operation.OpType, _ ...any) error {
+	if opCtx == nil || bypassed(ctx) {
+		return nil
+	}
+	switch opType {
+	case operation.OpTypeBeforeInsert, operation.OpTypeBeforeAggregate,
+		operation.OpTypeBeforeUpdate, operation.OpTypeBeforeUpsert, operation.OpTypeBeforeReplace,
+		operation.OpTypeBeforeFind, operation.OpTypeBeforeDelete, operation.OpTypeBeforeCount, operation.OpTypeBeforeDistinct:
+	default:
+		return nil
+	}
+	tenant, ok := extractor(ctx)
+	if !ok {
+		return ErrMissingTenant
+	}
+	switch opType {
+	case operation.OpTypeBeforeInsert:
+		return stamp(opCtx.Doc, tenant)
+	case operation.OpTypeBeforeAggregate:
+		pipeline, err := Pipeline(opCtx.Pipeline, tenant)
+		if err != nil {
+			return err
+		}
+		opCtx.Pipeline = pipeline
+		return nil
+	case operation.OpTypeBeforeUpdate, operation.OpTypeBeforeUpsert:
+		if err := checkUpdates(opCtx.Updates, tenant); err != nil {
+			return err
+		}
+	case operation.OpTypeBeforeReplace:
+		if err := stamp(opCtx.Replacement, tenant); err != nil {
+			return err
+		}
+	}
+	opCtx.Filter = Filter(opCtx.Filter, tenant)
+	return nil
+}
+
+// Filter combines the filter with the condition on the tenant
+// A nil filter is returned as is so that the driver keeps rejecting it, a filter already scoped to the tenant is not scoped twice
+func Filter(filter any, tenant any) any {
+	cond := bson.D{bson.E{Key: field, Value: tenant}}
+	if filter == nil || reflect.DeepEqual(filter, cond) {
+		return filter
+	}
+	if isEmpty(filter) {
+		return cond
+	}
+	if and, ok := filter.(bson.D); ok && len(and) == 1 && and[0].Key == "$and" {
+		if conds, ok := and[0].Value.(bson.A); ok && len(conds) == 2 && reflect.DeepEqual(conds[1], cond) {
+			return filter
+		}
+	}
+	return bson.D{bson.E{Key: "$and", Value: bson.A{filter, cond}}}
+}
+
+// Pipeline adds a $match stage on the tenant to the pipeline, see utils.MatchPipeline for the stages which have
+// to stay first
+// The sub-pipelines of the $lookup, $unionWith and $graphLookup stages are scoped as well, so the joined collections
+// must store the tenant in the same field, use Bypass to join the collections which are shared by the tenants
+// ErrUnscopablePipeline is returned if the pipeline or one of its stages cannot be read
+func Pipeline(pipeline any, tenant any) (any, error) {
+	stages, ok := utils.MatchPipeline(pipeline, bson.D{bson.E{Key: field, Value: tenant}})
+	if !ok {
+		return nil, ErrUnscopablePipeline
+	}
+	if err := scopeStages(stages, tenant); err != nil {
+		return nil, err
+	}
+	return stages, nil
+}
+
+// scopeStages scopes the stages which read another collection, the stages are replaced in place
+func scopeStages(stages bson.A, tenant any) error {
+	for i, stage := range stages {
+		op, spec := utils.StageOf(stage)
+		if op == "" {
+			// the stage is read as a document so that a join cannot hide in a type which is not a document
+			d, ok := utils.ToDocument(stage)
+			if !ok || len(d) != 1 {
+				return ErrUnscopablePipeline
+			}
+			op, spec = d[0].Key, d[0].Value
+			stages[i] = d
+		}
+		var err error
+		switch op {
+		case "$lookup":
+			spec, err = scopeJoin(spec, tenant)
+		case "$unionWith":
+			if coll, ok := spec.(string); ok {
+				spec = bson.D{bson.E{Key: "coll", Value: coll}}
+			}
+			spec, err = scopeJoin(spec, tenant)
+		case "$graphLookup":
+			d, ok := utils.ToDocument(spec)
+			if !ok {
+				return ErrUnscopablePipeline
+			}
+			spec = utils.AndCond(d, "restrictSearchWithMatch", bson.D{bson.E{Key: field, Value: tenant}})
+		case "$facet":
+			// the facets read the documents of the pipeline which are already scoped, only their joins are scoped
+			spec, err = scopeFacets(spec, tenant)
+		default:
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		stages[i] = bson.D{bson.E{Key: op, Value: spec}}
+	}
+	return nil
+}
+
+// scopeJoin scopes the pipeline of a $lookup or $unionWith specification, a $match stage on the tenant is the pipeline
+// of the specifications which have none
+func scopeJoin(spec any, tenant any) (bson.D, error) {
+	d, ok := utils.ToDocument(spec)
+	if !ok {
+		return nil, ErrUnscopablePipeline
+	}
+	for i, e := range d {
+		if e.Key == "pipeline" {
+			pipeline, err := Pipeline(e.Value, tenant)
+			if err != nil {
+				return nil, err
+			}
+			d[i].Value = pipeline
+			return d, nil
+		}
+	}
+	return append(d, bson.E{Key: "pipeline", Value: bson.A{bson.D{bson.E{Key: "$match", Value: bson.D{bson.E{Key: field, Value: tenant}}}}}}), nil
+}
+
+func scopeFacets(spec any, tenant any) (bson.D, error) {
+	d, ok := utils.ToDocument(spec)
+	if !ok {
+		return nil, ErrUnscopablePipeline
+	}
+	for i, e := range d {
+		stages, ok := utils.ToStages(e.Value)
+		if !ok {
+			return nil, ErrUnscopablePipeline
+		}
+		if err := scopeStages(stages, tenant); err != nil {
+			return nil, err
+		}
+		d[i].Value = stages
+	}
+	return d, nil
+}
+
+// stamp sets the tenant on the documents which have none and rejects the documents of another tenant
+func stamp(doc any, tenant any) error {
+	if doc == nil {
+		return nil
+	}
+	value := reflect.ValueOf(doc)
+	if value.Kind() == reflect.Slice {
+		for i := 0; i < value.Len(); i++ {
+			if err := stampValue(value.Index(i), tenant); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return stampValue(value, tenant)
+}
+
+func stampValue(value reflect.Value, tenant any) error {
+	for value.Kind() == reflect.Interface || value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	switch value.Kind() {
+	case reflect.Struct:
+		return stampStruct(value, tenant)
+	case reflect.Map:
+		if value.IsNil() || value.Type().Key().Kind() != reflect.String {
+			return ErrNoTenantField
+		}
+		key := reflect.ValueOf(field).Convert(value.Type().Key())
+		if current := value.MapIndex(key); current.IsValid() && !current.IsZero() {
+			return compare(current.Interface(), tenant)
+		}
+		tenantValue := reflect.ValueOf(tenant)
+		if !tenantValue.Type().AssignableTo(value.Type().Elem()) {
+			return ErrNoTenantField
+		}
+		value.SetMapIndex(key, tenantValue)
+		return nil
+	default:
+		return ErrNoTenantField
+	}
+}
+
+func stampStruct(value reflect.Value, tenant any) error {
+	for _, f := range structs.Fields(value.Type()) {
+		if f.Path != field {
+			continue
+		}
+		fieldValue, err := value.FieldByIndexErr(f.Index)
+		if err == nil && !fieldValue.IsZero() {
+			return compare(fieldValue.Interface(), tenant)
+		}
+		if !value.CanAddr() {
+			return ErrNoTenantField
+		}
+		if err != nil {
+			// an embedded pointer struct is nil, the tenant is set after it is allocated
+			fieldValue = allocate(value, f.Index)
+		}
+		tenantValue := reflect.ValueOf(tenant)
+		switch {
+		case tenantValue.Type().AssignableTo(fieldValue.Type()):
+			fieldValue.Set(tenantValue)
+		case tenantValue.Kind() == fieldValue.Kind() && tenantValue.Type().ConvertibleTo(fieldValue.Type()):
+			fieldValue.Set(tenantValue.Convert(fieldValue.Type()))
+		default:
+			return ErrNoTenantField
+		}
+		return nil
+	}
+	return ErrNoTenantField
+}
+
+// allocate returns the field at the index sequence, allocating the nil pointers on the way
+func allocate(value reflect.Value, index []int) reflect.Value {
+	for i, idx := range index {
+		if i > 0 {
+			if value.Kind() == reflect.Pointer {
+				if value.IsNil() {
+					value.Set(reflect.New(value.Type().Elem()))
+				}
+				value = value.Elem()
+			}
+		}
+		value = value.Field(idx)
+	}
+	return value
+}
+
+// compare rejects the value if it is not the tenant, the values are compared in their bson form
+func compare(value any, tenant any) error {
+	if !equal(value, tenant) {
+		return ErrTenantMismatch
+	}
+	return nil
+}
+
+func equal(a, b any) bool {
+	typA, dataA, errA := bson.MarshalValue(a)
+	typB, dataB, errB := bson.MarshalValue(b)
+	return errA == nil && errB == nil && typA == typB && bytes.Equal(dataA, dataB)
+}
+
+// checkUpdates rejects the updates which change the tenant field
+// Setting the field to the tenant itself is allowed, the $set, $addFields, $unset and $project stages of a pipeline
+// update are checked and the $replaceWith and $replaceRoot stages are rejected since they can replace the tenant
+func checkUpdates(updates any, tenant any) error {
+	if updates == nil {
+		return nil
+	}
+	typ, data, err := bson.MarshalValue(updates)
+	if err != nil {
+		return err
+	}
+	raw := bson.RawValue{Type: typ, Value: data}
+	switch typ {
+	case bson.TypeEmbeddedDocument:
+		return checkOperators(raw.Document(), tenant)
+	case bson.TypeArray:
+		stages, err := raw.Array().Values()
+		if err != nil {
+			return err
+		}
+		for _, stage := range stages {
+			doc, ok := stage.DocumentOK()
+			if !ok {
+				continue
+			}
+			if err = checkStage(doc, tenant); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func checkOperators(updates bson.Raw, tenant any) error {
+	elements, err := updates.Elements()
+	if err != nil {
+		return err
+	}
+	for _, element := range elements {
+		fields, ok := element.Value().DocumentOK()
+		if !ok {
+			continue
+		}
+		operator := element.Key()
+		if err = checkFields(fields, tenant, operator == "$set" || operator == "$setOnInsert", operator == "$rename"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkStage(stage bson.Raw, tenant any) error {
+	elements, err := stage.Elements()
+	if err != nil {
+		return err
+	}
+	for _, element := range elements {
+		switch element.Key() {
+		case "$set", "$addFields":
+			if fields, ok := element.Value().DocumentOK(); ok {
+				if err = checkFields(fields, tenant, true, false); err != nil {
+					return err
+				}
+			}
+		case "$replaceWith", "$replaceRoot":
+			return ErrTenantChange
+		case "$project":
+			if fields, ok := element.Value().DocumentOK(); ok {
+				if err = checkProjection(fields); err != nil {
+					return err
+				}
+			}
+		case "$unset":
+			if name, ok := element.Value().StringValueOK(); ok && touches(name) {
+				return ErrTenantChange
+			}
+			if names, ok := element.Value().ArrayOK(); ok {
+				values, _ := names.Values()
+				for _, v := range values {
+					if name, ok := v.StringValueOK(); ok && touches(name) {
+						return ErrTenantChange
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// checkFields checks the fields of an update operator, set reports whether the operator sets the values as they are
+// and rename reports whether the values are the new names of the fields
+func checkFields(fields bson.Raw, tenant any, set bool, rename bool) error {
+	elements, err := fields.Elements()
+	if err != nil {
+		return err
+	}
+	for _, element := range elements {
+		if rename {
+			if name, ok := element.Value().StringValueOK(); ok && touches(name) {
+				return ErrTenantChange
+			}
+		}
+		if !touches(element.Key()) {
+			continue
+		}
+		if set && element.Key() == field && equal(element.Value(), tenant) {
+			continue
+		}
+		return ErrTenantChange
+	}
+	return nil
+}
+
+// checkProjection rejects the projections which drop the tenant field, i.e. the inclusions which do not include it
+// and the projections which exclude or compute it
+func checkProjection(fields bson.Raw) error {
+	elements, err := fields.Elements()
+	if err != nil {
+		return err
+	}
+	inclusion, included := false, false
+	for _, element := range elements {
+		key, kept := element.Key(), !excluded(element.Value())
+		if touches(key) {
+			if key != field || !keptAsIs(element.Value()) {
+				return ErrTenantChange
+			}
+			included = true
+		}
+		if key != "_id" && kept {
+			inclusion = true
+		}
+	}
+	if inclusion && !included {
+		return ErrTenantChange
+	}
+	return nil
+}
+
+// excluded reports whether the value of a projection field excludes the field
+func excluded(value bson.RawValue) bool {
+	if b, ok := value.BooleanOK(); ok {
+		return !b
+	}
+	if n, ok := value.AsInt64OK(); ok {
+		return n == 0
+	}
+	return false
+}
+
+// keptAsIs reports whether the value of a projection field includes the field as it is, not as a new value
+func keptAsIs(value bson.RawValue) bool {
+	if b, ok := value.BooleanOK(); ok {
+		return b
+	}
+	n, ok := value.AsInt64OK()
+	return ok && n != 0
+}
+
+// touches reports whether the path is the tenant field, one of its sub fields or one of its parents, since setting
+// the parent replaces the tenant field
+func touches(path string) bool {
+	return path == field || strings.HasPrefix(path, field+".") || strings.HasPrefix(field, path+".")
+}
+
+func isEmpty(filter any) bool {
+	switch f := filter.(type) {
+	case bson.D:
+		return len(f) == 0
+	case bson.M:
+		return len(f) == 0
+	case map[string]any:
+		return len(f) == 0
+	}
+	return false
+}
@@ -0,0 +1,328 @@
+package tenant
+
+import (
+	"context"
+	"testing"
+
+	"github.com/matiniiuu/mongox/operation"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+type Scope struct {
+	TenantID string `bson:"tenantId"`
+}
+
+type testUser struct {
+	Name   string `bson:"name"`
+	*Scope `bson:",inline"`
+}
+
+type plainUser struct {
+	Name string `bson:"name"`
+}
+
+func TestExecute(t *testing.T) {
+	ctx := NewContext(context.Background(), "acme")
+	scoped := func(filter any) any {
+		return bson.D{{Key: "$and", Value: bson.A{filter, bson.D{{Key: "tenantId", Value: "acme"}}}}}
+	}
+
+	testCases := []struct {
+		name       string
+		ctx        context.Context
+		opCtx      *operation.OpContext
+		opType     operation.OpType
+		wantErr    error
+		wantFilter any
+	}{
+		{
+			name:       "no tenant",
+			ctx:        context.Background(),
+			opCtx:      operation.NewOpContext(nil, operation.WithFilter(bson.D{})),
+			opType:     operation.OpTypeBeforeFind,
+			wantErr:    ErrMissingTenant,
+			wantFilter: bson.D{},
+		},
+		{
+			name:       "bypassed",
+			ctx:        Bypass(context.Background()),
+			opCtx:      operation.NewOpContext(nil, operation.WithFilter(bson.D{})),
+			opType:     operation.OpTypeBeforeFind,
+			wantFilter: bson.D{},
+		},
+		{
+			name:       "find",
+			ctx:        ctx,
+			opCtx:      operation.NewOpContext(nil, operation.WithFilter(bson.M{"name": "Mingyong Chen"})),
+			opType:     operation.OpTypeBeforeFind,
+			wantFilter: scoped(bson.M{"name": "Mingyong Chen"}),
+		},
+		{
+			name:       "already scoped",
+			ctx:        ctx,
+			opCtx:      operation.NewOpContext(nil, operation.WithFilter(scoped(bson.M{"name": "Mingyong Chen"}))),
+			opType:     operation.OpTypeBeforeUpdate,
+			wantFilter: scoped(bson.M{"name": "Mingyong Chen"}),
+		},
+		{
+			name:       "empty filter",
+			ctx:        ctx,
+			opCtx:      operation.NewOpContext(nil, operation.WithFilter(bson.D{})),
+			opType:     operation.OpTypeBeforeCount,
+			wantFilter: bson.D{{Key: "tenantId", Value: "acme"}},
+		},
+		{
+			name:   "update changing the tenant",
+			ctx:    ctx,
+			opCtx:  operation.NewOpContext(nil, operation.WithFilter(bson.D{}), operation.WithUpdates(bson.M{"$set": bson.M{"tenantId": "umbrella"}})),
+			opType: operation.OpTypeBeforeUpdate,
+			// the filter is left as is once the update is rejected
+			wantErr:    ErrTenantChange,
+			wantFilter: bson.D{},
+		},
+		{
+			name:       "upsert setting the same tenant",
+			ctx:        ctx,
+			opCtx:      operation.NewOpContext(nil, operation.WithFilter(bson.D{}), operation.WithUpdates(bson.M{"$set": bson.M{"tenantId": "acme", "name": "burt"}})),
+			opType:     operation.OpTypeBeforeUpsert,
+			wantFilter: bson.D{{Key: "tenantId", Value: "acme"}},
+		},
+		{
+			name:       "other operation",
+			ctx:        context.Background(),
+			opCtx:      operation.NewOpContext(nil, operation.WithFilter(bson.D{})),
+			opType:     operation.OpTypeAfterFind,
+			wantFilter: bson.D{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Execute(tc.ctx, tc.opCtx, tc.opType)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantFilter, tc.opCtx.Filter)
+		})
+	}
+}
+
+func TestExecute_Insert(t *testing.T) {
+	ctx := NewContext(context.Background(), "acme")
+
+	t.Run("stamp documents", func(t *testing.T) {
+		users := []*testUser{{Name: "Mingyong Chen"}, {Name: "burt", Scope: &Scope{TenantID: "acme"}}}
+		require.NoError(t, Execute(ctx, operation.NewOpContext(nil, operation.WithDoc(users)), operation.OpTypeBeforeInsert))
+		assert.Equal(t, "acme", users[0].TenantID)
+		assert.Equal(t, "acme", users[1].TenantID)
+	})
+	t.Run("stamp map", func(t *testing.T) {
+		doc := bson.M{"name": "Mingyong Chen"}
+		require.NoError(t, Execute(ctx, operation.NewOpContext(nil, operation.WithDoc(doc)), operation.OpTypeBeforeInsert))
+		assert.Equal(t, "acme", doc["tenantId"])
+	})
+	t.Run("another tenant", func(t *testing.T) {
+		user := &testUser{Name: "Mingyong Chen", Scope: &Scope{TenantID: "umbrella"}}
+		err := Execute(ctx, operation.NewOpContext(nil, operation.WithDoc(user)), operation.OpTypeBeforeInsert)
+		assert.Equal(t, ErrTenantMismatch, err)
+	})
+	t.Run("no tenant field", func(t *testing.T) {
+		err := Execute(ctx, operation.NewOpContext(nil, operation.WithDoc(&plainUser{Name: "Mingyong Chen"})), operation.OpTypeBeforeInsert)
+		assert.Equal(t, ErrNoTenantField, err)
+	})
+	t.Run("replace", func(t *testing.T) {
+		user := &testUser{Name: "Mingyong Chen"}
+		opCtx := operation.NewOpContext(nil, operation.WithDoc(user), operation.WithReplacement(user), operation.WithFilter(bson.D{}))
+		require.NoError(t, Execute(ctx, opCtx, operation.OpTypeBeforeReplace))
+		assert.Equal(t, "acme", user.TenantID)
+		assert.Equal(t, bson.D{{Key: "tenantId", Value: "acme"}}, opCtx.Filter)
+	})
+}
+
+func TestExecute_Aggregate(t *testing.T) {
+	ctx := NewContext(context.Background(), "acme")
+	opCtx := operation.NewOpContext(nil, operation.WithPipeline(mongo.Pipeline{{{Key: "$sort", Value: bson.D{{Key: "age", Value: 1}}}}}))
+	require.NoError(t, Execute(ctx, opCtx, operation.OpTypeBeforeAggregate))
+	assert.Equal(t, bson.A{
+		bson.D{{Key: "$match", Value: bson.D{{Key: "tenantId", Value: "acme"}}}},
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "age", Value: 1}}}},
+	}, opCtx.Pipeline)
+}
+
+func TestExecute_AggregateFirstStages(t *testing.T) {
+	ctx := NewContext(context.Background(), "acme")
+	opCtx := operation.NewOpContext(nil, operation.WithPipeline(mongo.Pipeline{
+		{{Key: "$geoNear", Value: bson.D{{Key: "distanceField", Value: "distance"}, {Key: "query", Value: bson.D{{Key: "age", Value: 18}}}}}},
+	}))
+	require.NoError(t, Execute(ctx, opCtx, operation.OpTypeBeforeAggregate))
+	assert.Equal(t, bson.A{
+		bson.D{{Key: "$geoNear", Value: bson.D{
+			{Key: "distanceField", Value: "distance"},
+			{Key: "query", Value: bson.D{{Key: "$and", Value: bson.A{bson.D{{Key: "age", Value: 18}}, bson.D{{Key: "tenantId", Value: "acme"}}}}}},
+		}}},
+	}, opCtx.Pipeline)
+
+	opCtx = operation.NewOpContext(nil, operation.WithPipeline(mongo.Pipeline{{{Key: "$indexStats", Value: bson.D{}}}}))
+	require.NoError(t, Execute(ctx, opCtx, operation.OpTypeBeforeAggregate))
+	assert.Equal(t, bson.A{
+		bson.D{{Key: "$indexStats", Value: bson.D{}}},
+		bson.D{{Key: "$match", Value: bson.D{{Key: "tenantId", Value: "acme"}}}},
+	}, opCtx.Pipeline)
+}
+
+func TestExecute_AggregateUnscopable(t *testing.T) {
+	ctx := NewContext(context.Background(), "acme")
+	testCases := []struct {
+		name     string
+		pipeline any
+	}{
+		{name: "nil", pipeline: nil},
+		{name: "not a slice", pipeline: bson.D{{Key: "$match", Value: bson.D{}}}},
+		{name: "raw", pipeline: bson.Raw{}},
+		{name: "stage of several keys", pipeline: bson.A{bson.D{{Key: "$match", Value: bson.D{}}, {Key: "$limit", Value: 1}}}},
+		{name: "lookup pipeline not a slice", pipeline: bson.A{bson.D{{Key: "$lookup", Value: bson.D{{Key: "from", Value: "orders"}, {Key: "pipeline", Value: "all"}}}}}},
+		{name: "graphLookup not a document", pipeline: bson.A{bson.D{{Key: "$graphLookup", Value: "orders"}}}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			opCtx := operation.NewOpContext(nil, operation.WithPipeline(tc.pipeline))
+			assert.Equal(t, ErrUnscopablePipeline, Execute(ctx, opCtx, operation.OpTypeBeforeAggregate))
+			assert.Equal(t, tc.pipeline, opCtx.Pipeline)
+		})
+	}
+
+	// the pipelines are left to the caller once the tenant is bypassed
+	opCtx := operation.NewOpContext(nil, operation.WithPipeline(bson.D{}))
+	require.NoError(t, Execute(Bypass(ctx), opCtx, operation.OpTypeBeforeAggregate))
+	assert.Equal(t, bson.D{}, opCtx.Pipeline)
+}
+
+func TestPipeline_joins(t *testing.T) {
+	cond := bson.D{{Key: "tenantId", Value: "acme"}}
+	match := bson.D{{Key: "$match", Value: cond}}
+
+	testCases := []struct {
+		name     string
+		pipeline any
+		want     any
+	}{
+		{
+			name:     "lookup with local and foreign fields",
+			pipeline: mongo.Pipeline{{{Key: "$lookup", Value: bson.D{{Key: "from", Value: "orders"}, {Key: "localField", Value: "_id"}, {Key: "foreignField", Value: "userId"}, {Key: "as", Value: "orders"}}}}},
+			want: bson.A{match, bson.D{{Key: "$lookup", Value: bson.D{
+				{Key: "from", Value: "orders"}, {Key: "localField", Value: "_id"}, {Key: "foreignField", Value: "userId"}, {Key: "as", Value: "orders"},
+				{Key: "pipeline", Value: bson.A{match}},
+			}}}},
+		},
+		{
+			name: "lookup with a pipeline",
+			pipeline: bson.A{bson.M{"$lookup": bson.D{{Key: "from", Value: "orders"}, {Key: "pipeline", Value: bson.A{
+				bson.D{{Key: "$lookup", Value: bson.D{{Key: "from", Value: "items"}, {Key: "pipeline", Value: mongo.Pipeline{}}}}},
+			}}}}},
+			want: bson.A{match, bson.D{{Key: "$lookup", Value: bson.D{
+				{Key: "from", Value: "orders"},
+				{Key: "pipeline", Value: bson.A{match, bson.D{{Key: "$lookup", Value: bson.D{{Key: "from", Value: "items"}, {Key: "pipeline", Value: bson.A{match}}}}}}},
+			}}}},
+		},
+		{
+			name:     "unionWith a collection",
+			pipeline: mongo.Pipeline{{{Key: "$unionWith", Value: "archive"}}},
+			want:     bson.A{match, bson.D{{Key: "$unionWith", Value: bson.D{{Key: "coll", Value: "archive"}, {Key: "pipeline", Value: bson.A{match}}}}}},
+		},
+		{
+			name:     "unionWith a pipeline",
+			pipeline: mongo.Pipeline{{{Key: "$unionWith", Value: bson.D{{Key: "coll", Value: "archive"}, {Key: "pipeline", Value: mongo.Pipeline{{{Key: "$limit", Value: 1}}}}}}}},
+			want: bson.A{match, bson.D{{Key: "$unionWith", Value: bson.D{
+				{Key: "coll", Value: "archive"},
+				{Key: "pipeline", Value: bson.A{match, bson.D{{Key: "$limit", Value: 1}}}},
+			}}}},
+		},
+		{
+			name:     "graphLookup",
+			pipeline: mongo.Pipeline{{{Key: "$graphLookup", Value: bson.D{{Key: "from", Value: "users"}, {Key: "restrictSearchWithMatch", Value: bson.D{{Key: "active", Value: true}}}}}}},
+			want: bson.A{match, bson.D{{Key: "$graphLookup", Value: bson.D{
+				{Key: "from", Value: "users"},
+				{Key: "restrictSearchWithMatch", Value: bson.D{{Key: "$and", Value: bson.A{bson.D{{Key: "active", Value: true}}, cond}}}},
+			}}}},
+		},
+		{
+			name:     "lookup in a facet",
+			pipeline: mongo.Pipeline{{{Key: "$facet", Value: bson.D{{Key: "orders", Value: mongo.Pipeline{{{Key: "$lookup", Value: bson.D{{Key: "from", Value: "orders"}}}}}}}}}},
+			want: bson.A{match, bson.D{{Key: "$facet", Value: bson.D{
+				{Key: "orders", Value: bson.A{bson.D{{Key: "$lookup", Value: bson.D{{Key: "from", Value: "orders"}, {Key: "pipeline", Value: bson.A{match}}}}}}},
+			}}}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Pipeline(tc.pipeline, "acme")
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func Test_checkUpdates(t *testing.T) {
+	testCases := []struct {
+		name    string
+		updates any
+		wantErr error
+	}{
+		{name: "nil", updates: nil},
+		{name: "other fields", updates: bson.D{{Key: "$set", Value: bson.D{{Key: "name", Value: "burt"}}}}},
+		{name: "set the same tenant", updates: bson.M{"$setOnInsert": bson.M{"tenantId": "acme"}}},
+		{name: "set another tenant", updates: bson.M{"$set": bson.M{"tenantId": "umbrella"}}, wantErr: ErrTenantChange},
+		{name: "unset", updates: bson.M{"$unset": bson.M{"tenantId": ""}}, wantErr: ErrTenantChange},
+		{name: "sub field", updates: bson.M{"$set": bson.M{"tenantId.name": "acme"}}, wantErr: ErrTenantChange},
+		{name: "rename to the tenant field", updates: bson.M{"$rename": bson.M{"owner": "tenantId"}}, wantErr: ErrTenantChange},
+		{name: "pipeline set", updates: mongo.Pipeline{{{Key: "$set", Value: bson.D{{Key: "tenantId", Value: "umbrella"}}}}}, wantErr: ErrTenantChange},
+		{name: "pipeline unset", updates: bson.A{bson.D{{Key: "$unset", Value: bson.A{"name", "tenantId"}}}}, wantErr: ErrTenantChange},
+		{name: "pipeline other fields", updates: bson.A{bson.D{{Key: "$set", Value: bson.D{{Key: "name", Value: "burt"}}}}}},
+		{name: "pipeline replaceWith", updates: bson.A{bson.D{{Key: "$replaceWith", Value: bson.D{{Key: "name", Value: "burt"}}}}}, wantErr: ErrTenantChange},
+		{name: "pipeline replaceRoot", updates: bson.A{bson.D{{Key: "$replaceRoot", Value: bson.D{{Key: "newRoot", Value: "$profile"}}}}}, wantErr: ErrTenantChange},
+		{name: "pipeline project excluding other fields", updates: bson.A{bson.D{{Key: "$project", Value: bson.D{{Key: "secret", Value: 0}}}}}},
+		{name: "pipeline project including the tenant", updates: bson.A{bson.D{{Key: "$project", Value: bson.D{{Key: "name", Value: 1}, {Key: "tenantId", Value: true}}}}}},
+		{name: "pipeline project without the tenant", updates: bson.A{bson.D{{Key: "$project", Value: bson.D{{Key: "name", Value: 1}}}}}, wantErr: ErrTenantChange},
+		{name: "pipeline project excluding the tenant", updates: bson.A{bson.D{{Key: "$project", Value: bson.D{{Key: "tenantId", Value: 0}}}}}, wantErr: ErrTenantChange},
+		{name: "pipeline project computing the tenant", updates: bson.A{bson.D{{Key: "$project", Value: bson.D{{Key: "tenantId", Value: "umbrella"}}}}}, wantErr: ErrTenantChange},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.wantErr, checkUpdates(tc.updates, "acme"))
+		})
+	}
+}
+
+func Test_checkUpdates_nestedField(t *testing.T) {
+	SetField("org.tenantId")
+	defer SetField("")
+
+	testCases := []struct {
+		name    string
+		updates any
+		wantErr error
+	}{
+		{name: "set the same tenant", updates: bson.M{"$set": bson.M{"org.tenantId": "acme"}}},
+		{name: "set a sibling", updates: bson.M{"$set": bson.M{"org.name": "acme inc"}}},
+		{name: "set the parent", updates: bson.M{"$set": bson.M{"org": bson.M{"tenantId": "umbrella"}}}, wantErr: ErrTenantChange},
+		{name: "unset the parent", updates: bson.M{"$unset": bson.M{"org": ""}}, wantErr: ErrTenantChange},
+		{name: "rename to the parent", updates: bson.M{"$rename": bson.M{"owner": "org"}}, wantErr: ErrTenantChange},
+		{name: "pipeline set the parent", updates: bson.A{bson.D{{Key: "$set", Value: bson.D{{Key: "org", Value: "$$REMOVE"}}}}}, wantErr: ErrTenantChange},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.wantErr, checkUpdates(tc.updates, "acme"))
+		})
+	}
+}
+
+func TestSetExtractor(t *testing.T) {
+	defer SetExtractor(nil)
+	SetExtractor(func(ctx context.Context) (any, bool) {
+		return "acme", true
+	})
+	opCtx := operation.NewOpContext(nil, operation.WithFilter(bson.D{}))
+	require.NoError(t, Execute(context.Background(), opCtx, operation.OpTypeBeforeDelete))
+	assert.Equal(t, bson.D{{Key: "tenantId", Value: "acme"}}, opCtx.Filter)
+}
@@ -0,0 +1,188 @@
+package validator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/matiniiuu/mongox/bsonx"
+	"github.com/matiniiuu/mongox/builder/update"
+	"github.com/matiniiuu/mongox/internal/pkg/structs"
+	"github.com/matiniiuu/mongox/operation"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+var ErrInvalidUpdate = errors.New("mongox: invalid update")
+
+// UpdateValidation is the validation of the update documents of the updates and upserts against the model
+// The values of $set and $setOnInsert are validated with the validate tags of the fields their paths resolve to
+type UpdateValidation struct {
+	// RejectUnsetRequired rejects the $unset of the fields whose validate tag has required
+	RejectUnsetRequired bool
+	// RejectUnknownPaths rejects the paths of the field update operators which resolve to no field of the model
+	RejectUnknownPaths bool
+}
+
+var updateValidation *UpdateValidation
+
+// SetUpdateValidation turns the validation of the update documents on, or off if v is nil
+func SetUpdateValidation(v *UpdateValidation) {
+	updateValidation = v
+}
+
+// validateUpdates validates the update document of the operation against the model given as the doc
+// The update pipelines are not validated
+func validateUpdates(ctx context.Context, opCtx *operation.OpContext) error {
+	updates := bsonx.ToBsonM(opCtx.Updates)
+	if updates == nil || opCtx.Doc == nil {
+		return nil
+	}
+	model := structs.Indirect(reflect.TypeOf(opCtx.Doc))
+	if model.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var errs []error
+	for _, op := range sortedKeys(updates) {
+		fields := bsonx.ToBsonM(updates[op])
+		for _, path := range sortedKeys(fields) {
+			target, ok := resolve(model, path)
+			if !ok {
+				if updateValidation.RejectUnknownPaths {
+					errs = append(errs, fmt.Errorf("%w: %s of unknown path %q", ErrInvalidUpdate, op, path))
+				}
+				continue
+			}
+			switch op {
+			case update.SetOp, update.SetOnInsertOp:
+				if err := target.validate(ctx, fields[path]); err != nil {
+					errs = append(errs, fmt.Errorf("%w: %s of %q: %w", ErrInvalidUpdate, op, path, err))
+				}
+			case update.UnsetOp:
+				if updateValidation.RejectUnsetRequired && slices.Contains(target.rules, "required") {
+					errs = append(errs, fmt.Errorf("%w: %s of required %q", ErrInvalidUpdate, op, path))
+				}
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// target is the type a path of an update resolves to together with the validate rules which apply to it
+type target struct {
+	typ   reflect.Type
+	rules []string
+}
+
+// resolve walks the path through the fields of the structs, the items of the arrays and the values of the maps
+// A path is unknown if a segment is no field of a struct or no index of an array, anything goes below an interface
+func resolve(model reflect.Type, path string) (target, bool) {
+	t := target{typ: model}
+	for _, segment := range strings.Split(path, ".") {
+		typ := structs.Indirect(t.typ)
+		switch typ.Kind() {
+		case reflect.Interface:
+			return target{typ: typ}, true
+		case reflect.Struct:
+			if isTime(typ) {
+				return target{}, false
+			}
+			f, ok := fieldByKey(typ, segment)
+			if !ok {
+				return target{}, false
+			}
+			t = target{typ: f.Type, rules: parseRules(f.Tag.Get("validate"))}
+		case reflect.Slice, reflect.Array:
+			if !isIndex(segment) {
+				return target{}, false
+			}
+			t = target{typ: typ.Elem(), rules: itemRules(t.rules)}
+		case reflect.Map:
+			t = target{typ: typ.Elem(), rules: itemRules(t.rules)}
+		default:
+			return target{}, false
+		}
+	}
+	return t, true
+}
+
+// validate decodes the value into the type of the target, so that e.g. a bson.M set to a struct field is validated
+// as that struct, and validates it with the rules of the target
+func (t target) validate(ctx context.Context, value any) error {
+	if t.typ.Kind() == reflect.Interface {
+		return nil
+	}
+	holder := reflect.StructOf([]reflect.StructField{{Name: "V", Type: t.typ, Tag: `bson:"v"`}})
+	data, err := bson.Marshal(bson.D{{Key: "v", Value: value}})
+	if err != nil {
+		return err
+	}
+	decoded := reflect.New(holder)
+	if err = bson.Unmarshal(data, decoded.Interface()); err != nil {
+		return err
+	}
+	v := decoded.Elem().Field(0)
+	if len(t.rules) > 0 {
+		if err = validate.VarCtx(ctx, v.Interface(), strings.Join(t.rules, ",")); err != nil {
+			return err
+		}
+	}
+	if doc := validateStruct(v); doc != nil {
+		return validate.StructCtx(ctx, doc)
+	}
+	return nil
+}
+
+// fieldByKey returns the field of the struct with the bson key, the fields of inline structs included
+func fieldByKey(typ reflect.Type, key string) (reflect.StructField, bool) {
+	for _, f := range structs.Fields(typ) {
+		if f.Path == key {
+			return f.StructField, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// isIndex reports whether the segment is an index of an array or one of the positional operators $, $[] and $[<identifier>]
+func isIndex(segment string) bool {
+	if segment == update.PositionalOp || strings.HasPrefix(segment, "$[") && strings.HasSuffix(segment, "]") {
+		return true
+	}
+	_, err := strconv.ParseUint(segment, 10, 64)
+	return err == nil
+}
+
+// isTime reports whether the struct is encoded as a date rather than as a document of its fields
+func isTime(typ reflect.Type) bool {
+	return typ.ConvertibleTo(reflect.TypeFor[time.Time]())
+}
+
+func parseRules(tag string) []string {
+	if tag == "" || tag == "-" {
+		return nil
+	}
+	return strings.Split(tag, ",")
+}
+
+// itemRules returns the rules following dive, which apply to the items of an array or the values of a map
+func itemRules(rules []string) []string {
+	if idx := slices.Index(rules, "dive"); idx >= 0 {
+		return rules[idx+1:]
+	}
+	return nil
+}
+
+func sortedKeys(m bson.M) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,193 @@
+package validator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+
+	mxerrors "github.com/matiniiuu/mongox/errors"
+	"github.com/matiniiuu/mongox/operation"
+)
+
+type Address struct {
+	City string `bson:"city" validate:"required"`
+	Zip  string `bson:"zip" validate:"omitempty,len=5"`
+}
+
+type Model struct {
+	Id        string    `bson:"_id"`
+	CreatedAt time.Time `bson:"created_at"`
+}
+
+type Member struct {
+	Model     `bson:",inline"`
+	Name      string            `bson:"name" validate:"required"`
+	Age       int               `bson:"age" validate:"gte=0,lte=150"`
+	Tags      []string          `bson:"tags" validate:"max=3,dive,min=2"`
+	Address   *Address          `bson:"address"`
+	Addresses []Address         `bson:"addresses"`
+	Labels    map[string]string `bson:"labels" validate:"dive,max=5"`
+	Extra     any               `bson:"extra"`
+}
+
+func TestExecute_updates(t *testing.T) {
+	testCases := []struct {
+		name       string
+		validation *UpdateValidation
+		opType     operation.OpType
+		updates    any
+
+		wantErr error
+	}{
+		{
+			name:       "update validation off",
+			validation: nil,
+			opType:     operation.OpTypeBeforeUpdate,
+			updates:    bson.M{"$set": bson.M{"age": -5}},
+		},
+		{
+			name:       "valid set",
+			validation: &UpdateValidation{},
+			opType:     operation.OpTypeBeforeUpdate,
+			updates: bson.D{{Key: "$set", Value: bson.D{
+				{Key: "name", Value: "cmy"},
+				{Key: "age", Value: int32(24)},
+				{Key: "tags.1", Value: "go"},
+				{Key: "addresses.$[elem].city", Value: "Paris"},
+				{Key: "labels.team", Value: "core"},
+				{Key: "extra.anything", Value: 1},
+				{Key: "created_at", Value: time.Now()},
+			}}},
+		},
+		{
+			name:       "invalid set",
+			validation: &UpdateValidation{},
+			opType:     operation.OpTypeBeforeUpdate,
+			updates:    bson.M{"$set": bson.M{"age": -5}},
+			wantErr:    ErrInvalidUpdate,
+		},
+		{
+			name:       "invalid set on insert of an upsert",
+			validation: &UpdateValidation{},
+			opType:     operation.OpTypeBeforeUpsert,
+			updates:    bson.M{"$setOnInsert": bson.M{"name": ""}},
+			wantErr:    ErrInvalidUpdate,
+		},
+		{
+			name:       "invalid item of an array",
+			validation: &UpdateValidation{},
+			opType:     operation.OpTypeBeforeUpdate,
+			updates:    bson.M{"$set": bson.M{"tags.$": "a"}},
+			wantErr:    ErrInvalidUpdate,
+		},
+		{
+			name:       "invalid value of a map",
+			validation: &UpdateValidation{},
+			opType:     operation.OpTypeBeforeUpdate,
+			updates:    bson.M{"$set": bson.M{"labels.team": "platform"}},
+			wantErr:    ErrInvalidUpdate,
+		},
+		{
+			name:       "invalid nested document",
+			validation: &UpdateValidation{},
+			opType:     operation.OpTypeBeforeUpdate,
+			updates:    bson.M{"$set": bson.M{"address": bson.M{"zip": "75001"}}},
+			wantErr:    ErrInvalidUpdate,
+		},
+		{
+			name:       "invalid field of a nested document",
+			validation: &UpdateValidation{},
+			opType:     operation.OpTypeBeforeUpdate,
+			updates:    bson.M{"$set": bson.M{"address.zip": "750"}},
+			wantErr:    ErrInvalidUpdate,
+		},
+		{
+			name:       "value of another type",
+			validation: &UpdateValidation{},
+			opType:     operation.OpTypeBeforeUpdate,
+			updates:    bson.M{"$set": bson.M{"age": "old"}},
+			wantErr:    ErrInvalidUpdate,
+		},
+		{
+			name:       "unknown path accepted",
+			validation: &UpdateValidation{},
+			opType:     operation.OpTypeBeforeUpdate,
+			updates:    bson.M{"$set": bson.M{"nickname": "cmy"}, "$inc": bson.M{"visits": 1}},
+		},
+		{
+			name:       "unknown path rejected",
+			validation: &UpdateValidation{RejectUnknownPaths: true},
+			opType:     operation.OpTypeBeforeUpdate,
+			updates:    bson.M{"$inc": bson.M{"visits": 1}},
+			wantErr:    ErrInvalidUpdate,
+		},
+		{
+			name:       "field of an array item without index rejected",
+			validation: &UpdateValidation{RejectUnknownPaths: true},
+			opType:     operation.OpTypeBeforeUpdate,
+			updates:    bson.M{"$set": bson.M{"addresses.city": "Paris"}},
+			wantErr:    ErrInvalidUpdate,
+		},
+		{
+			name:       "unset of a required field accepted",
+			validation: &UpdateValidation{},
+			opType:     operation.OpTypeBeforeUpdate,
+			updates:    bson.M{"$unset": bson.M{"name": ""}},
+		},
+		{
+			name:       "unset of a required field rejected",
+			validation: &UpdateValidation{RejectUnsetRequired: true},
+			opType:     operation.OpTypeBeforeUpdate,
+			updates:    bson.M{"$unset": bson.M{"address.city": ""}},
+			wantErr:    ErrInvalidUpdate,
+		},
+		{
+			name:       "unset of an optional field",
+			validation: &UpdateValidation{RejectUnsetRequired: true},
+			opType:     operation.OpTypeBeforeUpdate,
+			updates:    bson.M{"$unset": bson.M{"address.zip": ""}},
+		},
+		{
+			name:       "pipeline not validated",
+			validation: &UpdateValidation{RejectUnknownPaths: true},
+			opType:     operation.OpTypeBeforeUpdate,
+			updates:    mongo.Pipeline{{{Key: "$set", Value: bson.M{"age": -5}}}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			SetUpdateValidation(tc.validation)
+			defer SetUpdateValidation(nil)
+
+			opCtx := operation.NewOpContext(nil, operation.WithDoc(new(Member)), operation.WithUpdates(tc.updates))
+			err := Execute(context.Background(), opCtx, tc.opType)
+			assert.ErrorIs(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestExecute_updatesErrors(t *testing.T) {
+	SetUpdateValidation(&UpdateValidation{RejectUnsetRequired: true})
+	defer SetUpdateValidation(nil)
+
+	opCtx := operation.NewOpContext(nil, operation.WithDoc(new(Member)), operation.WithUpdates(bson.M{
+		"$set":   bson.M{"age": -5},
+		"$unset": bson.M{"name": ""},
+	}))
+	err := Execute(context.Background(), opCtx, operation.OpTypeBeforeUpdate)
+	require.Error(t, err)
+	var fieldErrs validator.ValidationErrors
+	assert.ErrorAs(t, err, &fieldErrs)
+	assert.Equal(t, "gte", fieldErrs[0].Tag())
+	var validationErr *mxerrors.ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Len(t, validationErr.Fields, 1)
+	assert.Equal(t, "mongox: validation failed: mongox: invalid update: $set of \"age\": Key: '' Error:Field validation for '' failed on the 'gte' tag\n"+
+		"mongox: invalid update: $unset of required \"name\"", err.Error())
+}
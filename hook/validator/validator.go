@@ -7,6 +7,8 @@ import (
 
 	"github.com/go-playground/validator/v10"
 
+	mxerrors "github.com/matiniiuu/mongox/errors"
+
 	"github.com/matiniiuu/mongox/operation"
 )
 
@@ -25,7 +27,7 @@ func getPayload(opCtx *operation.OpContext, opType operation.OpType) any {
 	switch opType {
 	case operation.OpTypeBeforeInsert:
 		return opCtx.Doc
-	case operation.OpTypeBeforeUpsert:
+	case operation.OpTypeBeforeUpsert, operation.OpTypeBeforeReplace:
 		return opCtx.Replacement
 	default:
 		return nil
@@ -33,6 +35,11 @@ func getPayload(opCtx *operation.OpContext, opType operation.OpType) any {
 }
 
 func Execute(ctx context.Context, opCtx *operation.OpContext, opType operation.OpType, opts ...any) error {
+	if updateValidation != nil && opCtx != nil && (opType == operation.OpTypeBeforeUpdate || opType == operation.OpTypeBeforeUpsert) {
+		if err := validateUpdates(ctx, opCtx); err != nil {
+			return mxerrors.Validation(err)
+		}
+	}
 	payLoad := getPayload(opCtx, opType)
 	if payLoad == nil {
 		return nil
@@ -41,12 +48,12 @@ func Execute(ctx context.Context, opCtx *operation.OpContext, opType operation.O
 
 	switch value.Type().Kind() {
 	case reflect.Slice:
-		return executeSlice(ctx, value, opts...)
+		return mxerrors.Validation(executeSlice(ctx, value, opts...))
 	case reflect.Ptr:
 		if value.IsZero() {
 			return nil
 		}
-		return execute(ctx, value, opts...)
+		return mxerrors.Validation(execute(ctx, value, opts...))
 	default:
 		return nil
 	}
@@ -193,6 +193,13 @@ func Test_getPayload(t *testing.T) {
 
 			want: &User{},
 		},
+		{
+			name:   "BeforeReplace",
+			opCtx:  &operation.OpContext{Doc: &User{}, Replacement: &User{Name: "Mingyong Chen"}},
+			opType: operation.OpTypeBeforeReplace,
+
+			want: &User{Name: "Mingyong Chen"},
+		},
 	}
 
 	for _, tc := range testCases {
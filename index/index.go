@@ -0,0 +1,186 @@
+package index
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/matiniiuu/mongox/internal/pkg/structs"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// TagName is the struct tag the indexes are declared with
+const TagName = "mongox"
+
+var ErrInvalidTag = errors.New("mongox: invalid index tag")
+
+// Index is an index declared on the model
+type Index struct {
+	// Name follows the default naming of MongoDB, e.g. name_1_age_-1
+	Name               string
+	Keys               bson.D
+	Unique             bool
+	Sparse             bool
+	ExpireAfterSeconds *int32
+}
+
+// Text reports whether the index is a text index
+func (i Index) Text() bool {
+	for _, e := range i.Keys {
+		if e.Value == "text" {
+			return true
+		}
+	}
+	return false
+}
+
+// Model returns the index model used to create the index
+func (i Index) Model() mongo.IndexModel {
+	opts := options.Index().SetName(i.Name)
+	if i.Unique {
+		opts.SetUnique(true)
+	}
+	if i.Sparse {
+		opts.SetSparse(true)
+	}
+	if i.ExpireAfterSeconds != nil {
+		opts.SetExpireAfterSeconds(*i.ExpireAfterSeconds)
+	}
+	return mongo.IndexModel{Keys: i.Keys, Options: opts}
+}
+
+// FromModel returns the indexes declared by the mongox tags of T
+//
+// The tag is a comma separated list of the following options:
+//   - index: a single field index on the field
+//   - index=<group>: the field joins the compound index of the group, the keys follow the order of the fields
+//   - order=1|-1: the order of the field in its indexes, 1 by default
+//   - unique: the single field index, or the compound indexes of the field, are unique
+//   - sparse: the single field index, or the compound indexes of the field, are sparse
+//   - ttl=<seconds>: the single field index expires the documents after the seconds
+//   - text: the field joins the text index of the collection
+//
+// The options which are not about indexes are ignored
+func FromModel[T any]() ([]Index, error) {
+	return Parse(reflect.TypeFor[T]())
+}
+
+// Parse returns the indexes declared by the mongox tags of the struct type
+func Parse(typ reflect.Type) ([]Index, error) {
+	var (
+		indexes []Index
+		groups  = make(map[string]int)
+		text    bson.D
+	)
+	for _, field := range structs.Fields(typ) {
+		tag, ok := field.Tag.Lookup(TagName)
+		if !ok {
+			continue
+		}
+		decl, err := parseTag(tag)
+		if err != nil {
+			return nil, fmt.Errorf("%w of field %s: %v", ErrInvalidTag, field.Name, err)
+		}
+		if decl.text {
+			text = append(text, bson.E{Key: field.Path, Value: "text"})
+		}
+		key := bson.E{Key: field.Path, Value: decl.order}
+		if decl.single() {
+			indexes = append(indexes, Index{
+				Keys:               bson.D{key},
+				Unique:             decl.unique,
+				Sparse:             decl.sparse,
+				ExpireAfterSeconds: decl.ttl,
+			})
+		}
+		for _, group := range decl.groups {
+			i, ok := groups[group]
+			if !ok {
+				i = len(indexes)
+				groups[group] = i
+				indexes = append(indexes, Index{})
+			}
+			indexes[i].Keys = append(indexes[i].Keys, key)
+			indexes[i].Unique = indexes[i].Unique || decl.unique
+			indexes[i].Sparse = indexes[i].Sparse || decl.sparse
+		}
+	}
+	if len(text) > 0 {
+		indexes = append(indexes, Index{Keys: text})
+	}
+	for i := range indexes {
+		indexes[i].Name = name(indexes[i].Keys)
+	}
+	return indexes, nil
+}
+
+// declaration is the parsed mongox tag of a field
+type declaration struct {
+	index  bool
+	groups []string
+	order  int32
+	unique bool
+	sparse bool
+	ttl    *int32
+	text   bool
+}
+
+// single reports whether the field has a single field index
+func (d declaration) single() bool {
+	return d.index || d.ttl != nil || (len(d.groups) == 0 && (d.unique || d.sparse))
+}
+
+func parseTag(tag string) (declaration, error) {
+	decl := declaration{order: 1}
+	for _, opt := range strings.Split(tag, ",") {
+		key, value, hasValue := strings.Cut(strings.TrimSpace(opt), "=")
+		switch key {
+		case "index":
+			if !hasValue {
+				decl.index = true
+				continue
+			}
+			if value == "" {
+				return decl, errors.New("empty index group")
+			}
+			decl.groups = append(decl.groups, value)
+		case "order":
+			switch value {
+			case "1":
+				decl.order = 1
+			case "-1":
+				decl.order = -1
+			default:
+				return decl, fmt.Errorf("order must be 1 or -1, got %q", value)
+			}
+		case "unique":
+			decl.unique = true
+		case "sparse":
+			decl.sparse = true
+		case "ttl":
+			seconds, err := strconv.ParseInt(value, 10, 32)
+			if err != nil || seconds < 0 {
+				return decl, fmt.Errorf("ttl must be a non negative number of seconds, got %q", value)
+			}
+			ttl := int32(seconds)
+			decl.ttl = &ttl
+		case "text":
+			decl.text = true
+		}
+	}
+	return decl, nil
+}
+
+// name returns the default name MongoDB gives to the index with the keys
+func name(keys bson.D) string {
+	parts := make([]string, 0, len(keys)*2)
+	for _, e := range keys {
+		parts = append(parts, e.Key, fmt.Sprint(e.Value))
+	}
+	return strings.Join(parts, "_")
+}
@@ -0,0 +1,82 @@
+package index
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type Timestamps struct {
+	CreatedAt time.Time `bson:"created_at" mongox:"ttl=3600"`
+}
+
+type Profile struct {
+	City string `bson:"city" mongox:"index"`
+}
+
+type TestUser struct {
+	ID         bson.ObjectID `bson:"_id,omitempty"`
+	Timestamps `bson:",inline"`
+	Name       string  `bson:"name" mongox:"index=name_age,unique"`
+	Age        int     `bson:"age" mongox:"index=name_age,order=-1"`
+	Email      string  `bson:"email" mongox:"unique,sparse"`
+	Title      string  `bson:"title" mongox:"text"`
+	Bio        string  `bson:"bio" mongox:"text"`
+	Profile    Profile `bson:"profile"`
+	Version    int64   `bson:"version" mongox:"version"`
+}
+
+func int32Ptr(i int32) *int32 {
+	return &i
+}
+
+func TestFromModel(t *testing.T) {
+	indexes, err := FromModel[TestUser]()
+	require.NoError(t, err)
+	assert.Equal(t, []Index{
+		{Name: "created_at_1", Keys: bson.D{{Key: "created_at", Value: int32(1)}}, ExpireAfterSeconds: int32Ptr(3600)},
+		{Name: "name_1_age_-1", Keys: bson.D{{Key: "name", Value: int32(1)}, {Key: "age", Value: int32(-1)}}, Unique: true},
+		{Name: "email_1", Keys: bson.D{{Key: "email", Value: int32(1)}}, Unique: true, Sparse: true},
+		{Name: "profile.city_1", Keys: bson.D{{Key: "profile.city", Value: int32(1)}}},
+		{Name: "title_text_bio_text", Keys: bson.D{{Key: "title", Value: "text"}, {Key: "bio", Value: "text"}}},
+	}, indexes)
+}
+
+func TestParse_InvalidTag(t *testing.T) {
+	testCases := []struct {
+		name string
+		typ  reflect.Type
+	}{
+		{name: "invalid order", typ: reflect.TypeOf(struct {
+			Name string `mongox:"index,order=2"`
+		}{})},
+		{name: "invalid ttl", typ: reflect.TypeOf(struct {
+			CreatedAt time.Time `mongox:"ttl=soon"`
+		}{})},
+		{name: "empty group", typ: reflect.TypeOf(struct {
+			Name string `mongox:"index="`
+		}{})},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Parse(tc.typ)
+			assert.ErrorIs(t, err, ErrInvalidTag)
+		})
+	}
+}
+
+func TestIndex_Text(t *testing.T) {
+	assert.False(t, Index{Keys: bson.D{{Key: "name", Value: int32(1)}}}.Text())
+	assert.True(t, Index{Keys: bson.D{{Key: "name", Value: "text"}}}.Text())
+}
+
+func TestIndex_Model(t *testing.T) {
+	model := Index{Name: "created_at_1", Keys: bson.D{{Key: "created_at", Value: int32(1)}}, Unique: true, ExpireAfterSeconds: int32Ptr(60)}.Model()
+	assert.Equal(t, bson.D{{Key: "created_at", Value: int32(1)}}, model.Keys)
+	require.NotNil(t, model.Options)
+	assert.Len(t, model.Options.List(), 3)
+}
@@ -0,0 +1,191 @@
+package index
+
+import (
+	"context"
+	"slices"
+
+	mxerrors "github.com/matiniiuu/mongox/errors"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// idIndexName is the name of the index MongoDB creates on _id, it is never dropped
+const idIndexName = "_id_"
+
+func NewIndexer[T any](collection *mongo.Collection) *Indexer[T] {
+	return &Indexer[T]{collection: collection}
+}
+
+// Indexer keeps the indexes of the collection in sync with the indexes declared on T
+type Indexer[T any] struct {
+	collection *mongo.Collection
+	dropStale  bool
+	dryRun     bool
+}
+
+// DropStale is used to set whether the indexes of the collection which are not declared on T are dropped
+// The _id index is never dropped
+func (i *Indexer[T]) DropStale(dropStale bool) *Indexer[T] {
+	i.dropStale = dropStale
+	return i
+}
+
+// DryRun is used to set whether Ensure only reports the planned changes without applying them
+func (i *Indexer[T]) DryRun(dryRun bool) *Indexer[T] {
+	i.dryRun = dryRun
+	return i
+}
+
+// Plan is the difference between the indexes declared on T and the indexes of the collection
+type Plan struct {
+	// Create is the indexes to create, including the ones recreated because their options changed
+	Create []Index
+	// Drop is the names of the indexes to drop, they are dropped before the indexes are created
+	Drop []string
+	// Stale is the names of the indexes of the collection which are not declared on T
+	Stale []string
+}
+
+// Empty reports whether the plan changes nothing
+func (p *Plan) Empty() bool {
+	return len(p.Create) == 0 && len(p.Drop) == 0
+}
+
+// Plan computes the changes needed to bring the indexes of the collection in line with the indexes declared on T
+func (i *Indexer[T]) Plan(ctx context.Context) (*Plan, error) {
+	declared, err := FromModel[T]()
+	if err != nil {
+		return nil, err
+	}
+	cursor, err := i.collection.Indexes().List(ctx)
+	if err != nil {
+		return nil, mxerrors.Wrap(err)
+	}
+	var existing []spec
+	if err = cursor.All(ctx, &existing); err != nil {
+		return nil, mxerrors.Wrap(err)
+	}
+	return diff(declared, existing, i.dropStale), nil
+}
+
+// Ensure drops and creates the indexes of the collection following the plan and returns the plan
+// In dry run mode the plan is returned without being applied
+// The errors of the driver are classified, e.g. the documents which violate a new unique index give ErrDuplicateKey
+func (i *Indexer[T]) Ensure(ctx context.Context) (*Plan, error) {
+	plan, err := i.Plan(ctx)
+	if err != nil || i.dryRun {
+		return plan, err
+	}
+	for _, name := range plan.Drop {
+		if err = i.collection.Indexes().DropOne(ctx, name); err != nil {
+			return plan, mxerrors.Wrap(err)
+		}
+	}
+	if len(plan.Create) == 0 {
+		return plan, nil
+	}
+	models := make([]mongo.IndexModel, 0, len(plan.Create))
+	for _, index := range plan.Create {
+		models = append(models, index.Model())
+	}
+	_, err = i.collection.Indexes().CreateMany(ctx, models)
+	return plan, mxerrors.Wrap(err)
+}
+
+// spec is an index returned by listIndexes
+type spec struct {
+	Name               string   `bson:"name"`
+	Key                bson.D   `bson:"key"`
+	Unique             bool     `bson:"unique"`
+	Sparse             bool     `bson:"sparse"`
+	ExpireAfterSeconds *float64 `bson:"expireAfterSeconds"`
+	Weights            bson.D   `bson:"weights"`
+}
+
+// matches reports whether the existing index has the keys of the declared index
+func (s spec) matches(index Index) bool {
+	if index.Text() {
+		if !slices.ContainsFunc(s.Key, func(e bson.E) bool { return e.Key == "_fts" }) {
+			return false
+		}
+		fields := make([]string, 0, len(index.Keys))
+		for _, e := range index.Keys {
+			fields = append(fields, e.Key)
+		}
+		weights := make([]string, 0, len(s.Weights))
+		for _, e := range s.Weights {
+			weights = append(weights, e.Key)
+		}
+		slices.Sort(fields)
+		slices.Sort(weights)
+		return slices.Equal(fields, weights)
+	}
+	return slices.EqualFunc(s.Key, index.Keys, func(a, b bson.E) bool {
+		return a.Key == b.Key && normalize(a.Value) == normalize(b.Value)
+	})
+}
+
+// equal reports whether the existing index is the same as the declared index
+func (s spec) equal(index Index) bool {
+	if !s.matches(index) || s.Unique != index.Unique || s.Sparse != index.Sparse {
+		return false
+	}
+	if s.ExpireAfterSeconds == nil || index.ExpireAfterSeconds == nil {
+		return s.ExpireAfterSeconds == nil && index.ExpireAfterSeconds == nil
+	}
+	return int32(*s.ExpireAfterSeconds) == *index.ExpireAfterSeconds
+}
+
+// normalize turns the numeric key values into 1 or -1 since the servers store them with various number types
+func normalize(value any) any {
+	var f float64
+	switch v := value.(type) {
+	case int32:
+		f = float64(v)
+	case int64:
+		f = float64(v)
+	case int:
+		f = float64(v)
+	case float64:
+		f = v
+	default:
+		return value
+	}
+	if f < 0 {
+		return int32(-1)
+	}
+	return int32(1)
+}
+
+// diff compares the declared indexes with the existing ones
+// An existing index is matched by name first and then by keys, a matched index whose options differ is recreated
+func diff(declared []Index, existing []spec, dropStale bool) *Plan {
+	plan := &Plan{}
+	matched := make(map[string]bool, len(existing))
+	for _, index := range declared {
+		i := slices.IndexFunc(existing, func(s spec) bool { return s.Name == index.Name })
+		if i < 0 {
+			i = slices.IndexFunc(existing, func(s spec) bool { return !matched[s.Name] && s.matches(index) })
+		}
+		if i < 0 {
+			plan.Create = append(plan.Create, index)
+			continue
+		}
+		matched[existing[i].Name] = true
+		if !existing[i].equal(index) {
+			plan.Drop = append(plan.Drop, existing[i].Name)
+			plan.Create = append(plan.Create, index)
+		}
+	}
+	for _, s := range existing {
+		if s.Name == idIndexName || matched[s.Name] {
+			continue
+		}
+		plan.Stale = append(plan.Stale, s.Name)
+		if dropStale {
+			plan.Drop = append(plan.Drop, s.Name)
+		}
+	}
+	return plan
+}
@@ -0,0 +1,72 @@
+//go:build e2e
+
+package index
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"go.mongodb.org/mongo-driver/v2/mongo/readpref"
+)
+
+func getCollection(t *testing.T) *mongo.Collection {
+	client, err := mongo.Connect(options.Client().ApplyURI("mongodb://localhost:27017").SetAuth(options.Credential{
+		Username:   "test",
+		Password:   "test",
+		AuthSource: "db-test",
+	}))
+	require.NoError(t, err)
+	require.NoError(t, client.Ping(context.Background(), readpref.Primary()))
+	return client.Database("db-test").Collection("test_index")
+}
+
+func TestIndexer_e2e_Ensure(t *testing.T) {
+	collection := getCollection(t)
+	ctx := context.Background()
+	defer func() {
+		require.NoError(t, collection.Drop(context.Background()))
+	}()
+
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{Key: "stale", Value: 1}}})
+	require.NoError(t, err)
+
+	declared, err := FromModel[TestUser]()
+	require.NoError(t, err)
+
+	// dry run
+	plan, err := NewIndexer[TestUser](collection).DryRun(true).DropStale(true).Ensure(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, declared, plan.Create)
+	assert.Equal(t, []string{"stale_1"}, plan.Drop)
+	specs, err := collection.Indexes().ListSpecifications(ctx)
+	require.NoError(t, err)
+	assert.Len(t, specs, 2)
+
+	// stale indexes are kept by default
+	plan, err = NewIndexer[TestUser](collection).Ensure(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, declared, plan.Create)
+	assert.Empty(t, plan.Drop)
+	assert.Equal(t, []string{"stale_1"}, plan.Stale)
+	specs, err = collection.Indexes().ListSpecifications(ctx)
+	require.NoError(t, err)
+	assert.Len(t, specs, len(declared)+2)
+
+	// nothing to do once the indexes are in sync
+	plan, err = NewIndexer[TestUser](collection).Ensure(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, plan.Create)
+
+	// stale indexes dropped
+	plan, err = NewIndexer[TestUser](collection).DropStale(true).Ensure(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"stale_1"}, plan.Drop)
+	specs, err = collection.Indexes().ListSpecifications(ctx)
+	require.NoError(t, err)
+	assert.Len(t, specs, len(declared)+1)
+}
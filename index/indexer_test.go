@@ -0,0 +1,123 @@
+package index
+
+import (
+	"context"
+	"testing"
+
+	mxerrors "github.com/matiniiuu/mongox/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"go.mongodb.org/mongo-driver/v2/x/mongo/driver/drivertest"
+)
+
+func float64Ptr(f float64) *float64 {
+	return &f
+}
+
+func Test_diff(t *testing.T) {
+	nameIndex := Index{Name: "name_1", Keys: bson.D{{Key: "name", Value: int32(1)}}}
+	ttlIndex := Index{Name: "created_at_1", Keys: bson.D{{Key: "created_at", Value: int32(1)}}, ExpireAfterSeconds: int32Ptr(60)}
+	textIndex := Index{Name: "title_text_bio_text", Keys: bson.D{{Key: "title", Value: "text"}, {Key: "bio", Value: "text"}}}
+	idSpec := spec{Name: "_id_", Key: bson.D{{Key: "_id", Value: int32(1)}}}
+
+	testCases := []struct {
+		name      string
+		declared  []Index
+		existing  []spec
+		dropStale bool
+		want      *Plan
+	}{
+		{
+			name:     "missing indexes",
+			declared: []Index{nameIndex, ttlIndex},
+			existing: []spec{idSpec},
+			want:     &Plan{Create: []Index{nameIndex, ttlIndex}},
+		},
+		{
+			name:     "up to date",
+			declared: []Index{nameIndex, ttlIndex, textIndex},
+			existing: []spec{
+				idSpec,
+				{Name: "name_1", Key: bson.D{{Key: "name", Value: float64(1)}}},
+				{Name: "created_at_1", Key: bson.D{{Key: "created_at", Value: int64(1)}}, ExpireAfterSeconds: float64Ptr(60)},
+				{Name: "title_text_bio_text", Key: bson.D{{Key: "_fts", Value: "text"}, {Key: "_ftsx", Value: int32(1)}}, Weights: bson.D{{Key: "bio", Value: int32(1)}, {Key: "title", Value: int32(1)}}},
+			},
+			want: &Plan{},
+		},
+		{
+			name:     "matched by keys",
+			declared: []Index{nameIndex},
+			existing: []spec{idSpec, {Name: "by_name", Key: bson.D{{Key: "name", Value: int32(1)}}}},
+			want:     &Plan{},
+		},
+		{
+			name:     "options changed",
+			declared: []Index{ttlIndex},
+			existing: []spec{idSpec, {Name: "created_at_1", Key: bson.D{{Key: "created_at", Value: int32(1)}}, ExpireAfterSeconds: float64Ptr(3600)}},
+			want:     &Plan{Create: []Index{ttlIndex}, Drop: []string{"created_at_1"}},
+		},
+		{
+			name:     "text fields changed",
+			declared: []Index{textIndex},
+			existing: []spec{idSpec, {Name: "title_text_bio_text", Key: bson.D{{Key: "_fts", Value: "text"}, {Key: "_ftsx", Value: int32(1)}}, Weights: bson.D{{Key: "title", Value: int32(1)}}}},
+			want:     &Plan{Create: []Index{textIndex}, Drop: []string{"title_text_bio_text"}},
+		},
+		{
+			name:     "stale indexes kept",
+			declared: []Index{nameIndex},
+			existing: []spec{idSpec, {Name: "name_1", Key: bson.D{{Key: "name", Value: int32(1)}}}, {Name: "age_1", Key: bson.D{{Key: "age", Value: int32(1)}}}},
+			want:     &Plan{Stale: []string{"age_1"}},
+		},
+		{
+			name:      "stale indexes dropped",
+			declared:  []Index{nameIndex},
+			existing:  []spec{idSpec, {Name: "age_1", Key: bson.D{{Key: "age", Value: int32(1)}}}},
+			dropStale: true,
+			want:      &Plan{Create: []Index{nameIndex}, Drop: []string{"age_1"}, Stale: []string{"age_1"}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, diff(tc.declared, tc.existing, tc.dropStale))
+		})
+	}
+}
+
+func TestPlan_Empty(t *testing.T) {
+	assert.True(t, (&Plan{Stale: []string{"age_1"}}).Empty())
+	assert.False(t, (&Plan{Drop: []string{"age_1"}}).Empty())
+	assert.False(t, (&Plan{Create: []Index{{Name: "name_1"}}}).Empty())
+}
+
+// newMockCollection returns a collection whose commands are answered with the responses in order
+func newMockCollection(t *testing.T, responses ...bson.D) *mongo.Collection {
+	clientOpts := options.Client()
+	clientOpts.Deployment = drivertest.NewMockDeployment(responses...)
+	client, err := mongo.Connect(clientOpts)
+	require.NoError(t, err)
+	return client.Database("db-test").Collection("test_user")
+}
+
+func TestIndexer_Ensure_duplicateKey(t *testing.T) {
+	type uniqueUser struct {
+		Email string `bson:"email" mongox:"unique"`
+	}
+	collection := newMockCollection(t,
+		bson.D{{Key: "ok", Value: 1}, {Key: "cursor", Value: bson.D{
+			{Key: "id", Value: int64(0)},
+			{Key: "ns", Value: "db-test.test_user"},
+			{Key: "firstBatch", Value: bson.A{bson.D{{Key: "name", Value: "_id_"}, {Key: "key", Value: bson.D{{Key: "_id", Value: 1}}}}}},
+		}}},
+		bson.D{{Key: "ok", Value: 0}, {Key: "code", Value: 11000}, {Key: "errmsg", Value: "E11000 duplicate key error"}},
+	)
+	plan, err := NewIndexer[uniqueUser](collection).Ensure(context.Background())
+	assert.ErrorIs(t, err, mxerrors.ErrDuplicateKey)
+	var cmdErr mongo.CommandError
+	assert.ErrorAs(t, err, &cmdErr)
+	require.NotNil(t, plan)
+	assert.Len(t, plan.Create, 1)
+}
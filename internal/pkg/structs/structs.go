@@ -0,0 +1,169 @@
+package structs
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Field is an exported struct field together with the key the bson codec uses for it
+type Field struct {
+	reflect.StructField
+	// Key is the bson key of the field itself
+	Key string
+	// Path is the dotted bson path of the field from the root struct, inline structs add no segment
+	Path      string
+	OmitEmpty bool
+	Inline    bool
+}
+
+// Tag is the parsed bson struct tag of a field
+type Tag struct {
+	Key       string
+	OmitEmpty bool
+	Inline    bool
+	Skip      bool
+}
+
+type lookupKey struct {
+	typ  reflect.Type
+	name string
+}
+
+type lookupResult struct {
+	field Field
+	ok    bool
+}
+
+var lookupCache sync.Map
+
+// ParseTag parses the bson tag of the struct field the same way as the default struct codec of the driver
+func ParseTag(sf reflect.StructField) Tag {
+	tag, ok := sf.Tag.Lookup("bson")
+	if !ok && !strings.Contains(string(sf.Tag), ":") && len(sf.Tag) > 0 {
+		tag = string(sf.Tag)
+	}
+	parts := strings.Split(tag, ",")
+	t := Tag{Key: parts[0]}
+	if t.Key == "-" {
+		t.Skip = true
+		return t
+	}
+	for _, opt := range parts[1:] {
+		switch opt {
+		case "omitempty":
+			t.OmitEmpty = true
+		case "inline":
+			t.Inline = true
+		}
+	}
+	if t.Key == "" {
+		t.Key = strings.ToLower(sf.Name)
+	}
+	return t
+}
+
+// Lookup finds the field with the given Go name, following the promotion rules of embedded structs,
+// and resolves its bson path
+func Lookup(typ reflect.Type, name string) (Field, bool) {
+	typ = Indirect(typ)
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return Field{}, false
+	}
+	key := lookupKey{typ: typ, name: name}
+	if v, ok := lookupCache.Load(key); ok {
+		r := v.(lookupResult)
+		return r.field, r.ok
+	}
+	field, ok := lookup(typ, name)
+	lookupCache.Store(key, lookupResult{field: field, ok: ok})
+	return field, ok
+}
+
+func lookup(typ reflect.Type, name string) (Field, bool) {
+	sf, ok := typ.FieldByName(name)
+	if !ok {
+		return Field{}, false
+	}
+	var (
+		segments []string
+		cur      = typ
+		tag      Tag
+	)
+	for i, idx := range sf.Index {
+		f := cur.Field(idx)
+		if !f.IsExported() {
+			return Field{}, false
+		}
+		tag = ParseTag(f)
+		if tag.Skip {
+			return Field{}, false
+		}
+		if !tag.Inline || i == len(sf.Index)-1 {
+			segments = append(segments, tag.Key)
+		}
+		cur = Indirect(f.Type)
+	}
+	return Field{
+		StructField: sf,
+		Key:         tag.Key,
+		Path:        strings.Join(segments, "."),
+		OmitEmpty:   tag.OmitEmpty,
+		Inline:      tag.Inline,
+	}, true
+}
+
+// Indirect returns the type pointed to by the pointer types
+func Indirect(typ reflect.Type) reflect.Type {
+	for typ != nil && typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	return typ
+}
+
+// Fields returns the exported fields of the struct and of its nested structs in declaration order, together with
+// their bson paths. The fields of inline structs are flattened into their parent, the nested structs themselves are
+// also returned. The index of a field is the index sequence from the root struct as used by reflect.Value.FieldByIndex
+func Fields(typ reflect.Type) []Field {
+	typ = Indirect(typ)
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return nil
+	}
+	return fields(typ, nil, "", map[reflect.Type]bool{})
+}
+
+func fields(typ reflect.Type, index []int, prefix string, visiting map[reflect.Type]bool) []Field {
+	if visiting[typ] {
+		return nil
+	}
+	visiting[typ] = true
+	defer delete(visiting, typ)
+
+	var result []Field
+	for i := 0; i < typ.NumField(); i++ {
+		sf := typ.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		sf.Index = append(slices.Clone(index), i)
+		tag := ParseTag(sf)
+		if tag.Skip {
+			continue
+		}
+		ft := Indirect(sf.Type)
+		if tag.Inline && ft.Kind() == reflect.Struct {
+			result = append(result, fields(ft, sf.Index, prefix, visiting)...)
+			continue
+		}
+		path := tag.Key
+		if prefix != "" {
+			path = prefix + "." + tag.Key
+		}
+		result = append(result, Field{StructField: sf, Key: tag.Key, Path: path, OmitEmpty: tag.OmitEmpty, Inline: tag.Inline})
+		if ft.Kind() == reflect.Struct {
+			result = append(result, fields(ft, sf.Index, path, visiting)...)
+		}
+	}
+	return result
+}
@@ -0,0 +1,129 @@
+package structs
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type Timestamps struct {
+	CreatedAt time.Time `bson:"createdAt,omitempty"`
+	UpdatedAt time.Time `bson:"updatedAt"`
+}
+
+type Profile struct {
+	Age int
+}
+
+type user struct {
+	ID         bson.ObjectID `bson:"_id,omitempty"`
+	Timestamps `bson:",inline"`
+	Name       string `bson:"name"`
+	Profile    Profile
+	Ignored    string `bson:"-"`
+	Legacy     string `json:"legacy"`
+	secret     string
+}
+
+type nestedUser struct {
+	Timestamps
+}
+
+func TestParseTag(t *testing.T) {
+	typ := reflect.TypeOf(user{})
+	testCases := []struct {
+		name  string
+		field string
+		want  Tag
+	}{
+		{name: "key and omitempty", field: "ID", want: Tag{Key: "_id", OmitEmpty: true}},
+		{name: "inline", field: "Timestamps", want: Tag{Key: "timestamps", Inline: true}},
+		{name: "key only", field: "Name", want: Tag{Key: "name"}},
+		{name: "no tag", field: "Profile", want: Tag{Key: "profile"}},
+		{name: "skip", field: "Ignored", want: Tag{Key: "-", Skip: true}},
+		{name: "other tags only", field: "Legacy", want: Tag{Key: "legacy"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sf, ok := typ.FieldByName(tc.field)
+			assert.True(t, ok)
+			assert.Equal(t, tc.want, ParseTag(sf))
+		})
+	}
+}
+
+func TestLookup(t *testing.T) {
+	testCases := []struct {
+		name     string
+		typ      reflect.Type
+		field    string
+		wantPath string
+		wantOk   bool
+	}{
+		{name: "not a struct", typ: reflect.TypeOf(""), field: "Name", wantOk: false},
+		{name: "missing field", typ: reflect.TypeOf(user{}), field: "Email", wantOk: false},
+		{name: "skipped field", typ: reflect.TypeOf(user{}), field: "Ignored", wantOk: false},
+		{name: "unexported field", typ: reflect.TypeOf(user{}), field: "secret", wantOk: false},
+		{name: "top level field", typ: reflect.TypeOf(&user{}), field: "ID", wantPath: "_id", wantOk: true},
+		{name: "inline field", typ: reflect.TypeOf(&user{}), field: "CreatedAt", wantPath: "createdAt", wantOk: true},
+		{name: "embedded without inline", typ: reflect.TypeOf(nestedUser{}), field: "UpdatedAt", wantPath: "timestamps.updatedAt", wantOk: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := Lookup(tc.typ, tc.field)
+			assert.Equal(t, tc.wantOk, ok)
+			assert.Equal(t, tc.wantPath, got.Path)
+		})
+	}
+}
+
+func TestIndirect(t *testing.T) {
+	assert.Nil(t, Indirect(nil))
+	assert.Equal(t, reflect.TypeOf(user{}), Indirect(reflect.TypeOf(&user{})))
+	var u **user
+	assert.Equal(t, reflect.TypeOf(user{}), Indirect(reflect.TypeOf(u)))
+}
+
+type node struct {
+	Name string `bson:"name"`
+	Next *node  `bson:"next"`
+}
+
+func TestFields(t *testing.T) {
+	paths := func(fields []Field) []string {
+		result := make([]string, 0, len(fields))
+		for _, field := range fields {
+			result = append(result, field.Path)
+		}
+		return result
+	}
+	testCases := []struct {
+		name string
+		typ  reflect.Type
+		want []string
+	}{
+		{name: "not a struct", typ: reflect.TypeOf(""), want: []string{}},
+		{name: "inline and nested", typ: reflect.TypeOf(&user{}), want: []string{"_id", "createdAt", "updatedAt", "name", "profile", "profile.age", "legacy"}},
+		{name: "embedded without inline", typ: reflect.TypeOf(nestedUser{}), want: []string{"timestamps", "timestamps.createdAt", "timestamps.updatedAt"}},
+		{name: "recursive type", typ: reflect.TypeOf(node{}), want: []string{"name", "next"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, paths(Fields(tc.typ)))
+		})
+	}
+
+	t.Run("index from the root", func(t *testing.T) {
+		for _, field := range Fields(reflect.TypeOf(user{})) {
+			if field.Path == "updatedAt" {
+				assert.Equal(t, []int{1, 1}, field.Index)
+			}
+			if field.Path == "profile.age" {
+				assert.Equal(t, []int{3, 0}, field.Index)
+			}
+		}
+	})
+}
@@ -0,0 +1,111 @@
+package utils
+
+import (
+	"reflect"
+	"slices"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// firstStages is the stages the server only accepts as the first stage of a pipeline
+var firstStages = []string{"$geoNear", "$documents", "$collStats", "$indexStats"}
+
+// MatchPipeline scopes the pipeline to the condition with a $match stage, which is put first unless the first stage
+// has to stay first, then it is put right after it
+// The condition of a $geoNear first stage is merged into its query instead, so that the nearest documents are the scoped ones
+// ok is false if the pipeline is nil or not a slice of stages, the caller decides whether to keep it as it is
+func MatchPipeline(pipeline any, cond any) (stages bson.A, ok bool) {
+	stages, ok = ToStages(pipeline)
+	if !ok {
+		return nil, false
+	}
+
+	match := bson.D{bson.E{Key: "$match", Value: cond}}
+	if len(stages) == 0 {
+		return append(stages, match), true
+	}
+	op, spec := StageOf(stages[0])
+	if op == "$geoNear" {
+		if geoNear, ok := scopeGeoNear(spec, cond); ok {
+			stages[0] = bson.D{bson.E{Key: op, Value: geoNear}}
+			return stages, true
+		}
+	}
+	if slices.Contains(firstStages, op) {
+		return slices.Insert(stages, 1, any(match)), true
+	}
+	return slices.Insert(stages, 0, any(match)), true
+}
+
+// ToStages copies the stages of the pipeline to a bson.A, ok is false if the pipeline is nil or not a slice of stages
+func ToStages(pipeline any) (bson.A, bool) {
+	if pipeline == nil {
+		return nil, false
+	}
+	value := reflect.ValueOf(pipeline)
+	if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+		return nil, false
+	}
+	if value.Type().Elem().Kind() == reflect.Uint8 {
+		// a raw document such as bson.Raw is not a list of stages
+		return nil, false
+	}
+	stages := make(bson.A, 0, value.Len()+1)
+	for i := 0; i < value.Len(); i++ {
+		stages = append(stages, value.Index(i).Interface())
+	}
+	return stages, true
+}
+
+// StageOf returns the operator and the specification of the stage, the operator is empty if the stage has not one key
+func StageOf(stage any) (string, any) {
+	switch s := stage.(type) {
+	case bson.D:
+		if len(s) == 1 {
+			return s[0].Key, s[0].Value
+		}
+	case bson.M:
+		return StageOf(map[string]any(s))
+	case map[string]any:
+		if len(s) == 1 {
+			for k, v := range s {
+				return k, v
+			}
+		}
+	}
+	return "", nil
+}
+
+// ToDocument returns a copy of the value as a bson.D, ok is false if the value cannot be read as a document
+func ToDocument(value any) (bson.D, bool) {
+	if d, ok := value.(bson.D); ok {
+		return slices.Clone(d), true
+	}
+	var d bson.D
+	data, err := bson.Marshal(value)
+	if err != nil || bson.Unmarshal(data, &d) != nil {
+		return nil, false
+	}
+	return d, true
+}
+
+// AndCond combines the condition with the query of the document stored under the key, or sets it as the query
+func AndCond(d bson.D, key string, cond any) bson.D {
+	for i, e := range d {
+		if e.Key == key {
+			d[i].Value = bson.D{bson.E{Key: "$and", Value: bson.A{e.Value, cond}}}
+			return d
+		}
+	}
+	return append(d, bson.E{Key: key, Value: cond})
+}
+
+// scopeGeoNear combines the query of the $geoNear specification with the condition
+// It reports false if the specification cannot be read as a document
+func scopeGeoNear(spec any, cond any) (bson.D, bool) {
+	d, ok := ToDocument(spec)
+	if !ok {
+		return nil, false
+	}
+	return AndCond(d, "query", cond), true
+}
@@ -0,0 +1,121 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+func TestMatchPipeline(t *testing.T) {
+	cond := bson.D{{Key: "tenantId", Value: "acme"}}
+	match := bson.D{{Key: "$match", Value: cond}}
+	near := bson.D{{Key: "type", Value: "Point"}, {Key: "coordinates", Value: []float64{2.35, 48.85}}}
+
+	testCases := []struct {
+		name     string
+		pipeline any
+		want     bson.A
+		wantOk   bool
+	}{
+		{
+			name:     "nil pipeline",
+			pipeline: nil,
+		},
+		{
+			name:     "not a slice",
+			pipeline: bson.M{"$limit": 1},
+		},
+		{
+			name:     "raw document",
+			pipeline: bson.Raw{},
+		},
+		{
+			name:     "empty pipeline",
+			pipeline: mongo.Pipeline{},
+			want:     bson.A{match},
+			wantOk:   true,
+		},
+		{
+			name:     "match first",
+			pipeline: mongo.Pipeline{{{Key: "$limit", Value: 1}}},
+			want:     bson.A{match, bson.D{{Key: "$limit", Value: 1}}},
+			wantOk:   true,
+		},
+		{
+			name: "geoNear without query",
+			pipeline: mongo.Pipeline{
+				{{Key: "$geoNear", Value: bson.D{{Key: "near", Value: near}, {Key: "distanceField", Value: "distance"}}}},
+				{{Key: "$limit", Value: 1}},
+			},
+			want: bson.A{
+				bson.D{{Key: "$geoNear", Value: bson.D{
+					{Key: "near", Value: near},
+					{Key: "distanceField", Value: "distance"},
+					{Key: "query", Value: cond},
+				}}},
+				bson.D{{Key: "$limit", Value: 1}},
+			},
+			wantOk: true,
+		},
+		{
+			name: "geoNear with query",
+			pipeline: mongo.Pipeline{
+				{{Key: "$geoNear", Value: bson.D{{Key: "near", Value: near}, {Key: "query", Value: bson.D{{Key: "age", Value: 18}}}}}},
+			},
+			want: bson.A{
+				bson.D{{Key: "$geoNear", Value: bson.D{
+					{Key: "near", Value: near},
+					{Key: "query", Value: bson.D{{Key: "$and", Value: bson.A{bson.D{{Key: "age", Value: 18}}, cond}}}},
+				}}},
+			},
+			wantOk: true,
+		},
+		{
+			name:     "geoNear of a map",
+			pipeline: bson.A{bson.M{"$geoNear": bson.M{"query": bson.M{"age": 18}}}},
+			want: bson.A{
+				bson.D{{Key: "$geoNear", Value: bson.D{
+					{Key: "query", Value: bson.D{{Key: "$and", Value: bson.A{bson.D{{Key: "age", Value: int32(18)}}, cond}}}},
+				}}},
+			},
+			wantOk: true,
+		},
+		{
+			name:     "documents",
+			pipeline: mongo.Pipeline{{{Key: "$documents", Value: bson.A{bson.D{{Key: "x", Value: 1}}}}}},
+			want:     bson.A{bson.D{{Key: "$documents", Value: bson.A{bson.D{{Key: "x", Value: 1}}}}}, match},
+			wantOk:   true,
+		},
+		{
+			name:     "collStats",
+			pipeline: mongo.Pipeline{{{Key: "$collStats", Value: bson.D{{Key: "count", Value: bson.D{}}}}}, {{Key: "$limit", Value: 1}}},
+			want: bson.A{
+				bson.D{{Key: "$collStats", Value: bson.D{{Key: "count", Value: bson.D{}}}}},
+				match,
+				bson.D{{Key: "$limit", Value: 1}},
+			},
+			wantOk: true,
+		},
+		{
+			name:     "indexStats",
+			pipeline: []bson.M{{"$indexStats": bson.M{}}},
+			want:     bson.A{bson.M{"$indexStats": bson.M{}}, match},
+			wantOk:   true,
+		},
+		{
+			name:     "geoNear not first",
+			pipeline: mongo.Pipeline{{{Key: "$limit", Value: 1}}, {{Key: "$geoNear", Value: bson.D{}}}},
+			want:     bson.A{match, bson.D{{Key: "$limit", Value: 1}}, bson.D{{Key: "$geoNear", Value: bson.D{}}}},
+			wantOk:   true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := MatchPipeline(tc.pipeline, cond)
+			assert.Equal(t, tc.wantOk, ok)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"fmt"
+	"iter"
 	"reflect"
 
 	"go.mongodb.org/mongo-driver/v2/mongo"
@@ -13,6 +14,33 @@ func ToPtr[T any](v T) *T {
 	return &v
 }
 
+// Chunk groups the values of seq into slices of the given size, the last slice may be smaller
+// An error is yielded on its own after the values collected so far
+func Chunk[T any](seq iter.Seq2[T, error], size int) iter.Seq2[[]T, error] {
+	return func(yield func([]T, error) bool) {
+		chunk := make([]T, 0, size)
+		for v, err := range seq {
+			if err != nil {
+				if len(chunk) > 0 && !yield(chunk, nil) {
+					return
+				}
+				yield(nil, err)
+				return
+			}
+			chunk = append(chunk, v)
+			if len(chunk) == size {
+				if !yield(chunk, nil) {
+					return
+				}
+				chunk = make([]T, 0, size)
+			}
+		}
+		if len(chunk) > 0 {
+			yield(chunk, nil)
+		}
+	}
+}
+
 func ToAnySlice[T any](values ...T) []any {
 	if values == nil {
 		return nil
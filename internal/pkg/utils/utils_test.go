@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"errors"
+	"iter"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func seqOf(values []int, err error) iter.Seq2[int, error] {
+	return func(yield func(int, error) bool) {
+		for _, v := range values {
+			if !yield(v, nil) {
+				return
+			}
+		}
+		if err != nil {
+			yield(0, err)
+		}
+	}
+}
+
+func TestChunk(t *testing.T) {
+	testCases := []struct {
+		name    string
+		seq     iter.Seq2[int, error]
+		size    int
+		limit   int
+		want    [][]int
+		wantErr error
+	}{
+		{
+			name: "empty",
+			seq:  seqOf(nil, nil),
+			size: 2,
+			want: nil,
+		},
+		{
+			name: "exact chunks",
+			seq:  seqOf([]int{1, 2, 3, 4}, nil),
+			size: 2,
+			want: [][]int{{1, 2}, {3, 4}},
+		},
+		{
+			name: "last chunk is smaller",
+			seq:  seqOf([]int{1, 2, 3}, nil),
+			size: 2,
+			want: [][]int{{1, 2}, {3}},
+		},
+		{
+			name:    "error after partial chunk",
+			seq:     seqOf([]int{1, 2, 3}, errors.New("decode error")),
+			size:    2,
+			want:    [][]int{{1, 2}, {3}},
+			wantErr: errors.New("decode error"),
+		},
+		{
+			name:  "break early",
+			seq:   seqOf([]int{1, 2, 3, 4, 5}, nil),
+			size:  2,
+			limit: 1,
+			want:  [][]int{{1, 2}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				got [][]int
+				err error
+			)
+			for chunk, e := range Chunk(tc.seq, tc.size) {
+				if e != nil {
+					err = e
+					break
+				}
+				got = append(got, chunk)
+				if tc.limit > 0 && len(got) == tc.limit {
+					break
+				}
+			}
+			assert.Equal(t, tc.want, got)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package version
+
+import (
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/matiniiuu/mongox/bsonx"
+	"github.com/matiniiuu/mongox/internal/pkg/structs"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// TagOption is the option of the mongox tag which marks the version field of a model
+const TagOption = "version"
+
+var lookupCache sync.Map
+
+type lookupResult struct {
+	field structs.Field
+	ok    bool
+}
+
+// Lookup returns the version field of the model, the first integer field whose mongox tag has the version option
+func Lookup(typ reflect.Type) (structs.Field, bool) {
+	typ = structs.Indirect(typ)
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return structs.Field{}, false
+	}
+	if v, ok := lookupCache.Load(typ); ok {
+		r := v.(lookupResult)
+		return r.field, r.ok
+	}
+	var result lookupResult
+	for _, field := range structs.Fields(typ) {
+		tag, ok := field.Tag.Lookup("mongox")
+		if !ok || !slices.Contains(strings.Split(tag, ","), TagOption) || !isInt(field.Type) {
+			continue
+		}
+		result = lookupResult{field: field, ok: true}
+		break
+	}
+	lookupCache.Store(typ, result)
+	return result.field, result.ok
+}
+
+// Get returns the version of the document, ok is false if the document is not a struct pointer with a version field
+func Get(doc any) (int64, bool) {
+	value, ok := fieldValue(doc, false)
+	if !ok {
+		return 0, false
+	}
+	if value.CanInt() {
+		return value.Int(), true
+	}
+	return int64(value.Uint()), true
+}
+
+// Set sets the version of the document, it does nothing if the document has no version field
+func Set(doc any, version int64) {
+	value, ok := fieldValue(doc, true)
+	if !ok {
+		return
+	}
+	if value.CanInt() {
+		value.SetInt(version)
+		return
+	}
+	value.SetUint(uint64(version))
+}
+
+// Init sets the version of the document to 1 if it has a version field which is not set yet
+func Init(doc any) {
+	if v, ok := Get(doc); !ok || v == 0 {
+		Set(doc, 1)
+	}
+}
+
+// Filter combines the filter with the condition on the current version
+// The documents written before the version field existed match the version 0
+func Filter(filter any, path string, current int64) any {
+	cond := bson.D{bson.E{Key: path, Value: current}}
+	if current == 0 {
+		cond = bson.D{bson.E{Key: path, Value: bson.D{bson.E{Key: "$in", Value: bson.A{0, nil}}}}}
+	}
+	if filter == nil {
+		return filter
+	}
+	if m := bsonx.ToBsonM(filter); m != nil && len(m) == 0 {
+		return cond
+	}
+	return bson.D{bson.E{Key: "$and", Value: bson.A{filter, cond}}}
+}
+
+// Inc adds the increment of the version to the updates, the updates are either update operators or a pipeline
+// The updates are returned as is if they are neither
+func Inc(updates any, path string) any {
+	if updates == nil {
+		return updates
+	}
+	if m := bsonx.ToBsonM(updates); m != nil {
+		// the updates of the caller are copied rather than changed in place
+		result, inc := maps.Clone(m), bson.M{}
+		maps.Copy(inc, bsonx.ToBsonM(m["$inc"]))
+		inc[path] = 1
+		result["$inc"] = inc
+		return result
+	}
+	pipeline, ok := bsonx.ToPipeline(updates)
+	if !ok {
+		return updates
+	}
+	// $ifNull covers the documents written before the version field existed
+	increment := bson.D{bson.E{Key: "$add", Value: bson.A{bson.D{bson.E{Key: "$ifNull", Value: bson.A{"$" + path, 0}}}, 1}}}
+	return append(pipeline, bson.D{bson.E{Key: "$set", Value: bson.D{bson.E{Key: path, Value: increment}}}})
+}
+
+// fieldValue returns the version field of the struct pointed to by doc, the nil embedded pointers are allocated if alloc is true
+func fieldValue(doc any, alloc bool) (reflect.Value, bool) {
+	value := reflect.ValueOf(doc)
+	if value.Kind() != reflect.Pointer || value.IsNil() {
+		return reflect.Value{}, false
+	}
+	field, ok := Lookup(value.Type())
+	if !ok {
+		return reflect.Value{}, false
+	}
+	value = value.Elem()
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return reflect.Value{}, false
+		}
+		value = value.Elem()
+	}
+	for i, idx := range field.Index {
+		if i > 0 && value.Kind() == reflect.Pointer {
+			if value.IsNil() {
+				if !alloc {
+					return reflect.Value{}, false
+				}
+				value.Set(reflect.New(value.Type().Elem()))
+			}
+			value = value.Elem()
+		}
+		value = value.Field(idx)
+	}
+	return value, true
+}
+
+func isInt(typ reflect.Type) bool {
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
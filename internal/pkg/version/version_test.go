@@ -0,0 +1,150 @@
+package version
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+type Meta struct {
+	Version uint32 `bson:"version" mongox:"version"`
+}
+
+type user struct {
+	Name    string `bson:"name"`
+	Version int64  `bson:"version" mongox:"version"`
+}
+
+type embeddedUser struct {
+	Name  string `bson:"name"`
+	*Meta `bson:",inline"`
+}
+
+type plainUser struct {
+	Name    string `bson:"name"`
+	Version string `bson:"version" mongox:"version"`
+}
+
+func TestLookup(t *testing.T) {
+	field, ok := Lookup(reflect.TypeOf(&user{}))
+	require.True(t, ok)
+	assert.Equal(t, "version", field.Path)
+
+	field, ok = Lookup(reflect.TypeOf(embeddedUser{}))
+	require.True(t, ok)
+	assert.Equal(t, []int{1, 0}, field.Index)
+
+	_, ok = Lookup(reflect.TypeOf(plainUser{}))
+	assert.False(t, ok)
+
+	_, ok = Lookup(reflect.TypeOf(bson.M{}))
+	assert.False(t, ok)
+}
+
+func TestGetSet(t *testing.T) {
+	u := &user{Name: "Mingyong Chen", Version: 3}
+	v, ok := Get(u)
+	require.True(t, ok)
+	assert.Equal(t, int64(3), v)
+	Set(u, 4)
+	assert.Equal(t, int64(4), u.Version)
+
+	e := &embeddedUser{Name: "burt"}
+	_, ok = Get(e)
+	assert.False(t, ok)
+	Set(e, 2)
+	require.NotNil(t, e.Meta)
+	assert.Equal(t, uint32(2), e.Version)
+
+	_, ok = Get(user{})
+	assert.False(t, ok)
+	_, ok = Get((*user)(nil))
+	assert.False(t, ok)
+	_, ok = Get(&plainUser{})
+	assert.False(t, ok)
+}
+
+func TestInit(t *testing.T) {
+	u := &user{}
+	Init(u)
+	assert.Equal(t, int64(1), u.Version)
+
+	u = &user{Version: 5}
+	Init(u)
+	assert.Equal(t, int64(5), u.Version)
+
+	e := &embeddedUser{}
+	Init(e)
+	assert.Equal(t, uint32(1), e.Version)
+
+	p := &plainUser{}
+	Init(p)
+	assert.Equal(t, "", p.Version)
+}
+
+func TestFilter(t *testing.T) {
+	testCases := []struct {
+		name    string
+		filter  any
+		current int64
+		want    any
+	}{
+		{
+			name:    "nil",
+			filter:  nil,
+			current: 1,
+			want:    nil,
+		},
+		{
+			name:    "empty",
+			filter:  bson.D{},
+			current: 2,
+			want:    bson.D{{Key: "version", Value: int64(2)}},
+		},
+		{
+			name:    "combined",
+			filter:  bson.M{"name": "Mingyong Chen"},
+			current: 2,
+			want:    bson.D{{Key: "$and", Value: bson.A{bson.M{"name": "Mingyong Chen"}, bson.D{{Key: "version", Value: int64(2)}}}}},
+		},
+		{
+			name:    "no version yet",
+			filter:  bson.D{},
+			current: 0,
+			want:    bson.D{{Key: "version", Value: bson.D{{Key: "$in", Value: bson.A{0, nil}}}}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, Filter(tc.filter, "version", tc.current))
+		})
+	}
+}
+
+func TestInc(t *testing.T) {
+	t.Run("operators", func(t *testing.T) {
+		updates := bson.M{"$set": bson.M{"name": "burt"}, "$inc": bson.M{"age": 1}}
+		got := Inc(updates, "version")
+		assert.Equal(t, bson.M{"$set": bson.M{"name": "burt"}, "$inc": bson.M{"age": 1, "version": 1}}, got)
+		// the updates of the caller are left as they are
+		assert.Equal(t, bson.M{"age": 1}, updates["$inc"])
+	})
+	t.Run("document", func(t *testing.T) {
+		got := Inc(bson.D{{Key: "$set", Value: bson.D{{Key: "name", Value: "burt"}}}}, "version")
+		assert.Equal(t, bson.M{"$set": bson.M{"name": "burt"}, "$inc": bson.M{"version": 1}}, got)
+	})
+	t.Run("pipeline", func(t *testing.T) {
+		got := Inc(mongo.Pipeline{{{Key: "$set", Value: bson.D{{Key: "name", Value: "burt"}}}}}, "version")
+		assert.Equal(t, mongo.Pipeline{
+			bson.D{{Key: "$set", Value: bson.D{{Key: "name", Value: "burt"}}}},
+			bson.D{{Key: "$set", Value: bson.D{{Key: "version", Value: bson.D{{Key: "$add", Value: bson.A{bson.D{{Key: "$ifNull", Value: bson.A{"$version", 0}}}, 1}}}}}}},
+		}, got)
+	})
+	t.Run("nil", func(t *testing.T) {
+		assert.Nil(t, Inc(nil, "version"))
+	})
+}
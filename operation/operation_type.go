@@ -7,25 +7,53 @@ import (
 type OpType string
 
 const (
-	OpTypeBeforeInsert OpType = "beforeInsert"
-	OpTypeAfterInsert  OpType = "afterInsert"
-	OpTypeBeforeUpdate OpType = "beforeUpdate"
-	OpTypeAfterUpdate  OpType = "afterUpdate"
-	OpTypeBeforeDelete OpType = "beforeDelete"
-	OpTypeAfterDelete  OpType = "afterDelete"
-	OpTypeBeforeUpsert OpType = "beforeUpsert"
-	OpTypeAfterUpsert  OpType = "afterUpsert"
-	OpTypeBeforeFind   OpType = "beforeFind"
-	OpTypeAfterFind    OpType = "afterFind"
+	OpTypeBeforeInsert    OpType = "beforeInsert"
+	OpTypeAfterInsert     OpType = "afterInsert"
+	OpTypeBeforeUpdate    OpType = "beforeUpdate"
+	OpTypeAfterUpdate     OpType = "afterUpdate"
+	OpTypeBeforeDelete    OpType = "beforeDelete"
+	OpTypeAfterDelete     OpType = "afterDelete"
+	OpTypeBeforeUpsert    OpType = "beforeUpsert"
+	OpTypeAfterUpsert     OpType = "afterUpsert"
+	OpTypeBeforeFind      OpType = "beforeFind"
+	OpTypeAfterFind       OpType = "afterFind"
+	OpTypeBeforeReplace   OpType = "beforeReplace"
+	OpTypeAfterReplace    OpType = "afterReplace"
+	OpTypeBeforeAggregate OpType = "beforeAggregate"
+	OpTypeAfterAggregate  OpType = "afterAggregate"
+	OpTypeBeforeCount     OpType = "beforeCount"
+	OpTypeAfterCount      OpType = "afterCount"
+	OpTypeBeforeDistinct  OpType = "beforeDistinct"
+	OpTypeAfterDistinct   OpType = "afterDistinct"
 )
 
 type OpContext struct {
 	Col *mongo.Collection `opt:"-"`
 	Doc any
 	// filter also can be used as query
-	Filter       any
-	Updates      any
-	Replacement  any
+	Filter      any
+	Updates     any
+	Replacement any
+	// Pipeline is the pipeline of the aggregation, the before callbacks may change it
+	Pipeline     any
 	MongoOptions any
 	ModelHook    any
+	// Transactional reports whether the operation runs inside a transaction, it is set before the callbacks run
+	Transactional bool `opt:"-"`
+
+	values map[any]any
+}
+
+// SetValue stores a value for the later callbacks of the same operation, e.g. a before callback passes it to an after callback
+// Like the keys of context.WithValue, the keys should be of unexported types to avoid collisions between the callbacks
+func (o *OpContext) SetValue(key, value any) {
+	if o.values == nil {
+		o.values = make(map[any]any)
+	}
+	o.values[key] = value
+}
+
+// Value returns the value stored by SetValue for the key, or nil if there is none
+func (o *OpContext) Value(key any) any {
+	return o.values[key]
 }
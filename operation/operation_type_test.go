@@ -0,0 +1,17 @@
+package operation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpContext_Value(t *testing.T) {
+	type key struct{}
+	opCtx := NewOpContext(nil)
+	assert.Nil(t, opCtx.Value(key{}))
+
+	opCtx.SetValue(key{}, "value")
+	assert.Equal(t, "value", opCtx.Value(key{}))
+	assert.Nil(t, opCtx.Value("key"))
+}
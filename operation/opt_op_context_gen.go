@@ -46,6 +46,12 @@ func WithUpdates(updates any) OpContextOption {
 	}
 }
 
+func WithPipeline(pipeline any) OpContextOption {
+	return func(opContext *OpContext) {
+		opContext.Pipeline = pipeline
+	}
+}
+
 func WithMongoOptions(mongoOptions any) OpContextOption {
 	return func(opContext *OpContext) {
 		opContext.MongoOptions = mongoOptions
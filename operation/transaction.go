@@ -0,0 +1,49 @@
+package operation
+
+import (
+	"context"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+type commitHooksKey struct{}
+
+type commitHooks struct {
+	mu  sync.Mutex
+	fns []func(ctx context.Context)
+}
+
+// InTransaction reports whether ctx carries a session with a running transaction
+func InTransaction(ctx context.Context) bool {
+	sess := mongo.SessionFromContext(ctx)
+	return sess != nil && sess.ClientSession().TransactionRunning()
+}
+
+// OnCommit registers fn to run after the transaction started by mongox.WithTransaction commits
+// It returns false if ctx does not belong to such a transaction, in which case fn is not registered
+func OnCommit(ctx context.Context, fn func(ctx context.Context)) bool {
+	hooks, ok := ctx.Value(commitHooksKey{}).(*commitHooks)
+	if !ok {
+		return false
+	}
+	hooks.mu.Lock()
+	defer hooks.mu.Unlock()
+	hooks.fns = append(hooks.fns, fn)
+	return true
+}
+
+// WithCommitHooks returns a copy of ctx which collects the functions registered by OnCommit,
+// together with a function which runs them in the order of registration
+func WithCommitHooks(ctx context.Context) (context.Context, func(ctx context.Context)) {
+	hooks := &commitHooks{}
+	return context.WithValue(ctx, commitHooksKey{}, hooks), func(ctx context.Context) {
+		hooks.mu.Lock()
+		fns := hooks.fns
+		hooks.fns = nil
+		hooks.mu.Unlock()
+		for _, fn := range fns {
+			fn(ctx)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package operation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInTransaction(t *testing.T) {
+	assert.False(t, InTransaction(context.Background()))
+}
+
+func TestOnCommit(t *testing.T) {
+	var got []int
+	assert.False(t, OnCommit(context.Background(), func(_ context.Context) { got = append(got, 0) }))
+
+	ctx, run := WithCommitHooks(context.Background())
+	assert.True(t, OnCommit(ctx, func(_ context.Context) { got = append(got, 1) }))
+	assert.True(t, OnCommit(ctx, func(_ context.Context) { got = append(got, 2) }))
+	assert.Empty(t, got)
+
+	run(context.Background())
+	assert.Equal(t, []int{1, 2}, got)
+
+	// the hooks only run once
+	run(context.Background())
+	assert.Equal(t, []int{1, 2}, got)
+}
@@ -0,0 +1,241 @@
+// Package schema keeps the $jsonSchema validator of a collection in sync with the struct and validate tags of
+// its model, so that the documents written by other services are validated by the server too
+package schema
+
+import (
+	"context"
+	"reflect"
+	"slices"
+
+	"github.com/matiniiuu/mongox/builder/query"
+	mxerrors "github.com/matiniiuu/mongox/errors"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// Level is the validationLevel of a collection, which decides the documents the validator applies to
+type Level string
+
+const (
+	// LevelStrict applies the validator to all inserts and updates, it is the default level of the server
+	LevelStrict Level = "strict"
+	// LevelModerate applies the validator to inserts and to updates of the documents which are already valid
+	LevelModerate Level = "moderate"
+	LevelOff      Level = "off"
+)
+
+// Action is the validationAction of a collection, which decides what happens to the invalid documents
+type Action string
+
+const (
+	// ActionError rejects the invalid documents, it is the default action of the server
+	ActionError Action = "error"
+	// ActionWarn accepts the invalid documents and logs a warning
+	ActionWarn Action = "warn"
+)
+
+func NewSyncer[T any](collection *mongo.Collection) *Syncer[T] {
+	return &Syncer[T]{collection: collection}
+}
+
+// Syncer installs the $jsonSchema derived from T as the validator of the collection, see query.JsonSchemaOf
+type Syncer[T any] struct {
+	collection *mongo.Collection
+	dryRun     bool
+}
+
+// DryRun is used to set whether Sync only reports the differences without applying the validator
+func (s *Syncer[T]) DryRun(dryRun bool) *Syncer[T] {
+	s.dryRun = dryRun
+	return s
+}
+
+// Diff is the difference between the validator derived from T and the validator installed on the collection
+type Diff struct {
+	// Create reports whether the collection is missing, it is created with the validator
+	Create bool
+	// Added is the paths of the properties which the installed validator misses
+	Added []string
+	// Removed is the paths of the properties of the installed validator which T no longer declares
+	Removed []string
+	// Changed is the paths of the properties whose schema changed, including the ones which became required or optional
+	Changed []string
+	// Level and Action are the installed validation level and action if they change, they are empty otherwise
+	Level  Level
+	Action Action
+}
+
+// Empty reports whether the diff changes nothing
+func (d *Diff) Empty() bool {
+	return !d.Create && len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0 && d.Level == "" && d.Action == ""
+}
+
+// collectionSpec is a collection returned by listCollections
+type collectionSpec struct {
+	Options struct {
+		Validator        bson.D `bson:"validator"`
+		ValidationLevel  Level  `bson:"validationLevel"`
+		ValidationAction Action `bson:"validationAction"`
+	} `bson:"options"`
+}
+
+// Plan computes the difference between the validator derived from T and the validator installed on the collection
+// An empty level or action stands for the default one of the server
+func (s *Syncer[T]) Plan(ctx context.Context, level Level, action Action) (*Diff, error) {
+	_, diff, err := s.plan(ctx, level, action)
+	return diff, err
+}
+
+// Sync installs the validator derived from T with collMod, or creates the collection with it if it is missing,
+// and returns the difference with the previously installed validator
+// Nothing is applied if the validator is already installed or in dry run mode
+func (s *Syncer[T]) Sync(ctx context.Context, level Level, action Action) (*Diff, error) {
+	validator, diff, err := s.plan(ctx, level, action)
+	if err != nil || s.dryRun || diff.Empty() {
+		return diff, err
+	}
+	db := s.collection.Database()
+	if diff.Create {
+		opts := options.CreateCollection().SetValidator(validator)
+		if level != "" {
+			opts.SetValidationLevel(string(level))
+		}
+		if action != "" {
+			opts.SetValidationAction(string(action))
+		}
+		return diff, mxerrors.Wrap(db.CreateCollection(ctx, s.collection.Name(), opts))
+	}
+	cmd := bson.D{
+		{Key: "collMod", Value: s.collection.Name()},
+		{Key: "validator", Value: validator},
+		{Key: "validationLevel", Value: orDefault(level, LevelStrict)},
+		{Key: "validationAction", Value: orDefault(action, ActionError)},
+	}
+	return diff, mxerrors.Wrap(db.RunCommand(ctx, cmd).Err())
+}
+
+func (s *Syncer[T]) plan(ctx context.Context, level Level, action Action) (bson.D, *Diff, error) {
+	schema, err := query.JsonSchemaOf[T]()
+	if err != nil {
+		return nil, nil, err
+	}
+	validator := bson.D{{Key: query.JsonSchemaOp, Value: schema}}
+
+	cursor, err := s.collection.Database().ListCollections(ctx, bson.D{{Key: "name", Value: s.collection.Name()}})
+	if err != nil {
+		return nil, nil, mxerrors.Wrap(err)
+	}
+	var specs []collectionSpec
+	if err = cursor.All(ctx, &specs); err != nil {
+		return nil, nil, mxerrors.Wrap(err)
+	}
+	if len(specs) == 0 {
+		return validator, &Diff{Create: true}, nil
+	}
+
+	// the declared schema goes through the codec so that its values have the types of the installed one
+	declared, err := normalize(schema)
+	if err != nil {
+		return nil, nil, err
+	}
+	installed := specs[0].Options
+	var installedSchema bson.D
+	if v, ok := lookup(installed.Validator, query.JsonSchemaOp).(bson.D); ok {
+		installedSchema = v
+	}
+	diff := &Diff{}
+	diffSchema("", installedSchema, declared, diff)
+	// a validator with other conditions than $jsonSchema is replaced as a whole
+	if len(installed.Validator) > 1 || len(installed.Validator) == 1 && installedSchema == nil {
+		diff.Changed = append(diff.Changed, "")
+	}
+	if current := orDefault(installed.ValidationLevel, LevelStrict); current != orDefault(level, LevelStrict) {
+		diff.Level = current
+	}
+	if current := orDefault(installed.ValidationAction, ActionError); current != orDefault(action, ActionError) {
+		diff.Action = current
+	}
+	return validator, diff, nil
+}
+
+// diffSchema compares the properties of the object schemas and records the paths of their differences
+func diffSchema(prefix string, installed, declared bson.D, diff *Diff) {
+	installedProperties, _ := lookup(installed, "properties").(bson.D)
+	declaredProperties, _ := lookup(declared, "properties").(bson.D)
+	installedRequired := requiredOf(installed)
+	declaredRequired := requiredOf(declared)
+
+	for _, e := range declaredProperties {
+		path := join(prefix, e.Key)
+		old := lookup(installedProperties, e.Key)
+		if old == nil {
+			diff.Added = append(diff.Added, path)
+			continue
+		}
+		oldSchema, _ := old.(bson.D)
+		newSchema, _ := e.Value.(bson.D)
+		if slices.Contains(installedRequired, e.Key) != slices.Contains(declaredRequired, e.Key) ||
+			!reflect.DeepEqual(keywords(oldSchema), keywords(newSchema)) {
+			diff.Changed = append(diff.Changed, path)
+		}
+		// the properties of a nested object are compared one by one
+		diffSchema(path, oldSchema, newSchema, diff)
+	}
+	for _, e := range installedProperties {
+		if lookup(declaredProperties, e.Key) == nil {
+			diff.Removed = append(diff.Removed, join(prefix, e.Key))
+		}
+	}
+}
+
+// keywords returns the keywords of the schema but the properties and the required ones, which are compared apart
+func keywords(schema bson.D) bson.D {
+	return slices.DeleteFunc(slices.Clone(schema), func(e bson.E) bool {
+		return e.Key == "properties" || e.Key == "required"
+	})
+}
+
+func requiredOf(schema bson.D) []string {
+	values, _ := lookup(schema, "required").(bson.A)
+	required := make([]string, 0, len(values))
+	for _, v := range values {
+		if s, ok := v.(string); ok {
+			required = append(required, s)
+		}
+	}
+	return required
+}
+
+func lookup(d bson.D, key string) any {
+	for _, e := range d {
+		if e.Key == key {
+			return e.Value
+		}
+	}
+	return nil
+}
+
+func normalize(schema bson.D) (bson.D, error) {
+	data, err := bson.Marshal(schema)
+	if err != nil {
+		return nil, err
+	}
+	var d bson.D
+	return d, bson.Unmarshal(data, &d)
+}
+
+func join(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+func orDefault[S ~string](value, def S) S {
+	if value == "" {
+		return def
+	}
+	return value
+}
@@ -0,0 +1,82 @@
+//go:build e2e
+
+package schema
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"go.mongodb.org/mongo-driver/v2/mongo/readpref"
+)
+
+func getCollection(t *testing.T) *mongo.Collection {
+	client, err := mongo.Connect(options.Client().ApplyURI("mongodb://localhost:27017").SetAuth(options.Credential{
+		Username:   "test",
+		Password:   "test",
+		AuthSource: "db-test",
+	}))
+	require.NoError(t, err)
+	require.NoError(t, client.Ping(context.Background(), readpref.Primary()))
+	return client.Database("db-test").Collection("test_schema")
+}
+
+func TestSyncer_e2e_Sync(t *testing.T) {
+	collection := getCollection(t)
+	ctx := context.Background()
+	require.NoError(t, collection.Drop(ctx))
+	defer func() {
+		require.NoError(t, collection.Drop(context.Background()))
+	}()
+
+	// dry run on a missing collection
+	diff, err := NewSyncer[TestUser](collection).DryRun(true).Sync(ctx, "", "")
+	require.NoError(t, err)
+	assert.Equal(t, &Diff{Create: true}, diff)
+	names, err := collection.Database().ListCollectionNames(ctx, bson.D{{Key: "name", Value: collection.Name()}})
+	require.NoError(t, err)
+	assert.Empty(t, names)
+
+	// the collection is created with the validator
+	diff, err = NewSyncer[TestUser](collection).Sync(ctx, LevelStrict, ActionError)
+	require.NoError(t, err)
+	assert.True(t, diff.Create)
+	_, err = collection.InsertOne(ctx, bson.D{{Key: "name", Value: "cmy"}, {Key: "age", Value: -5}})
+	assert.Error(t, err)
+	_, err = collection.InsertOne(ctx, bson.D{{Key: "name", Value: "cmy"}, {Key: "age", Value: 24}})
+	assert.NoError(t, err)
+
+	// nothing to do once the validator is installed
+	diff, err = NewSyncer[TestUser](collection).Sync(ctx, "", "")
+	require.NoError(t, err)
+	assert.True(t, diff.Empty())
+
+	// the differences with a validator installed by hand are reported and the validator is replaced
+	err = collection.Database().RunCommand(ctx, bson.D{
+		{Key: "collMod", Value: collection.Name()},
+		{Key: "validator", Value: bson.D{{Key: "$jsonSchema", Value: bson.D{
+			{Key: "bsonType", Value: "object"},
+			{Key: "properties", Value: bson.D{
+				{Key: "name", Value: bson.D{{Key: "bsonType", Value: "string"}}},
+				{Key: "email", Value: bson.D{{Key: "bsonType", Value: "string"}}},
+			}},
+		}}}},
+		{Key: "validationAction", Value: "warn"},
+	}).Err()
+	require.NoError(t, err)
+	diff, err = NewSyncer[TestUser](collection).Sync(ctx, "", ActionError)
+	require.NoError(t, err)
+	assert.Equal(t, &Diff{
+		Added:   []string{"age", "address"},
+		Removed: []string{"email"},
+		Changed: []string{"name"},
+		Action:  ActionWarn,
+	}, diff)
+	diff, err = NewSyncer[TestUser](collection).DryRun(true).Sync(ctx, "", "")
+	require.NoError(t, err)
+	assert.True(t, diff.Empty())
+}
@@ -0,0 +1,93 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+
+	"github.com/matiniiuu/mongox/builder/query"
+)
+
+type TestUser struct {
+	Name    string `bson:"name" validate:"required,min=2"`
+	Age     int    `bson:"age" validate:"gte=0,lte=150"`
+	Address struct {
+		City string `bson:"city" validate:"required"`
+		Zip  string `bson:"zip" validate:"len=5"`
+	} `bson:"address"`
+}
+
+func TestNewSyncer(t *testing.T) {
+	s := NewSyncer[TestUser](&mongo.Collection{})
+	assert.NotNil(t, s)
+	assert.False(t, s.dryRun)
+	assert.True(t, s.DryRun(true).dryRun)
+}
+
+func TestDiff_Empty(t *testing.T) {
+	assert.True(t, (&Diff{}).Empty())
+	assert.False(t, (&Diff{Create: true}).Empty())
+	assert.False(t, (&Diff{Changed: []string{"age"}}).Empty())
+	assert.False(t, (&Diff{Level: LevelModerate}).Empty())
+}
+
+func Test_diffSchema(t *testing.T) {
+	schema, err := query.JsonSchemaOf[TestUser]()
+	require.NoError(t, err)
+	declared, err := normalize(schema)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name      string
+		installed bson.D
+		want      *Diff
+	}{
+		{
+			name:      "same schema",
+			installed: declared,
+			want:      &Diff{},
+		},
+		{
+			name:      "no validator",
+			installed: nil,
+			want:      &Diff{Added: []string{"name", "age", "address"}},
+		},
+		{
+			name: "added, removed and changed properties",
+			installed: bson.D{
+				{Key: "bsonType", Value: "object"},
+				{Key: "properties", Value: bson.D{
+					{Key: "name", Value: bson.D{{Key: "bsonType", Value: "string"}, {Key: "minLength", Value: int64(2)}}},
+					{Key: "age", Value: bson.D{{Key: "bsonType", Value: "int"}}},
+					{Key: "email", Value: bson.D{{Key: "bsonType", Value: "string"}}},
+					{Key: "address", Value: bson.D{
+						{Key: "bsonType", Value: "object"},
+						{Key: "properties", Value: bson.D{
+							{Key: "city", Value: bson.D{{Key: "bsonType", Value: "string"}}},
+						}},
+					}},
+				}},
+			},
+			want: &Diff{
+				Added:   []string{"address.zip"},
+				Removed: []string{"email"},
+				Changed: []string{"name", "age", "address.city"},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			diff := &Diff{}
+			diffSchema("", tc.installed, declared, diff)
+			assert.Equal(t, tc.want, diff)
+		})
+	}
+}
+
+func Test_orDefault(t *testing.T) {
+	assert.Equal(t, LevelStrict, orDefault(Level(""), LevelStrict))
+	assert.Equal(t, ActionWarn, orDefault(ActionWarn, ActionError))
+}
@@ -0,0 +1,111 @@
+package softdelete
+
+import (
+	"time"
+
+	"github.com/matiniiuu/mongox/internal/pkg/utils"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Scope controls which documents an operation can see when soft delete is enabled
+type Scope int
+
+const (
+	// ScopeDefault only matches documents which have not been soft deleted
+	ScopeDefault Scope = iota
+	// ScopeWithDeleted matches documents regardless of whether they have been soft deleted
+	ScopeWithDeleted
+	// ScopeOnlyDeleted only matches documents which have been soft deleted
+	ScopeOnlyDeleted
+)
+
+// DefaultField is the field name used by mongox.Base to record the deletion time
+const DefaultField = "deletedAt"
+
+var (
+	enabled bool
+	field   = DefaultField
+)
+
+// SetEnabled turns the soft delete mode on or off for every operator created afterwards
+func SetEnabled(on bool) {
+	enabled = on
+}
+
+func Enabled() bool {
+	return enabled
+}
+
+// SetField replaces the field used to record the deletion time, an empty name resets it to DefaultField
+func SetField(name string) {
+	if name == "" {
+		name = DefaultField
+	}
+	field = name
+}
+
+func Field() string {
+	return field
+}
+
+// Cond returns the condition which restricts a query to the given scope, it returns nil for ScopeWithDeleted
+func Cond(scope Scope) bson.D {
+	switch scope {
+	case ScopeWithDeleted:
+		return nil
+	case ScopeOnlyDeleted:
+		return bson.D{bson.E{Key: field, Value: bson.D{bson.E{Key: "$ne", Value: nil}}}}
+	default:
+		return bson.D{bson.E{Key: field, Value: nil}}
+	}
+}
+
+// Filter combines the filter with the condition of the scope
+// A nil filter is returned as is so that the driver keeps rejecting it
+func Filter(filter any, scope Scope) any {
+	cond := Cond(scope)
+	if filter == nil || cond == nil {
+		return filter
+	}
+	if isEmpty(filter) {
+		return cond
+	}
+	return bson.D{bson.E{Key: "$and", Value: bson.A{filter, cond}}}
+}
+
+// Pipeline adds a $match stage with the condition of the scope to the pipeline, see utils.MatchPipeline for the
+// stages which have to stay first
+// The pipeline is returned as is if it is not a slice
+func Pipeline(pipeline any, scope Scope) any {
+	cond := Cond(scope)
+	if cond == nil {
+		return pipeline
+	}
+	if stages, ok := utils.MatchPipeline(pipeline, cond); ok {
+		return stages
+	}
+	return pipeline
+}
+
+// DeleteUpdates returns the updates which mark a document as deleted
+func DeleteUpdates() bson.D {
+	return bson.D{bson.E{Key: "$set", Value: bson.D{bson.E{Key: field, Value: time.Now().Local()}}}}
+}
+
+// RestoreUpdates returns the updates which bring a soft deleted document back
+func RestoreUpdates() bson.D {
+	return bson.D{bson.E{Key: "$unset", Value: bson.D{bson.E{Key: field, Value: ""}}}}
+}
+
+func isEmpty(filter any) bool {
+	switch f := filter.(type) {
+	case bson.D:
+		return len(f) == 0
+	case bson.M:
+		return len(f) == 0
+	case map[string]any:
+		return len(f) == 0
+	}
+	return false
+}
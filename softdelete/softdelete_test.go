@@ -0,0 +1,185 @@
+package softdelete
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+func TestSetEnabled(t *testing.T) {
+	require.False(t, Enabled())
+	SetEnabled(true)
+	assert.True(t, Enabled())
+	SetEnabled(false)
+	assert.False(t, Enabled())
+}
+
+func TestSetField(t *testing.T) {
+	SetField("removedAt")
+	assert.Equal(t, "removedAt", Field())
+	assert.Equal(t, bson.D{{Key: "removedAt", Value: nil}}, Cond(ScopeDefault))
+	SetField("")
+	assert.Equal(t, DefaultField, Field())
+}
+
+func TestCond(t *testing.T) {
+	testCases := []struct {
+		name  string
+		scope Scope
+		want  bson.D
+	}{
+		{
+			name:  "default",
+			scope: ScopeDefault,
+			want:  bson.D{{Key: "deletedAt", Value: nil}},
+		},
+		{
+			name:  "with deleted",
+			scope: ScopeWithDeleted,
+			want:  nil,
+		},
+		{
+			name:  "only deleted",
+			scope: ScopeOnlyDeleted,
+			want:  bson.D{{Key: "deletedAt", Value: bson.D{{Key: "$ne", Value: nil}}}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, Cond(tc.scope))
+		})
+	}
+}
+
+func TestFilter(t *testing.T) {
+	testCases := []struct {
+		name   string
+		filter any
+		scope  Scope
+		want   any
+	}{
+		{
+			name:   "nil filter",
+			filter: nil,
+			scope:  ScopeDefault,
+			want:   nil,
+		},
+		{
+			name:   "empty bson.D",
+			filter: bson.D{},
+			scope:  ScopeDefault,
+			want:   bson.D{{Key: "deletedAt", Value: nil}},
+		},
+		{
+			name:   "empty bson.M",
+			filter: bson.M{},
+			scope:  ScopeOnlyDeleted,
+			want:   bson.D{{Key: "deletedAt", Value: bson.D{{Key: "$ne", Value: nil}}}},
+		},
+		{
+			name:   "with deleted",
+			filter: bson.D{{Key: "name", Value: "Mingyong Chen"}},
+			scope:  ScopeWithDeleted,
+			want:   bson.D{{Key: "name", Value: "Mingyong Chen"}},
+		},
+		{
+			name:   "combine with filter",
+			filter: bson.M{"name": "Mingyong Chen"},
+			scope:  ScopeDefault,
+			want: bson.D{{Key: "$and", Value: bson.A{
+				bson.M{"name": "Mingyong Chen"},
+				bson.D{{Key: "deletedAt", Value: nil}},
+			}}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, Filter(tc.filter, tc.scope))
+		})
+	}
+}
+
+func TestPipeline(t *testing.T) {
+	testCases := []struct {
+		name     string
+		pipeline any
+		scope    Scope
+		want     any
+	}{
+		{
+			name:     "nil pipeline",
+			pipeline: nil,
+			scope:    ScopeDefault,
+			want:     nil,
+		},
+		{
+			name:     "not a slice",
+			pipeline: bson.D{},
+			scope:    ScopeDefault,
+			want:     bson.A{bson.D{{Key: "$match", Value: bson.D{{Key: "deletedAt", Value: nil}}}}},
+		},
+		{
+			name:     "with deleted",
+			pipeline: mongo.Pipeline{{{Key: "$limit", Value: 1}}},
+			scope:    ScopeWithDeleted,
+			want:     mongo.Pipeline{{{Key: "$limit", Value: 1}}},
+		},
+		{
+			name:     "mongo pipeline",
+			pipeline: mongo.Pipeline{{{Key: "$limit", Value: 1}}},
+			scope:    ScopeDefault,
+			want: bson.A{
+				bson.D{{Key: "$match", Value: bson.D{{Key: "deletedAt", Value: nil}}}},
+				bson.D{{Key: "$limit", Value: 1}},
+			},
+		},
+		{
+			name:     "geoNear pipeline",
+			pipeline: mongo.Pipeline{{{Key: "$geoNear", Value: bson.D{{Key: "distanceField", Value: "distance"}}}}},
+			scope:    ScopeDefault,
+			want: bson.A{
+				bson.D{{Key: "$geoNear", Value: bson.D{
+					{Key: "distanceField", Value: "distance"},
+					{Key: "query", Value: bson.D{{Key: "deletedAt", Value: nil}}},
+				}}},
+			},
+		},
+		{
+			name:     "documents pipeline",
+			pipeline: mongo.Pipeline{{{Key: "$documents", Value: bson.A{}}}},
+			scope:    ScopeDefault,
+			want: bson.A{
+				bson.D{{Key: "$documents", Value: bson.A{}}},
+				bson.D{{Key: "$match", Value: bson.D{{Key: "deletedAt", Value: nil}}}},
+			},
+		},
+		{
+			name:     "map pipeline",
+			pipeline: bson.M{"$limit": 1},
+			scope:    ScopeDefault,
+			want:     bson.M{"$limit": 1},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, Pipeline(tc.pipeline, tc.scope))
+		})
+	}
+}
+
+func TestDeleteUpdates(t *testing.T) {
+	updates := DeleteUpdates()
+	require.Len(t, updates, 1)
+	assert.Equal(t, "$set", updates[0].Key)
+	set := updates[0].Value.(bson.D)
+	require.Len(t, set, 1)
+	assert.Equal(t, "deletedAt", set[0].Key)
+	assert.NotZero(t, set[0].Value)
+}
+
+func TestRestoreUpdates(t *testing.T) {
+	assert.Equal(t, bson.D{{Key: "$unset", Value: bson.D{{Key: "deletedAt", Value: ""}}}}, RestoreUpdates())
+}
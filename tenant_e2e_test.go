@@ -0,0 +1,91 @@
+//go:build e2e
+
+package mongox
+
+import (
+	"context"
+	"testing"
+
+	"github.com/matiniiuu/mongox/builder/aggregation"
+	"github.com/matiniiuu/mongox/builder/query"
+	"github.com/matiniiuu/mongox/builder/update"
+	"github.com/matiniiuu/mongox/hook/tenant"
+	"github.com/matiniiuu/mongox/operation"
+
+	"github.com/stretchr/testify/require"
+)
+
+type tenantUser struct {
+	Base     `bson:",inline"`
+	Name     string `bson:"name"`
+	Age      int    `bson:"age"`
+	TenantID string `bson:"tenantId"`
+}
+
+func TestCollection_e2e_Tenant(t *testing.T) {
+	collection := getCollection[tenantUser](t)
+	InitPlugin(&PluginConfig{EnableTenant: true})
+	defer func() {
+		for _, opType := range []operation.OpType{
+			operation.OpTypeBeforeInsert, operation.OpTypeBeforeUpdate, operation.OpTypeBeforeUpsert,
+			operation.OpTypeBeforeReplace, operation.OpTypeBeforeDelete, operation.OpTypeBeforeFind,
+			operation.OpTypeBeforeCount, operation.OpTypeBeforeDistinct, operation.OpTypeBeforeAggregate,
+		} {
+			RemovePlugin("mongox:tenant", opType)
+		}
+	}()
+
+	acme := tenant.NewContext(context.Background(), "acme")
+	umbrella := tenant.NewContext(context.Background(), "umbrella")
+	defer func() {
+		_, err := collection.Deleter().Filter(query.In("tenantId", "acme", "umbrella")).DeleteMany(tenant.Bypass(context.Background()))
+		require.NoError(t, err)
+	}()
+
+	_, err := collection.Creator().InsertMany(acme, []*tenantUser{{Name: "Mingyong Chen", Age: 18}, {Name: "burt", Age: 20}})
+	require.NoError(t, err)
+	_, err = collection.Creator().InsertOne(umbrella, &tenantUser{Name: "gopher", Age: 22})
+	require.NoError(t, err)
+
+	t.Run("missing tenant", func(t *testing.T) {
+		_, err := collection.Finder().Filter(query.Eq("name", "gopher")).FindOne(context.Background())
+		require.Equal(t, tenant.ErrMissingTenant, err)
+	})
+
+	t.Run("reads are scoped", func(t *testing.T) {
+		users, err := collection.Finder().Filter(query.In("name", "Mingyong Chen", "burt", "gopher")).Find(acme)
+		require.NoError(t, err)
+		require.Len(t, users, 2)
+		for _, user := range users {
+			require.Equal(t, "acme", user.TenantID)
+		}
+
+		count, err := collection.Finder().Filter(query.In("name", "Mingyong Chen", "burt", "gopher")).Count(umbrella)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), count)
+
+		pipeline := aggregation.NewStageBuilder().Match(query.In("name", "Mingyong Chen", "burt", "gopher")).Build()
+		aggregated, err := collection.Aggregator().Pipeline(pipeline).Aggregate(umbrella)
+		require.NoError(t, err)
+		require.Len(t, aggregated, 1)
+		require.Equal(t, "gopher", aggregated[0].Name)
+	})
+
+	t.Run("writes are scoped", func(t *testing.T) {
+		result, err := collection.Updater().Filter(query.Eq("name", "gopher")).Updates(update.Set("age", 30)).UpdateOne(acme)
+		require.NoError(t, err)
+		require.Equal(t, int64(0), result.MatchedCount)
+
+		deleted, err := collection.Deleter().Filter(query.Eq("name", "gopher")).DeleteOne(acme)
+		require.NoError(t, err)
+		require.Equal(t, int64(0), deleted.DeletedCount)
+	})
+
+	t.Run("tenant can not be changed", func(t *testing.T) {
+		_, err := collection.Updater().Filter(query.Eq("name", "burt")).Updates(update.Set("tenantId", "umbrella")).UpdateOne(acme)
+		require.Equal(t, tenant.ErrTenantChange, err)
+
+		_, err = collection.Creator().InsertOne(acme, &tenantUser{Name: "intruder", TenantID: "umbrella"})
+		require.Equal(t, tenant.ErrTenantMismatch, err)
+	})
+}
@@ -0,0 +1,41 @@
+package mongox
+
+import (
+	"context"
+
+	mxerrors "github.com/matiniiuu/mongox/errors"
+	"github.com/matiniiuu/mongox/operation"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// WithTransaction runs fn in a transaction of a new session of the client
+// The operations of mongox must use the ctx passed to fn to take part in the transaction
+// fn is retried on transient transaction errors, so it must be safe to run more than once
+// The transaction is committed if fn returns nil and aborted otherwise
+// The functions registered by operation.OnCommit run after the transaction commits
+// If ctx already carries a running transaction, fn joins it and no new session is started
+func WithTransaction(ctx context.Context, client *mongo.Client, fn func(ctx context.Context) error, opts ...options.Lister[options.TransactionOptions]) error {
+	if operation.InTransaction(ctx) {
+		return fn(ctx)
+	}
+
+	session, err := client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	var runCommitHooks func(ctx context.Context)
+	_, err = session.WithTransaction(ctx, func(ctx context.Context) (any, error) {
+		// every attempt collects its own hooks so that a retried attempt does not run them twice
+		ctx, runCommitHooks = operation.WithCommitHooks(ctx)
+		return nil, fn(ctx)
+	}, opts...)
+	if err != nil {
+		return mxerrors.Wrap(err)
+	}
+	runCommitHooks(ctx)
+	return nil
+}
@@ -0,0 +1,71 @@
+//go:build e2e
+
+package mongox
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/matiniiuu/mongox/builder/query"
+	"github.com/matiniiuu/mongox/operation"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithTransaction_e2e(t *testing.T) {
+	collection := getCollection[baseUser](t)
+	client := collection.Collection().Database().Client()
+	ctx := context.Background()
+
+	t.Run("commit", func(t *testing.T) {
+		var (
+			transactional bool
+			committed     bool
+		)
+		callbackName := "transactional"
+		RegisterPlugin(callbackName, func(_ context.Context, opCtx *operation.OpContext, _ ...any) error {
+			transactional = opCtx.Transactional
+			return nil
+		}, operation.OpTypeBeforeInsert)
+		defer RemovePlugin(callbackName, operation.OpTypeBeforeInsert)
+
+		err := WithTransaction(ctx, client, func(ctx context.Context) error {
+			_, err := collection.Creator().InsertOne(ctx, &baseUser{Name: "Mingyong Chen", Age: 18})
+			if err != nil {
+				return err
+			}
+			require.True(t, operation.OnCommit(ctx, func(_ context.Context) {
+				committed = true
+			}))
+			return nil
+		})
+		require.NoError(t, err)
+		require.True(t, transactional)
+		require.True(t, committed)
+
+		deleteResult, err := collection.Collection().DeleteMany(ctx, query.Eq("name", "Mingyong Chen"))
+		require.NoError(t, err)
+		require.Equal(t, int64(1), deleteResult.DeletedCount)
+	})
+
+	t.Run("abort", func(t *testing.T) {
+		var committed bool
+		err := WithTransaction(ctx, client, func(ctx context.Context) error {
+			_, err := collection.Creator().InsertOne(ctx, &baseUser{Name: "burt", Age: 19})
+			if err != nil {
+				return err
+			}
+			operation.OnCommit(ctx, func(_ context.Context) {
+				committed = true
+			})
+			return errors.New("abort")
+		})
+		require.Equal(t, errors.New("abort"), err)
+		require.False(t, committed)
+
+		count, err := collection.Finder().Filter(query.Eq("name", "burt")).Count(ctx)
+		require.NoError(t, err)
+		require.Zero(t, count)
+	})
+}
@@ -2,19 +2,31 @@ package updater
 
 import (
 	"context"
+	"reflect"
 
 	"github.com/matiniiuu/mongox/internal/pkg/utils"
+	"github.com/matiniiuu/mongox/internal/pkg/version"
 
 	"github.com/matiniiuu/mongox/bsonx"
 
 	"github.com/matiniiuu/mongox/callback"
+	mxerrors "github.com/matiniiuu/mongox/errors"
 
 	"github.com/matiniiuu/mongox/operation"
+	"github.com/matiniiuu/mongox/softdelete"
 
 	"go.mongodb.org/mongo-driver/v2/mongo"
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
 
+// ErrVersionConflict is returned when the document to update has been changed since its version was read
+// It is the same error as errors.ErrVersionConflict of mongox/errors
+var ErrVersionConflict = mxerrors.ErrVersionConflict
+
+// ErrVersionRequired is returned by UpdateOne when T has a version field and Version has not been called
+// It is the same error as errors.ErrVersionRequired of mongox/errors
+var ErrVersionRequired = mxerrors.ErrVersionRequired
+
 //go:generate mockgen -source=updater.go -destination=../mock/updater.mock.go -package=mocks
 type IUpdater[T any] interface {
 	UpdateOne(ctx context.Context, opts ...options.Lister[options.UpdateOneOptions]) (*mongo.UpdateResult, error)
@@ -23,7 +35,7 @@ type IUpdater[T any] interface {
 }
 
 func NewUpdater[T any](collection *mongo.Collection) *Updater[T] {
-	return &Updater[T]{collection: collection, filter: nil}
+	return &Updater[T]{collection: collection, filter: nil, softDelete: softdelete.Enabled()}
 }
 
 var _ IUpdater[any] = (*Updater[any])(nil)
@@ -36,6 +48,11 @@ type Updater[T any] struct {
 	modelHook   any
 	beforeHooks []beforeHookFn
 	afterHooks  []afterHookFn
+	softDelete  bool
+	scope       softdelete.Scope
+	version     *int64
+	// arrayFilters select the array elements updated by the $[<identifier>] paths
+	arrayFilters []any
 }
 
 // Filter is used to set the filter of the query
@@ -60,6 +77,54 @@ func (u *Updater[T]) ModelHook(modelHook any) *Updater[T] {
 	return u
 }
 
+// ArrayFilters is used to set the filters which select the array elements updated by the $[<identifier>] paths of
+// UpdateOne, UpdateMany and Upsert, e.g. query.Gte("elem.score", 85) for update.FilteredPositional("grades", "elem")
+// The filters take precedence over the array filters of the mongo options
+func (u *Updater[T]) ArrayFilters(filters ...any) *Updater[T] {
+	u.arrayFilters = append(u.arrayFilters, filters...)
+	return u
+}
+
+// Version is used to set the version of the document read before the update for optimistic locking
+// If T has a field tagged with mongox:"version", UpdateOne only updates the document which still has this version
+// and returns ErrVersionConflict if there is none, UpdateOne requires the version for such a T
+func (u *Updater[T]) Version(current int64) *Updater[T] {
+	u.version = &current
+	return u
+}
+
+// SoftDelete is used to turn the soft delete mode on or off for this updater
+func (u *Updater[T]) SoftDelete(enabled bool) *Updater[T] {
+	u.softDelete = enabled
+	return u
+}
+
+// WithDeleted makes the update also match soft deleted documents
+func (u *Updater[T]) WithDeleted() *Updater[T] {
+	u.scope = softdelete.ScopeWithDeleted
+	return u
+}
+
+// normalizedUpdates converts the updates to a bson.M, or to a mongo.Pipeline for the update pipelines such as the
+// output of aggregation.StageBuilder, so that the global callbacks can add fields or stages to them
+func (u *Updater[T]) normalizedUpdates() any {
+	if m := bsonx.ToBsonM(u.updates); len(m) != 0 {
+		u.updates = m
+	} else if pipeline, ok := bsonx.ToPipeline(u.updates); ok {
+		// the stages are copied on every call so that the stages appended by the callbacks do not pile up
+		return pipeline
+	}
+	return u.updates
+}
+
+// scopedFilter returns the filter restricted to the soft delete scope
+func (u *Updater[T]) scopedFilter() any {
+	if !u.softDelete {
+		return u.filter
+	}
+	return softdelete.Filter(u.filter, u.scope)
+}
+
 func (u *Updater[T]) RegisterBeforeHooks(hooks ...beforeHookFn) *Updater[T] {
 	u.beforeHooks = append(u.beforeHooks, hooks...)
 	return u
@@ -75,10 +140,13 @@ func (u *Updater[T]) preActionHandler(ctx context.Context, globalOpContext *oper
 	if err != nil {
 		return err
 	}
-	for _, beforeHook := range u.beforeHooks {
+	// the global callbacks may have changed the filter, e.g. to scope it to a tenant, or the updates,
+	// e.g. appended a stage to an update pipeline
+	opContext.Filter, opContext.Updates = globalOpContext.Filter, globalOpContext.Updates
+	for i, beforeHook := range u.beforeHooks {
 		err = beforeHook(ctx, opContext)
 		if err != nil {
-			return err
+			return mxerrors.Hook(mxerrors.HookName(beforeHook, i), opType, err)
 		}
 	}
 	return nil
@@ -89,33 +157,51 @@ func (u *Updater[T]) postActionHandler(ctx context.Context, globalOpContext *ope
 	if err != nil {
 		return err
 	}
-	for _, afterHook := range u.afterHooks {
+	for i, afterHook := range u.afterHooks {
 		err = afterHook(ctx, opContext)
 		if err != nil {
-			return err
+			return mxerrors.Hook(mxerrors.HookName(afterHook, i), opType, err)
 		}
 	}
 	return nil
 }
 
+// UpdateOne is used to update the first document matched by the filter
+// If T has a field tagged with mongox:"version", the version is incremented and the filter is restricted to the version
+// set by Version, ErrVersionConflict is returned if no document has this version any more and was not upserted
+// ErrVersionRequired is returned for such a T if Version has not been called, UpdateMany increments the version
+// without checking it for the updates which do not need the optimistic locking
 func (u *Updater[T]) UpdateOne(ctx context.Context, opts ...options.Lister[options.UpdateOneOptions]) (*mongo.UpdateResult, error) {
-	updates := bsonx.ToBsonM(u.updates)
-	if len(updates) != 0 {
-		u.updates = updates
+	if len(u.arrayFilters) != 0 {
+		opts = append(opts, options.UpdateOne().SetArrayFilters(u.arrayFilters))
+	}
+	updates := u.normalizedUpdates()
+	filter := u.scopedFilter()
+	versionField, versioned := version.Lookup(reflect.TypeFor[T]())
+	if versioned {
+		if u.version == nil {
+			return nil, ErrVersionRequired
+		}
+		updates = version.Inc(updates, versionField.Path)
+		filter = version.Filter(filter, versionField.Path, *u.version)
 	}
 
-	globalOpContext := operation.NewOpContext(u.collection, operation.WithDoc(new(T)), operation.WithFilter(u.filter), operation.WithUpdates(u.updates), operation.WithMongoOptions(opts), operation.WithModelHook(u.modelHook))
-	err := u.preActionHandler(ctx, globalOpContext, NewBeforeOpContext(u.collection, NewCondContext(u.filter, WithUpdates(u.updates), WithMongoOptions(opts), WithModelHook(u.modelHook))), operation.OpTypeBeforeUpdate)
+	globalOpContext := operation.NewOpContext(u.collection, operation.WithDoc(new(T)), operation.WithFilter(filter), operation.WithUpdates(updates), operation.WithMongoOptions(opts), operation.WithModelHook(u.modelHook))
+	err := u.preActionHandler(ctx, globalOpContext, NewBeforeOpContext(u.collection, NewCondContext(filter, WithUpdates(updates), WithMongoOptions(opts), WithModelHook(u.modelHook))), operation.OpTypeBeforeUpdate)
 	if err != nil {
 		return nil, err
 	}
+	filter, updates = globalOpContext.Filter, globalOpContext.Updates
 
-	result, err := u.collection.UpdateOne(ctx, u.filter, u.updates, opts...)
+	result, err := u.collection.UpdateOne(ctx, filter, updates, opts...)
 	if err != nil {
-		return nil, err
+		return nil, mxerrors.Wrap(err)
+	}
+	if versioned && result.MatchedCount == 0 && result.UpsertedCount == 0 {
+		return nil, ErrVersionConflict
 	}
 
-	err = u.postActionHandler(ctx, globalOpContext, NewAfterOpContext(u.collection, NewCondContext(u.filter, WithUpdates(u.updates), WithMongoOptions(opts), WithModelHook(u.modelHook))), operation.OpTypeAfterUpdate)
+	err = u.postActionHandler(ctx, globalOpContext, NewAfterOpContext(u.collection, NewCondContext(filter, WithUpdates(updates), WithMongoOptions(opts), WithModelHook(u.modelHook))), operation.OpTypeAfterUpdate)
 	if err != nil {
 		return nil, err
 	}
@@ -123,23 +209,28 @@ func (u *Updater[T]) UpdateOne(ctx context.Context, opts ...options.Lister[optio
 }
 
 func (u *Updater[T]) UpdateMany(ctx context.Context, opts ...options.Lister[options.UpdateManyOptions]) (*mongo.UpdateResult, error) {
-	updates := bsonx.ToBsonM(u.updates)
-	if len(updates) != 0 {
-		u.updates = updates
+	if len(u.arrayFilters) != 0 {
+		opts = append(opts, options.UpdateMany().SetArrayFilters(u.arrayFilters))
+	}
+	updates := u.normalizedUpdates()
+	filter := u.scopedFilter()
+	if versionField, ok := version.Lookup(reflect.TypeFor[T]()); ok {
+		updates = version.Inc(updates, versionField.Path)
 	}
 
-	globalOpContext := operation.NewOpContext(u.collection, operation.WithDoc(new(T)), operation.WithFilter(u.filter), operation.WithUpdates(u.updates), operation.WithMongoOptions(opts), operation.WithModelHook(u.modelHook))
-	err := u.preActionHandler(ctx, globalOpContext, NewBeforeOpContext(u.collection, NewCondContext(u.filter, WithUpdates(u.updates), WithMongoOptions(opts), WithModelHook(u.modelHook))), operation.OpTypeBeforeUpdate)
+	globalOpContext := operation.NewOpContext(u.collection, operation.WithDoc(new(T)), operation.WithFilter(filter), operation.WithUpdates(updates), operation.WithMongoOptions(opts), operation.WithModelHook(u.modelHook))
+	err := u.preActionHandler(ctx, globalOpContext, NewBeforeOpContext(u.collection, NewCondContext(filter, WithUpdates(updates), WithMongoOptions(opts), WithModelHook(u.modelHook))), operation.OpTypeBeforeUpdate)
 	if err != nil {
 		return nil, err
 	}
+	filter, updates = globalOpContext.Filter, globalOpContext.Updates
 
-	result, err := u.collection.UpdateMany(ctx, u.filter, u.updates, opts...)
+	result, err := u.collection.UpdateMany(ctx, filter, updates, opts...)
 	if err != nil {
-		return nil, err
+		return nil, mxerrors.Wrap(err)
 	}
 
-	err = u.postActionHandler(ctx, globalOpContext, NewAfterOpContext(u.collection, NewCondContext(u.filter, WithUpdates(u.updates), WithMongoOptions(opts), WithModelHook(u.modelHook))), operation.OpTypeAfterUpdate)
+	err = u.postActionHandler(ctx, globalOpContext, NewAfterOpContext(u.collection, NewCondContext(filter, WithUpdates(updates), WithMongoOptions(opts), WithModelHook(u.modelHook))), operation.OpTypeAfterUpdate)
 	if err != nil {
 		return nil, err
 	}
@@ -157,25 +248,71 @@ func (u *Updater[T]) Upsert(ctx context.Context, opts ...options.Lister[options.
 			})
 		}
 	}
+	if len(u.arrayFilters) != 0 {
+		opts = append(opts, options.UpdateOne().SetArrayFilters(u.arrayFilters))
+	}
 
-	updates := bsonx.ToBsonM(u.updates)
-	if len(updates) != 0 {
-		u.updates = updates
+	updates := u.normalizedUpdates()
+	filter := u.scopedFilter()
+	if versionField, ok := version.Lookup(reflect.TypeFor[T]()); ok {
+		updates = version.Inc(updates, versionField.Path)
 	}
 
-	globalOpContext := operation.NewOpContext(u.collection, operation.WithDoc(new(T)), operation.WithFilter(u.filter), operation.WithUpdates(u.updates), operation.WithMongoOptions(opts), operation.WithModelHook(u.modelHook))
+	globalOpContext := operation.NewOpContext(u.collection, operation.WithDoc(new(T)), operation.WithFilter(filter), operation.WithUpdates(updates), operation.WithMongoOptions(opts), operation.WithModelHook(u.modelHook))
 
-	err := u.preActionHandler(ctx, globalOpContext, NewBeforeOpContext(u.collection, NewCondContext(u.filter, WithUpdates(u.updates), WithMongoOptions(opts), WithModelHook(u.modelHook))), operation.OpTypeBeforeUpsert)
+	err := u.preActionHandler(ctx, globalOpContext, NewBeforeOpContext(u.collection, NewCondContext(filter, WithUpdates(updates), WithMongoOptions(opts), WithModelHook(u.modelHook))), operation.OpTypeBeforeUpsert)
 	if err != nil {
 		return nil, err
 	}
+	filter, updates = globalOpContext.Filter, globalOpContext.Updates
 
-	result, err := u.collection.UpdateOne(ctx, u.filter, u.updates, opts...)
+	result, err := u.collection.UpdateOne(ctx, filter, updates, opts...)
+	if err != nil {
+		return nil, mxerrors.Wrap(err)
+	}
+
+	err = u.postActionHandler(ctx, globalOpContext, NewAfterOpContext(u.collection, NewCondContext(filter, WithUpdates(updates), WithMongoOptions(opts), WithModelHook(u.modelHook))), operation.OpTypeAfterUpsert)
 	if err != nil {
 		return nil, err
 	}
+	return result, nil
+}
+
+// ReplaceOne is used to replace the first document matched by the filter with the replacement
+// The replacement is passed to the global callbacks as both opContext.Doc and opContext.Replacement
+// If the replacement has a field tagged with mongox:"version", the filter is restricted to its version which is then
+// incremented, ErrVersionConflict is returned if no document has this version any more and none was upserted
+func (u *Updater[T]) ReplaceOne(ctx context.Context, opts ...options.Lister[options.ReplaceOptions]) (result *mongo.UpdateResult, err error) {
+	filter := u.scopedFilter()
+	current, versioned := version.Get(u.replacement)
+	if versioned {
+		versionField, _ := version.Lookup(reflect.TypeOf(u.replacement))
+		filter = version.Filter(filter, versionField.Path, current)
+		version.Set(u.replacement, current+1)
+		defer func() {
+			// the replacement keeps the version it was read with if it has not been written
+			if err != nil {
+				version.Set(u.replacement, current)
+			}
+		}()
+	}
+
+	globalOpContext := operation.NewOpContext(u.collection, operation.WithDoc(u.replacement), operation.WithFilter(filter), operation.WithReplacement(u.replacement), operation.WithMongoOptions(opts), operation.WithModelHook(u.modelHook))
+	err = u.preActionHandler(ctx, globalOpContext, NewBeforeOpContext(u.collection, NewCondContext(filter, WithReplacement(u.replacement), WithMongoOptions(opts), WithModelHook(u.modelHook))), operation.OpTypeBeforeReplace)
+	if err != nil {
+		return nil, err
+	}
+	filter = globalOpContext.Filter
+
+	result, err = u.collection.ReplaceOne(ctx, filter, u.replacement, opts...)
+	if err != nil {
+		return nil, mxerrors.Wrap(err)
+	}
+	if versioned && result.MatchedCount == 0 && result.UpsertedCount == 0 {
+		return nil, ErrVersionConflict
+	}
 
-	err = u.postActionHandler(ctx, globalOpContext, NewAfterOpContext(u.collection, NewCondContext(u.filter, WithUpdates(u.updates), WithMongoOptions(opts), WithModelHook(u.modelHook))), operation.OpTypeAfterUpsert)
+	err = u.postActionHandler(ctx, globalOpContext, NewAfterOpContext(u.collection, NewCondContext(filter, WithReplacement(u.replacement), WithMongoOptions(opts), WithModelHook(u.modelHook))), operation.OpTypeAfterReplace)
 	if err != nil {
 		return nil, err
 	}
@@ -13,11 +13,13 @@ import (
 
 	"github.com/matiniiuu/mongox/bsonx"
 	"github.com/matiniiuu/mongox/callback"
+	mxerrors "github.com/matiniiuu/mongox/errors"
 	"github.com/matiniiuu/mongox/operation"
 	"github.com/stretchr/testify/require"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
 
+	"github.com/matiniiuu/mongox/builder/aggregation"
 	"github.com/matiniiuu/mongox/builder/query"
 	"github.com/matiniiuu/mongox/builder/update"
 
@@ -281,7 +283,7 @@ func TestUpdater_e2e_UpdateOne(t *testing.T) {
 				},
 			},
 			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
-				return assert.Equal(t, errors.New("before hook error"), err)
+				return assert.ErrorIs(t, err, mxerrors.ErrHookFailed) && assert.ErrorContains(t, err, "before hook error")
 			},
 		},
 		{
@@ -310,7 +312,7 @@ func TestUpdater_e2e_UpdateOne(t *testing.T) {
 			updates: update.NewBuilder().Set("name", "chenmingyong").Build(),
 			want:    nil,
 			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
-				return assert.Equal(t, errors.New("after hook error"), err)
+				return assert.ErrorIs(t, err, mxerrors.ErrHookFailed) && assert.ErrorContains(t, err, "after hook error")
 			},
 		},
 		{
@@ -367,7 +369,7 @@ func TestUpdater_e2e_UpdateOne(t *testing.T) {
 				},
 			},
 			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
-				return assert.Equal(t, errors.New("before hook error"), err)
+				return assert.ErrorIs(t, err, mxerrors.ErrHookFailed) && assert.ErrorContains(t, err, "before hook error")
 			},
 		},
 		{
@@ -392,7 +394,7 @@ func TestUpdater_e2e_UpdateOne(t *testing.T) {
 			updates: update.NewBuilder().Set("name", "chenmingyong").Build(),
 			want:    nil,
 			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
-				return assert.Equal(t, errors.New("after hook error"), err)
+				return assert.ErrorIs(t, err, mxerrors.ErrHookFailed) && assert.ErrorContains(t, err, "after hook error")
 			},
 		},
 		{
@@ -630,7 +632,7 @@ func TestUpdater_e2e_UpdateMany(t *testing.T) {
 				},
 			},
 			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
-				return assert.Equal(t, errors.New("before hook error"), err)
+				return assert.ErrorIs(t, err, mxerrors.ErrHookFailed) && assert.ErrorContains(t, err, "before hook error")
 			},
 		},
 		{
@@ -662,7 +664,7 @@ func TestUpdater_e2e_UpdateMany(t *testing.T) {
 			updates: update.Set("name", "chenmingyong"),
 			want:    nil,
 			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
-				return assert.Equal(t, errors.New("after hook error"), err)
+				return assert.ErrorIs(t, err, mxerrors.ErrHookFailed) && assert.ErrorContains(t, err, "after hook error")
 			},
 		},
 		{
@@ -722,7 +724,7 @@ func TestUpdater_e2e_UpdateMany(t *testing.T) {
 				},
 			},
 			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
-				return assert.Equal(t, errors.New("before hook error"), err)
+				return assert.ErrorIs(t, err, mxerrors.ErrHookFailed) && assert.ErrorContains(t, err, "before hook error")
 			},
 		},
 		{
@@ -758,7 +760,7 @@ func TestUpdater_e2e_UpdateMany(t *testing.T) {
 			updates: update.Set("name", "chenmingyong"),
 			want:    nil,
 			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
-				return assert.Equal(t, errors.New("after hook error"), err)
+				return assert.ErrorIs(t, err, mxerrors.ErrHookFailed) && assert.ErrorContains(t, err, "after hook error")
 			},
 		},
 		{
@@ -1054,7 +1056,8 @@ func TestUpdater_e2e_Upsert(t *testing.T) {
 				},
 			},
 			wantErr: func(t require.TestingT, err error, i ...interface{}) {
-				require.Equal(t, errors.New("before hook error"), err)
+				require.ErrorIs(t, err, mxerrors.ErrHookFailed)
+				require.ErrorContains(t, err, "before hook error")
 			},
 		},
 		{
@@ -1091,7 +1094,8 @@ func TestUpdater_e2e_Upsert(t *testing.T) {
 				},
 			},
 			wantErr: func(t require.TestingT, err error, i ...interface{}) {
-				require.Equal(t, errors.New("after hook error"), err)
+				require.ErrorIs(t, err, mxerrors.ErrHookFailed)
+				require.ErrorContains(t, err, "after hook error")
 			},
 		},
 		{
@@ -1158,7 +1162,8 @@ func TestUpdater_e2e_Upsert(t *testing.T) {
 				},
 			},
 			wantErr: func(t require.TestingT, err error, i ...interface{}) {
-				require.Equal(t, errors.New("before hook error"), err)
+				require.ErrorIs(t, err, mxerrors.ErrHookFailed)
+				require.ErrorContains(t, err, "before hook error")
 			},
 		},
 		{
@@ -1191,7 +1196,8 @@ func TestUpdater_e2e_Upsert(t *testing.T) {
 				},
 			},
 			wantErr: func(t require.TestingT, err error, i ...interface{}) {
-				require.Equal(t, errors.New("after hook error"), err)
+				require.ErrorIs(t, err, mxerrors.ErrHookFailed)
+				require.ErrorContains(t, err, "after hook error")
 			},
 		},
 		{
@@ -1260,3 +1266,224 @@ func TestUpdater_e2e_Upsert(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdater_e2e_ReplaceOne(t *testing.T) {
+	collection := getCollection(t)
+	ctx := context.Background()
+
+	callback.GetCallback().Register(operation.OpTypeBeforeReplace, "mongox:default_field", func(ctx context.Context, opCtx *operation.OpContext, opts ...any) error {
+		return field.Execute(ctx, opCtx, operation.OpTypeBeforeReplace, opts...)
+	})
+	defer callback.GetCallback().Remove(operation.OpTypeBeforeReplace, "mongox:default_field")
+
+	user := &TestUser{Name: "Mingyong Chen", Age: 18}
+	user.DefaultId()
+	createdAt := user.DefaultCreatedAt()
+	_, err := collection.InsertOne(ctx, user)
+	require.NoError(t, err)
+	defer func() {
+		_, err := collection.DeleteOne(ctx, query.Id(user.ID))
+		require.NoError(t, err)
+	}()
+
+	t.Run("nil replacement", func(t *testing.T) {
+		_, err := NewUpdater[TestUser](collection).Filter(query.Id(user.ID)).ReplaceOne(ctx)
+		require.Error(t, err)
+	})
+
+	t.Run("keep created time and refresh updated time", func(t *testing.T) {
+		var afterReplace bool
+		result, err := NewUpdater[TestUser](collection).Filter(query.Id(user.ID)).
+			Replacement(&TestUser{Name: "burt", Age: 19}).
+			RegisterAfterHooks(func(_ context.Context, opContext *AfterOpContext, _ ...any) error {
+				afterReplace = opContext.Replacement != nil
+				return nil
+			}).
+			ReplaceOne(ctx)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), result.ModifiedCount)
+		require.True(t, afterReplace)
+
+		got := &TestUser{}
+		require.NoError(t, collection.FindOne(ctx, query.Id(user.ID)).Decode(got))
+		require.Equal(t, "burt", got.Name)
+		require.Equal(t, int64(19), got.Age)
+		require.Equal(t, createdAt.UnixMilli(), got.CreatedAt.UnixMilli())
+		require.False(t, got.UpdatedAt.IsZero())
+	})
+
+	t.Run("before hook error", func(t *testing.T) {
+		_, err := NewUpdater[TestUser](collection).Filter(query.Id(user.ID)).
+			Replacement(&TestUser{Name: "gopher"}).
+			RegisterBeforeHooks(func(_ context.Context, _ *BeforeOpContext, _ ...any) error {
+				return errors.New("before hook error")
+			}).
+			ReplaceOne(ctx)
+		require.ErrorIs(t, err, mxerrors.ErrHookFailed)
+		require.ErrorContains(t, err, "before hook error")
+	})
+}
+
+type versionedUser struct {
+	ID      bson.ObjectID `bson:"_id,omitempty"`
+	Name    string        `bson:"name"`
+	Age     int64         `bson:"age"`
+	Version int64         `bson:"version" mongox:"version"`
+}
+
+func TestUpdater_e2e_Version(t *testing.T) {
+	collection := getCollection(t)
+	ctx := context.Background()
+	id := bson.NewObjectID()
+	_, err := collection.InsertOne(ctx, versionedUser{ID: id, Name: "Mingyong Chen", Age: 18, Version: 1})
+	require.NoError(t, err)
+	defer func() {
+		_, err := collection.DeleteOne(ctx, query.Id(id))
+		require.NoError(t, err)
+	}()
+
+	find := func() *versionedUser {
+		var user versionedUser
+		require.NoError(t, collection.FindOne(ctx, query.Id(id)).Decode(&user))
+		return &user
+	}
+
+	t.Run("update one with the current version", func(t *testing.T) {
+		result, err := NewUpdater[versionedUser](collection).Filter(query.Id(id)).Updates(update.Set("age", 19)).Version(1).UpdateOne(ctx)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), result.ModifiedCount)
+		require.Equal(t, &versionedUser{ID: id, Name: "Mingyong Chen", Age: 19, Version: 2}, find())
+	})
+
+	t.Run("update one with a stale version", func(t *testing.T) {
+		result, err := NewUpdater[versionedUser](collection).Filter(query.Id(id)).Updates(update.Set("age", 20)).Version(1).UpdateOne(ctx)
+		require.Equal(t, ErrVersionConflict, err)
+		require.Nil(t, result)
+		require.Equal(t, int64(2), find().Version)
+	})
+
+	t.Run("update many increments the version", func(t *testing.T) {
+		_, err := NewUpdater[versionedUser](collection).Filter(query.Id(id)).Updates(update.Set("age", 21)).UpdateMany(ctx)
+		require.NoError(t, err)
+		require.Equal(t, int64(3), find().Version)
+	})
+
+	t.Run("replace one", func(t *testing.T) {
+		user := find()
+		user.Age = 22
+		result, err := NewUpdater[versionedUser](collection).Filter(query.Id(id)).Replacement(user).ReplaceOne(ctx)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), result.ModifiedCount)
+		require.Equal(t, int64(4), user.Version)
+		require.Equal(t, user, find())
+
+		stale := *user
+		stale.Version = 3
+		_, err = NewUpdater[versionedUser](collection).Filter(query.Id(id)).Replacement(&stale).ReplaceOne(ctx)
+		require.Equal(t, ErrVersionConflict, err)
+		// the replacement keeps the version it was read with
+		require.Equal(t, int64(3), stale.Version)
+	})
+}
+
+func TestUpdater_e2e_ArrayFilters(t *testing.T) {
+	collection := getCollection(t)
+	ctx := context.Background()
+	id := bson.NewObjectID()
+	_, err := collection.InsertOne(ctx, bson.M{"_id": id, "name": "Mingyong Chen", "flags": 5, "grades": bson.A{
+		bson.M{"score": 80, "passed": false},
+		bson.M{"score": 90, "passed": false},
+	}})
+	require.NoError(t, err)
+	callback.GetCallback().Register(operation.OpTypeBeforeUpdate, "mongox:model", func(ctx context.Context, opCtx *operation.OpContext, opts ...any) error {
+		return field.Execute(ctx, opCtx, operation.OpTypeBeforeUpdate, opts...)
+	})
+	defer func() {
+		callback.GetCallback().Remove(operation.OpTypeBeforeUpdate, "mongox:model")
+		_, err := collection.DeleteOne(ctx, query.Id(id))
+		require.NoError(t, err)
+	}()
+
+	result, err := NewUpdater[TestUser](collection).
+		Filter(query.Id(id)).
+		Updates(update.NewBuilder().Set(update.FilteredPositional("grades", "elem", "passed"), true).Inc(update.AllPositional("grades", "score"), 1).BitOr("flags", 2).Build()).
+		ArrayFilters(query.Gte("elem.score", 85)).
+		UpdateOne(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), result.ModifiedCount)
+
+	var got struct {
+		Flags     int64         `bson:"flags"`
+		UpdatedAt bson.DateTime `bson:"updated_at"`
+		Grades    []struct {
+			Score  int64 `bson:"score"`
+			Passed bool  `bson:"passed"`
+		} `bson:"grades"`
+	}
+	require.NoError(t, collection.FindOne(ctx, query.Id(id)).Decode(&got))
+	assert.Equal(t, int64(7), got.Flags)
+	// the updated time is set by the field hook next to the positional paths
+	assert.NotZero(t, got.UpdatedAt)
+	require.Len(t, got.Grades, 2)
+	assert.Equal(t, int64(81), got.Grades[0].Score)
+	assert.False(t, got.Grades[0].Passed)
+	assert.Equal(t, int64(91), got.Grades[1].Score)
+	assert.True(t, got.Grades[1].Passed)
+}
+
+func TestUpdater_e2e_Pipeline(t *testing.T) {
+	collection := getCollection(t)
+	ctx := context.Background()
+	for _, opType := range []operation.OpType{operation.OpTypeBeforeUpdate, operation.OpTypeBeforeUpsert} {
+		callback.GetCallback().Register(opType, "mongox:model", func(ctx context.Context, opCtx *operation.OpContext, opts ...any) error {
+			return field.Execute(ctx, opCtx, opType, opts...)
+		})
+	}
+	defer func() {
+		callback.GetCallback().Remove(operation.OpTypeBeforeUpdate, "mongox:model")
+		callback.GetCallback().Remove(operation.OpTypeBeforeUpsert, "mongox:model")
+		_, err := collection.DeleteMany(ctx, query.In("name", "Mingyong Chen", "burt"))
+		require.NoError(t, err)
+	}()
+
+	t.Run("update one", func(t *testing.T) {
+		id := bson.NewObjectID()
+		_, err := collection.InsertOne(ctx, bson.M{"_id": id, "name": "Mingyong Chen", "Age": 18})
+		require.NoError(t, err)
+
+		result, err := NewUpdater[TestUser](collection).
+			Filter(query.Id(id)).
+			Updates(aggregation.NewStageBuilder().Set(bson.D{{Key: "Age", Value: aggregation.AddWithoutKey("$Age", 1)}}).Build()).
+			UpdateOne(ctx)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), result.ModifiedCount)
+
+		var user TestUser
+		require.NoError(t, collection.FindOne(ctx, query.Id(id)).Decode(&user))
+		assert.Equal(t, int64(19), user.Age)
+		assert.NotZero(t, user.UpdatedAt)
+		assert.Zero(t, user.CreatedAt)
+	})
+
+	t.Run("upsert", func(t *testing.T) {
+		pipeline := aggregation.NewStageBuilder().Set(bson.D{{Key: "Age", Value: 20}}).Build()
+		result, err := NewUpdater[TestUser](collection).Filter(query.Eq("name", "burt")).Updates(pipeline).Upsert(ctx)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), result.UpsertedCount)
+
+		var inserted TestUser
+		require.NoError(t, collection.FindOne(ctx, query.Eq("name", "burt")).Decode(&inserted))
+		assert.Equal(t, result.UpsertedID, inserted.ID)
+		assert.NotZero(t, inserted.CreatedAt)
+		assert.NotZero(t, inserted.UpdatedAt)
+
+		// the _id and the created time of the existing document are kept as $setOnInsert does
+		result, err = NewUpdater[TestUser](collection).Filter(query.Eq("name", "burt")).Updates(pipeline).Upsert(ctx)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), result.MatchedCount)
+		var updated TestUser
+		require.NoError(t, collection.FindOne(ctx, query.Eq("name", "burt")).Decode(&updated))
+		assert.Equal(t, inserted.ID, updated.ID)
+		assert.True(t, inserted.CreatedAt.Equal(updated.CreatedAt))
+	})
+}
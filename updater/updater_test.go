@@ -4,9 +4,19 @@ import (
 	"context"
 	"testing"
 
+	"github.com/matiniiuu/mongox/builder/aggregation"
+	"github.com/matiniiuu/mongox/builder/query"
+	"github.com/matiniiuu/mongox/builder/update"
+	"github.com/matiniiuu/mongox/callback"
+	"github.com/matiniiuu/mongox/hook/field"
 	mocks "github.com/matiniiuu/mongox/mock"
+	"github.com/matiniiuu/mongox/operation"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"go.mongodb.org/mongo-driver/v2/x/mongo/driver/drivertest"
 	"go.uber.org/mock/gomock"
 )
 
@@ -214,3 +224,253 @@ func TestUpdater_Upsert(t *testing.T) {
 		})
 	}
 }
+
+// mergedOptions applies the option listers captured from the mongo options of the context to a single options value
+func mergedOptions[O any](t *testing.T, got any) O {
+	var opts O
+	for _, lister := range got.([]options.Lister[O]) {
+		for _, set := range lister.List() {
+			require.NoError(t, set(&opts))
+		}
+	}
+	return opts
+}
+
+func TestUpdater_ArrayFilters(t *testing.T) {
+	filters := []any{query.Gte("elem.score", 85)}
+	path := update.FilteredPositional("grades", "elem", "passed")
+	// the before hook aborts the update so that the options and the updates can be checked without a server
+	newUpdater := func(gotOpts, gotUpdates *any) *Updater[TestUser] {
+		return NewUpdater[TestUser](&mongo.Collection{}).
+			Filter(query.Id("1")).
+			Updates(update.Set(path, true)).
+			ArrayFilters(filters...).
+			RegisterBeforeHooks(func(ctx context.Context, opContext *BeforeOpContext, opts ...any) error {
+				*gotOpts, *gotUpdates = opContext.MongoOptions, opContext.Updates
+				return assert.AnError
+			})
+	}
+	updateOne := func(u *Updater[TestUser]) error {
+		_, err := u.UpdateOne(context.Background(), options.UpdateOne().SetArrayFilters([]any{query.Eq("elem.score", 0)}))
+		return err
+	}
+	updateOneOptions := func(t *testing.T, got any) ([]any, *bool) {
+		opts := mergedOptions[options.UpdateOneOptions](t, got)
+		return opts.ArrayFilters, opts.Upsert
+	}
+
+	testCases := []struct {
+		name      string
+		fieldHook bool
+		update    func(u *Updater[TestUser]) error
+		options   func(t *testing.T, got any) ([]any, *bool)
+		wantSet   []string
+		upsert    bool
+	}{
+		{
+			name:    "update one",
+			update:  updateOne,
+			options: updateOneOptions,
+			wantSet: []string{path},
+		},
+		{
+			name: "update many",
+			update: func(u *Updater[TestUser]) error {
+				_, err := u.UpdateMany(context.Background())
+				return err
+			},
+			options: func(t *testing.T, got any) ([]any, *bool) {
+				opts := mergedOptions[options.UpdateManyOptions](t, got)
+				return opts.ArrayFilters, opts.Upsert
+			},
+			wantSet: []string{path},
+		},
+		{
+			name: "upsert",
+			update: func(u *Updater[TestUser]) error {
+				_, err := u.Upsert(context.Background())
+				return err
+			},
+			options: updateOneOptions,
+			wantSet: []string{path},
+			upsert:  true,
+		},
+		{
+			name:      "update one with the default fields",
+			fieldHook: true,
+			update:    updateOne,
+			options:   updateOneOptions,
+			wantSet:   []string{path, "updated_at"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.fieldHook {
+				callback.GetCallback().Register(operation.OpTypeBeforeUpdate, "mongox:model", func(ctx context.Context, opCtx *operation.OpContext, opts ...any) error {
+					return field.Execute(ctx, opCtx, operation.OpTypeBeforeUpdate, opts...)
+				})
+				defer callback.GetCallback().Remove(operation.OpTypeBeforeUpdate, "mongox:model")
+			}
+
+			var gotOpts, gotUpdates any
+			require.ErrorIs(t, tc.update(newUpdater(&gotOpts, &gotUpdates)), assert.AnError)
+			arrayFilters, upsert := tc.options(t, gotOpts)
+			assert.Equal(t, filters, arrayFilters)
+			assert.Equal(t, tc.upsert, upsert != nil && *upsert)
+
+			require.IsType(t, bson.M{}, gotUpdates)
+			set, ok := gotUpdates.(bson.M)["$set"].(bson.M)
+			require.True(t, ok)
+			fields := make([]string, 0, len(set))
+			for key := range set {
+				fields = append(fields, key)
+			}
+			assert.ElementsMatch(t, tc.wantSet, fields)
+			assert.Equal(t, true, set[path])
+		})
+	}
+}
+
+func TestUpdater_Pipeline(t *testing.T) {
+	for _, opType := range []operation.OpType{operation.OpTypeBeforeUpdate, operation.OpTypeBeforeUpsert} {
+		callback.GetCallback().Register(opType, "mongox:model", func(ctx context.Context, opCtx *operation.OpContext, opts ...any) error {
+			return field.Execute(ctx, opCtx, opType, opts...)
+		})
+	}
+	defer func() {
+		callback.GetCallback().Remove(operation.OpTypeBeforeUpdate, "mongox:model")
+		callback.GetCallback().Remove(operation.OpTypeBeforeUpsert, "mongox:model")
+	}()
+
+	pipeline := aggregation.NewStageBuilder().Set(bson.D{{Key: "name", Value: "Mingyong Chen"}}).Build()
+	// the before hook aborts the update so that the updates can be checked without a server
+	newUpdater := func(got *any) *Updater[TestUser] {
+		return NewUpdater[TestUser](&mongo.Collection{}).Filter(query.Id("1")).Updates(pipeline).
+			RegisterBeforeHooks(func(ctx context.Context, opContext *BeforeOpContext, opts ...any) error {
+				*got = opContext.Updates
+				return assert.AnError
+			})
+	}
+
+	testCases := []struct {
+		name       string
+		update     func(u *Updater[TestUser]) error
+		wantFields []string
+	}{
+		{
+			name: "update one",
+			update: func(u *Updater[TestUser]) error {
+				_, err := u.UpdateOne(context.Background())
+				return err
+			},
+			wantFields: []string{"updated_at"},
+		},
+		{
+			name: "update many",
+			update: func(u *Updater[TestUser]) error {
+				_, err := u.UpdateMany(context.Background())
+				return err
+			},
+			wantFields: []string{"updated_at"},
+		},
+		{
+			name: "upsert",
+			update: func(u *Updater[TestUser]) error {
+				_, err := u.Upsert(context.Background())
+				return err
+			},
+			wantFields: []string{"updated_at", "_id", "created_at"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got any
+			u := newUpdater(&got)
+			require.ErrorIs(t, tc.update(u), assert.AnError)
+			require.IsType(t, mongo.Pipeline{}, got)
+			stages := got.(mongo.Pipeline)
+			require.Len(t, stages, 2)
+			assert.Equal(t, pipeline[0], stages[0])
+			require.Equal(t, "$set", stages[1][0].Key)
+			set, ok := stages[1][0].Value.(bson.D)
+			require.True(t, ok)
+			fields := make([]string, 0, len(set))
+			for _, e := range set {
+				fields = append(fields, e.Key)
+			}
+			assert.Equal(t, tc.wantFields, fields)
+
+			// the stage of the timestamps is not appended again by the next update
+			require.ErrorIs(t, tc.update(u), assert.AnError)
+			assert.Len(t, got, 2)
+		})
+	}
+	// the output of the stage builder is left as it is
+	assert.Len(t, pipeline, 1)
+}
+
+// newMockCollection returns a collection whose commands are answered with the responses in order
+func newMockCollection(t *testing.T, responses ...bson.D) *mongo.Collection {
+	clientOpts := options.Client()
+	clientOpts.Deployment = drivertest.NewMockDeployment(responses...)
+	client, err := mongo.Connect(clientOpts)
+	require.NoError(t, err)
+	return client.Database("db-test").Collection("test_user")
+}
+
+type versionedTestUser struct {
+	ID      string `bson:"_id"`
+	Name    string `bson:"name"`
+	Version int64  `bson:"version" mongox:"version"`
+}
+
+func TestUpdater_Version(t *testing.T) {
+	matched := bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}, {Key: "nModified", Value: 1}}
+	upserted := bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}, {Key: "nModified", Value: 0}, {Key: "upserted", Value: bson.A{
+		bson.D{{Key: "index", Value: 0}, {Key: "_id", Value: "1"}},
+	}}}
+	conflict := bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 0}, {Key: "nModified", Value: 0}}
+
+	t.Run("update one without the version", func(t *testing.T) {
+		_, err := NewUpdater[versionedTestUser](newMockCollection(t, matched)).Filter(query.Id("1")).Updates(update.Set("name", "burt")).UpdateOne(context.Background())
+		assert.ErrorIs(t, err, ErrVersionRequired)
+	})
+
+	updateTestCases := []struct {
+		name     string
+		response bson.D
+		wantErr  error
+	}{
+		{name: "update one", response: matched},
+		{name: "update one upserted", response: upserted},
+		{name: "update one conflict", response: conflict, wantErr: ErrVersionConflict},
+	}
+	for _, tc := range updateTestCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewUpdater[versionedTestUser](newMockCollection(t, tc.response)).Filter(query.Id("1")).Updates(update.Set("name", "burt")).
+				Version(1).UpdateOne(context.Background(), options.UpdateOne().SetUpsert(true))
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+
+	replaceTestCases := []struct {
+		name        string
+		response    bson.D
+		wantErr     error
+		wantVersion int64
+	}{
+		{name: "replace one", response: matched, wantVersion: 2},
+		{name: "replace one upserted", response: upserted, wantVersion: 2},
+		// the replacement keeps the version it was read with
+		{name: "replace one conflict", response: conflict, wantErr: ErrVersionConflict, wantVersion: 1},
+	}
+	for _, tc := range replaceTestCases {
+		t.Run(tc.name, func(t *testing.T) {
+			user := &versionedTestUser{ID: "1", Name: "burt", Version: 1}
+			_, err := NewUpdater[versionedTestUser](newMockCollection(t, tc.response)).Filter(query.Id("1")).Replacement(user).
+				ReplaceOne(context.Background(), options.Replace().SetUpsert(true))
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantVersion, user.Version)
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package mongox
+
+import (
+	"context"
+	"errors"
+
+	"github.com/matiniiuu/mongox/updater"
+)
+
+// RetryOnConflict runs fn until it does not fail with updater.ErrVersionConflict, at most attempts times
+// fn is the whole read-modify-write, it must read the document again on every attempt to get its latest version
+// The last error of fn is returned once the attempts are used up, attempts below 1 are treated as 1
+func RetryOnConflict(ctx context.Context, attempts int, fn func(ctx context.Context) error) error {
+	attempts = max(attempts, 1)
+	var err error
+	for i := 0; i < attempts; i++ {
+		if i > 0 {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+		}
+		err = fn(ctx)
+		if !errors.Is(err, updater.ErrVersionConflict) {
+			return err
+		}
+	}
+	return err
+}
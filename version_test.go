@@ -0,0 +1,84 @@
+package mongox
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/matiniiuu/mongox/updater"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryOnConflict(t *testing.T) {
+	errOther := errors.New("other")
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	testCases := []struct {
+		name      string
+		ctx       context.Context
+		attempts  int
+		errs      []error
+		wantErr   error
+		wantCalls int
+	}{
+		{
+			name:      "success",
+			ctx:       context.Background(),
+			attempts:  3,
+			errs:      []error{nil},
+			wantCalls: 1,
+		},
+		{
+			name:      "success after conflicts",
+			ctx:       context.Background(),
+			attempts:  3,
+			errs:      []error{updater.ErrVersionConflict, updater.ErrVersionConflict, nil},
+			wantCalls: 3,
+		},
+		{
+			name:      "attempts used up",
+			ctx:       context.Background(),
+			attempts:  2,
+			errs:      []error{updater.ErrVersionConflict, updater.ErrVersionConflict, nil},
+			wantErr:   updater.ErrVersionConflict,
+			wantCalls: 2,
+		},
+		{
+			name:      "other error",
+			ctx:       context.Background(),
+			attempts:  3,
+			errs:      []error{errOther, nil},
+			wantErr:   errOther,
+			wantCalls: 1,
+		},
+		{
+			name:      "no attempts",
+			ctx:       context.Background(),
+			attempts:  0,
+			errs:      []error{updater.ErrVersionConflict, nil},
+			wantErr:   updater.ErrVersionConflict,
+			wantCalls: 1,
+		},
+		{
+			name:      "canceled context",
+			ctx:       canceled,
+			attempts:  3,
+			errs:      []error{updater.ErrVersionConflict, nil},
+			wantErr:   context.Canceled,
+			wantCalls: 1,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var calls int
+			err := RetryOnConflict(tc.ctx, tc.attempts, func(_ context.Context) error {
+				calls++
+				return tc.errs[calls-1]
+			})
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantCalls, calls)
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package watcher
+
+import (
+	"context"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// ResumeTokenStore persists the resume token of a change stream so that it can be resumed after a restart
+type ResumeTokenStore interface {
+	// Load returns the saved token, or nil if there is none
+	Load(ctx context.Context) (bson.Raw, error)
+	Save(ctx context.Context, token bson.Raw) error
+}
+
+var _ ResumeTokenStore = (*MemoryTokenStore)(nil)
+
+// MemoryTokenStore keeps the resume token in memory, it is safe for concurrent use
+type MemoryTokenStore struct {
+	mu    sync.RWMutex
+	token bson.Raw
+}
+
+func NewMemoryTokenStore() *MemoryTokenStore {
+	return &MemoryTokenStore{}
+}
+
+func (s *MemoryTokenStore) Load(_ context.Context) (bson.Raw, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.token, nil
+}
+
+func (s *MemoryTokenStore) Save(_ context.Context, token bson.Raw) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// the token is copied so that the caller can reuse its buffer
+	s.token = append(bson.Raw(nil), token...)
+	return nil
+}
@@ -0,0 +1,29 @@
+package watcher
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestMemoryTokenStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryTokenStore()
+
+	token, err := store.Load(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, token)
+
+	raw, err := bson.Marshal(bson.M{"_data": "826"})
+	require.NoError(t, err)
+	require.NoError(t, store.Save(ctx, raw))
+
+	// the saved token does not change with the buffer of the caller
+	raw[len(raw)-2] = 'x'
+	token, err = store.Load(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "826", token.Lookup("_data").StringValue())
+}
@@ -0,0 +1,53 @@
+package watcher
+
+import (
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type OperationType string
+
+const (
+	OperationTypeInsert       OperationType = "insert"
+	OperationTypeUpdate       OperationType = "update"
+	OperationTypeReplace      OperationType = "replace"
+	OperationTypeDelete       OperationType = "delete"
+	OperationTypeDrop         OperationType = "drop"
+	OperationTypeRename       OperationType = "rename"
+	OperationTypeDropDatabase OperationType = "dropDatabase"
+	OperationTypeInvalidate   OperationType = "invalidate"
+)
+
+// ChangeEvent is a change event of the documents of T
+// FullDocument is only set for updates if the full document option of the change stream is set
+type ChangeEvent[T any] struct {
+	// ID is the resume token of the event
+	ID                       bson.Raw           `bson:"_id"`
+	OperationType            OperationType      `bson:"operationType"`
+	FullDocument             *T                 `bson:"fullDocument,omitempty"`
+	FullDocumentBeforeChange *T                 `bson:"fullDocumentBeforeChange,omitempty"`
+	DocumentKey              bson.M             `bson:"documentKey,omitempty"`
+	UpdateDescription        *UpdateDescription `bson:"updateDescription,omitempty"`
+	Namespace                Namespace          `bson:"ns"`
+	ClusterTime              bson.Timestamp     `bson:"clusterTime"`
+}
+
+// ResumeToken returns the token used to resume the change stream after the event
+func (e *ChangeEvent[T]) ResumeToken() bson.Raw {
+	return e.ID
+}
+
+type UpdateDescription struct {
+	UpdatedFields   bson.M           `bson:"updatedFields"`
+	RemovedFields   []string         `bson:"removedFields"`
+	TruncatedArrays []TruncatedArray `bson:"truncatedArrays,omitempty"`
+}
+
+type TruncatedArray struct {
+	Field   string `bson:"field"`
+	NewSize int32  `bson:"newSize"`
+}
+
+type Namespace struct {
+	Database   string `bson:"db"`
+	Collection string `bson:"coll"`
+}
@@ -0,0 +1,167 @@
+package watcher
+
+import (
+	"context"
+	"iter"
+
+	mxerrors "github.com/matiniiuu/mongox/errors"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+func NewWatcher[T any](collection *mongo.Collection) *Watcher[T] {
+	return &Watcher[T]{collection: collection}
+}
+
+type Watcher[T any] struct {
+	collection *mongo.Collection
+	pipeline   any
+	tokenStore ResumeTokenStore
+}
+
+// Pipeline is used to set the pipeline of the change stream, e.g. the result of aggregation.StageBuilder.Build
+func (w *Watcher[T]) Pipeline(pipeline any) *Watcher[T] {
+	w.pipeline = pipeline
+	return w
+}
+
+// TokenStore is used to set the store of the resume token
+// The change stream resumes after the saved token unless the options set where to start
+func (w *Watcher[T]) TokenStore(store ResumeTokenStore) *Watcher[T] {
+	w.tokenStore = store
+	return w
+}
+
+// Watch opens a change stream on the collection
+func (w *Watcher[T]) Watch(ctx context.Context, opts ...options.Lister[options.ChangeStreamOptions]) (*Stream[T], error) {
+	pipeline := w.pipeline
+	if pipeline == nil {
+		pipeline = mongo.Pipeline{}
+	}
+	if w.tokenStore != nil {
+		token, err := w.tokenStore.Load(ctx)
+		if err != nil {
+			return nil, err
+		}
+		start, err := hasStart(opts...)
+		if err != nil {
+			return nil, err
+		}
+		if len(token) > 0 && !start {
+			opts = append(opts, options.ChangeStream().SetResumeAfter(token))
+		}
+	}
+
+	cs, err := w.collection.Watch(ctx, pipeline, opts...)
+	if err != nil {
+		return nil, mxerrors.Wrap(err)
+	}
+	return &Stream[T]{cs: cs, tokenStore: w.tokenStore}, nil
+}
+
+// hasStart reports whether the options set where the change stream starts
+func hasStart(opts ...options.Lister[options.ChangeStreamOptions]) (bool, error) {
+	csOpts := &options.ChangeStreamOptions{}
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		for _, fn := range opt.List() {
+			if err := fn(csOpts); err != nil {
+				return false, err
+			}
+		}
+	}
+	return csOpts.ResumeAfter != nil || csOpts.StartAfter != nil || csOpts.StartAtOperationTime != nil, nil
+}
+
+// Stream is a change stream which decodes the events into ChangeEvent[T]
+// If a token store is set, Next saves the resume token of the previous event before moving on,
+// so an event is only marked as processed once the next one is requested or Commit is called
+type Stream[T any] struct {
+	cs         *mongo.ChangeStream
+	tokenStore ResumeTokenStore
+	event      *ChangeEvent[T]
+	pending    bson.Raw
+	err        error
+}
+
+// Next blocks until the next event is available, it returns false if the stream fails or ctx is done
+func (s *Stream[T]) Next(ctx context.Context) bool {
+	return s.next(ctx, s.cs.Next)
+}
+
+// TryNext is the same as Next but returns false immediately if there is no event available
+func (s *Stream[T]) TryNext(ctx context.Context) bool {
+	return s.next(ctx, s.cs.TryNext)
+}
+
+func (s *Stream[T]) next(ctx context.Context, advance func(ctx context.Context) bool) bool {
+	if s.err != nil {
+		return false
+	}
+	if s.err = s.Commit(ctx); s.err != nil {
+		return false
+	}
+	if !advance(ctx) {
+		return false
+	}
+	event := new(ChangeEvent[T])
+	if s.err = mxerrors.Wrap(s.cs.Decode(event)); s.err != nil {
+		return false
+	}
+	s.event = event
+	s.pending = event.ID
+	return true
+}
+
+// Event returns the current event
+func (s *Stream[T]) Event() *ChangeEvent[T] {
+	return s.event
+}
+
+// Commit saves the resume token of the current event to the token store
+func (s *Stream[T]) Commit(ctx context.Context) error {
+	if s.tokenStore == nil || s.pending == nil {
+		return nil
+	}
+	if err := s.tokenStore.Save(ctx, s.pending); err != nil {
+		return err
+	}
+	s.pending = nil
+	return nil
+}
+
+// ResumeToken returns the latest resume token of the change stream
+func (s *Stream[T]) ResumeToken() bson.Raw {
+	return s.cs.ResumeToken()
+}
+
+// Err returns the error of the last Next or TryNext
+func (s *Stream[T]) Err() error {
+	if s.err != nil {
+		return s.err
+	}
+	return mxerrors.Wrap(s.cs.Err())
+}
+
+func (s *Stream[T]) Close(ctx context.Context) error {
+	return s.cs.Close(ctx)
+}
+
+// Iter returns an iterator over the events, the stream is closed once the iteration ends
+func (s *Stream[T]) Iter(ctx context.Context) iter.Seq2[*ChangeEvent[T], error] {
+	return func(yield func(*ChangeEvent[T], error) bool) {
+		defer s.Close(ctx)
+		for s.Next(ctx) {
+			if !yield(s.event, nil) {
+				return
+			}
+		}
+		if err := s.Err(); err != nil {
+			yield(nil, err)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+//go:build e2e
+
+package watcher
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/matiniiuu/mongox/builder/aggregation"
+	"github.com/matiniiuu/mongox/builder/query"
+	"github.com/matiniiuu/mongox/builder/update"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"go.mongodb.org/mongo-driver/v2/mongo/readpref"
+)
+
+func getCollection(t *testing.T) *mongo.Collection {
+	client, err := mongo.Connect(options.Client().ApplyURI("mongodb://localhost:27017").SetAuth(options.Credential{
+		Username:   "test",
+		Password:   "test",
+		AuthSource: "db-test",
+	}))
+	require.NoError(t, err)
+	require.NoError(t, client.Ping(context.Background(), readpref.Primary()))
+	return client.Database("db-test").Collection("test_user")
+}
+
+func TestWatcher_e2e_Watch(t *testing.T) {
+	collection := getCollection(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	store := NewMemoryTokenStore()
+	pipeline := aggregation.NewStageBuilder().Match(query.In("operationType", "insert", "update")).Build()
+	stream, err := NewWatcher[TestUser](collection).Pipeline(pipeline).TokenStore(store).
+		Watch(ctx, options.ChangeStream().SetFullDocument(options.UpdateLookup))
+	require.NoError(t, err)
+
+	user := &TestUser{Name: "Mingyong Chen", Age: 18}
+	insertOneResult, err := collection.InsertOne(ctx, user)
+	require.NoError(t, err)
+	defer func() {
+		_, err := collection.DeleteOne(context.Background(), query.Id(insertOneResult.InsertedID))
+		require.NoError(t, err)
+	}()
+	_, err = collection.UpdateOne(ctx, query.Id(insertOneResult.InsertedID), update.Set("age", 19))
+	require.NoError(t, err)
+
+	require.True(t, stream.Next(ctx))
+	event := stream.Event()
+	require.Equal(t, OperationTypeInsert, event.OperationType)
+	require.Equal(t, "Mingyong Chen", event.FullDocument.Name)
+	require.Equal(t, insertOneResult.InsertedID, event.DocumentKey["_id"])
+	insertToken := event.ResumeToken()
+
+	// the token of the insert event is saved once the next event is requested
+	token, err := store.Load(ctx)
+	require.NoError(t, err)
+	require.Nil(t, token)
+
+	require.True(t, stream.Next(ctx))
+	event = stream.Event()
+	require.Equal(t, OperationTypeUpdate, event.OperationType)
+	require.Equal(t, int64(19), event.FullDocument.Age)
+	require.Equal(t, int64(19), event.UpdateDescription.UpdatedFields["age"])
+	token, err = store.Load(ctx)
+	require.NoError(t, err)
+	require.Equal(t, insertToken, token)
+	require.NoError(t, stream.Close(ctx))
+
+	// resume after the insert event from the saved token
+	stream, err = NewWatcher[TestUser](collection).Pipeline(pipeline).TokenStore(store).Watch(ctx)
+	require.NoError(t, err)
+	for event, err := range stream.Iter(ctx) {
+		require.NoError(t, err)
+		require.Equal(t, OperationTypeUpdate, event.OperationType)
+		break
+	}
+}
@@ -0,0 +1,121 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type TestUser struct {
+	ID   bson.ObjectID `bson:"_id,omitempty"`
+	Name string        `bson:"name"`
+	Age  int64         `bson:"age"`
+}
+
+func TestNewWatcher(t *testing.T) {
+	store := NewMemoryTokenStore()
+	pipeline := mongo.Pipeline{{{Key: "$match", Value: bson.D{{Key: "operationType", Value: "insert"}}}}}
+	w := NewWatcher[TestUser](&mongo.Collection{}).Pipeline(pipeline).TokenStore(store)
+	assert.Equal(t, pipeline, w.pipeline)
+	assert.Equal(t, store, w.tokenStore)
+}
+
+func Test_hasStart(t *testing.T) {
+	testCases := []struct {
+		name string
+		opts []options.Lister[options.ChangeStreamOptions]
+		want bool
+	}{
+		{name: "no options", want: false},
+		{name: "nil option", opts: []options.Lister[options.ChangeStreamOptions]{nil}, want: false},
+		{name: "other options", opts: []options.Lister[options.ChangeStreamOptions]{options.ChangeStream().SetFullDocument(options.UpdateLookup)}, want: false},
+		{name: "resume after", opts: []options.Lister[options.ChangeStreamOptions]{options.ChangeStream().SetResumeAfter(bson.M{"_data": "826"})}, want: true},
+		{name: "start after", opts: []options.Lister[options.ChangeStreamOptions]{options.ChangeStream().SetStartAfter(bson.M{"_data": "826"})}, want: true},
+		{name: "start at operation time", opts: []options.Lister[options.ChangeStreamOptions]{options.ChangeStream().SetStartAtOperationTime(&bson.Timestamp{T: 1})}, want: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := hasStart(tc.opts...)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestChangeEvent_decode(t *testing.T) {
+	id := bson.NewObjectID()
+	raw, err := bson.Marshal(bson.D{
+		{Key: "_id", Value: bson.D{{Key: "_data", Value: "826"}}},
+		{Key: "operationType", Value: "update"},
+		{Key: "clusterTime", Value: bson.Timestamp{T: uint32(time.Now().Unix()), I: 1}},
+		{Key: "ns", Value: bson.D{{Key: "db", Value: "db-test"}, {Key: "coll", Value: "test_user"}}},
+		{Key: "documentKey", Value: bson.D{{Key: "_id", Value: id}}},
+		{Key: "fullDocument", Value: bson.D{{Key: "_id", Value: id}, {Key: "name", Value: "Mingyong Chen"}, {Key: "age", Value: int64(18)}}},
+		{Key: "updateDescription", Value: bson.D{
+			{Key: "updatedFields", Value: bson.D{{Key: "age", Value: int64(18)}}},
+			{Key: "removedFields", Value: bson.A{"email"}},
+		}},
+	})
+	require.NoError(t, err)
+
+	event := &ChangeEvent[TestUser]{}
+	require.NoError(t, bson.Unmarshal(raw, event))
+	assert.Equal(t, OperationTypeUpdate, event.OperationType)
+	assert.Equal(t, &TestUser{ID: id, Name: "Mingyong Chen", Age: 18}, event.FullDocument)
+	assert.Nil(t, event.FullDocumentBeforeChange)
+	assert.Equal(t, bson.M{"_id": id}, event.DocumentKey)
+	assert.Equal(t, bson.M{"age": int64(18)}, event.UpdateDescription.UpdatedFields)
+	assert.Equal(t, []string{"email"}, event.UpdateDescription.RemovedFields)
+	assert.Equal(t, Namespace{Database: "db-test", Collection: "test_user"}, event.Namespace)
+	assert.Equal(t, "826", event.ResumeToken().Lookup("_data").StringValue())
+}
+
+type failedTokenStore struct {
+	MemoryTokenStore
+}
+
+func (s *failedTokenStore) Save(_ context.Context, _ bson.Raw) error {
+	return errors.New("save error")
+}
+
+func TestStream_Commit(t *testing.T) {
+	ctx := context.Background()
+	raw, err := bson.Marshal(bson.M{"_data": "826"})
+	require.NoError(t, err)
+	token := bson.Raw(raw)
+
+	t.Run("no token store", func(t *testing.T) {
+		s := &Stream[TestUser]{pending: token}
+		assert.NoError(t, s.Commit(ctx))
+	})
+
+	t.Run("no pending token", func(t *testing.T) {
+		store := NewMemoryTokenStore()
+		s := &Stream[TestUser]{tokenStore: store}
+		assert.NoError(t, s.Commit(ctx))
+		got, _ := store.Load(ctx)
+		assert.Nil(t, got)
+	})
+
+	t.Run("save the pending token", func(t *testing.T) {
+		store := NewMemoryTokenStore()
+		s := &Stream[TestUser]{tokenStore: store, pending: token}
+		assert.NoError(t, s.Commit(ctx))
+		assert.Nil(t, s.pending)
+		got, _ := store.Load(ctx)
+		assert.Equal(t, token, got)
+	})
+
+	t.Run("save error", func(t *testing.T) {
+		s := &Stream[TestUser]{tokenStore: &failedTokenStore{}, pending: token}
+		assert.Equal(t, errors.New("save error"), s.Commit(ctx))
+		assert.Equal(t, token, s.pending)
+	})
+}